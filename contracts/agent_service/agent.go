@@ -3,24 +3,27 @@ package agent_service
 
 import (
 	"agent-service/domain/model"
+
+	"monorepo/pkg/validator"
 )
 
+func init() {
+	validator.RegisterStructValidation(validateCreateAgentRequest, CreateAgentRequest{})
+	validator.RegisterStructValidation(validateUpdateAgentRequest, UpdateAgentRequest{})
+}
+
 // CreateAgentRequest represents the request payload for creating a new agent
 type CreateAgentRequest struct {
 	AgentName     string  `json:"agent_name" validate:"required,min=1,max=255"`
 	AgentType     string  `json:"agent_type" validate:"required,oneof=IATA SUB_AGENT"`
-	ParentAgentID *string `json:"parent_agent_id,omitempty" validate:"required_if=AgentType SUB_AGENT,ulid"`
+	ParentAgentID *string `json:"parent_agent_id,omitempty" validate:"omitempty,ulid"`
 	Email         string  `json:"email" validate:"required,email"`
 }
 
-// GetAgentByIDRequest represents the request for getting an agent by ID
-type GetAgentByIDRequest struct {
-	ID string `validate:"required,ulid"`
-}
-
-// DeleteAgentRequest represents the request for deleting an agent
-type DeleteAgentRequest struct {
-	ID string `validate:"required,ulid"`
+// validateCreateAgentRequest checks that sub-agents, and only sub-agents, have a parent agent
+func validateCreateAgentRequest(sl validator.StructLevel) {
+	req := sl.Current().Interface().(CreateAgentRequest)
+	validateAgentHierarchy(sl, req.AgentType, req.ParentAgentID)
 }
 
 // UpdateAgentRequest represents the request payload for updating an existing agent
@@ -28,11 +31,30 @@ type UpdateAgentRequest struct {
 	ID            string  `json:"id" validate:"required,ulid"`
 	AgentName     string  `json:"agent_name,omitempty" validate:"omitempty,min=1,max=255"`
 	AgentType     string  `json:"agent_type,omitempty" validate:"omitempty,oneof=IATA SUB_AGENT"`
-	ParentAgentID *string `json:"parent_agent_id,omitempty" validate:"required_if=AgentType SUB_AGENT,ulid"`
+	ParentAgentID *string `json:"parent_agent_id,omitempty" validate:"omitempty,ulid"`
 	Email         string  `json:"email,omitempty" validate:"omitempty,email"`
 	IsActive      *bool   `json:"is_active,omitempty"`
 }
 
+// validateUpdateAgentRequest checks the parent agent against the agent type when the type is changed
+func validateUpdateAgentRequest(sl validator.StructLevel) {
+	req := sl.Current().Interface().(UpdateAgentRequest)
+	if req.AgentType == "" {
+		return
+	}
+	validateAgentHierarchy(sl, req.AgentType, req.ParentAgentID)
+}
+
+// validateAgentHierarchy reports a missing parent agent for sub-agents and a parent agent set on other agent types
+func validateAgentHierarchy(sl validator.StructLevel, agentType string, parentAgentID *string) {
+	switch {
+	case agentType == model.AgentTypeSubAgent && parentAgentID == nil:
+		sl.ReportError(parentAgentID, "parent_agent_id", "ParentAgentID", "required_if", "AgentType "+model.AgentTypeSubAgent)
+	case agentType != model.AgentTypeSubAgent && parentAgentID != nil:
+		sl.ReportError(agentType, "agent_type", "AgentType", "eq", model.AgentTypeSubAgent)
+	}
+}
+
 type AgentsListResponse struct {
 	Agents []AgentResponse `json:"agents"`
 }
@@ -84,6 +106,6 @@ type CreateSubAgentWithUserRequest struct {
 	AgentEmail      string `json:"agent_email" validate:"required,email"`
 	UserName        string `json:"user_name" validate:"required,min=1,max=255"`
 	UserEmail       string `json:"user_email" validate:"required,email"`
-	UserPassword    string `json:"user_password" validate:"required,min=8"`
+	UserPassword    string `json:"user_password" validate:"required,strongpassword"`
 	PasswordConfirm string `json:"password_confirm" validate:"required,min=8,eqfield=UserPassword"`
 }
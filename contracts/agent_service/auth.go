@@ -9,10 +9,13 @@ type LoginRequest struct {
 
 // LoginResponse represents the response payload for user login
 type LoginResponse struct {
+	TokenType          string `json:"token_type"`
 	AccessToken        string `json:"access_token"`
 	RefreshToken       string `json:"refresh_token"`
 	AccessTokenExpire  int64  `json:"access_token_expire"`
 	RefreshTokenExpire int64  `json:"refresh_token_expire"`
+	// SessionID identifies the login session in stateful mode, for use on logout
+	SessionID string `json:"session_id,omitempty"`
 }
 
 // RefreshTokenRequest represents the request payload for token refresh
@@ -22,10 +25,41 @@ type RefreshTokenRequest struct {
 
 // RefreshTokenResponse represents the response payload for token refresh
 type RefreshTokenResponse struct {
+	TokenType          string `json:"token_type"`
 	AccessToken        string `json:"access_token"`
 	RefreshToken       string `json:"refresh_token"`
 	AccessTokenExpire  int64  `json:"access_token_expire"`
 	RefreshTokenExpire int64  `json:"refresh_token_expire"`
+	// SessionID identifies the session of the new tokens in stateful mode, for use on logout
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// LogoutRequest represents the request payload for logout
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	SessionID    string `json:"session_id,omitempty"`
+}
+
+// LogoutResponse represents the response payload for logout
+type LogoutResponse struct {
+	Message string `json:"message"`
+}
+
+// SessionResponse represents a login session of the authenticated user
+type SessionResponse struct {
+	SessionID  string `json:"session_id"`
+	DeviceInfo string `json:"device_info"`
+	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
+	LastSeen   string `json:"last_seen"`
+	Status     string `json:"status"`
+}
+
+// SessionsResponse represents a page of the authenticated user's sessions
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	// NextCursor is the cursor of the next page; zero means there are no more pages
+	NextCursor uint64 `json:"next_cursor"`
 }
 
 // ForgotPasswordRequest represents the request payload for forgot password
@@ -41,7 +75,7 @@ type ForgotPasswordResponse struct {
 // ResetPasswordRequest represents the request payload for reset password
 type ResetPasswordRequest struct {
 	Token    string `json:"token" validate:"required"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required,strongpassword"`
 }
 
 // ResetPasswordResponse represents the response payload for reset password
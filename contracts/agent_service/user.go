@@ -12,10 +12,30 @@ type CreateUserRequest struct {
 	AgentID         *string `json:"agent_id,omitempty" validate:"omitempty,ulid"`
 	Name            string  `json:"name" validate:"required,min=1,max=255"`
 	Email           string  `json:"email" validate:"required,email"`
-	Password        string  `json:"password" validate:"required,min=8"`
+	Password        string  `json:"password" validate:"required,strongpassword"`
 	PasswordConfirm string  `json:"password_confirm" validate:"required,min=8,eqfield=Password"`
 }
 
+// BulkCreateUsersRequest represents the request payload for importing several users at once
+// Rows are validated one by one, so an invalid row is reported without rejecting the batch
+// A batch holds at most 100 users
+type BulkCreateUsersRequest struct {
+	Users []CreateUserRequest `json:"users" validate:"required,min=1,max=100"`
+}
+
+// BulkUserFailureResponse describes a row of a bulk import that was not created
+type BulkUserFailureResponse struct {
+	Index  int    `json:"index"`
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
+}
+
+// BulkCreateUsersResponse represents the response payload for a bulk user import
+type BulkCreateUsersResponse struct {
+	Created []UserResponse            `json:"created"`
+	Failed  []BulkUserFailureResponse `json:"failed"`
+}
+
 // UserResponse represents the response payload for a user
 type UserResponse struct {
 	ID        string         `json:"id"`
@@ -42,28 +62,18 @@ type AgentResponse struct {
 	UpdatedAt     string          `json:"updated_at"`
 }
 
-// GetUserByIDRequest represents the request for getting a user by ID
-type GetUserByIDRequest struct {
-	ID string `validate:"required,ulid"`
-}
-
 // GetUserByEmailRequest represents the request for getting a user by email
 type GetUserByEmailRequest struct {
 	Email string `validate:"required,email"`
 }
 
-// DeleteUserRequest represents the request for deleting a user
-type DeleteUserRequest struct {
-	ID string `validate:"required,ulid"`
-}
-
 // UpdateUserRequest represents the request payload for updating an existing user
 type UpdateUserRequest struct {
 	ID              string  `json:"id" validate:"required,ulid"`
 	AgentID         *string `json:"agent_id,omitempty" validate:"omitempty,ulid"`
 	Name            string  `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	Email           string  `json:"email,omitempty" validate:"omitempty,email"`
-	Password        string  `json:"password,omitempty" validate:"omitempty,min=8"`
+	Password        string  `json:"password,omitempty" validate:"omitempty,strongpassword"`
 	PasswordConfirm string  `json:"password_confirm,omitempty" validate:"omitempty,min=8,eqfield=Password"`
 	IsActive        *bool   `json:"is_active,omitempty"`
 }
@@ -132,6 +142,34 @@ func AgentModelToResponse(agent *model.Agent) *AgentResponse {
 	return resp
 }
 
+// AgentNodeToResponse converts an agent tree to an AgentResponse with nested children
+func AgentNodeToResponse(node *model.AgentNode) *AgentResponse {
+	resp := AgentModelToResponse(node.Agent)
+	if len(node.Children) > 0 {
+		resp.Children = make([]AgentResponse, len(node.Children))
+		for i, child := range node.Children {
+			resp.Children[i] = *AgentNodeToResponse(child)
+		}
+	}
+	return resp
+}
+
+// BulkResultToResponse converts model.BulkResult to BulkCreateUsersResponse
+func BulkResultToResponse(result model.BulkResult) *BulkCreateUsersResponse {
+	resp := &BulkCreateUsersResponse{
+		Created: UserModelsToResponses(result.Created),
+		Failed:  make([]BulkUserFailureResponse, len(result.Failed)),
+	}
+	for i, failure := range result.Failed {
+		resp.Failed[i] = BulkUserFailureResponse{
+			Index:  failure.Index,
+			Email:  failure.Email,
+			Reason: failure.Reason,
+		}
+	}
+	return resp
+}
+
 // UserModelsToResponses converts slice of model.User to slice of UserResponse
 func UserModelsToResponses(users []*model.User) []UserResponse {
 	responses := make([]UserResponse, len(users))
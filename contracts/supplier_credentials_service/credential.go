@@ -19,25 +19,49 @@ type UpdateCredentialRequest struct {
 	Credentials string `json:"credentials" validate:"required"`
 }
 
-// GetCredentialByIDRequest represents the request for getting a credential by ID
-type GetCredentialByIDRequest struct {
-	ID string `validate:"required,ulid"`
-}
-
-// DeleteCredentialRequest represents the request for deleting a credential
-type DeleteCredentialRequest struct {
-	ID string `validate:"required,ulid"`
+// RotateCredentialRequest represents the request payload for rotating a credential
+// OverlapSeconds keeps the previous credentials valid for that long, up to 30 days; zero replaces them at once
+type RotateCredentialRequest struct {
+	ID             string `json:"id" validate:"required,ulid"`
+	Credentials    string `json:"credentials" validate:"required"`
+	OverlapSeconds int    `json:"overlap_seconds" validate:"gte=0,lte=2592000"`
 }
 
 // CredentialResponse represents the response payload for a credential
 type CredentialResponse struct {
-	ID          string            `json:"id"`
-	IataAgentID string            `json:"iata_agent_id"`
-	SupplierID  string            `json:"supplier_id"`
-	Supplier    *SupplierResponse `json:"supplier,omitempty"`
-	Credentials string            `json:"credentials"`
-	CreatedAt   string            `json:"created_at"`
-	UpdatedAt   string            `json:"updated_at"`
+	ID                  string            `json:"id"`
+	IataAgentID         string            `json:"iata_agent_id"`
+	SupplierID          string            `json:"supplier_id"`
+	Supplier            *SupplierResponse `json:"supplier,omitempty"`
+	Credentials         string            `json:"credentials"`
+	Masked              bool              `json:"masked"`                         // Set when Credentials and PreviousCredentials are redacted
+	PreviousCredentials string            `json:"previous_credentials,omitempty"` // Set during a rotation overlap, until PreviousExpiresAt
+	PreviousExpiresAt   string            `json:"previous_expires_at,omitempty"`
+	CreatedAt           string            `json:"created_at"`
+	UpdatedAt           string            `json:"updated_at"`
+}
+
+// CredentialFailureResponse describes a credential that could not be decrypted
+type CredentialFailureResponse struct {
+	ID          string `json:"id"`
+	IataAgentID string `json:"iata_agent_id"`
+	SupplierID  string `json:"supplier_id"`
+	Reason      string `json:"reason"`
+}
+
+// InternalCredentialListResponse represents the response payload for the internal credential list
+// Credentials that could not be decrypted are listed in Failures instead of failing the whole response
+type InternalCredentialListResponse struct {
+	Credentials []*CredentialResponse        `json:"credentials"`
+	Failures    []*CredentialFailureResponse `json:"failures"`
+}
+
+// ReencryptCredentialsResponse represents the response payload for re-encrypting credentials with the primary key
+type ReencryptCredentialsResponse struct {
+	Reencrypted int                          `json:"reencrypted"`
+	Unchanged   int                          `json:"unchanged"`
+	Conflicts   int                          `json:"conflicts"`
+	Failures    []*CredentialFailureResponse `json:"failures"`
 }
 
 // SupplierResponse represents the response payload for a supplier
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 )
@@ -13,6 +16,13 @@ const (
 	StatusError   = "error"
 )
 
+// CodeValidationFailed is the error code of responses sent by ValidationError
+const CodeValidationFailed = "VALIDATION_FAILED"
+
+// StatusClientClosedRequest is the non-standard status used when the client
+// went away or the request context expired before a response could be produced
+const StatusClientClosedRequest = 499
+
 // Response represents the standard API response format
 type Response struct {
 	RequestID string `json:"request_id"`
@@ -50,21 +60,46 @@ type ErrorDetail struct {
 	Message string `json:"message"`
 }
 
+// ProblemContentType is the content type of RFC 7807 problem details responses
+const ProblemContentType = "application/problem+json"
+
+// Problem represents an RFC 7807 problem details object
+type Problem struct {
+	// Type is a URI identifying the problem type; defaults to "about:blank"
+	Type string `json:"type"`
+	// Title is a short summary of the problem type; defaults to the status text for "about:blank"
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code; set from the status passed to ProblemDetails
+	Status int `json:"status,omitempty"`
+	// Detail explains this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI identifying this occurrence of the problem
+	Instance string `json:"instance,omitempty"`
+	// RequestID is an extension member holding the request ID, as in Response
+	RequestID string `json:"request_id,omitempty"`
+}
+
 // Api interface defines methods for standard API responses
 type Api interface {
 	Success(ctx context.Context, w http.ResponseWriter, data any)
+	SuccessWithETag(ctx context.Context, w http.ResponseWriter, r *http.Request, data any)
 	Created(ctx context.Context, w http.ResponseWriter, data any)
 	Error(ctx context.Context, w http.ResponseWriter, statusCode int, apiErr *Error)
 	SuccessWithMeta(ctx context.Context, w http.ResponseWriter, data any, meta *Meta)
 	SuccessWithCode(ctx context.Context, w http.ResponseWriter, data any)
 	SuccessWithCodeAndMeta(ctx context.Context, w http.ResponseWriter, data any, meta *Meta)
 	BadRequest(ctx context.Context, w http.ResponseWriter, message string)
+	InvalidBody(ctx context.Context, w http.ResponseWriter, err error)
 	Unauthorized(ctx context.Context, w http.ResponseWriter, message string)
 	Forbidden(ctx context.Context, w http.ResponseWriter, message string)
 	NotFound(ctx context.Context, w http.ResponseWriter, message string)
 	Conflict(ctx context.Context, w http.ResponseWriter, message string)
+	PayloadTooLarge(ctx context.Context, w http.ResponseWriter, message string)
+	TooManyRequests(ctx context.Context, w http.ResponseWriter, message string, retryAfter time.Duration)
 	InternalServerError(ctx context.Context, w http.ResponseWriter, message string)
+	ClientClosedRequest(ctx context.Context, w http.ResponseWriter, message string)
 	ValidationError(ctx context.Context, w http.ResponseWriter, details []ErrorDetail)
+	ProblemDetails(ctx context.Context, w http.ResponseWriter, status int, problem Problem)
 }
 
 type api struct {
@@ -226,6 +261,28 @@ func (a *api) Conflict(ctx context.Context, w http.ResponseWriter, message strin
 	a.Error(ctx, w, http.StatusConflict, apiErr)
 }
 
+// TooManyRequests sends a 429 Too Many Requests response
+// The Retry-After header is set from retryAfter, and omitted when it is not positive
+func (a *api) TooManyRequests(ctx context.Context, w http.ResponseWriter, message string, retryAfter time.Duration) {
+	apiErr := &Error{
+		Code:    "TOO_MANY_REQUESTS",
+		Message: message,
+	}
+
+	SetRetryAfter(w, retryAfter)
+	a.Error(ctx, w, http.StatusTooManyRequests, apiErr)
+}
+
+// SetRetryAfter sets the Retry-After header to retryAfter in whole seconds, rounded up
+// It does nothing when retryAfter is not positive, and must be called before the status is written
+func SetRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		return
+	}
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
 // InternalServerError sends a 500 Internal Server Error response
 func (a *api) InternalServerError(ctx context.Context, w http.ResponseWriter, message string) {
 	apiErr := &Error{
@@ -236,13 +293,59 @@ func (a *api) InternalServerError(ctx context.Context, w http.ResponseWriter, me
 	a.Error(ctx, w, http.StatusInternalServerError, apiErr)
 }
 
+// ClientClosedRequest sends a 499 Client Closed Request response
+func (a *api) ClientClosedRequest(ctx context.Context, w http.ResponseWriter, message string) {
+	apiErr := &Error{
+		Code:    "CLIENT_CLOSED_REQUEST",
+		Message: message,
+	}
+
+	a.Error(ctx, w, StatusClientClosedRequest, apiErr)
+}
+
+// ValidationDetails converts validator errors, keyed by dotted field path, to error details sorted by field
+func ValidationDetails(validationErrors map[string]string) []ErrorDetail {
+	details := make([]ErrorDetail, 0, len(validationErrors))
+	for field, message := range validationErrors {
+		details = append(details, ErrorDetail{
+			Field:   field,
+			Message: message,
+		})
+	}
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].Field < details[j].Field
+	})
+	return details
+}
+
 // ValidationError sends a 422 Unprocessable Entity response with validation details
+// The error code is always CodeValidationFailed
 func (a *api) ValidationError(ctx context.Context, w http.ResponseWriter, details []ErrorDetail) {
 	apiErr := &Error{
-		Code:    "VALIDATION_ERROR",
+		Code:    CodeValidationFailed,
 		Message: "Validation failed",
 		Details: details,
 	}
 
 	a.Error(ctx, w, http.StatusUnprocessableEntity, apiErr)
 }
+
+// ProblemDetails sends an RFC 7807 application/problem+json response
+// It is an opt-in alternative to Error for endpoints whose clients expect problem details
+func (a *api) ProblemDetails(ctx context.Context, w http.ResponseWriter, status int, problem Problem) {
+	problem.Status = status
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" && problem.Type == "about:blank" {
+		problem.Title = http.StatusText(status)
+	}
+	problem.RequestID = a.getRequestID(ctx)
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		// Log error but don't expose it to client
+		_ = err
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
@@ -36,6 +38,50 @@ func TestApi_Success(t *testing.T) {
 	assert.NotNil(t, response.Data, "Expected data in response")
 }
 
+func TestApi_SuccessWithETag(t *testing.T) {
+	api := New()
+	ctx := context.Background()
+	data := map[string]string{"id": "agent-1"}
+
+	w := httptest.NewRecorder()
+	api.SuccessWithETag(ctx, w, httptest.NewRequest(http.MethodGet, "/agents/agent-1", nil), data)
+
+	assert.Equal(t, http.StatusOK, w.Code, "Expected status OK")
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag, "Expected an ETag header")
+	assert.True(t, strings.HasPrefix(etag, `W/"`), "Expected a weak ETag")
+
+	var response Response
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(t, err, "Failed to decode response")
+	assert.Equal(t, StatusSuccess, response.Status)
+
+	t.Run("matching If-None-Match returns 304", func(t *testing.T) {
+		for _, header := range []string{etag, strings.TrimPrefix(etag, "W/"), `"other", ` + etag, "*"} {
+			req := httptest.NewRequest(http.MethodGet, "/agents/agent-1", nil)
+			req.Header.Set("If-None-Match", header)
+			w := httptest.NewRecorder()
+
+			api.SuccessWithETag(ctx, w, req, data)
+
+			assert.Equal(t, http.StatusNotModified, w.Code, "Expected Not Modified for %q", header)
+			assert.Empty(t, w.Body.String(), "Not Modified should have no body")
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("changed resource returns 200", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/agents/agent-1", nil)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+
+		api.SuccessWithETag(ctx, w, req, map[string]string{"id": "agent-1", "name": "changed"})
+
+		assert.Equal(t, http.StatusOK, w.Code, "Expected status OK for a changed resource")
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
+
 func TestApi_Created(t *testing.T) {
 	api := New()
 	w := httptest.NewRecorder()
@@ -157,6 +203,36 @@ func TestApi_Conflict(t *testing.T) {
 	assert.Equal(t, "CONFLICT", response.Error.Code, "Expected error code CONFLICT")
 }
 
+func TestApi_TooManyRequests(t *testing.T) {
+	tests := []struct {
+		name               string
+		retryAfter         time.Duration
+		expectedRetryAfter string
+	}{
+		{name: "whole seconds", retryAfter: 30 * time.Second, expectedRetryAfter: "30"},
+		{name: "rounded up", retryAfter: 1500 * time.Millisecond, expectedRetryAfter: "2"},
+		{name: "no retry hint", retryAfter: 0, expectedRetryAfter: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := New()
+			w := httptest.NewRecorder()
+
+			api.TooManyRequests(context.Background(), w, "Too many requests", tt.retryAfter)
+
+			assert.Equal(t, http.StatusTooManyRequests, w.Code, "Expected status Too Many Requests")
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get("Retry-After"), "Unexpected Retry-After header")
+
+			var response Response
+			err := json.NewDecoder(w.Body).Decode(&response)
+			require.NoError(t, err, "Failed to decode response")
+
+			assert.Equal(t, "TOO_MANY_REQUESTS", response.Error.Code, "Expected error code TOO_MANY_REQUESTS")
+		})
+	}
+}
+
 func TestApi_InternalServerError(t *testing.T) {
 	api := New()
 	w := httptest.NewRecorder()
@@ -173,6 +249,22 @@ func TestApi_InternalServerError(t *testing.T) {
 	assert.Equal(t, "INTERNAL_SERVER_ERROR", response.Error.Code, "Expected error code INTERNAL_SERVER_ERROR")
 }
 
+func TestApi_ClientClosedRequest(t *testing.T) {
+	api := New()
+	w := httptest.NewRecorder()
+	ctx := context.Background()
+
+	api.ClientClosedRequest(ctx, w, "Request cancelled")
+
+	assert.Equal(t, StatusClientClosedRequest, w.Code, "Expected status ClientClosedRequest")
+
+	var response Response
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(t, err, "Failed to decode response")
+
+	assert.Equal(t, "CLIENT_CLOSED_REQUEST", response.Error.Code, "Expected error code CLIENT_CLOSED_REQUEST")
+}
+
 func TestApi_ValidationError(t *testing.T) {
 	api := New()
 	w := httptest.NewRecorder()
@@ -190,10 +282,63 @@ func TestApi_ValidationError(t *testing.T) {
 	err := json.NewDecoder(w.Body).Decode(&response)
 	require.NoError(t, err, "Failed to decode response")
 
-	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code, "Expected error code VALIDATION_ERROR")
+	assert.Equal(t, CodeValidationFailed, response.Error.Code, "Expected error code VALIDATION_FAILED")
 	assert.Len(t, response.Error.Details, 2, "Expected 2 error details")
 }
 
+func TestApi_ProblemDetails(t *testing.T) {
+	api := New()
+	w := httptest.NewRecorder()
+	ctx := context.Background()
+
+	api.ProblemDetails(ctx, w, http.StatusConflict, Problem{
+		Type:     "https://example.com/problems/duplicate-agent",
+		Title:    "Duplicate agent",
+		Detail:   "An agent with this email already exists",
+		Instance: "/api/v1/agents",
+	})
+
+	assert.Equal(t, http.StatusConflict, w.Code, "Expected status Conflict")
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"), "Expected problem+json content type")
+
+	var problem Problem
+	err := json.NewDecoder(w.Body).Decode(&problem)
+	require.NoError(t, err, "Failed to decode response")
+
+	assert.Equal(t, "https://example.com/problems/duplicate-agent", problem.Type)
+	assert.Equal(t, "Duplicate agent", problem.Title)
+	assert.Equal(t, http.StatusConflict, problem.Status, "Status should be set from the status code")
+	assert.Equal(t, "An agent with this email already exists", problem.Detail)
+	assert.Equal(t, "/api/v1/agents", problem.Instance)
+}
+
+func TestApi_ProblemDetails_Defaults(t *testing.T) {
+	api := New()
+	w := httptest.NewRecorder()
+
+	api.ProblemDetails(context.Background(), w, http.StatusNotFound, Problem{Detail: "Agent not found"})
+
+	var problem Problem
+	err := json.NewDecoder(w.Body).Decode(&problem)
+	require.NoError(t, err, "Failed to decode response")
+
+	assert.Equal(t, "about:blank", problem.Type, "Type should default to about:blank")
+	assert.Equal(t, "Not Found", problem.Title, "Title should default to the status text")
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+}
+
+func TestValidationDetails(t *testing.T) {
+	details := ValidationDetails(map[string]string{
+		"user.email":  "Email is required",
+		"agent_email": "Agent Email is required",
+	})
+
+	assert.Equal(t, []ErrorDetail{
+		{Field: "agent_email", Message: "Agent Email is required"},
+		{Field: "user.email", Message: "Email is required"},
+	}, details, "Details should be sorted by field")
+}
+
 func TestApi_SuccessWithMeta(t *testing.T) {
 	api := New()
 	w := httptest.NewRecorder()
@@ -288,3 +433,162 @@ func TestApi_SuccessWithCodeAndMeta(t *testing.T) {
 	assert.Equal(t, 1, response.Meta.Pagination.Page, "Expected page 1")
 	assert.Equal(t, StatusSuccess, response.Status, "Expected status success")
 }
+
+func TestRequireContextString(t *testing.T) {
+	type contextKey string
+	const key contextKey = "agent_iata_id"
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		expectedValue  string
+		expectedOK     bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:          "value present",
+			ctx:           context.WithValue(context.Background(), key, "IATA123"),
+			expectedValue: "IATA123",
+			expectedOK:    true,
+		},
+		{
+			name:           "value missing",
+			ctx:            context.Background(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:           "value empty",
+			ctx:            context.WithValue(context.Background(), key, ""),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:           "value wrong type",
+			ctx:            context.WithValue(context.Background(), key, 123),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			var value string
+			var ok bool
+			require.NotPanics(t, func() {
+				value, ok = RequireContextString(tt.ctx, key, w)
+			}, "RequireContextString should not panic")
+
+			assert.Equal(t, tt.expectedOK, ok, "Unexpected ok result")
+			assert.Equal(t, tt.expectedValue, value, "Unexpected value")
+
+			if tt.expectedOK {
+				assert.Equal(t, 0, w.Body.Len(), "No response should be written on success")
+				return
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "Unexpected status code")
+
+			var response Response
+			err := json.NewDecoder(w.Body).Decode(&response)
+			require.NoError(t, err, "Failed to decode response")
+			assert.Equal(t, tt.expectedCode, response.Error.Code, "Unexpected error code")
+		})
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	t.Run("captures status and bytes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec := NewResponseRecorder(w)
+
+		New().NotFound(context.Background(), rec, "Agent not found")
+
+		assert.Equal(t, http.StatusNotFound, rec.Status(), "Expected recorded status 404")
+		assert.Equal(t, w.Body.Len(), rec.BytesWritten(), "Expected recorded bytes to match the body")
+		assert.Equal(t, http.StatusNotFound, w.Code, "Expected status to reach the wrapped writer")
+	})
+
+	t.Run("defaults to 200", func(t *testing.T) {
+		rec := NewResponseRecorder(httptest.NewRecorder())
+		assert.Equal(t, http.StatusOK, rec.Status(), "Expected 200 when nothing was written")
+
+		_, err := rec.Write([]byte("ok"))
+		require.NoError(t, err)
+		rec.WriteHeader(http.StatusInternalServerError)
+
+		assert.Equal(t, http.StatusOK, rec.Status(), "Expected implicit 200 to be kept after a late WriteHeader")
+		assert.Equal(t, 2, rec.BytesWritten())
+	})
+}
+
+func TestMaxBodySize(t *testing.T) {
+	// next decodes the body like the handlers do and reports decode failures with InvalidBody
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			New().InvalidBody(r.Context(), w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := MaxBodySize(32)(next)
+
+	tests := []struct {
+		name           string
+		body           string
+		unknownLength  bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "body within limit",
+			body:           `{"name":"ok"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "declared length over limit",
+			body:           `{"name":"` + strings.Repeat("a", 64) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   "PAYLOAD_TOO_LARGE",
+		},
+		{
+			name:           "streamed body over limit",
+			body:           `{"name":"` + strings.Repeat("a", 64) + `"}`,
+			unknownLength:  true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   "PAYLOAD_TOO_LARGE",
+		},
+		{
+			name:           "malformed body within limit",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "BAD_REQUEST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, "Unexpected status code")
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var response Response
+			err := json.NewDecoder(w.Body).Decode(&response)
+			require.NoError(t, err, "Failed to decode response")
+			assert.Equal(t, tt.expectedCode, response.Error.Code, "Unexpected error code")
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// DefaultMaxBodySize is the request body limit used by MaxBodySize when no positive limit is given
+const DefaultMaxBodySize int64 = 1 << 20 // 1 MiB
+
+// MaxBodySize limits request bodies to limit bytes using http.MaxBytesReader
+// Requests declaring a larger Content-Length are rejected with a 413 before the handler runs;
+// bodies that exceed the limit while streaming make reads fail, which InvalidBody reports as a 413
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				New().PayloadTooLarge(r.Context(), w, "Request body too large")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsBodyTooLarge reports whether err was caused by a request body exceeding the MaxBodySize limit
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// PayloadTooLarge sends a 413 Request Entity Too Large response
+func (a *api) PayloadTooLarge(ctx context.Context, w http.ResponseWriter, message string) {
+	apiErr := &Error{
+		Code:    "PAYLOAD_TOO_LARGE",
+		Message: message,
+	}
+
+	a.Error(ctx, w, http.StatusRequestEntityTooLarge, apiErr)
+}
+
+// InvalidBody sends the response for a request body that could not be decoded
+// It sends a 413 if the body exceeded the MaxBodySize limit and a 400 otherwise
+func (a *api) InvalidBody(ctx context.Context, w http.ResponseWriter, err error) {
+	if IsBodyTooLarge(err) {
+		a.PayloadTooLarge(ctx, w, "Request body too large")
+		return
+	}
+	a.BadRequest(ctx, w, "Invalid request body")
+}
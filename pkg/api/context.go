@@ -0,0 +1,20 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequireContextString extracts a required string value from the context
+// If the value is missing, empty or not a string, a 401 Unauthorized response is written,
+// so a route registered without the middleware that sets the value fails closed instead of panicking
+// Returns the value and true on success, or an empty string and false when a response was written
+func RequireContextString(ctx context.Context, key any, w http.ResponseWriter) (string, bool) {
+	value, ok := ctx.Value(key).(string)
+	if !ok || value == "" {
+		New().Unauthorized(ctx, w, "Missing required request context")
+		return "", false
+	}
+
+	return value, true
+}
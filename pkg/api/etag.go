@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ETag returns a weak entity tag computed over the JSON encoding of data
+func ETag(data any) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+// Tags are compared weakly, so W/"x" and "x" match, and "*" matches any tag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// SuccessWithETag sends a successful response with data and a weak ETag computed over data
+// It sends 304 Not Modified without a body when the request's If-None-Match matches the ETag
+func (a *api) SuccessWithETag(ctx context.Context, w http.ResponseWriter, r *http.Request, data any) {
+	etag, err := ETag(data)
+	if err != nil {
+		a.Success(ctx, w, data)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	a.Success(ctx, w, data)
+}
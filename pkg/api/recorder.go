@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+)
+
+// ResponseRecorder wraps an http.ResponseWriter to capture the status code and number of bytes written
+// It lets middleware such as access loggers report on responses written by handlers
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int
+	wroteHeader  bool
+}
+
+// NewResponseRecorder wraps w in a ResponseRecorder
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w}
+}
+
+// WriteHeader records the status code and sends it to the wrapped writer
+// Only the first call is recorded, matching net/http which ignores superfluous calls
+func (r *ResponseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the number of bytes written, implying a 200 status if no header was written
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytesWritten += n
+	return n, err
+}
+
+// Status returns the recorded status code
+// It returns 200 if the handler wrote nothing, as net/http does for such responses
+func (r *ResponseRecorder) Status() int {
+	if !r.wroteHeader {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// BytesWritten returns the number of body bytes written
+func (r *ResponseRecorder) BytesWritten() int {
+	return r.bytesWritten
+}
+
+// Flush sends buffered data to the client if the wrapped writer supports it
+func (r *ResponseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach its optional interfaces
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,111 @@
+// Package auth provides HTTP middleware that authorizes requests from JWT access tokens
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"monorepo/pkg/api"
+	"monorepo/pkg/contextkeys"
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/logger"
+)
+
+// AgentTypeIATA is the agent_type claim of IATA (top-level) agents
+const AgentTypeIATA = "IATA"
+
+// IATAAgentMiddleware validates the bearer access token and requires it to belong to an IATA agent
+// The agent ID from the token is stored in the context under contextkeys.AgentIATAIDKey
+// Returns a 401 status code for missing or invalid tokens and a 403 status code for sub-agents
+func IATAAgentMiddleware(jwtClient jwt.JWTClient, logger logger.LoggerInterface, apiClient api.Api) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			tokenString, ok := BearerToken(w, r, logger, apiClient)
+			if !ok {
+				return
+			}
+
+			claims, err := jwtClient.ValidateAccessToken(tokenString)
+			if err != nil {
+				if errors.Is(err, jwt.ErrInvalidTokenTypeErr) || errors.Is(err, jwt.ErrMissingTokenTypeErr) || errors.Is(err, jwt.ErrUnknownTokenTypeErr) {
+					logger.WarnContext(ctx, "Access token with invalid token type", "error", err)
+					apiClient.Unauthorized(ctx, w, "Invalid token type")
+					return
+				}
+				logger.WarnContext(ctx, "Invalid access token", "error", err)
+				apiClient.Unauthorized(ctx, w, "Invalid access token")
+				return
+			}
+
+			if claims.AgentID == "" {
+				logger.WarnContext(ctx, "Access token has no agent", "user_id", claims.UserID)
+				apiClient.Forbidden(ctx, w, "Access denied: no agent associated with token")
+				return
+			}
+
+			if claims.AgentType != AgentTypeIATA {
+				logger.WarnContext(ctx, "Access denied: agent is not an IATA agent", "agent_id", claims.AgentID, "agent_type", claims.AgentType)
+				apiClient.Forbidden(ctx, w, "Access denied: IATA agent required")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(contextkeys.WithAgentIATAID(ctx, claims.AgentID)))
+		})
+	}
+}
+
+// RequireFreshToken rejects access tokens whose user authenticated more than maxAge ago, forcing recent re-authentication
+// Freshness is read from the auth_time claim, which refreshing tokens does not reset
+// It should be used on sensitive operations such as password changes, after the middleware authenticating the request
+// Returns a 401 status code if the token is too old or invalid
+func RequireFreshToken(maxAge time.Duration, jwtClient jwt.JWTClient, logger logger.LoggerInterface, apiClient api.Api) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			tokenString, ok := BearerToken(w, r, logger, apiClient)
+			if !ok {
+				return
+			}
+
+			if _, err := jwtClient.ValidateAccessTokenMaxAge(tokenString, maxAge); err != nil {
+				if errors.Is(err, jwt.ErrTokenTooOldErr) {
+					logger.WarnContext(ctx, "Access token too old for sensitive operation", "max_age", maxAge.String())
+					apiClient.Unauthorized(ctx, w, "Recent authentication required")
+					return
+				}
+				logger.WarnContext(ctx, "Invalid access token", "error", err)
+				apiClient.Unauthorized(ctx, w, "Invalid access token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BearerToken extracts the token from the Authorization header
+// Writes a 401 response and returns false if the header is missing or malformed
+func BearerToken(w http.ResponseWriter, r *http.Request, logger logger.LoggerInterface, apiClient api.Api) (string, bool) {
+	ctx := r.Context()
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		logger.WarnContext(ctx, "Missing Authorization header")
+		apiClient.Unauthorized(ctx, w, "Missing Authorization header")
+		return "", false
+	}
+
+	// Check for Bearer token format
+	const bearerPrefix = "Bearer "
+	if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
+		logger.WarnContext(ctx, "Invalid Authorization header format")
+		apiClient.Unauthorized(ctx, w, "Invalid Authorization header format")
+		return "", false
+	}
+
+	return authHeader[len(bearerPrefix):], true
+}
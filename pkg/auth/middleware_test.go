@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"monorepo/pkg/api"
+	"monorepo/pkg/contextkeys"
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/logger"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJWTClient(t *testing.T) jwt.JWTClient {
+	client, err := jwt.New(
+		jwt.WithAccessTokenSecret("test-access-secret"),
+		jwt.WithRefreshTokenSecret("test-refresh-secret"),
+		jwt.WithAccessTokenExpiry(15*time.Minute),
+		jwt.WithRefreshTokenExpiry(time.Hour),
+	)
+	require.NoError(t, err, "Failed to create JWT client")
+	return client
+}
+
+// serveIATA runs a request with the given Authorization header through IATAAgentMiddleware
+// It returns the response and the IATA agent ID seen by the next handler
+func serveIATA(t *testing.T, jwtClient jwt.JWTClient, authHeader string) (*httptest.ResponseRecorder, string) {
+	var seenAgentIATAID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenAgentIATAID, _ = contextkeys.AgentIATAID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/credentials", nil)
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	w := httptest.NewRecorder()
+	IATAAgentMiddleware(jwtClient, logger.NoOpLogger(), api.New())(next).ServeHTTP(w, req)
+	return w, seenAgentIATAID
+}
+
+func TestIATAAgentMiddleware(t *testing.T) {
+	jwtClient := newTestJWTClient(t)
+
+	t.Run("IATA agent allowed", func(t *testing.T) {
+		token, err := jwtClient.GenerateAccessToken("user-1", "agent-iata", AgentTypeIATA)
+		require.NoError(t, err, "GenerateAccessToken should not return error")
+
+		w, agentIATAID := serveIATA(t, jwtClient, "Bearer "+token)
+		assert.Equal(t, http.StatusOK, w.Code, "IATA agent should be allowed")
+		assert.Equal(t, "agent-iata", agentIATAID, "Agent ID should be injected into the context")
+	})
+
+	t.Run("sub-agent forbidden", func(t *testing.T) {
+		token, err := jwtClient.GenerateAccessToken("user-2", "agent-sub", "SUB_AGENT")
+		require.NoError(t, err, "GenerateAccessToken should not return error")
+
+		w, agentIATAID := serveIATA(t, jwtClient, "Bearer "+token)
+		assert.Equal(t, http.StatusForbidden, w.Code, "Sub-agent should be forbidden")
+		assert.Empty(t, agentIATAID, "Next handler should not run")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		w, _ := serveIATA(t, jwtClient, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Missing token should be unauthorized")
+	})
+
+	t.Run("refresh token rejected", func(t *testing.T) {
+		token, err := jwtClient.GenerateRefreshToken("user-1", "agent-iata", AgentTypeIATA)
+		require.NoError(t, err, "GenerateRefreshToken should not return error")
+
+		w, _ := serveIATA(t, jwtClient, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Refresh token should be unauthorized")
+	})
+}
+
+func TestRequireFreshToken(t *testing.T) {
+	jwtClient := newTestJWTClient(t)
+
+	// serve runs a request with the given access token through RequireFreshToken
+	serve := func(token string) *httptest.ResponseRecorder {
+		next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/password", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		RequireFreshToken(5*time.Minute, jwtClient, logger.NoOpLogger(), api.New())(next).ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("token from a recent login allowed", func(t *testing.T) {
+		token, err := jwtClient.GenerateAccessToken("user-1", "agent-iata", AgentTypeIATA)
+		require.NoError(t, err, "GenerateAccessToken should not return error")
+
+		assert.Equal(t, http.StatusOK, serve(token).Code, "A recent login should be fresh")
+	})
+
+	t.Run("token refreshed after an old login rejected", func(t *testing.T) {
+		// The access token is issued now, but the user last logged in ten minutes ago
+		authTime := gojwt.NewNumericDate(time.Now().Add(-10 * time.Minute))
+		token, err := jwtClient.ReissueAccessToken(&jwt.TokenClaims{UserID: "user-1", AgentID: "agent-iata", AgentType: AgentTypeIATA, AuthTime: authTime})
+		require.NoError(t, err, "ReissueAccessToken should not return error")
+
+		assert.Equal(t, http.StatusUnauthorized, serve(token).Code, "A refreshed token should not count as a recent login")
+	})
+
+	t.Run("invalid token rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("invalid.token").Code, "An invalid token should be unauthorized")
+	})
+}
@@ -0,0 +1,305 @@
+// Package bootstrap provides the infrastructure wiring and server lifecycle shared by all services
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/kafka"
+	"monorepo/pkg/logger"
+	"monorepo/pkg/postgres"
+	"monorepo/pkg/redis"
+)
+
+// DefaultShutdownTimeout is used when ServerConfig.ShutdownTimeout is not set
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	// Port specifies the port number the server will listen on; 0 picks a free port
+	Port int
+	// ReadTimeout defines the maximum duration for reading the entire request
+	ReadTimeout time.Duration
+	// WriteTimeout defines the maximum duration before timing out writes of the response
+	WriteTimeout time.Duration
+	// ShutdownTimeout bounds graceful shutdown of the server and all registered closers
+	ShutdownTimeout time.Duration
+	// Protocols specifies the HTTP protocols accepted by the server; nil uses net/http defaults
+	Protocols *http.Protocols
+	// TLSCertFile and TLSKeyFile enable TLS when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Config holds the infrastructure a service needs
+// Optional components are skipped when their config is nil
+type Config struct {
+	// Name specifies the name of the application
+	Name string
+	// Version specifies the version of the application
+	Version string
+	// Server contains HTTP server settings
+	Server ServerConfig
+	// Postgres contains PostgreSQL settings
+	Postgres *postgres.Config
+	// Models are auto-migrated when Migrate is true
+	Models  []any
+	Migrate bool
+	// Redis contains Redis settings
+	Redis *redis.Config
+	// Kafka contains Kafka settings
+	Kafka *kafka.Config
+	// JWT contains JWT settings; stateful mode requires Redis
+	JWT *jwt.TokenConfig
+	// SessionJanitorInterval is how often inactive sessions are cleaned up in stateful mode
+	SessionJanitorInterval time.Duration
+	// VerifyTokenStore checks the refresh token store on startup in stateful mode
+	VerifyTokenStore bool
+}
+
+// storeCheckTimeout bounds the startup check of the refresh token store
+const storeCheckTimeout = 5 * time.Second
+
+// closer is a named cleanup function run on shutdown
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// App holds the initialized infrastructure handles and manages the server lifecycle
+type App struct {
+	// Logger is the application logger
+	Logger logger.LoggerInterface
+	// Postgres is the PostgreSQL client, nil when not configured
+	Postgres postgres.PostgresClient
+	// Redis is the Redis client, nil when not configured
+	Redis redis.RedisClient
+	// Kafka is the Kafka client, nil when not configured
+	Kafka kafka.KafkaClient
+	// JWT is the JWT client, nil when not configured
+	JWT jwt.JWTClient
+
+	config  Config
+	closers []closer
+
+	mu   sync.Mutex
+	addr net.Addr
+}
+
+// New initializes the infrastructure described by cfg
+// Resources opened before a failure are closed before the error is returned
+func New(cfg Config, appLogger logger.LoggerInterface) (*App, error) {
+	app := &App{
+		Logger: appLogger,
+		config: cfg,
+	}
+
+	if err := app.init(); err != nil {
+		_ = app.Close(context.Background())
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// init opens each configured component and registers its closer
+func (a *App) init() error {
+	cfg := a.config
+
+	if cfg.Postgres != nil {
+		postgresClient, err := postgres.NewPostgresClientWithLogger(*cfg.Postgres, a.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		a.Postgres = postgresClient
+		a.OnShutdown("postgres", func(context.Context) error { return postgresClient.Close() })
+
+		if cfg.Migrate {
+			if err := postgresClient.Migrate(cfg.Models...); err != nil {
+				return fmt.Errorf("failed to migrate database: %w", err)
+			}
+		}
+	}
+
+	if cfg.Redis != nil {
+		redisClient, err := redis.NewWithConfig(*cfg.Redis)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Redis client: %w", err)
+		}
+		a.Redis = redisClient
+		a.OnShutdown("redis", func(context.Context) error { return redisClient.Close() })
+	}
+
+	if cfg.Kafka != nil {
+		kafkaClient, err := kafka.NewWithConfig(*cfg.Kafka)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Kafka client: %w", err)
+		}
+		a.Kafka = kafkaClient
+		a.OnShutdown("kafka", func(context.Context) error { return kafkaClient.Close() })
+	}
+
+	if cfg.JWT != nil {
+		var jwtClient jwt.JWTClient
+		var err error
+		if cfg.JWT.Stateful {
+			if a.Redis == nil {
+				return errors.New("failed to initialize JWT client: stateful mode requires Redis")
+			}
+			jwtClient, err = jwt.NewStatefulWithRedisConfig(a.Redis, *cfg.JWT)
+		} else {
+			jwtClient, err = jwt.NewWithConfig(*cfg.JWT)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to initialize JWT client: %w", err)
+		}
+		a.JWT = jwtClient
+
+		if cfg.VerifyTokenStore && jwtClient.IsStateful() {
+			if err := a.verifyTokenStore(); err != nil {
+				return err
+			}
+		}
+
+		// Start cleanup of inactive sessions (no-op in stateless mode)
+		janitorCtx, stopJanitor := context.WithCancel(context.Background())
+		jwtClient.StartSessionJanitor(janitorCtx, cfg.SessionJanitorInterval)
+		a.OnShutdown("session janitor", func(context.Context) error {
+			stopJanitor()
+			return nil
+		})
+	}
+
+	return nil
+}
+
+// verifyTokenStore fails if the refresh token store is unreachable and warns if it is empty
+func (a *App) verifyTokenStore() error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeCheckTimeout)
+	defer cancel()
+
+	err := a.JWT.StoreHealthy(ctx)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, jwt.ErrStoreEmptyErr):
+		a.Logger.Warn("Refresh token store is empty; if tokens were issued before, the store may have been flushed and users must log in again")
+		return nil
+	default:
+		return fmt.Errorf("refresh token store check failed: %w", err)
+	}
+}
+
+// OnShutdown registers a cleanup function that runs when the app shuts down
+// Closers run in reverse registration order, after the HTTP server has stopped
+func (a *App) OnShutdown(name string, fn func(ctx context.Context) error) {
+	a.closers = append(a.closers, closer{name: name, close: fn})
+}
+
+// Close runs all registered closers in reverse order
+// Every closer runs even if an earlier one fails; failures are logged and joined
+func (a *App) Close(ctx context.Context) error {
+	var errs []error
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		c := a.closers[i]
+		if err := c.close(ctx); err != nil {
+			a.Logger.Warn("Error closing resource", "resource", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", c.name, err))
+		}
+	}
+	a.closers = nil
+
+	return errors.Join(errs...)
+}
+
+// Addr returns the address the server is listening on, or nil before it has started
+func (a *App) Addr() net.Addr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.addr
+}
+
+// newServer builds the HTTP server for handler from the server settings
+func (a *App) newServer(handler http.Handler) *http.Server {
+	serverCfg := a.config.Server
+	return &http.Server{
+		Handler:      handler,
+		ReadTimeout:  serverCfg.ReadTimeout,
+		WriteTimeout: serverCfg.WriteTimeout,
+		Protocols:    serverCfg.Protocols,
+	}
+}
+
+// Run serves handler until SIGINT or SIGTERM is received, then shuts everything down gracefully
+func (a *App) Run(handler http.Handler) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return a.RunContext(ctx, handler)
+}
+
+// RunContext serves handler until ctx is done or the server fails
+// It then shuts down the server and runs all registered closers within the shutdown timeout
+func (a *App) RunContext(ctx context.Context, handler http.Handler) error {
+	serverCfg := a.config.Server
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(serverCfg.Port))
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to listen: %w", err), a.Close(context.Background()))
+	}
+
+	server := a.newServer(handler)
+
+	a.mu.Lock()
+	a.addr = listener.Addr()
+	a.mu.Unlock()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.Logger.Info("Service starting", "name", a.config.Name, "version", a.config.Version, "addr", listener.Addr().String())
+		if serverCfg.TLSCertFile != "" && serverCfg.TLSKeyFile != "" {
+			serveErr <- server.ServeTLS(listener, serverCfg.TLSCertFile, serverCfg.TLSKeyFile)
+		} else {
+			serveErr <- server.Serve(listener)
+		}
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.Logger.Info("Shutting down server...")
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Error("Failed to start server", "error", err)
+			runErr = fmt.Errorf("server failed: %w", err)
+		}
+	}
+
+	shutdownTimeout := serverCfg.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Shutdown the server gracefully
+	var shutdownErr error
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		a.Logger.Error("Server forced to shutdown", "error", err)
+		shutdownErr = fmt.Errorf("server forced to shutdown: %w", err)
+	}
+
+	closeErr := a.Close(shutdownCtx)
+
+	a.Logger.Info("Server exited")
+	return errors.Join(runErr, shutdownErr, closeErr)
+}
@@ -0,0 +1,277 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp creates an App without infrastructure listening on a free port
+func newTestApp(t *testing.T) *App {
+	app, err := New(Config{
+		Name: "test-service",
+		Server: ServerConfig{
+			Port:            0,
+			ShutdownTimeout: 5 * time.Second,
+		},
+	}, logger.NoOpLogger())
+	require.NoError(t, err, "New() should not fail")
+	return app
+}
+
+// closeRecorder records the order in which closers run
+type closeRecorder struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (r *closeRecorder) closer(name string, err error) func(context.Context) error {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.closed = append(r.closed, name)
+		return err
+	}
+}
+
+func (r *closeRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.closed...)
+}
+
+// waitForAddr waits until the app is listening and returns its base URL
+func waitForAddr(t *testing.T, app *App) string {
+	require.Eventually(t, func() bool { return app.Addr() != nil }, 5*time.Second, 10*time.Millisecond, "Server should start listening")
+	port := app.Addr().(*net.TCPAddr).Port
+	return "http://127.0.0.1:" + strconv.Itoa(port)
+}
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestRunContext_ServesAndShutsDown(t *testing.T) {
+	app := newTestApp(t)
+	recorder := &closeRecorder{}
+	app.OnShutdown("first", recorder.closer("first", nil))
+	app.OnShutdown("second", recorder.closer("second", nil))
+	app.OnShutdown("third", recorder.closer("third", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunContext(ctx, okHandler) }()
+
+	baseURL := waitForAddr(t, app)
+	resp, err := http.Get(baseURL + "/")
+	require.NoError(t, err, "Request to running server should not fail")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected handler response")
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err, "RunContext() should shut down cleanly")
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunContext() did not return after cancellation")
+	}
+
+	assert.Equal(t, []string{"third", "second", "first"}, recorder.names(), "Closers should run in reverse order")
+
+	_, err = http.Get(baseURL + "/")
+	assert.Error(t, err, "Server should no longer accept requests")
+}
+
+func TestRun_Signal(t *testing.T) {
+	app := newTestApp(t)
+	recorder := &closeRecorder{}
+	app.OnShutdown("resource", recorder.closer("resource", nil))
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run(okHandler) }()
+
+	// The signal handler is registered before the server starts listening
+	waitForAddr(t, app)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM), "Failed to send SIGTERM")
+
+	select {
+	case err := <-done:
+		require.NoError(t, err, "Run() should shut down cleanly on SIGTERM")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after SIGTERM")
+	}
+
+	assert.Equal(t, []string{"resource"}, recorder.names(), "Closers should run on signal")
+}
+
+func TestRunContext_CloserErrors(t *testing.T) {
+	app := newTestApp(t)
+	recorder := &closeRecorder{}
+	closeErr := errors.New("close failed")
+	app.OnShutdown("first", recorder.closer("first", nil))
+	app.OnShutdown("failing", recorder.closer("failing", closeErr))
+	app.OnShutdown("last", recorder.closer("last", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunContext(ctx, okHandler) }()
+
+	waitForAddr(t, app)
+	cancel()
+
+	err := <-done
+	require.Error(t, err, "RunContext() should report closer failures")
+	assert.ErrorIs(t, err, closeErr, "Closer error should be wrapped")
+	assert.Equal(t, []string{"last", "failing", "first"}, recorder.names(), "All closers should run despite failures")
+}
+
+func TestRunContext_ListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err, "Failed to reserve port")
+	defer listener.Close()
+
+	app, err := New(Config{
+		Server: ServerConfig{Port: listener.Addr().(*net.TCPAddr).Port},
+	}, logger.NoOpLogger())
+	require.NoError(t, err, "New() should not fail")
+
+	recorder := &closeRecorder{}
+	app.OnShutdown("resource", recorder.closer("resource", nil))
+
+	err = app.RunContext(context.Background(), okHandler)
+	require.Error(t, err, "RunContext() should fail when the port is in use")
+	assert.Equal(t, []string{"resource"}, recorder.names(), "Closers should run when the server cannot start")
+}
+
+func TestNew_StatefulJWTRequiresRedis(t *testing.T) {
+	_, err := New(Config{
+		JWT: &jwt.TokenConfig{
+			AccessTokenSecret:  "access-secret",
+			RefreshTokenSecret: "refresh-secret",
+			Stateful:           true,
+		},
+	}, logger.NoOpLogger())
+	require.Error(t, err, "Stateful JWT without Redis should fail")
+}
+
+func TestNew_StatelessJWT(t *testing.T) {
+	app, err := New(Config{
+		JWT: &jwt.TokenConfig{
+			AccessTokenSecret:  "access-secret",
+			RefreshTokenSecret: "refresh-secret",
+			AccessTokenExpiry:  time.Minute,
+			RefreshTokenExpiry: time.Hour,
+		},
+	}, logger.NoOpLogger())
+	require.NoError(t, err, "New() should not fail")
+	require.NotNil(t, app.JWT, "JWT client should be initialized")
+	assert.False(t, app.JWT.IsStateful(), "JWT client should be stateless")
+
+	require.NoError(t, app.Close(context.Background()), "Close() should not fail")
+}
+
+// h2cProtocols accepts HTTP/1.1 and HTTP/2 over cleartext
+func h2cProtocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return protocols
+}
+
+func TestNewServer_AppliesSettings(t *testing.T) {
+	protocols := h2cProtocols()
+	app, err := New(Config{
+		Name: "test-service",
+		Server: ServerConfig{
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 4 * time.Second,
+			Protocols:    protocols,
+		},
+	}, logger.NoOpLogger())
+	require.NoError(t, err, "New() should not fail")
+
+	server := app.newServer(okHandler)
+	assert.Equal(t, 3*time.Second, server.ReadTimeout, "Read timeout should be applied")
+	assert.Equal(t, 4*time.Second, server.WriteTimeout, "Write timeout should be applied")
+	require.NotNil(t, server.Protocols, "Protocols should be applied")
+	assert.True(t, server.Protocols.HTTP1(), "HTTP/1.1 should be enabled")
+	assert.True(t, server.Protocols.UnencryptedHTTP2(), "h2c should be enabled")
+
+	assert.Nil(t, newTestApp(t).newServer(okHandler).Protocols, "Protocols should default to net/http defaults")
+}
+
+func TestRunContext_H2C(t *testing.T) {
+	// h2cClient only speaks HTTP/2 over cleartext, so it fails against servers without h2c
+	h2cClient := func() *http.Client {
+		protocols := new(http.Protocols)
+		protocols.SetUnencryptedHTTP2(true)
+		return &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+	}
+	protoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+
+	tests := []struct {
+		name      string
+		protocols *http.Protocols
+		wantH2C   bool
+	}{
+		{name: "enabled", protocols: h2cProtocols(), wantH2C: true},
+		{name: "default", protocols: nil, wantH2C: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, err := New(Config{
+				Name: "test-service",
+				Server: ServerConfig{
+					ShutdownTimeout: 5 * time.Second,
+					Protocols:       tt.protocols,
+				},
+			}, logger.NoOpLogger())
+			require.NoError(t, err, "New() should not fail")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- app.RunContext(ctx, protoHandler) }()
+			defer func() {
+				cancel()
+				require.NoError(t, <-done, "RunContext() should shut down cleanly")
+			}()
+
+			client := h2cClient()
+			defer client.CloseIdleConnections()
+
+			resp, err := client.Get(waitForAddr(t, app) + "/")
+			if !tt.wantH2C {
+				if err == nil {
+					resp.Body.Close()
+				}
+				require.Error(t, err, "h2c request should fail when h2c is not enabled")
+				return
+			}
+			require.NoError(t, err, "h2c request should not fail")
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err, "Failed to read response body")
+			assert.Equal(t, "HTTP/2.0", resp.Proto, "Response should be served over HTTP/2")
+			assert.Equal(t, "HTTP/2.0", string(body), "Request should reach the handler over HTTP/2")
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Package contextkeys defines typed context keys for the identity of the authenticated caller
+// Values are set by the authentication middleware and read by handlers and usecases
+package contextkeys
+
+import "context"
+
+// Key is the type of the context keys defined by this package
+// A distinct type keeps these keys from colliding with plain string keys set by other packages
+type Key string
+
+const (
+	// UserIDKey holds the ID of the authenticated user
+	UserIDKey Key = "user_id"
+	// AgentIDKey holds the ID of the agent the authenticated user belongs to
+	AgentIDKey Key = "agent_id"
+	// AgentTypeKey holds the type of the agent the authenticated user belongs to
+	AgentTypeKey Key = "agent_type"
+	// AgentIATAIDKey holds the ID of the authenticated IATA agent
+	AgentIATAIDKey Key = "agent_iata_id"
+)
+
+// WithUserID returns a copy of ctx carrying the user ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, UserIDKey, userID)
+}
+
+// UserID returns the user ID stored in ctx
+func UserID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, UserIDKey)
+}
+
+// WithAgentID returns a copy of ctx carrying the agent ID
+func WithAgentID(ctx context.Context, agentID string) context.Context {
+	return context.WithValue(ctx, AgentIDKey, agentID)
+}
+
+// AgentID returns the agent ID stored in ctx
+func AgentID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, AgentIDKey)
+}
+
+// WithAgentType returns a copy of ctx carrying the agent type
+func WithAgentType(ctx context.Context, agentType string) context.Context {
+	return context.WithValue(ctx, AgentTypeKey, agentType)
+}
+
+// AgentType returns the agent type stored in ctx
+func AgentType(ctx context.Context) (string, bool) {
+	return stringValue(ctx, AgentTypeKey)
+}
+
+// WithAgentIATAID returns a copy of ctx carrying the IATA agent ID
+func WithAgentIATAID(ctx context.Context, agentIATAID string) context.Context {
+	return context.WithValue(ctx, AgentIATAIDKey, agentIATAID)
+}
+
+// AgentIATAID returns the IATA agent ID stored in ctx
+func AgentIATAID(ctx context.Context) (string, bool) {
+	return stringValue(ctx, AgentIATAIDKey)
+}
+
+// stringValue returns the string stored in ctx under key
+// It reports false if the value is missing, empty or not a string
+func stringValue(ctx context.Context, key Key) (string, bool) {
+	value, ok := ctx.Value(key).(string)
+	return value, ok && value != ""
+}
@@ -0,0 +1,46 @@
+package contextkeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessors(t *testing.T) {
+	tests := []struct {
+		name string
+		with func(context.Context, string) context.Context
+		get  func(context.Context) (string, bool)
+		key  Key
+	}{
+		{name: "user ID", with: WithUserID, get: UserID, key: UserIDKey},
+		{name: "agent ID", with: WithAgentID, get: AgentID, key: AgentIDKey},
+		{name: "agent type", with: WithAgentType, get: AgentType, key: AgentTypeKey},
+		{name: "IATA agent ID", with: WithAgentIATAID, get: AgentIATAID, key: AgentIATAIDKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := tt.get(tt.with(context.Background(), "value-1"))
+			assert.True(t, ok, "Stored value should be found")
+			assert.Equal(t, "value-1", value, "Stored value should be returned")
+
+			_, ok = tt.get(context.Background())
+			assert.False(t, ok, "Missing value should not be found")
+
+			_, ok = tt.get(tt.with(context.Background(), ""))
+			assert.False(t, ok, "Empty value should not be found")
+
+			_, ok = tt.get(context.WithValue(context.Background(), tt.key, 123))
+			assert.False(t, ok, "Non-string value should not be found")
+		})
+	}
+}
+
+func TestKeysDoNotCollideWithStringKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "user_id", "plain-string-key")
+
+	_, ok := UserID(ctx)
+	assert.False(t, ok, "A plain string key should not be read as the typed key")
+}
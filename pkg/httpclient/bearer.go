@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TokenProvider returns the bearer token to send with a request
+// When RefreshRequested(ctx) is true the previous token was rejected and a fresh one must be fetched
+type TokenProvider func(ctx context.Context) (string, error)
+
+// refreshKey is the context key marking a token request as a refresh
+type refreshKey struct{}
+
+// RefreshRequested reports whether a TokenProvider is asked to bypass any cached token
+func RefreshRequested(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// bearerAuth sets the Authorization header on every attempt from the token provider
+// With retryOnUnauthorized, a 401 response is retried once with a freshly fetched token
+func (c *Client) bearerAuth(next RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+
+		token, err := c.tokenProvider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := next(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || !c.retryOnUnauthorized {
+			return resp, err
+		}
+
+		// The body was consumed by the first attempt and cannot be sent again
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		token, err = c.tokenProvider(context.WithValue(ctx, refreshKey{}, true))
+		if err != nil {
+			// Keep the 401 so callers see why the request failed
+			return resp, nil
+		}
+
+		retry := req.Clone(ctx)
+		if req.GetBody != nil {
+			retry.Body, err = req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+		}
+		retry.Header.Set("Authorization", "Bearer "+token)
+
+		// Drain and close the rejected response so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if c.logger != nil {
+			c.logger.Info("Retrying HTTP request with refreshed bearer token", "method", req.Method, "url", req.URL.String())
+		}
+
+		return next(retry)
+	}
+}
@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitState is the state of a circuit breaker
+type circuitState int
+
+const (
+	// circuitClosed lets all requests through
+	circuitClosed circuitState = iota
+	// circuitOpen fails all requests fast until the open duration has elapsed
+	circuitOpen
+	// circuitHalfOpen lets a single trial request through to test recovery
+	circuitHalfOpen
+)
+
+// circuitBreaker trips after a number of consecutive failures
+// It is safe for concurrent use
+type circuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	// probing is set while the half-open trial request is in flight
+	probing bool
+	// now is overridable in tests
+	now func() time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		now:              time.Now,
+	}
+}
+
+// allow reports whether a request may be sent
+// Once the open duration has elapsed, one trial request is allowed through
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.openDuration {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return nil
+	case circuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// recordSuccess closes the breaker and resets the failure count
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = circuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// recordFailure counts a failure and opens the breaker once the threshold is reached
+// A failed trial request reopens the breaker immediately
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == circuitHalfOpen || b.failures >= b.failureThreshold {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// recordCanceled releases the trial slot without counting the outcome,
+// for requests abandoned by the caller
+func (b *circuitBreaker) recordCanceled() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
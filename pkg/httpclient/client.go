@@ -2,24 +2,44 @@ package httpclient
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"monorepo/pkg/logger"
 )
 
+// ErrResponseTooLarge is returned when a response body exceeds the limit set with WithMaxResponseBytes
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// RoundTripFunc sends a single HTTP request attempt
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc to add cross-cutting behavior such as auth headers or metrics
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
 // HTTPClient defines the interface for HTTP client operations
 type HTTPClient interface {
 	Get(ctx context.Context, path string, headers map[string]string) (*http.Response, error)
 	Post(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, error)
+	PostMultipart(ctx context.Context, path string, fields map[string]string, files map[string]io.Reader, headers map[string]string) (*http.Response, error)
+	PostForm(ctx context.Context, path string, form url.Values, headers map[string]string) (*http.Response, error)
 	Put(ctx context.Context, path string, data interface{}, headers map[string]string) (*http.Response, error)
 	Delete(ctx context.Context, path string, headers map[string]string) (*http.Response, error)
 	GetJSON(ctx context.Context, path string, result interface{}, headers map[string]string) error
+	GetJSONQuery(ctx context.Context, path string, query url.Values, result interface{}, headers map[string]string) error
 	PostJSON(ctx context.Context, path string, data interface{}, result interface{}, headers map[string]string) error
 	Do(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, error)
+	DoWithTimeout(ctx context.Context, method, path string, body io.Reader, headers map[string]string, timeout time.Duration) (*http.Response, error)
 	BaseURL() string
 	Timeout() time.Duration
 	RetryCount() int
@@ -33,7 +53,31 @@ type Client struct {
 	headers    map[string]string
 	timeout    time.Duration
 	retryCount int
-	logger     *slog.Logger
+	// retryableStatuses are response status codes that are retried like transport errors
+	retryableStatuses map[int]bool
+	// gzipRequests compresses JSON request bodies sent by Post, Put and PostJSON
+	gzipRequests bool
+	// maxResponseBytes caps the response bodies read by the JSON helpers; 0 means unlimited
+	maxResponseBytes int64
+	// transport, maxIdleConnsPerHost and keepAlive tune the connection pool; zero values keep the defaults
+	transport           *http.Transport
+	maxIdleConnsPerHost int
+	keepAlive           *bool
+	// middlewares wrap every request attempt; the first one is the outermost
+	middlewares []Middleware
+	// correlationHeader is the request header carrying the correlation ID from the context; empty disables it
+	correlationHeader string
+	// tokenProvider supplies the bearer token sent with every request; nil disables it
+	tokenProvider TokenProvider
+	// retryOnUnauthorized retries a 401 response once with a refreshed bearer token
+	retryOnUnauthorized bool
+	// bodyLogging logs request and response bodies at debug level
+	bodyLogging bool
+	// redactedHeaders are header names, in canonical form, whose values are masked in logs
+	redactedHeaders map[string]bool
+	// breaker fails requests fast while a downstream is down; nil disables it
+	breaker *circuitBreaker
+	logger  *slog.Logger
 }
 
 // New creates a new HTTP client with the provided options
@@ -53,6 +97,7 @@ func New(opts ...Option) HTTPClient {
 
 	// Update the client's timeout with the configured timeout
 	client.client.Timeout = client.timeout
+	client.applyTransport()
 
 	// Ensure headers map is properly initialized and immutable after this point
 	if client.headers == nil {
@@ -73,7 +118,22 @@ func (c *Client) Post(ctx context.Context, path string, data interface{}, header
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
-	return c.do(ctx, http.MethodPost, path, bytes.NewBuffer(body), headers)
+	reqBody, headers, err := c.encodeBody(body, headers)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, http.MethodPost, path, reqBody, headers)
+}
+
+// PostForm performs an HTTP POST request with an application/x-www-form-urlencoded body
+func (c *Client) PostForm(ctx context.Context, path string, form url.Values, headers map[string]string) (*http.Response, error) {
+	formHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		formHeaders[k] = v
+	}
+	formHeaders["Content-Type"] = "application/x-www-form-urlencoded"
+
+	return c.do(ctx, http.MethodPost, path, strings.NewReader(form.Encode()), formHeaders)
 }
 
 // Put performs an HTTP PUT request with JSON data
@@ -82,7 +142,11 @@ func (c *Client) Put(ctx context.Context, path string, data interface{}, headers
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
-	return c.do(ctx, http.MethodPut, path, bytes.NewBuffer(body), headers)
+	reqBody, headers, err := c.encodeBody(body, headers)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, http.MethodPut, path, reqBody, headers)
 }
 
 // Delete performs an HTTP DELETE request
@@ -92,13 +156,63 @@ func (c *Client) Delete(ctx context.Context, path string, headers map[string]str
 
 // do performs an HTTP request with the given method, path, and body
 func (c *Client) do(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	return c.doWithClient(ctx, c.client, method, path, body, headers)
+}
+
+// doWithClient performs an HTTP request using the given http.Client, guarded by the circuit breaker if configured
+func (c *Client) doWithClient(ctx context.Context, httpClient *http.Client, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if c.breaker == nil {
+		return c.send(ctx, httpClient, method, path, body, headers)
+	}
+
+	if err := c.breaker.allow(); err != nil {
+		if c.logger != nil {
+			c.logger.Warn("HTTP request rejected by circuit breaker", "method", method, "url", c.baseURL+path)
+		}
+		return nil, err
+	}
+
+	resp, err := c.send(ctx, httpClient, method, path, body, headers)
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		// The caller gave up; this says nothing about the downstream
+		c.breaker.recordCanceled()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		c.breaker.recordFailure()
+	default:
+		c.breaker.recordSuccess()
+	}
+
+	return resp, err
+}
+
+// send performs an HTTP request using the given http.Client, retrying as configured
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
 	url := c.baseURL + path
 
+	// Buffer the body so it can be logged and re-sent
+	var bodyBytes []byte
+	logBodies := c.bodyLogging && c.logger != nil
+	if logBodies && body != nil {
+		var err error
+		bodyBytes, body, err = bufferBody(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Streaming bodies such as multipart uploads provide their own way to replay the body
+	if replayable, ok := body.(interface {
+		GetBody() (io.ReadCloser, error)
+	}); ok {
+		req.GetBody = replayable.GetBody
+	}
+
 	// Set content type if body is provided
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -114,22 +228,46 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 		req.Header.Set(k, v)
 	}
 
+	// Propagate the correlation ID unless the caller set the header explicitly
+	correlationID := ""
+	if c.correlationHeader != "" {
+		correlationID = req.Header.Get(c.correlationHeader)
+		if correlationID == "" {
+			correlationID = logger.CorrelationID(ctx)
+			if correlationID != "" {
+				req.Header.Set(c.correlationHeader, correlationID)
+			}
+		}
+	}
+
 	// Log the request if logger is configured
 	if c.logger != nil {
-		c.logger.Info("HTTP request", "method", method, "url", url, "headers", headers)
+		c.logger.Info("HTTP request", "method", method, "url", url, "headers", c.redactHeaders(headers), "correlationID", correlationID)
+	}
+	if logBodies {
+		c.logger.Debug("HTTP request body", "method", method, "url", url, "body", string(bodyBytes))
 	}
 
 	// Perform the request with retries if configured
+	roundTrip := c.chain(httpClient.Do)
 	var resp *http.Response
 	var lastErr error
 
 	for i := 0; i <= c.retryCount; i++ {
-		resp, lastErr = c.client.Do(req)
-		if lastErr == nil {
+		// Rewind the body for retries; NewRequest sets GetBody for in-memory bodies
+		if i > 0 && req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+		}
+
+		resp, lastErr = roundTrip(req)
+		if lastErr == nil && !c.retryableStatuses[resp.StatusCode] {
 			break
 		}
 
-		// If this was the last attempt, break and return the error
+		// If this was the last attempt, break and return the error or response
 		if i == c.retryCount {
 			break
 		}
@@ -138,12 +276,34 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 		backoffDuration := time.Duration(1<<uint(i)) * time.Second
 		// Add some jitter to prevent thundering herd
 		jitter := time.Duration((i+1)*100) * time.Millisecond
-		time.Sleep(backoffDuration + jitter)
+		wait := backoffDuration + jitter
 
-		// Log retry attempt if logger is configured
-		if c.logger != nil {
+		if lastErr == nil {
+			// Honor Retry-After on 429 responses
+			if resp.StatusCode == http.StatusTooManyRequests {
+				if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+					wait = retryAfter
+				}
+			}
+
+			// Drain and close the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+
+			// Log retry attempt if logger is configured
+			if c.logger != nil {
+				c.logger.Info("Retrying HTTP request", "attempt", i+1, "status", resp.StatusCode)
+			}
+		} else if c.logger != nil {
+			// Log retry attempt if logger is configured
 			c.logger.Info("Retrying HTTP request", "attempt", i+1, "error", lastErr.Error())
 		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("request cancelled while waiting to retry: %w", ctx.Err())
+		case <-time.After(wait):
+		}
 	}
 
 	if lastErr != nil {
@@ -156,7 +316,12 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 
 	// Log the response if logger is configured
 	if c.logger != nil {
-		c.logger.Info("HTTP response", "method", method, "url", url, "status", resp.Status, "statusCode", resp.StatusCode)
+		c.logger.Info("HTTP response", "method", method, "url", url, "status", resp.Status, "statusCode", resp.StatusCode, "correlationID", correlationID)
+	}
+	if logBodies {
+		if err := c.logResponseBody(method, url, resp); err != nil {
+			return nil, err
+		}
 	}
 
 	return resp, nil
@@ -164,6 +329,14 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 
 // GetJSON performs a GET request and unmarshals the response into the provided interface
 func (c *Client) GetJSON(ctx context.Context, path string, result interface{}, headers map[string]string) error {
+	return c.GetJSONQuery(ctx, path, nil, result, headers)
+}
+
+// GetJSONQuery performs a GET request with the URL-encoded query appended to path
+// and unmarshals the response into the provided interface
+func (c *Client) GetJSONQuery(ctx context.Context, path string, query url.Values, result interface{}, headers map[string]string) error {
+	path = withQuery(path, query)
+
 	resp, err := c.Get(ctx, path, headers)
 	if err != nil {
 		return err
@@ -174,7 +347,7 @@ func (c *Client) GetJSON(ctx context.Context, path string, result interface{}, h
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
+		body, err := c.readBody(resp)
 		if err != nil {
 			if c.logger != nil {
 				c.logger.Error("Failed to read response body", "path", path, "error", err)
@@ -188,7 +361,7 @@ func (c *Client) GetJSON(ctx context.Context, path string, result interface{}, h
 		return fmt.Errorf("request failed with status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	responseBody, err := io.ReadAll(resp.Body)
+	responseBody, err := c.readBody(resp)
 	if err != nil {
 		if c.logger != nil {
 			c.logger.Error("Failed to read response body", "path", path, "error", err)
@@ -216,7 +389,12 @@ func (c *Client) PostJSON(ctx context.Context, path string, data interface{}, re
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	resp, err := c.do(ctx, http.MethodPost, path, bytes.NewBuffer(body), headers)
+	reqBody, headers, err := c.encodeBody(body, headers)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, path, reqBody, headers)
 	if err != nil {
 		return err
 	}
@@ -226,7 +404,7 @@ func (c *Client) PostJSON(ctx context.Context, path string, data interface{}, re
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, err := io.ReadAll(resp.Body)
+		body, err := c.readBody(resp)
 		if err != nil {
 			if c.logger != nil {
 				c.logger.Error("Failed to read response body", "path", path, "error", err)
@@ -240,7 +418,7 @@ func (c *Client) PostJSON(ctx context.Context, path string, data interface{}, re
 		return fmt.Errorf("request failed with status: %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	responseBody, err := io.ReadAll(resp.Body)
+	responseBody, err := c.readBody(resp)
 	if err != nil {
 		if c.logger != nil {
 			c.logger.Error("Failed to read response body", "path", path, "error", err)
@@ -264,6 +442,179 @@ func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, he
 	return c.do(ctx, method, path, body, headers)
 }
 
+// DoWithTimeout performs an HTTP request bounded by timeout instead of the client-level timeout
+// The deadline applies to the whole request including retries and reading the response body;
+// the shared http.Client is not modified
+func (c *Client) DoWithTimeout(ctx context.Context, method, path string, body io.Reader, headers map[string]string, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return c.do(ctx, method, path, body, headers)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+
+	// Shallow copy shares the transport but drops the client-level timeout,
+	// so the per-request deadline can be longer than the default
+	httpClient := *c.client
+	httpClient.Timeout = 0
+
+	resp, err := c.doWithClient(ctx, &httpClient, method, path, body, headers)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Keep the context alive until the caller has finished reading the body
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnCloseBody releases a request context when the response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the underlying body and cancels the request context
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// applyTransport installs the configured transport and pool tuning on the http.Client
+// Without a transport from WithTransport, the client's own *http.Transport or a clone of
+// http.DefaultTransport is tuned; custom RoundTrippers are left untouched
+func (c *Client) applyTransport() {
+	if c.transport == nil && c.maxIdleConnsPerHost <= 0 && c.keepAlive == nil {
+		return
+	}
+
+	transport := c.transport
+	if transport == nil {
+		switch rt := c.client.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = rt.Clone()
+		default:
+			if c.logger != nil {
+				c.logger.Warn("Transport tuning ignored for custom RoundTripper")
+			}
+			return
+		}
+	}
+
+	if c.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+		if transport.MaxIdleConns > 0 && transport.MaxIdleConns < c.maxIdleConnsPerHost {
+			transport.MaxIdleConns = c.maxIdleConnsPerHost
+		}
+	}
+	if c.keepAlive != nil {
+		transport.DisableKeepAlives = !*c.keepAlive
+	}
+
+	c.client.Transport = transport
+}
+
+// chain wraps base with the configured middlewares, the first one outermost
+// Bearer authentication, if configured, runs innermost so it applies to every attempt
+func (c *Client) chain(base RoundTripFunc) RoundTripFunc {
+	if c.tokenProvider != nil {
+		base = c.bearerAuth(base)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		base = c.middlewares[i](base)
+	}
+	return base
+}
+
+// withQuery appends the encoded query to path, keeping any query already present
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + query.Encode()
+}
+
+// encodeBody wraps a marshaled JSON body, gzipping it when gzip requests are enabled
+// The returned headers include Content-Encoding when the body is compressed
+func (c *Client) encodeBody(body []byte, headers map[string]string) (io.Reader, map[string]string, error) {
+	if !c.gzipRequests {
+		return bytes.NewBuffer(body), headers, nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(body); err != nil {
+		return nil, nil, fmt.Errorf("failed to gzip request body: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to gzip request body: %w", err)
+	}
+
+	gzHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		gzHeaders[k] = v
+	}
+	gzHeaders["Content-Encoding"] = "gzip"
+
+	return bytes.NewReader(buf.Bytes()), gzHeaders, nil
+}
+
+// readBody reads the response body, decompressing it if the response is gzip-encoded
+// Returns ErrResponseTooLarge if the (decompressed) body exceeds the configured maximum
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer func() {
+			_ = gz.Close()
+		}()
+		reader = gz
+	}
+
+	if c.maxResponseBytes <= 0 {
+		return io.ReadAll(reader)
+	}
+
+	// Read one byte past the limit to detect oversized bodies
+	data, err := io.ReadAll(io.LimitReader(reader, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, c.maxResponseBytes)
+	}
+
+	return data, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
 // BaseURL returns the base URL of the client
 func (c *Client) BaseURL() string {
 	return c.baseURL
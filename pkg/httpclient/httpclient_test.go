@@ -1,16 +1,23 @@
 package httpclient
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"monorepo/pkg/logger"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -593,3 +600,669 @@ func TestClient_Do_TimeoutWithRetry(t *testing.T) {
 	_, err := client.Do(context.Background(), "GET", "/", nil, nil)
 	require.Error(t, err, "Do() should fail with timeout")
 }
+
+func TestClient_DoWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("slow"))
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithTimeout(50*time.Millisecond))
+
+	t.Run("longer per-request timeout succeeds", func(t *testing.T) {
+		resp, err := client.DoWithTimeout(context.Background(), http.MethodGet, "/", nil, nil, 2*time.Second)
+		require.NoError(t, err, "DoWithTimeout() should use the per-request timeout")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Body should be readable after DoWithTimeout returns")
+		assert.Equal(t, "slow", string(body), "Expected response body")
+	})
+
+	t.Run("shorter per-request timeout fails", func(t *testing.T) {
+		longClient := New(WithBaseURL(server.URL), WithTimeout(2*time.Second))
+		_, err := longClient.DoWithTimeout(context.Background(), http.MethodGet, "/", nil, nil, 50*time.Millisecond)
+		require.Error(t, err, "DoWithTimeout() should fail when the per-request timeout elapses")
+	})
+
+	t.Run("client-level timeout unchanged", func(t *testing.T) {
+		_, err := client.Do(context.Background(), http.MethodGet, "/", nil, nil)
+		require.Error(t, err, "Do() should keep using the client-level timeout")
+		assert.Equal(t, 50*time.Millisecond, client.Timeout(), "Client timeout should not be mutated")
+	})
+}
+
+func TestClient_RetryableStatuses(t *testing.T) {
+	t.Run("retries retryable status then succeeds", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, `{"key":"value"}`, string(body), "Request body should be re-sent on retry")
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := New(WithBaseURL(server.URL), WithRetryCount(2), WithRetryableStatuses(http.StatusTooManyRequests))
+		start := time.Now()
+		resp, err := client.Post(context.Background(), "/", map[string]string{"key": "value"}, nil)
+		require.NoError(t, err, "Post() should succeed after retrying")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected final status code")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "Expected one retry")
+		assert.Less(t, time.Since(start), time.Second, "Retry-After should override the backoff delay")
+	})
+
+	t.Run("returns last response when retries are exhausted", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("slow down"))
+		}))
+		defer server.Close()
+
+		client := New(WithBaseURL(server.URL), WithRetryCount(1), WithRetryableStatuses(http.StatusTooManyRequests))
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Get() should return the last response without error")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Last response body should be readable")
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "Expected last status code")
+		assert.Equal(t, "slow down", string(body), "Expected last response body")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "Expected retryCount+1 attempts")
+	})
+
+	t.Run("non-retryable status is returned immediately", func(t *testing.T) {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := New(WithBaseURL(server.URL), WithRetryCount(2), WithRetryableStatuses(http.StatusTooManyRequests))
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Get() should not fail")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "Expected status code")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts), "Non-retryable status should not be retried")
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("3")
+	assert.True(t, ok, "Seconds should parse")
+	assert.Equal(t, 3*time.Second, wait, "Expected wait in seconds")
+
+	wait, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok, "HTTP date should parse")
+	assert.Equal(t, time.Duration(0), wait, "Past dates should not wait")
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok, "Invalid values should be rejected")
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var attempts int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithCircuitBreaker(2, 100*time.Millisecond))
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Requests should reach the server before the breaker trips")
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(context.Background(), "/", nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen, "Breaker should trip after consecutive failures")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "Open breaker should not contact the server")
+
+	// Failed trial request reopens the breaker
+	time.Sleep(150 * time.Millisecond)
+	resp, err := client.Get(context.Background(), "/", nil)
+	require.NoError(t, err, "Trial request should be let through after the open duration")
+	resp.Body.Close()
+	_, err = client.Get(context.Background(), "/", nil)
+	assert.ErrorIs(t, err, ErrCircuitOpen, "Failed trial request should reopen the breaker")
+
+	// Successful trial request closes the breaker
+	healthy.Store(true)
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Breaker should close after a successful trial request")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status code")
+	}
+}
+
+func TestCircuitBreaker_SingleTrialRequest(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(1, time.Second)
+	breaker.now = func() time.Time { return now }
+
+	require.NoError(t, breaker.allow(), "Closed breaker should allow requests")
+	breaker.recordFailure()
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen, "Breaker should be open")
+
+	now = now.Add(time.Second)
+	require.NoError(t, breaker.allow(), "First request after the open duration should be allowed")
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen, "Concurrent requests should fail while the trial is in flight")
+
+	breaker.recordCanceled()
+	require.NoError(t, breaker.allow(), "Canceled trial should free the slot for another trial")
+	breaker.recordSuccess()
+	require.NoError(t, breaker.allow(), "Breaker should be closed after a successful trial")
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(data)
+	require.NoError(t, err, "Failed to gzip test data")
+	require.NoError(t, gz.Close(), "Failed to close gzip writer")
+	return buf.Bytes()
+}
+
+func TestClient_GzipResponses(t *testing.T) {
+	compressed := gzipBytes(t, []byte(`{"name":"gzipped"}`))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(compressed)
+	}))
+	defer server.Close()
+
+	// Disable transport compression so the gzip response reaches the client untouched
+	httpClient := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	client := New(WithBaseURL(server.URL), WithHTTPClient(httpClient))
+
+	t.Run("GetJSON decompresses", func(t *testing.T) {
+		var result map[string]string
+		require.NoError(t, client.GetJSON(context.Background(), "/", &result, nil), "GetJSON() should decode gzip responses")
+		assert.Equal(t, "gzipped", result["name"], "Expected decoded field")
+	})
+
+	t.Run("PostJSON decompresses", func(t *testing.T) {
+		var result map[string]string
+		require.NoError(t, client.PostJSON(context.Background(), "/", map[string]string{}, &result, nil), "PostJSON() should decode gzip responses")
+		assert.Equal(t, "gzipped", result["name"], "Expected decoded field")
+	})
+
+	t.Run("Do stays byte-faithful", func(t *testing.T) {
+		resp, err := client.Do(context.Background(), http.MethodGet, "/", nil, nil)
+		require.NoError(t, err, "Do() should not fail")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Body should be readable")
+		assert.Equal(t, compressed, body, "Do() should not decompress the body")
+	})
+}
+
+func TestClient_GzipRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"), "Expected gzip Content-Encoding")
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err, "Request body should be gzipped")
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err, "Request body should decompress")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithGzipRequests())
+
+	var result map[string]string
+	err := client.PostJSON(context.Background(), "/", map[string]string{"key": "value"}, &result, nil)
+	require.NoError(t, err, "PostJSON() should send a gzipped body")
+	assert.Equal(t, "value", result["key"], "Server should receive the original body")
+
+	resp, err := client.Put(context.Background(), "/", map[string]string{"key": "value"}, map[string]string{"X-Test": "1"})
+	require.NoError(t, err, "Put() should send a gzipped body")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+}
+
+func TestClient_BodyLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"key":"value"}`, string(body), "Request body should still be sent")
+		w.Header().Set("Set-Cookie", "session=secret-cookie")
+		_, _ = w.Write([]byte(`{"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := New(WithBaseURL(server.URL), WithLogger(logger), WithBodyLogging("X-Partner-Token"))
+
+	headers := map[string]string{
+		"Authorization":   "Bearer secret-token",
+		"X-Partner-Token": "secret-partner",
+		"X-Trace":         "trace-1",
+	}
+	resp, err := client.Post(context.Background(), "/", map[string]string{"key": "value"}, headers)
+	require.NoError(t, err, "Post() should not fail")
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "Response body should still be readable after logging")
+	assert.Equal(t, `{"result":"ok"}`, string(body), "Expected response body")
+
+	output := logs.String()
+	assert.Contains(t, output, `{\"key\":\"value\"}`, "Request body should be logged")
+	assert.Contains(t, output, `{\"result\":\"ok\"}`, "Response body should be logged")
+	assert.Contains(t, output, "trace-1", "Non-sensitive headers should be logged")
+	assert.NotContains(t, output, "secret-token", "Authorization should be redacted")
+	assert.NotContains(t, output, "secret-partner", "Named headers should be redacted")
+	assert.NotContains(t, output, "secret-cookie", "Set-Cookie should be redacted")
+}
+
+func TestClient_RedactsHeadersWithoutBodyLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := New(WithBaseURL(server.URL), WithLogger(logger))
+
+	resp, err := client.Get(context.Background(), "/", map[string]string{"Authorization": "Bearer secret-token"})
+	require.NoError(t, err, "Get() should not fail")
+	resp.Body.Close()
+
+	assert.NotContains(t, logs.String(), "secret-token", "Authorization should be redacted in request logs")
+	assert.NotContains(t, logs.String(), "HTTP request body", "Bodies should not be logged by default")
+}
+
+func TestClient_MaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"` + strings.Repeat("x", 100) + `"}`))
+	}))
+	defer server.Close()
+
+	t.Run("oversized response rejected", func(t *testing.T) {
+		client := New(WithBaseURL(server.URL), WithMaxResponseBytes(50))
+
+		var result map[string]string
+		err := client.GetJSON(context.Background(), "/", &result, nil)
+		assert.ErrorIs(t, err, ErrResponseTooLarge, "GetJSON() should reject oversized responses")
+
+		err = client.PostJSON(context.Background(), "/", map[string]string{}, &result, nil)
+		assert.ErrorIs(t, err, ErrResponseTooLarge, "PostJSON() should reject oversized responses")
+	})
+
+	t.Run("response within limit accepted", func(t *testing.T) {
+		client := New(WithBaseURL(server.URL), WithMaxResponseBytes(1024))
+
+		var result map[string]string
+		require.NoError(t, client.GetJSON(context.Background(), "/", &result, nil), "GetJSON() should accept responses within the limit")
+		assert.Len(t, result["name"], 100, "Expected decoded field")
+	})
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		client := New(WithBaseURL(server.URL))
+
+		var result map[string]string
+		require.NoError(t, client.GetJSON(context.Background(), "/", &result, nil), "GetJSON() should not limit responses by default")
+	})
+
+	t.Run("body logging keeps the full body", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		client := New(WithBaseURL(server.URL), WithMaxResponseBytes(10), WithLogger(logger), WithBodyLogging())
+
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Get() should not fail")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Body should be readable")
+		assert.Len(t, body, 111, "Do() should return the complete body even when logging is truncated")
+	})
+}
+
+func TestClient_GetJSONQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.URL.Query())
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL))
+
+	t.Run("encodes query values", func(t *testing.T) {
+		query := url.Values{}
+		query.Set("offset", "10")
+		query.Set("limit", "20")
+		query.Set("search", "a&b=c d")
+
+		var result map[string][]string
+		require.NoError(t, client.GetJSONQuery(context.Background(), "/users", query, &result, nil), "GetJSONQuery() should not fail")
+		assert.Equal(t, []string{"10"}, result["offset"], "Expected offset")
+		assert.Equal(t, []string{"20"}, result["limit"], "Expected limit")
+		assert.Equal(t, []string{"a&b=c d"}, result["search"], "Special characters should be escaped")
+	})
+
+	t.Run("keeps existing query", func(t *testing.T) {
+		var result map[string][]string
+		err := client.GetJSONQuery(context.Background(), "/users?active=true", url.Values{"limit": {"5"}}, &result, nil)
+		require.NoError(t, err, "GetJSONQuery() should not fail")
+		assert.Equal(t, []string{"true"}, result["active"], "Existing query should be kept")
+		assert.Equal(t, []string{"5"}, result["limit"], "Expected limit")
+	})
+
+	t.Run("nil query", func(t *testing.T) {
+		var result map[string][]string
+		require.NoError(t, client.GetJSONQuery(context.Background(), "/users", nil, &result, nil), "GetJSONQuery() should not fail")
+		assert.Empty(t, result, "No query should be sent")
+	})
+}
+
+func TestClient_PostForm(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"), "Expected form content type")
+		assert.Equal(t, "test-value", r.Header.Get("X-Test"), "Expected custom header")
+		require.NoError(t, r.ParseForm(), "Form should parse")
+		assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"), "Expected grant_type")
+		assert.Equal(t, "a b&c", r.PostForm.Get("code"), "Form values should be encoded")
+
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithRetryCount(1), WithRetryableStatuses(http.StatusServiceUnavailable))
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", "a b&c")
+	resp, err := client.PostForm(context.Background(), "/oauth/token", form, map[string]string{"X-Test": "test-value"})
+	require.NoError(t, err, "PostForm() should not fail")
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "Form body should be re-sent on retry")
+}
+
+func TestClient_PostMultipart(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20), "Multipart body should parse")
+		assert.Equal(t, "contract-1", r.FormValue("reference"), "Expected field value")
+
+		file, header, err := r.FormFile("contract")
+		require.NoError(t, err, "Expected file part")
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		assert.Equal(t, "%PDF-1.7 test", string(content), "File content should be sent")
+		assert.Equal(t, "contract", header.Filename, "Filename should default to the field name")
+
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fields := map[string]string{"reference": "contract-1"}
+
+	t.Run("seekable files are replayed on retry", func(t *testing.T) {
+		client := New(WithBaseURL(server.URL), WithRetryCount(1), WithRetryableStatuses(http.StatusServiceUnavailable))
+		files := map[string]io.Reader{"contract": strings.NewReader("%PDF-1.7 test")}
+
+		resp, err := client.PostMultipart(context.Background(), "/upload", fields, files, nil)
+		require.NoError(t, err, "PostMultipart() should not fail")
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts), "Expected one retry")
+	})
+
+	t.Run("non-seekable files fail fast with retries", func(t *testing.T) {
+		client := New(WithBaseURL(server.URL), WithRetryCount(1))
+		files := map[string]io.Reader{"contract": io.MultiReader(strings.NewReader("%PDF-1.7 test"))}
+
+		_, err := client.PostMultipart(context.Background(), "/upload", fields, files, nil)
+		assert.ErrorIs(t, err, ErrBodyNotRetryable, "Non-seekable files should not be accepted with retries")
+	})
+
+	t.Run("non-seekable files are streamed without retries", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 1)
+		client := New(WithBaseURL(server.URL))
+		files := map[string]io.Reader{"contract": io.MultiReader(strings.NewReader("%PDF-1.7 test"))}
+
+		resp, err := client.PostMultipart(context.Background(), "/upload", fields, files, nil)
+		require.NoError(t, err, "PostMultipart() should not fail")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+	})
+}
+
+func TestClient_PostMultipart_ReplyBeforeBodyIsRead(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 4<<20)
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reject the first attempt without reading its body, while the client is still sending it
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		require.NoError(t, r.ParseMultipartForm(1<<20), "Multipart body should parse")
+		file, _, err := r.FormFile("upload")
+		require.NoError(t, err, "Expected file part")
+		defer file.Close()
+		received, _ := io.ReadAll(file)
+		assert.Equal(t, len(content), len(received), "The replayed file should be sent in full")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(WithBaseURL(server.URL), WithRetryCount(1), WithRetryableStatuses(http.StatusServiceUnavailable))
+	files := map[string]io.Reader{"upload": bytes.NewReader(content)}
+
+	resp, err := client.PostMultipart(context.Background(), "/upload", nil, files, nil)
+	require.NoError(t, err, "PostMultipart() should not fail")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "Expected status OK")
+}
+
+func TestMultipartStream_StartsOnRead(t *testing.T) {
+	body, err := newMultipartBody(map[string]string{"reference": "contract-1"}, nil, false)
+	require.NoError(t, err, "newMultipartBody() should not fail")
+
+	unread := body.open()
+	assert.NoError(t, unread.Close(), "Closing an unread stream should not fail")
+	assert.Nil(t, unread.reader, "No pipe should be started before the first Read")
+	_, err = unread.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.ErrClosedPipe, "A closed stream should not start streaming")
+
+	stream := body.open()
+	data, err := io.ReadAll(stream)
+	require.NoError(t, err, "Reading the stream should not fail")
+	assert.Contains(t, string(data), "contract-1", "The stream should contain the fields")
+	assert.NoError(t, stream.Close(), "Closing a read stream should not fail")
+}
+
+func TestClient_Middleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization") + "|" + r.Header.Get("X-Order")))
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":before")
+				req.Header.Add("X-Order", name)
+				resp, err := next(req)
+				calls = append(calls, name+":after")
+				return resp, err
+			}
+		}
+	}
+	auth := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer injected")
+			return next(req)
+		}
+	}
+
+	client := New(WithBaseURL(server.URL), WithMiddleware(record("outer"), record("inner")), WithMiddleware(auth))
+
+	resp, err := client.Get(context.Background(), "/", nil)
+	require.NoError(t, err, "Get() should not fail")
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "Body should be readable")
+	assert.Equal(t, "Bearer injected|outer", string(body), "Middlewares should modify the outgoing request")
+	assert.Equal(t, []string{"outer:before", "inner:before", "inner:after", "outer:after"}, calls, "Middlewares should run in order")
+}
+
+func TestClient_BearerAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	// newProvider returns a caching provider that hands out a stale token until a refresh is requested
+	newProvider := func() (TokenProvider, *int32) {
+		var refreshes int32
+		token := "stale"
+		return func(ctx context.Context) (string, error) {
+			if RefreshRequested(ctx) {
+				atomic.AddInt32(&refreshes, 1)
+				token = "fresh"
+			}
+			return token, nil
+		}, &refreshes
+	}
+
+	t.Run("raw 401 without opt-in", func(t *testing.T) {
+		provider, refreshes := newProvider()
+		client := New(WithBaseURL(server.URL), WithBearerAuth(provider))
+
+		resp, err := client.Get(context.Background(), "/", nil)
+		require.NoError(t, err, "Get() should not fail")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "401 should be returned as-is")
+		assert.Equal(t, int32(0), atomic.LoadInt32(refreshes), "Token should not be refreshed")
+	})
+
+	t.Run("retries once with a fresh token", func(t *testing.T) {
+		provider, refreshes := newProvider()
+		client := New(WithBaseURL(server.URL), WithBearerAuth(provider), WithRetryOnUnauthorized())
+
+		resp, err := client.Post(context.Background(), "/", map[string]string{"key": "value"}, nil)
+		require.NoError(t, err, "Post() should not fail")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Body should be readable")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, "Request should succeed with the fresh token")
+		assert.Equal(t, `{"key":"value"}`, string(body), "Body should be re-sent with the fresh token")
+		assert.Equal(t, int32(1), atomic.LoadInt32(refreshes), "Token should be refreshed once")
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		providerErr := errors.New("token service down")
+		client := New(WithBaseURL(server.URL), WithBearerAuth(func(ctx context.Context) (string, error) {
+			return "", providerErr
+		}))
+
+		_, err := client.Get(context.Background(), "/", nil)
+		assert.ErrorIs(t, err, providerErr, "Provider errors should be returned")
+	})
+}
+
+func TestClient_TransportTuning(t *testing.T) {
+	t.Run("tunes default transport", func(t *testing.T) {
+		client := New(WithMaxIdleConnsPerHost(64), WithKeepAlive(false)).(*Client)
+
+		transport, ok := client.client.Transport.(*http.Transport)
+		require.True(t, ok, "Expected *http.Transport")
+		assert.Equal(t, 64, transport.MaxIdleConnsPerHost, "Expected tuned idle connections per host")
+		assert.True(t, transport.DisableKeepAlives, "Keep-alive should be disabled")
+		assert.NotSame(t, http.DefaultTransport, transport, "Default transport should not be mutated")
+		assert.Equal(t, 30*time.Second, client.client.Timeout, "Timeout should be kept")
+	})
+
+	t.Run("tunes provided transport", func(t *testing.T) {
+		base := &http.Transport{MaxIdleConns: 10}
+		client := New(WithTransport(base), WithMaxIdleConnsPerHost(32), WithTimeout(5*time.Second)).(*Client)
+
+		assert.Same(t, base, client.client.Transport, "Provided transport should be used")
+		assert.Equal(t, 32, base.MaxIdleConnsPerHost, "Expected tuned idle connections per host")
+		assert.Equal(t, 32, base.MaxIdleConns, "Total idle connections should allow the per-host limit")
+		assert.Equal(t, 5*time.Second, client.client.Timeout, "Timeout should be kept")
+	})
+
+	t.Run("no tuning keeps default", func(t *testing.T) {
+		client := New().(*Client)
+		assert.Nil(t, client.client.Transport, "Transport should not be set without tuning options")
+	})
+}
+
+func TestClient_CorrelationHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("X-Correlation-ID")))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	slogger := slog.New(slog.NewJSONHandler(&logs, nil))
+	client := New(WithBaseURL(server.URL), WithLogger(slogger), WithCorrelationHeader("X-Correlation-ID"))
+
+	get := func(ctx context.Context, headers map[string]string) string {
+		resp, err := client.Get(ctx, "/", headers)
+		require.NoError(t, err, "Get() should not fail")
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err, "Body should be readable")
+		return string(body)
+	}
+
+	ctx := logger.WithCorrelationID(context.Background(), "corr-123")
+	assert.Equal(t, "corr-123", get(ctx, nil), "Correlation ID should be sent from the context")
+	assert.Equal(t, 2, strings.Count(logs.String(), `"correlationID":"corr-123"`), "Request and response logs should include the correlation ID")
+
+	assert.Equal(t, "explicit", get(ctx, map[string]string{"X-Correlation-ID": "explicit"}), "Explicit header should win")
+	assert.Empty(t, get(context.Background(), nil), "No header should be sent without a correlation ID")
+}
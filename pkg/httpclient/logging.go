@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// redactedValue replaces the values of redacted headers in logs
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders are always masked when headers are logged
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// redactHeaders returns a copy of headers safe for logging, with the values of redacted headers masked
+func (c *Client) redactHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if c.isRedacted(k) {
+			v = redactedValue
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// isRedacted reports whether the value of the named header must not be logged
+func (c *Client) isRedacted(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	for _, h := range defaultRedactedHeaders {
+		if h == name {
+			return true
+		}
+	}
+	return c.redactedHeaders[name]
+}
+
+// bufferBody reads body into memory so it can be logged and still be sent, including on retries
+func bufferBody(body io.Reader) ([]byte, io.Reader, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return data, bytes.NewReader(data), nil
+}
+
+// logResponseBody logs the response body at debug level and replaces it with an equivalent reader
+// When a maximum response size is set, only that many bytes are buffered and logged
+func (c *Client) logResponseBody(method, url string, resp *http.Response) error {
+	var reader io.Reader = resp.Body
+	if c.maxResponseBytes > 0 {
+		reader = io.LimitReader(resp.Body, c.maxResponseBytes)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		_ = resp.Body.Close()
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+
+	c.logger.Debug("HTTP response body", "method", method, "url", url, "headers", c.redactResponseHeaders(resp.Header), "body", string(data))
+	return nil
+}
+
+// redactResponseHeaders flattens response headers for logging with redacted values masked
+func (c *Client) redactResponseHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for k := range header {
+		flat[k] = header.Get(k)
+	}
+	return c.redactHeaders(flat)
+}
@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// ErrBodyNotRetryable is returned when retries are configured but a request body cannot be replayed
+var ErrBodyNotRetryable = errors.New("request body is not retryable")
+
+// PostMultipart performs an HTTP POST request with a multipart/form-data body
+// File readers are streamed rather than buffered; files are named after the reader's Name()
+// (e.g. *os.File) or the field name otherwise
+// To replay the body on retries every file reader must implement io.Seeker; when retries are
+// configured and a reader is not seekable, ErrBodyNotRetryable is returned without sending anything
+func (c *Client) PostMultipart(ctx context.Context, path string, fields map[string]string, files map[string]io.Reader, headers map[string]string) (*http.Response, error) {
+	body, err := newMultipartBody(fields, files, c.retryCount > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	multipartHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		multipartHeaders[k] = v
+	}
+	multipartHeaders["Content-Type"] = body.contentType
+
+	resp, err := c.do(ctx, http.MethodPost, path, body.open(), multipartHeaders)
+	if err != nil {
+		// The request may have failed before the transport took the body, e.g. with the circuit open
+		body.close()
+	}
+	return resp, err
+}
+
+// multipartBody holds a multipart/form-data body that is streamed through a pipe
+// Each attempt reads a multipartStream; only the latest one may read the files
+type multipartBody struct {
+	fields      map[string]string
+	files       map[string]io.Reader
+	offsets     map[string]int64
+	boundary    string
+	contentType string
+
+	// mu guards last
+	mu sync.Mutex
+	// last is the most recently opened stream, stopped before the files are rewound for the next one
+	last *multipartStream
+}
+
+// newMultipartBody records the start offsets of seekable files
+func newMultipartBody(fields map[string]string, files map[string]io.Reader, retryable bool) (*multipartBody, error) {
+	b := &multipartBody{
+		fields:  fields,
+		files:   files,
+		offsets: make(map[string]int64, len(files)),
+	}
+
+	for name, file := range files {
+		seeker, ok := file.(io.Seeker)
+		if !ok {
+			if retryable {
+				return nil, fmt.Errorf("%w: file %q is not seekable", ErrBodyNotRetryable, name)
+			}
+			continue
+		}
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get offset of file %q: %w", name, err)
+		}
+		b.offsets[name] = offset
+	}
+
+	writer := multipart.NewWriter(io.Discard)
+	b.boundary = writer.Boundary()
+	b.contentType = writer.FormDataContentType()
+
+	return b, nil
+}
+
+// open returns the stream for the first attempt
+func (b *multipartBody) open() *multipartStream {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.last = &multipartStream{body: b}
+	return b.last
+}
+
+// rewind stops the previous stream, rewinds the files and returns a fresh stream for a retry
+// The previous stream must be stopped first: the transport may still be sending it while the
+// response is handled, and its goroutine would otherwise read the files as they are rewound
+func (b *multipartBody) rewind() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last != nil {
+		_ = b.last.Close()
+	}
+
+	for name, file := range b.files {
+		seeker, ok := file.(io.Seeker)
+		if !ok {
+			return nil, fmt.Errorf("%w: file %q is not seekable", ErrBodyNotRetryable, name)
+		}
+		if _, err := seeker.Seek(b.offsets[name], io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind file %q: %w", name, err)
+		}
+	}
+
+	b.last = &multipartStream{body: b}
+	return b.last, nil
+}
+
+// close stops the latest stream
+func (b *multipartBody) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.last != nil {
+		_ = b.last.Close()
+	}
+}
+
+// multipartStream is one attempt's copy of a multipart body
+// The goroutine writing the body into a pipe starts on the first Read, so a stream that is never
+// sent holds no goroutine, and it exits once the body is fully written or the stream is closed
+type multipartStream struct {
+	body *multipartBody
+
+	// mu guards the fields below
+	mu sync.Mutex
+	// reader is the read end of the pipe, nil until the first Read
+	reader *io.PipeReader
+	// done is closed when the writing goroutine exits
+	done   chan struct{}
+	closed bool
+}
+
+// Read starts streaming the body on the first call and reads from the pipe
+func (s *multipartStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, io.ErrClosedPipe
+	}
+	if s.reader == nil {
+		pr, pw := io.Pipe()
+		s.reader = pr
+		s.done = make(chan struct{})
+		go func() {
+			defer close(s.done)
+			pw.CloseWithError(s.body.write(pw))
+		}()
+	}
+	reader := s.reader
+	s.mu.Unlock()
+
+	return reader.Read(p)
+}
+
+// Close stops the writing goroutine, if started, and waits for it to exit
+func (s *multipartStream) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	reader, done := s.reader, s.done
+	s.mu.Unlock()
+
+	if reader == nil {
+		return nil
+	}
+	_ = reader.Close()
+	<-done
+	return nil
+}
+
+// GetBody returns a fresh copy of the body for a retry
+// It is picked up by the client so the request can be replayed when all files are seekable
+func (s *multipartStream) GetBody() (io.ReadCloser, error) {
+	return s.body.rewind()
+}
+
+// write encodes fields and files in sorted order so replays produce the same body
+func (b *multipartBody) write(w io.Writer) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(b.fields)) {
+		if err := writer.WriteField(name, b.fields[name]); err != nil {
+			return fmt.Errorf("failed to write field %q: %w", name, err)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(b.files)) {
+		part, err := writer.CreateFormFile(name, fileName(name, b.files[name]))
+		if err != nil {
+			return fmt.Errorf("failed to create part for file %q: %w", name, err)
+		}
+		if _, err := io.Copy(part, b.files[name]); err != nil {
+			return fmt.Errorf("failed to write file %q: %w", name, err)
+		}
+	}
+
+	return writer.Close()
+}
+
+// fileName returns the base name of readers that expose one, such as *os.File, or the field name
+func fileName(field string, file io.Reader) string {
+	if named, ok := file.(interface{ Name() string }); ok && named.Name() != "" {
+		return filepath.Base(named.Name())
+	}
+	return field
+}
@@ -43,6 +43,81 @@ func WithRetryCount(retryCount int) Option {
 	}
 }
 
+// WithRetryableStatuses sets response status codes that are retried up to the retry count
+// For 429 responses, a Retry-After header overrides the backoff delay
+func WithRetryableStatuses(codes ...int) Option {
+	return func(c *Client) {
+		if c.retryableStatuses == nil {
+			c.retryableStatuses = make(map[int]bool)
+		}
+		for _, code := range codes {
+			c.retryableStatuses[code] = true
+		}
+	}
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen after failureThreshold consecutive failures
+// A failure is a transport error or a 5xx response after all retries
+// After openDuration a single trial request is let through; success closes the breaker, failure reopens it
+func WithCircuitBreaker(failureThreshold int, openDuration time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = newCircuitBreaker(failureThreshold, openDuration)
+	}
+}
+
+// WithGzipRequests gzips JSON request bodies sent by Post, Put and PostJSON and sets Content-Encoding: gzip
+func WithGzipRequests() Option {
+	return func(c *Client) {
+		c.gzipRequests = true
+	}
+}
+
+// WithMaxResponseBytes limits the response bodies read by GetJSON and PostJSON to n bytes
+// Larger responses fail with ErrResponseTooLarge; n <= 0 means unlimited, which is the default
+func WithMaxResponseBytes(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseBytes = n
+	}
+}
+
+// WithBodyLogging logs request and response bodies at debug level when a logger is configured
+// Values of the named headers are masked in logged header maps, in addition to
+// Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key which are always masked
+func WithBodyLogging(redactHeaders ...string) Option {
+	return func(c *Client) {
+		c.bodyLogging = true
+		if c.redactedHeaders == nil {
+			c.redactedHeaders = make(map[string]bool)
+		}
+		for _, h := range redactHeaders {
+			c.redactedHeaders[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+}
+
+// WithMiddleware adds middlewares that wrap every request attempt, including retries
+// Middlewares run in the order given, and across calls in the order they were added
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// WithBearerAuth sets Authorization: Bearer <token> on every request from the token provider
+func WithBearerAuth(tokenProvider TokenProvider) Option {
+	return func(c *Client) {
+		c.tokenProvider = tokenProvider
+	}
+}
+
+// WithRetryOnUnauthorized retries a 401 response once with a fresh token when WithBearerAuth is set
+// The token provider is called with a context for which RefreshRequested returns true
+func WithRetryOnUnauthorized() Option {
+	return func(c *Client) {
+		c.retryOnUnauthorized = true
+	}
+}
+
 // WithHTTPClient allows using a custom http.Client
 func WithHTTPClient(client *http.Client) Option {
 	return func(c *Client) {
@@ -50,6 +125,37 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithTransport sets the transport used to send requests, keeping the timeout and retry settings
+// WithMaxIdleConnsPerHost and WithKeepAlive are applied on top of it
+func WithTransport(transport *http.Transport) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the maximum number of idle keep-alive connections kept per host
+// The net/http default of 2 can bottleneck high-throughput service-to-service traffic
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.maxIdleConnsPerHost = n
+	}
+}
+
+// WithKeepAlive enables or disables HTTP keep-alive connection reuse
+func WithKeepAlive(enabled bool) Option {
+	return func(c *Client) {
+		c.keepAlive = &enabled
+	}
+}
+
+// WithCorrelationHeader sets the named request header to the correlation ID from the request context
+// The ID is read with logger.CorrelationID and is also included in the request and response logs
+func WithCorrelationHeader(headerName string) Option {
+	return func(c *Client) {
+		c.correlationHeader = headerName
+	}
+}
+
 // WithLogger adds a slog logger to the client for request/response logging
 func WithLogger(logger *slog.Logger) Option {
 	return func(c *Client) {
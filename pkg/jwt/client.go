@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 const (
@@ -30,20 +31,39 @@ const (
 	SessionKeyPrefix  = "session:"
 	SessionKeyPattern = "session:*"
 
+	// UserSessionsKeyPrefix prefixes the per-user sets of session IDs used to find a user's sessions
+	UserSessionsKeyPrefix = "user_sessions:"
+
+	// RefreshTokenKeyPattern matches refresh tokens persisted by RedisStore
+	RefreshTokenKeyPattern = "refresh_token:*"
+
 	// Session expiry (24 hours)
 	SessionExpiry = 24 * time.Hour
+
+	// Default time an inactive session is kept before the janitor removes it
+	DefaultSessionGracePeriod = time.Hour
+
+	// Default number of sessions returned per page by ListUserSessions
+	DefaultSessionPageSize = 20
+
+	// SCAN COUNT hint used when iterating over keys
+	scanBatchSize = 1000
 )
 
 // JWTClient defines the interface for JWT token operations
 type JWTClient interface {
 	GenerateAccessToken(userID, agentID, agentType string) (string, error)
 	GenerateRefreshToken(userID, agentID, agentType string) (string, error)
+	ReissueAccessToken(claims *TokenClaims) (string, error)
+	ReissueRefreshToken(claims *TokenClaims) (string, error)
 	ValidateAccessToken(tokenString string) (*TokenClaims, error)
 	ValidateRefreshToken(tokenString string) (*TokenClaims, error)
+	ValidateAccessTokenMaxAge(tokenString string, maxAge time.Duration) (*TokenClaims, error)
 	RefreshAccessToken(refreshToken string) (string, error)
 	RevokeRefreshToken(userID, tokenID string) error
 	RevokeAllRefreshTokens(userID string) error
 	Cleanup() error
+	StoreHealthy(ctx context.Context) error
 	GetConfig() TokenConfig
 	IsStateful() bool
 	GetTokenExpiration(tokenString string) (time.Time, error)
@@ -55,8 +75,12 @@ type JWTClient interface {
 	GetSession(ctx context.Context, sessionID string) (*SessionInfo, error)
 	UpdateSessionLastSeen(ctx context.Context, sessionID string) error
 	EndSession(ctx context.Context, sessionID string) error
+	EndAllUserSessions(ctx context.Context, userID string) error
 	GetUserSessions(ctx context.Context, userID string) ([]string, error)
+	ListUserSessions(ctx context.Context, userID string, opts SessionListOptions) (*SessionPage, error)
 	GenerateTokensWithSession(ctx context.Context, userID, agentID, agentType, deviceInfo, ipAddress string) (string, string, string, error)
+	ReissueTokensWithSession(ctx context.Context, claims *TokenClaims, deviceInfo, ipAddress string) (string, string, string, error)
+	StartSessionJanitor(ctx context.Context, interval time.Duration)
 }
 
 const (
@@ -72,16 +96,78 @@ const (
 	ErrSessionRequiresStatefulRedis  = "session management requires stateful mode with Redis"
 	ErrRedisClientNotConfigured      = "Redis client not configured"
 	ErrSessionNotFound               = "session not found"
+	ErrActiveSigningKeyNotFound      = "active signing key not found"
+	ErrUnknownSigningKey             = "unknown signing key"
+	ErrTokenHasNoExpiration          = "token has no expiration"
+	ErrInvalidTokenClaims            = "invalid token claims"
+	ErrTokenIsExpired                = "token is expired"
+	ErrSecretsMustDiffer             = "access and refresh token secrets must differ"
+	ErrStoreUnreachable              = "refresh token store unreachable"
+	ErrStoreEmpty                    = "refresh token store is empty"
+	ErrTokenTooOld                   = "token exceeds maximum age"
+	ErrMissingTokenType              = "token type is missing"
+	ErrUnknownTokenType              = "unknown token type"
+)
+
+// Sentinel errors returned by the client, usable with errors.Is
+var (
+	ErrAccessTokenSecretRequiredErr     = errors.New(ErrAccessTokenSecretRequired)
+	ErrRefreshTokenSecretRequiredErr    = errors.New(ErrRefreshTokenSecretRequired)
+	ErrRefreshTokenNotFoundOrInvalidErr = errors.New(ErrRefreshTokenNotFoundOrInvalid)
+	ErrRefreshTokenNotInStoreErr        = errors.New(ErrRefreshTokenNotInStore)
+	ErrInvalidTokenTypeErr              = errors.New(ErrInvalidTokenType)
+	ErrInvalidTokenErr                  = errors.New(ErrInvalidToken)
+	ErrRevokeNotSupportedStatelessErr   = errors.New(ErrRevokeNotSupportedStateless)
+	ErrNoStoreConfiguredErr             = errors.New(ErrNoStoreConfigured)
+	ErrSessionRequiresStatefulRedisErr  = errors.New(ErrSessionRequiresStatefulRedis)
+	ErrRedisClientNotConfiguredErr      = errors.New(ErrRedisClientNotConfigured)
+	ErrSessionNotFoundErr               = errors.New(ErrSessionNotFound)
+	ErrActiveSigningKeyNotFoundErr      = errors.New(ErrActiveSigningKeyNotFound)
+	ErrUnknownSigningKeyErr             = errors.New(ErrUnknownSigningKey)
+	ErrTokenHasNoExpirationErr          = errors.New(ErrTokenHasNoExpiration)
+	ErrInvalidTokenClaimsErr            = errors.New(ErrInvalidTokenClaims)
+	ErrTokenIsExpiredErr                = errors.New(ErrTokenIsExpired)
+	ErrSecretsMustDifferErr             = errors.New(ErrSecretsMustDiffer)
+	ErrStoreUnreachableErr              = errors.New(ErrStoreUnreachable)
+	ErrStoreEmptyErr                    = errors.New(ErrStoreEmpty)
+	ErrTokenTooOldErr                   = errors.New(ErrTokenTooOld)
+	ErrMissingTokenTypeErr              = errors.New(ErrMissingTokenType)
+	ErrUnknownTokenTypeErr              = errors.New(ErrUnknownTokenType)
 )
 
 // SessionInfo represents user session information stored in Redis
 type SessionInfo struct {
+	SessionID  string `json:"session_id"`
+	UserID     string `json:"user_id"`
+	AgentID    string `json:"agent_id"`
+	AgentType  string `json:"agent_type"`
 	DeviceInfo string `json:"device_info"`
 	IPAddress  string `json:"ip_address"`
+	CreatedAt  string `json:"created_at"`
 	LastSeen   string `json:"last_seen"`
 	Status     string `json:"status"`
 }
 
+// SessionListOptions controls paging and filtering for ListUserSessions
+type SessionListOptions struct {
+	// Cursor is the continuation cursor from a previous page; zero starts from the beginning
+	Cursor uint64
+	// Limit is the page size target; defaults to DefaultSessionPageSize
+	Limit int
+	// ActiveOnly restricts results to sessions with active status
+	ActiveOnly bool
+}
+
+// SessionPage is a page of a user's sessions
+type SessionPage struct {
+	Sessions []*SessionInfo `json:"sessions"`
+	// NextCursor is the cursor for the next page; zero means there are no more pages
+	NextCursor uint64 `json:"next_cursor"`
+}
+
+// sessionFields lists the session hash fields in the order read by sessionInfoFromFields
+var sessionFields = []string{"user_id", "agent_id", "agent_type", "device_info", "ip_address", "created_at", "last_seen", "status"}
+
 // Client represents a JWT client that handles token operations
 type Client struct {
 	config      TokenConfig
@@ -98,6 +184,7 @@ func New(opts ...Option) (JWTClient, error) {
 		AccessTokenExpiry:  time.Minute * 15,
 		RefreshTokenExpiry: time.Hour * 24 * 7,
 		Stateful:           false,
+		SessionGracePeriod: DefaultSessionGracePeriod,
 	}
 
 	// Apply options
@@ -107,10 +194,22 @@ func New(opts ...Option) (JWTClient, error) {
 
 	// Validate configuration
 	if config.AccessTokenSecret == "" {
-		return nil, errors.New(ErrAccessTokenSecretRequired)
+		return nil, ErrAccessTokenSecretRequiredErr
 	}
 	if config.RefreshTokenSecret == "" {
-		return nil, errors.New(ErrRefreshTokenSecretRequired)
+		return nil, ErrRefreshTokenSecretRequiredErr
+	}
+	// A shared secret would let refresh tokens pass signature checks as access tokens
+	if config.AccessTokenSecret == config.RefreshTokenSecret {
+		return nil, ErrSecretsMustDifferErr
+	}
+	for _, key := range config.SigningKeys {
+		if key == config.RefreshTokenSecret {
+			return nil, ErrSecretsMustDifferErr
+		}
+	}
+	if len(config.SigningKeys) > 0 && config.SigningKeys[config.ActiveKID] == "" {
+		return nil, ErrActiveSigningKeyNotFoundErr
 	}
 
 	client := &Client{
@@ -154,8 +253,20 @@ func NewStatefulWithRedis(redisClient redis.RedisClient, opts ...Option) (JWTCli
 	return client, nil
 }
 
-// GenerateAccessToken generates a new access token
+// GenerateAccessToken generates a new access token for a user who has just authenticated
+// The auth_time claim is set to now
 func (c *Client) GenerateAccessToken(userID, agentID, agentType string) (string, error) {
+	return c.generateAccessToken(userID, agentID, agentType, jwt.NewNumericDate(time.Now()))
+}
+
+// ReissueAccessToken generates a new access token for the subject of validated refresh token claims
+// The auth_time claim is carried over, so refreshing does not make the token count as a recent authentication
+func (c *Client) ReissueAccessToken(claims *TokenClaims) (string, error) {
+	return c.generateAccessToken(claims.UserID, claims.AgentID, claims.AgentType, claims.AuthTime)
+}
+
+// generateAccessToken generates a new access token with the given auth_time claim
+func (c *Client) generateAccessToken(userID, agentID, agentType string, authTime *jwt.NumericDate) (string, error) {
 	// Create a unique JWT ID for this session
 	jti := fmt.Sprintf("%s_%d", userID, time.Now().UnixNano())
 
@@ -164,20 +275,41 @@ func (c *Client) GenerateAccessToken(userID, agentID, agentType string) (string,
 		AgentID:   agentID,
 		AgentType: agentType,
 		TokenType: TokenTypeAccess,
+		AuthTime:  authTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(c.config.AccessTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    DefaultIssuer,
+			Audience:  c.config.Audience,
 			ID:        jti,
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Sign with the active rotated key when configured
+	if len(c.config.SigningKeys) > 0 {
+		token.Header["kid"] = c.config.ActiveKID
+		return token.SignedString([]byte(c.config.SigningKeys[c.config.ActiveKID]))
+	}
+
 	return token.SignedString([]byte(c.config.AccessTokenSecret))
 }
 
-// GenerateRefreshToken generates a new refresh token
+// GenerateRefreshToken generates a new refresh token for a user who has just authenticated
+// The auth_time claim is set to now
 func (c *Client) GenerateRefreshToken(userID, agentID, agentType string) (string, error) {
+	return c.generateRefreshToken(userID, agentID, agentType, jwt.NewNumericDate(time.Now()))
+}
+
+// ReissueRefreshToken generates a new refresh token for the subject of validated refresh token claims
+// The auth_time claim is carried over, so rotating refresh tokens does not extend it
+func (c *Client) ReissueRefreshToken(claims *TokenClaims) (string, error) {
+	return c.generateRefreshToken(claims.UserID, claims.AgentID, claims.AgentType, claims.AuthTime)
+}
+
+// generateRefreshToken generates a new refresh token with the given auth_time claim
+func (c *Client) generateRefreshToken(userID, agentID, agentType string, authTime *jwt.NumericDate) (string, error) {
 	// Create a unique token ID
 	tokenID := fmt.Sprintf("%s_%d", userID, time.Now().UnixNano())
 
@@ -186,10 +318,12 @@ func (c *Client) GenerateRefreshToken(userID, agentID, agentType string) (string
 		AgentID:   agentID,
 		AgentType: agentType,
 		TokenType: TokenTypeRefresh,
+		AuthTime:  authTime,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(c.config.RefreshTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    DefaultIssuer,
+			Audience:  c.config.Audience,
 			ID:        tokenID,
 		},
 	}
@@ -214,12 +348,29 @@ func (c *Client) GenerateRefreshToken(userID, agentID, agentType string) (string
 
 // ValidateAccessToken validates an access token
 func (c *Client) ValidateAccessToken(tokenString string) (*TokenClaims, error) {
-	return c.validateToken(tokenString, c.config.AccessTokenSecret, "access")
+	return c.validateToken(tokenString, c.config.AccessTokenSecret, TokenTypeAccess)
+}
+
+// ValidateAccessTokenMaxAge validates an access token and rejects it if the user authenticated more than maxAge ago
+// Used to require recent authentication for sensitive operations even when the token has not expired
+// Freshness is measured from the auth_time claim rather than iat, which every refresh resets;
+// tokens without auth_time are treated as too old
+func (c *Client) ValidateAccessTokenMaxAge(tokenString string, maxAge time.Duration) (*TokenClaims, error) {
+	claims, err := c.ValidateAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.AuthTime == nil || time.Since(claims.AuthTime.Time) > maxAge {
+		return nil, ErrTokenTooOldErr
+	}
+
+	return claims, nil
 }
 
 // ValidateRefreshToken validates a refresh token
 func (c *Client) ValidateRefreshToken(tokenString string) (*TokenClaims, error) {
-	claims, err := c.validateToken(tokenString, c.config.RefreshTokenSecret, "refresh")
+	claims, err := c.validateToken(tokenString, c.config.RefreshTokenSecret, TokenTypeRefresh)
 	if err != nil {
 		return nil, err
 	}
@@ -228,11 +379,11 @@ func (c *Client) ValidateRefreshToken(tokenString string) (*TokenClaims, error)
 	if c.config.Stateful && c.store != nil {
 		storedToken, err := c.store.Get(claims.UserID, claims.ID)
 		if err != nil {
-			return nil, fmt.Errorf("refresh token not found or invalid: %w", err)
+			return nil, fmt.Errorf("%w: %w", ErrRefreshTokenNotFoundOrInvalidErr, err)
 		}
 
 		if storedToken != tokenString {
-			return nil, errors.New(ErrRefreshTokenNotInStore)
+			return nil, ErrRefreshTokenNotInStoreErr
 		}
 	}
 
@@ -240,23 +391,51 @@ func (c *Client) ValidateRefreshToken(tokenString string) (*TokenClaims, error)
 }
 
 // validateToken is a helper function to validate tokens
+// The token_type claim must be present, recognized and equal to expectedType
 func (c *Client) validateToken(tokenString, secret, expectedType string) (*TokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	var parserOpts []jwt.ParserOption
+	if c.config.ExpectedAudience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(c.config.ExpectedAudience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, c.keyFunc(secret), parserOpts...)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*TokenClaims); ok && token.Valid {
-		if claims.TokenType != expectedType {
-			return nil, errors.New(ErrInvalidTokenType)
+		switch claims.TokenType {
+		case "":
+			return nil, ErrMissingTokenTypeErr
+		case TokenTypeAccess, TokenTypeRefresh:
+			if claims.TokenType != expectedType {
+				return nil, ErrInvalidTokenTypeErr
+			}
+		default:
+			return nil, ErrUnknownTokenTypeErr
 		}
 		return claims, nil
 	}
 
-	return nil, errors.New(ErrInvalidToken)
+	return nil, ErrInvalidTokenErr
+}
+
+// keyFunc returns a jwt.Keyfunc that selects the verification secret from the token's kid header
+// Tokens without a kid are verified with the given fallback secret
+func (c *Client) keyFunc(secret string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return []byte(secret), nil
+		}
+
+		key, found := c.config.SigningKeys[kid]
+		if !found {
+			return nil, ErrUnknownSigningKeyErr
+		}
+		return []byte(key), nil
+	}
 }
 
 // RefreshAccessToken refreshes an access token using a refresh token
@@ -274,18 +453,18 @@ func (c *Client) RefreshAccessToken(refreshToken string) (string, error) {
 		}
 	}
 
-	// Generate new access token with same user details
-	return c.GenerateAccessToken(claims.UserID, claims.AgentID, claims.AgentType)
+	// Generate new access token with same user details and authentication time
+	return c.ReissueAccessToken(claims)
 }
 
 // RevokeRefreshToken revokes a refresh token (only works in stateful mode)
 func (c *Client) RevokeRefreshToken(userID, tokenID string) error {
 	if !c.config.Stateful {
-		return errors.New(ErrRevokeNotSupportedStateless)
+		return ErrRevokeNotSupportedStatelessErr
 	}
 
 	if c.store == nil {
-		return errors.New(ErrNoStoreConfigured)
+		return ErrNoStoreConfiguredErr
 	}
 
 	return c.store.Delete(userID, tokenID)
@@ -294,11 +473,11 @@ func (c *Client) RevokeRefreshToken(userID, tokenID string) error {
 // RevokeAllRefreshTokens revokes all refresh tokens for a user (only works in stateful mode)
 func (c *Client) RevokeAllRefreshTokens(userID string) error {
 	if !c.config.Stateful {
-		return errors.New(ErrRevokeNotSupportedStateless)
+		return ErrRevokeNotSupportedStatelessErr
 	}
 
 	if c.store == nil {
-		return errors.New(ErrNoStoreConfigured)
+		return ErrNoStoreConfiguredErr
 	}
 
 	return c.store.DeleteAll(userID)
@@ -313,6 +492,41 @@ func (c *Client) Cleanup() error {
 	return c.store.Cleanup()
 }
 
+// StoreHealthy checks that the refresh token store is reachable (only relevant in stateful mode)
+// It returns ErrStoreEmptyErr when Redis holds no refresh tokens, which may mean the store was
+// flushed and previously issued refresh tokens will be rejected; callers usually log it as a warning
+func (c *Client) StoreHealthy(ctx context.Context) error {
+	if !c.config.Stateful {
+		return nil
+	}
+	if c.store == nil {
+		return ErrNoStoreConfiguredErr
+	}
+	if c.redisClient == nil {
+		return ErrRedisClientNotConfiguredErr
+	}
+
+	if err := c.redisClient.GetClient().Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrStoreUnreachableErr, err)
+	}
+
+	// Scan every master node and stop at the first refresh token found
+	err := c.redisClient.Scan(ctx, RefreshTokenKeyPattern, scanBatchSize, func(string) error {
+		return errFoundRefreshToken
+	})
+	switch {
+	case errors.Is(err, errFoundRefreshToken):
+		return nil
+	case err != nil:
+		return fmt.Errorf("%w: %w", ErrStoreUnreachableErr, err)
+	default:
+		return ErrStoreEmptyErr
+	}
+}
+
+// errFoundRefreshToken stops the StoreHealthy scan once a refresh token is found
+var errFoundRefreshToken = errors.New("refresh token found")
+
 // GetConfig returns the current configuration
 func (c *Client) GetConfig() TokenConfig {
 	return c.config
@@ -325,11 +539,8 @@ func (c *Client) IsStateful() bool {
 
 // GetTokenExpiration returns the expiration time of a token without full validation
 func (c *Client) GetTokenExpiration(tokenString string) (time.Time, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Use the appropriate secret based on token type if possible
-		// For now, try access token secret first
-		return []byte(c.config.AccessTokenSecret), nil
-	})
+	// Try access token secret first, honoring the kid header of rotated keys
+	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, c.keyFunc(c.config.AccessTokenSecret))
 
 	if err != nil {
 		// If access token secret fails, try refresh token secret
@@ -345,10 +556,10 @@ func (c *Client) GetTokenExpiration(tokenString string) (time.Time, error) {
 		if claims.ExpiresAt != nil {
 			return claims.ExpiresAt.Time, nil
 		}
-		return time.Time{}, errors.New("token has no expiration")
+		return time.Time{}, ErrTokenHasNoExpirationErr
 	}
 
-	return time.Time{}, errors.New("invalid token claims")
+	return time.Time{}, ErrInvalidTokenClaimsErr
 }
 
 // GetTokenRemainingTime returns the remaining time until token expiration
@@ -360,7 +571,7 @@ func (c *Client) GetTokenRemainingTime(tokenString string) (time.Duration, error
 
 	remaining := time.Until(expiry)
 	if remaining < 0 {
-		return 0, errors.New("token is expired")
+		return 0, ErrTokenIsExpiredErr
 	}
 
 	return remaining, nil
@@ -389,16 +600,21 @@ func (c *Client) GetRefreshTokenExpiry() time.Duration {
 // CreateSession creates a new user session with device tracking
 func (c *Client) CreateSession(ctx context.Context, userID, agentID, agentType, deviceInfo, ipAddress string) (*SessionInfo, string, error) {
 	if !c.config.Stateful || c.redisClient == nil {
-		return nil, "", errors.New(ErrSessionRequiresStatefulRedis)
+		return nil, "", ErrSessionRequiresStatefulRedisErr
 	}
 
 	sessionID := fmt.Sprintf("%s_%d", userID, time.Now().UnixNano())
-	lastSeen := time.Now().Format(time.RFC3339)
+	createdAt := time.Now().Format(time.RFC3339)
 
 	sessionInfo := &SessionInfo{
+		SessionID:  sessionID,
+		UserID:     userID,
+		AgentID:    agentID,
+		AgentType:  agentType,
 		DeviceInfo: deviceInfo,
 		IPAddress:  ipAddress,
-		LastSeen:   lastSeen,
+		CreatedAt:  createdAt,
+		LastSeen:   createdAt,
 		Status:     SessionStatusActive,
 	}
 
@@ -410,9 +626,9 @@ func (c *Client) CreateSession(ctx context.Context, userID, agentID, agentType,
 		"agent_type":  agentType,
 		"device_info": deviceInfo,
 		"ip_address":  ipAddress,
-		"last_seen":   lastSeen,
+		"last_seen":   createdAt,
 		"status":      SessionStatusActive,
-		"created_at":  time.Now().Format(time.RFC3339),
+		"created_at":  createdAt,
 	})
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to store session info: %w", err)
@@ -424,13 +640,23 @@ func (c *Client) CreateSession(ctx context.Context, userID, agentID, agentType,
 		return nil, "", fmt.Errorf("failed to set session expiry: %w", err)
 	}
 
+	// Index the session under its user; the set outlives every session it holds since
+	// its expiry is pushed back with each new session
+	userSessionsKey := UserSessionsKeyPrefix + userID
+	if err := c.redisClient.SAdd(ctx, userSessionsKey, sessionID); err != nil {
+		return nil, "", fmt.Errorf("failed to index session: %w", err)
+	}
+	if err := c.redisClient.Expire(ctx, userSessionsKey, SessionExpiry); err != nil {
+		return nil, "", fmt.Errorf("failed to set user sessions expiry: %w", err)
+	}
+
 	return sessionInfo, sessionID, nil
 }
 
 // GetSession retrieves session information by session ID
 func (c *Client) GetSession(ctx context.Context, sessionID string) (*SessionInfo, error) {
 	if c.redisClient == nil {
-		return nil, errors.New(ErrRedisClientNotConfigured)
+		return nil, ErrRedisClientNotConfiguredErr
 	}
 
 	sessionKey := fmt.Sprintf("%s%s", SessionKeyPrefix, sessionID)
@@ -440,28 +666,21 @@ func (c *Client) GetSession(ctx context.Context, sessionID string) (*SessionInfo
 	}
 
 	if !exists {
-		return nil, errors.New(ErrSessionNotFound)
+		return nil, ErrSessionNotFoundErr
 	}
 
-	fields, err := c.redisClient.HMGet(ctx, sessionKey, "device_info", "ip_address", "last_seen", "status")
+	fields, err := c.redisClient.HMGet(ctx, sessionKey, sessionFields...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session info: %w", err)
 	}
 
-	sessionInfo := &SessionInfo{
-		DeviceInfo: getStringValue(fields[0]),
-		IPAddress:  getStringValue(fields[1]),
-		LastSeen:   getStringValue(fields[2]),
-		Status:     getStringValue(fields[3]),
-	}
-
-	return sessionInfo, nil
+	return sessionInfoFromFields(sessionID, fields), nil
 }
 
 // UpdateSessionLastSeen updates the last seen timestamp for a session
 func (c *Client) UpdateSessionLastSeen(ctx context.Context, sessionID string) error {
 	if c.redisClient == nil {
-		return errors.New(ErrRedisClientNotConfigured)
+		return ErrRedisClientNotConfiguredErr
 	}
 
 	sessionKey := fmt.Sprintf("%s%s", SessionKeyPrefix, sessionID)
@@ -478,7 +697,7 @@ func (c *Client) UpdateSessionLastSeen(ctx context.Context, sessionID string) er
 // EndSession marks a session as inactive
 func (c *Client) EndSession(ctx context.Context, sessionID string) error {
 	if c.redisClient == nil {
-		return errors.New(ErrRedisClientNotConfigured)
+		return ErrRedisClientNotConfiguredErr
 	}
 
 	sessionKey := fmt.Sprintf("%s%s", SessionKeyPrefix, sessionID)
@@ -490,35 +709,130 @@ func (c *Client) EndSession(ctx context.Context, sessionID string) error {
 	return nil
 }
 
-// GetUserSessions retrieves all active sessions for a user
+// EndAllUserSessions marks every session belonging to a user as inactive
+// Used for forced logout, e.g. when an account is deactivated
+func (c *Client) EndAllUserSessions(ctx context.Context, userID string) error {
+	opts := SessionListOptions{ActiveOnly: true}
+	for {
+		page, err := c.ListUserSessions(ctx, userID, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range page.Sessions {
+			if err := c.EndSession(ctx, session.SessionID); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == 0 {
+			return nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// GetUserSessions retrieves the IDs of the first page of sessions for a user
+// Use ListUserSessions to page through all sessions or filter by status
 func (c *Client) GetUserSessions(ctx context.Context, userID string) ([]string, error) {
+	page, err := c.ListUserSessions(ctx, userID, SessionListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	userSessions := make([]string, 0, len(page.Sessions))
+	for _, session := range page.Sessions {
+		userSessions = append(userSessions, session.SessionID)
+	}
+
+	return userSessions, nil
+}
+
+// ListUserSessions returns a page of a user's sessions using an SSCAN cursor over the user's session set
+// SSCAN batches are not split, so a page may hold slightly more than opts.Limit sessions
+// Sessions that have expired since they were indexed are dropped from the set
+func (c *Client) ListUserSessions(ctx context.Context, userID string, opts SessionListOptions) (*SessionPage, error) {
 	if c.redisClient == nil {
-		return nil, errors.New(ErrRedisClientNotConfigured)
+		return nil, ErrRedisClientNotConfiguredErr
 	}
 
-	// Find all session keys for this user
-	pattern := SessionKeyPattern
-	keys, err := c.redisClient.GetClient().Keys(ctx, pattern).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to find user sessions: %w", err)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultSessionPageSize
 	}
 
-	var userSessions []string
-	for _, key := range keys {
-		// Check if this session belongs to the user
-		userIDField, err := c.redisClient.HGet(ctx, key, "user_id")
-		if err == nil && userIDField == userID {
-			// Extract session ID from key (remove "session:" prefix)
-			sessionID := key[len(SessionKeyPrefix):]
-			userSessions = append(userSessions, sessionID)
+	userSessionsKey := UserSessionsKeyPrefix + userID
+	page := &SessionPage{Sessions: []*SessionInfo{}}
+	cursor := opts.Cursor
+	for {
+		sessionIDs, next, err := c.redisClient.GetClient().SScan(ctx, userSessionsKey, cursor, "", int64(limit)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find user sessions: %w", err)
+		}
+
+		// Fetch the fields of every session in the batch in one round trip
+		cmds := make([]*goredis.SliceCmd, len(sessionIDs))
+		err = c.redisClient.Pipeline(ctx, func(pipe redis.Pipe) error {
+			for i, sessionID := range sessionIDs {
+				cmds[i] = pipe.HMGet(SessionKeyPrefix+sessionID, sessionFields...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user sessions: %w", err)
+		}
+
+		var expired []interface{}
+		for i, sessionID := range sessionIDs {
+			fields, err := cmds[i].Result()
+			if err != nil {
+				continue
+			}
+
+			session := sessionInfoFromFields(sessionID, fields)
+			if session.UserID == "" {
+				// The session hash has expired or been cleaned up
+				expired = append(expired, sessionID)
+				continue
+			}
+			if session.UserID != userID {
+				continue
+			}
+			if opts.ActiveOnly && session.Status != SessionStatusActive {
+				continue
+			}
+			page.Sessions = append(page.Sessions, session)
+		}
+
+		if len(expired) > 0 {
+			// Best effort; stale members are skipped anyway and retried on the next listing
+			_ = c.redisClient.GetClient().SRem(ctx, userSessionsKey, expired...).Err()
+		}
+
+		cursor = next
+		if cursor == 0 || len(page.Sessions) >= limit {
+			break
 		}
 	}
 
-	return userSessions, nil
+	page.NextCursor = cursor
+	return page, nil
 }
 
 // GenerateTokensWithSession generates access and refresh tokens with session tracking
+// The auth_time claim of both tokens is set to now
 func (c *Client) GenerateTokensWithSession(ctx context.Context, userID, agentID, agentType, deviceInfo, ipAddress string) (string, string, string, error) {
+	return c.generateTokensWithSession(ctx, userID, agentID, agentType, deviceInfo, ipAddress, jwt.NewNumericDate(time.Now()))
+}
+
+// ReissueTokensWithSession generates access and refresh tokens with session tracking for the subject of
+// validated refresh token claims, carrying over their auth_time claim
+func (c *Client) ReissueTokensWithSession(ctx context.Context, claims *TokenClaims, deviceInfo, ipAddress string) (string, string, string, error) {
+	return c.generateTokensWithSession(ctx, claims.UserID, claims.AgentID, claims.AgentType, deviceInfo, ipAddress, claims.AuthTime)
+}
+
+// generateTokensWithSession generates access and refresh tokens with the given auth_time claim and a new session
+func (c *Client) generateTokensWithSession(ctx context.Context, userID, agentID, agentType, deviceInfo, ipAddress string, authTime *jwt.NumericDate) (string, string, string, error) {
 	// Create session
 	sessionInfo, sessionID, err := c.CreateSession(ctx, userID, agentID, agentType, deviceInfo, ipAddress)
 	if err != nil {
@@ -526,13 +840,13 @@ func (c *Client) GenerateTokensWithSession(ctx context.Context, userID, agentID,
 	}
 
 	// Generate access token with session info
-	accessToken, err := c.GenerateAccessToken(userID, agentID, agentType)
+	accessToken, err := c.generateAccessToken(userID, agentID, agentType, authTime)
 	if err != nil {
 		return "", "", "", err
 	}
 
 	// Generate refresh token
-	refreshToken, err := c.GenerateRefreshToken(userID, agentID, agentType)
+	refreshToken, err := c.generateRefreshToken(userID, agentID, agentType, authTime)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -541,6 +855,82 @@ func (c *Client) GenerateTokensWithSession(ctx context.Context, userID, agentID,
 	return accessToken, refreshToken, sessionID, nil
 }
 
+// StartSessionJanitor starts a background goroutine that periodically deletes inactive sessions
+// whose last activity is older than the configured grace period
+// The goroutine stops when ctx is cancelled; this is a no-op in stateless mode or without Redis
+func (c *Client) StartSessionJanitor(ctx context.Context, interval time.Duration) {
+	if !c.config.Stateful || c.redisClient == nil || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Errors are retried on the next tick
+				_, _ = c.cleanupInactiveSessions(ctx)
+			}
+		}
+	}()
+}
+
+// cleanupInactiveSessions deletes inactive sessions older than the grace period
+// Returns the number of deleted sessions
+func (c *Client) cleanupInactiveSessions(ctx context.Context) (int, error) {
+	var keys []string
+	err := c.redisClient.Scan(ctx, SessionKeyPattern, scanBatchSize, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find sessions: %w", err)
+	}
+
+	removed := 0
+	for _, key := range keys {
+		fields, err := c.redisClient.HMGet(ctx, key, "status", "last_seen")
+		if err != nil {
+			continue
+		}
+
+		if getStringValue(fields[0]) != SessionStatusInactive {
+			continue
+		}
+
+		lastSeen, err := time.Parse(time.RFC3339, getStringValue(fields[1]))
+		if err == nil && time.Since(lastSeen) < c.config.SessionGracePeriod {
+			continue
+		}
+
+		if err := c.redisClient.Del(ctx, key); err != nil {
+			return removed, fmt.Errorf("failed to delete session: %w", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// sessionInfoFromFields builds a SessionInfo from values fetched with sessionFields
+func sessionInfoFromFields(sessionID string, fields []interface{}) *SessionInfo {
+	return &SessionInfo{
+		SessionID:  sessionID,
+		UserID:     getStringValue(fields[0]),
+		AgentID:    getStringValue(fields[1]),
+		AgentType:  getStringValue(fields[2]),
+		DeviceInfo: getStringValue(fields[3]),
+		IPAddress:  getStringValue(fields[4]),
+		CreatedAt:  getStringValue(fields[5]),
+		LastSeen:   getStringValue(fields[6]),
+		Status:     getStringValue(fields[7]),
+	}
+}
+
 // Helper function to safely get string value from interface{}
 func getStringValue(value interface{}) string {
 	if value == nil {
@@ -2,6 +2,8 @@ package jwt
 
 import (
 	"time"
+
+	"monorepo/pkg/redis"
 )
 
 // TokenConfig holds the configuration for JWT tokens
@@ -11,16 +13,39 @@ type TokenConfig struct {
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
 	Stateful           bool
+	// SessionGracePeriod is how long an inactive session is kept before the janitor deletes it
+	SessionGracePeriod time.Duration
+	// SigningKeys maps key IDs to access token secrets, allowing secrets to be rotated
+	SigningKeys map[string]string
+	// ActiveKID is the key ID in SigningKeys used to sign new access tokens
+	ActiveKID string
+	// Audience is written to the aud claim of generated tokens
+	Audience []string
+	// ExpectedAudience, when set, must be present in the aud claim of validated tokens
+	ExpectedAudience string
 }
 
 // NewWithConfig creates a new JWT client from a config struct
 func NewWithConfig(config TokenConfig) (JWTClient, error) {
-	opts := []Option{
+	return New(configOptions(config)...)
+}
+
+// NewStatefulWithRedisConfig creates a stateful JWT client backed by Redis from a config struct
+func NewStatefulWithRedisConfig(redisClient redis.RedisClient, config TokenConfig) (JWTClient, error) {
+	return NewStatefulWithRedis(redisClient, configOptions(config)...)
+}
+
+// configOptions converts a config struct into the equivalent options
+func configOptions(config TokenConfig) []Option {
+	return []Option{
 		WithAccessTokenSecret(config.AccessTokenSecret),
 		WithRefreshTokenSecret(config.RefreshTokenSecret),
 		WithAccessTokenExpiry(config.AccessTokenExpiry),
 		WithRefreshTokenExpiry(config.RefreshTokenExpiry),
 		WithStateful(config.Stateful),
+		WithSessionGracePeriod(config.SessionGracePeriod),
+		WithSigningKeys(config.SigningKeys, config.ActiveKID),
+		WithAudience(config.Audience...),
+		WithExpectedAudience(config.ExpectedAudience),
 	}
-	return New(opts...)
 }
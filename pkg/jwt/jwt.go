@@ -12,6 +12,8 @@ type TokenClaims struct {
 	AgentID   string `json:"agent_id"`
 	AgentType string `json:"agent_type"`
 	TokenType string `json:"token_type"`
+	// AuthTime is when the user last authenticated with credentials; refreshing tokens carries it over unchanged
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
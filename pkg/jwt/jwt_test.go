@@ -2,15 +2,22 @@ package jwt
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-redis/redismock/v9"
+	"github.com/golang-jwt/jwt/v5"
 	goredis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"monorepo/pkg/redis"
 )
 
 // Test data constants
@@ -121,6 +128,258 @@ func TestWrongTokenType(t *testing.T) {
 	assert.Error(t, err, "ValidateAccessToken should return error for wrong token type")
 }
 
+func TestValidateAccessTokenMaxAge(t *testing.T) {
+	jwtManager := createTestJWTManager(t)
+
+	t.Run("recent token passes", func(t *testing.T) {
+		token, err := jwtManager.GenerateAccessToken(testUserID, testAgentID, testAgentType)
+		require.NoError(t, err, "GenerateAccessToken should not return error")
+
+		claims, err := jwtManager.ValidateAccessTokenMaxAge(token, 5*time.Minute)
+		require.NoError(t, err, "Recent token should satisfy the fresh-token requirement")
+		assertTokenClaims(t, claims, testUserID, testAgentID, testAgentType, TokenTypeAccess)
+	})
+
+	// signAccess signs an unexpired access token issued now with the given auth_time
+	signAccess := func(authTime *jwt.NumericDate) string {
+		claims := TokenClaims{
+			UserID:    testUserID,
+			AgentID:   testAgentID,
+			AgentType: testAgentType,
+			TokenType: TokenTypeAccess,
+			AuthTime:  authTime,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				Issuer:    DefaultIssuer,
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAccessSecret))
+		require.NoError(t, err, "Failed to sign test token")
+		return token
+	}
+
+	t.Run("recently issued token of an old authentication is rejected", func(t *testing.T) {
+		token := signAccess(jwt.NewNumericDate(time.Now().Add(-10 * time.Minute)))
+
+		_, err := jwtManager.ValidateAccessToken(token)
+		require.NoError(t, err, "Old token should still be valid without a max age")
+
+		_, err = jwtManager.ValidateAccessTokenMaxAge(token, 5*time.Minute)
+		assert.ErrorIs(t, err, ErrTokenTooOldErr, "Freshness should be measured from auth_time, not iat")
+	})
+
+	t.Run("token without auth_time is rejected", func(t *testing.T) {
+		_, err := jwtManager.ValidateAccessTokenMaxAge(signAccess(nil), 5*time.Minute)
+		assert.ErrorIs(t, err, ErrTokenTooOldErr, "A token without auth_time should not count as fresh")
+	})
+
+	t.Run("refresh keeps the login auth_time", func(t *testing.T) {
+		authTime := jwt.NewNumericDate(time.Now().Add(-10 * time.Minute))
+		refreshToken, err := jwtManager.ReissueRefreshToken(&TokenClaims{UserID: testUserID, AgentID: testAgentID, AgentType: testAgentType, AuthTime: authTime})
+		require.NoError(t, err, "ReissueRefreshToken should not return error")
+
+		accessToken, err := jwtManager.RefreshAccessToken(refreshToken)
+		require.NoError(t, err, "RefreshAccessToken should not return error")
+
+		claims, err := jwtManager.ValidateAccessToken(accessToken)
+		require.NoError(t, err, "Refreshed token should be valid")
+		require.NotNil(t, claims.AuthTime, "Refreshed token should carry auth_time")
+		assert.Equal(t, authTime.Unix(), claims.AuthTime.Unix(), "auth_time should be carried over")
+
+		_, err = jwtManager.ValidateAccessTokenMaxAge(accessToken, 5*time.Minute)
+		assert.ErrorIs(t, err, ErrTokenTooOldErr, "A refresh should not make the token fresh")
+	})
+
+	t.Run("invalid token still fails", func(t *testing.T) {
+		_, err := jwtManager.ValidateAccessTokenMaxAge("invalid.token", 5*time.Minute)
+		require.Error(t, err, "Invalid token should fail validation")
+		assert.NotErrorIs(t, err, ErrTokenTooOldErr, "Invalid token should not be reported as too old")
+	})
+}
+
+func TestTypedErrors(t *testing.T) {
+	t.Run("missing secrets", func(t *testing.T) {
+		_, err := New(WithAccessTokenSecret(""))
+		assert.ErrorIs(t, err, ErrAccessTokenSecretRequiredErr, "Error should match ErrAccessTokenSecretRequiredErr")
+
+		_, err = New(WithRefreshTokenSecret(""))
+		assert.ErrorIs(t, err, ErrRefreshTokenSecretRequiredErr, "Error should match ErrRefreshTokenSecretRequiredErr")
+	})
+
+	t.Run("identical secrets", func(t *testing.T) {
+		_, err := New(
+			WithAccessTokenSecret("shared-secret"),
+			WithRefreshTokenSecret("shared-secret"),
+		)
+		assert.ErrorIs(t, err, ErrSecretsMustDifferErr, "Error should match ErrSecretsMustDifferErr")
+
+		_, err = New(
+			WithRefreshTokenSecret("shared-secret"),
+			WithSigningKeys(map[string]string{"k1": "shared-secret"}, "k1"),
+		)
+		assert.ErrorIs(t, err, ErrSecretsMustDifferErr, "Signing keys should not reuse the refresh secret")
+
+		_, err = New(
+			WithAccessTokenSecret("custom-access-secret"),
+			WithRefreshTokenSecret("custom-refresh-secret"),
+		)
+		assert.NoError(t, err, "Distinct secrets overriding the defaults should be accepted")
+	})
+
+	t.Run("wrong token type", func(t *testing.T) {
+		// Same secret for both token types so only the type check can fail;
+		// New rejects this configuration, so the client is built directly
+		jwtManager := &Client{config: TokenConfig{
+			AccessTokenSecret:  testAccessSecret,
+			RefreshTokenSecret: testAccessSecret,
+			AccessTokenExpiry:  testAccessExpiry,
+			RefreshTokenExpiry: testRefreshExpiry,
+		}}
+
+		refreshToken, err := jwtManager.GenerateRefreshToken(testUserID, testAgentID, testAgentType)
+		require.NoError(t, err, "GenerateRefreshToken should not return error")
+
+		_, err = jwtManager.ValidateAccessToken(refreshToken)
+		assert.ErrorIs(t, err, ErrInvalidTokenTypeErr, "Error should match ErrInvalidTokenTypeErr")
+	})
+
+	t.Run("missing or unknown token type", func(t *testing.T) {
+		jwtManager := createTestJWTManager(t)
+
+		signWithType := func(tokenType string) string {
+			claims := TokenClaims{
+				UserID:    testUserID,
+				TokenType: tokenType,
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
+				},
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAccessSecret))
+			require.NoError(t, err, "Failed to sign test token")
+			return token
+		}
+
+		_, err := jwtManager.ValidateAccessToken(signWithType(""))
+		assert.ErrorIs(t, err, ErrMissingTokenTypeErr, "Error should match ErrMissingTokenTypeErr")
+
+		_, err = jwtManager.ValidateAccessToken(signWithType("admin"))
+		assert.ErrorIs(t, err, ErrUnknownTokenTypeErr, "Error should match ErrUnknownTokenTypeErr")
+		assert.NotErrorIs(t, err, ErrInvalidTokenTypeErr, "Unknown token type should be reported distinctly")
+	})
+
+	t.Run("revoke in stateless mode", func(t *testing.T) {
+		jwtManager := createTestJWTManager(t)
+
+		err := jwtManager.RevokeRefreshToken(testUserID, "token123")
+		assert.ErrorIs(t, err, ErrRevokeNotSupportedStatelessErr, "Error should match ErrRevokeNotSupportedStatelessErr")
+
+		err = jwtManager.RevokeAllRefreshTokens(testUserID)
+		assert.ErrorIs(t, err, ErrRevokeNotSupportedStatelessErr, "Error should match ErrRevokeNotSupportedStatelessErr")
+	})
+
+	t.Run("refresh token missing from store", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		statelessManager := createTestJWTManager(t)
+
+		refreshToken, err := statelessManager.GenerateRefreshToken(testUserID, testAgentID, testAgentType)
+		require.NoError(t, err, "GenerateRefreshToken should not return error")
+
+		claims, err := statelessManager.ValidateRefreshToken(refreshToken)
+		require.NoError(t, err, "ValidateRefreshToken should not return error")
+
+		mock.ExpectGet(fmt.Sprintf("refresh_token:%s:%s", testUserID, claims.ID)).RedisNil()
+
+		_, err = jwtClient.ValidateRefreshToken(refreshToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenNotFoundOrInvalidErr, "Error should match ErrRefreshTokenNotFoundOrInvalidErr")
+		assert.ErrorIs(t, err, goredis.Nil, "Error should wrap the underlying store error")
+	})
+}
+
+func TestAudienceValidation(t *testing.T) {
+	supplierClient, err := NewStateless(
+		WithAccessTokenSecret(testAccessSecret),
+		WithRefreshTokenSecret(testRefreshSecret),
+		WithAudience("supplier-service"),
+	)
+	require.NoError(t, err, "NewStateless should not return error")
+
+	agentClient, err := NewStateless(
+		WithAccessTokenSecret(testAccessSecret),
+		WithRefreshTokenSecret(testRefreshSecret),
+		WithAudience("agent-service"),
+		WithExpectedAudience("agent-service"),
+	)
+	require.NoError(t, err, "NewStateless should not return error")
+
+	// Token minted for another service must be rejected
+	supplierToken, err := supplierClient.GenerateAccessToken(testUserID, testAgentID, testAgentType)
+	require.NoError(t, err, "GenerateAccessToken should not return error")
+
+	_, err = agentClient.ValidateAccessToken(supplierToken)
+	assert.Error(t, err, "ValidateAccessToken should reject token with wrong audience")
+
+	// Token minted for this service must be accepted
+	agentToken, err := agentClient.GenerateAccessToken(testUserID, testAgentID, testAgentType)
+	require.NoError(t, err, "GenerateAccessToken should not return error")
+
+	claims, err := agentClient.ValidateAccessToken(agentToken)
+	require.NoError(t, err, "ValidateAccessToken should accept token with matching audience")
+	assert.Equal(t, []string{"agent-service"}, []string(claims.Audience), "Audience should match")
+
+	// Without an expected audience any audience is accepted
+	_, err = supplierClient.ValidateAccessToken(agentToken)
+	assert.NoError(t, err, "ValidateAccessToken should ignore audience when none is expected")
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	oldClient, err := NewStateless(
+		WithAccessTokenSecret(testAccessSecret),
+		WithRefreshTokenSecret(testRefreshSecret),
+		WithSigningKeys(map[string]string{"v1": "secret-v1"}, "v1"),
+	)
+	require.NoError(t, err, "NewStateless should not return error")
+
+	rotatedClient, err := NewStateless(
+		WithAccessTokenSecret(testAccessSecret),
+		WithRefreshTokenSecret(testRefreshSecret),
+		WithSigningKeys(map[string]string{"v1": "secret-v1", "v2": "secret-v2"}, "v2"),
+	)
+	require.NoError(t, err, "NewStateless should not return error")
+
+	// Token signed with the previous key stays valid after rotation
+	oldToken, err := oldClient.GenerateAccessToken(testUserID, testAgentID, testAgentType)
+	require.NoError(t, err, "GenerateAccessToken should not return error")
+
+	claims, err := rotatedClient.ValidateAccessToken(oldToken)
+	require.NoError(t, err, "ValidateAccessToken should accept token signed with previous key")
+	assertTokenClaims(t, claims, testUserID, testAgentID, testAgentType, TokenTypeAccess)
+
+	// New tokens are signed with the active key, unknown to the old client
+	newToken, err := rotatedClient.GenerateAccessToken(testUserID, testAgentID, testAgentType)
+	require.NoError(t, err, "GenerateAccessToken should not return error")
+
+	_, err = oldClient.ValidateAccessToken(newToken)
+	assert.Error(t, err, "ValidateAccessToken should reject token signed with unknown key")
+
+	// Tokens without kid fall back to the single access token secret
+	legacyToken, err := createTestJWTManager(t).GenerateAccessToken(testUserID, testAgentID, testAgentType)
+	require.NoError(t, err, "GenerateAccessToken should not return error")
+
+	_, err = rotatedClient.ValidateAccessToken(legacyToken)
+	assert.NoError(t, err, "ValidateAccessToken should accept token without kid")
+}
+
+func TestSigningKeyRotation_ActiveKeyMissing(t *testing.T) {
+	_, err := NewStateless(
+		WithAccessTokenSecret(testAccessSecret),
+		WithRefreshTokenSecret(testRefreshSecret),
+		WithSigningKeys(map[string]string{"v1": "secret-v1"}, "v2"),
+	)
+	assert.Error(t, err, "NewStateless should fail when active key is not in signing keys")
+}
+
 func TestTokenExpiry(t *testing.T) {
 	jwtManager, err := NewStateless(
 		WithAccessTokenSecret("access-secret-key"),
@@ -383,6 +642,25 @@ func (m *mockRedisClientForStore) Get(ctx context.Context, key string) (string,
 	return m.client.Get(ctx, key).Result()
 }
 
+func (m *mockRedisClientForStore) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return m.client.Set(ctx, key, data, expiration).Err()
+}
+
+func (m *mockRedisClientForStore) GetJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := m.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
 func (m *mockRedisClientForStore) Del(ctx context.Context, key string) error {
 	return m.client.Del(ctx, key).Err()
 }
@@ -400,6 +678,22 @@ func (m *mockRedisClientForStore) TTL(ctx context.Context, key string) (time.Dur
 	return m.client.TTL(ctx, key).Result()
 }
 
+func (m *mockRedisClientForStore) Incr(ctx context.Context, key string) (int64, error) {
+	return m.client.Incr(ctx, key).Result()
+}
+
+func (m *mockRedisClientForStore) IncrBy(ctx context.Context, key string, n int64) (int64, error) {
+	return m.client.IncrBy(ctx, key, n).Result()
+}
+
+func (m *mockRedisClientForStore) Decr(ctx context.Context, key string) (int64, error) {
+	return m.client.Decr(ctx, key).Result()
+}
+
+func (m *mockRedisClientForStore) IncrWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return 0, errors.New("IncrWithExpiry not supported by mock")
+}
+
 func (m *mockRedisClientForStore) HMSet(ctx context.Context, key string, values map[string]interface{}) error {
 	return m.client.HMSet(ctx, key, values).Err()
 }
@@ -432,6 +726,30 @@ func (m *mockRedisClientForStore) RPop(ctx context.Context, key string) (string,
 	return m.client.RPop(ctx, key).Result()
 }
 
+func (m *mockRedisClientForStore) Pipeline(ctx context.Context, fn func(redis.Pipe) error) error {
+	return redis.ExecPipeline(ctx, m.client, fn)
+}
+
+func (m *mockRedisClientForStore) Scan(ctx context.Context, match string, count int64, fn func(key string) error) error {
+	return redis.ScanKeys(ctx, m.client, match, count, fn)
+}
+
+func (m *mockRedisClientForStore) AcquireLock(ctx context.Context, key string, ttl time.Duration) (redis.Lock, error) {
+	return nil, errors.New("locks not supported by mock")
+}
+
+func (m *mockRedisClientForStore) Publish(ctx context.Context, channel string, message interface{}) error {
+	return m.client.Publish(ctx, channel, message).Err()
+}
+
+func (m *mockRedisClientForStore) Subscribe(ctx context.Context, channels ...string) (<-chan redis.Message, func() error, error) {
+	return nil, nil, errors.New("subscribe not supported by mock")
+}
+
+func (m *mockRedisClientForStore) Ping(ctx context.Context) error {
+	return m.client.Ping(ctx).Err()
+}
+
 func (m *mockRedisClientForStore) Close() error {
 	return m.client.Close()
 }
@@ -532,7 +850,7 @@ func TestRedisStore_DeleteAll(t *testing.T) {
 		"refresh_token:user123:token2",
 	}
 
-	mock.ExpectKeys(pattern).SetVal(keys)
+	mock.ExpectScan(0, pattern, scanBatchSize).SetVal(keys, 0)
 	mock.ExpectDel(keys[0], keys[1]).SetVal(2)
 
 	err := store.DeleteAll(userID)
@@ -636,6 +954,23 @@ func (m *mockRedisClient) Get(ctx context.Context, key string) (string, error) {
 	return "", fmt.Errorf("key not found")
 }
 
+func (m *mockRedisClient) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.data[key] = string(data)
+	return nil
+}
+
+func (m *mockRedisClient) GetJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	value, exists := m.data[key]
+	if !exists {
+		return false, nil
+	}
+	return true, json.Unmarshal([]byte(value), dest)
+}
+
 func (m *mockRedisClient) Del(ctx context.Context, key string) error {
 	delete(m.data, key)
 	return nil
@@ -650,6 +985,25 @@ func (m *mockRedisClient) TTL(ctx context.Context, key string) (time.Duration, e
 	return time.Hour, nil
 }
 
+func (m *mockRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return m.IncrBy(ctx, key, 1)
+}
+
+func (m *mockRedisClient) IncrBy(ctx context.Context, key string, n int64) (int64, error) {
+	value, _ := strconv.ParseInt(m.data[key], 10, 64)
+	value += n
+	m.data[key] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
+func (m *mockRedisClient) Decr(ctx context.Context, key string) (int64, error) {
+	return m.IncrBy(ctx, key, -1)
+}
+
+func (m *mockRedisClient) IncrWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return m.IncrBy(ctx, key, 1)
+}
+
 func (m *mockRedisClient) HSet(ctx context.Context, key string, field string, value interface{}) error {
 	return nil
 }
@@ -682,6 +1036,30 @@ func (m *mockRedisClient) RPop(ctx context.Context, key string) (string, error)
 	return "", nil
 }
 
+func (m *mockRedisClient) Pipeline(ctx context.Context, fn func(redis.Pipe) error) error {
+	return errors.New("pipeline not supported by mock")
+}
+
+func (m *mockRedisClient) Scan(ctx context.Context, match string, count int64, fn func(key string) error) error {
+	return nil
+}
+
+func (m *mockRedisClient) AcquireLock(ctx context.Context, key string, ttl time.Duration) (redis.Lock, error) {
+	return nil, errors.New("locks not supported by mock")
+}
+
+func (m *mockRedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return nil
+}
+
+func (m *mockRedisClient) Subscribe(ctx context.Context, channels ...string) (<-chan redis.Message, func() error, error) {
+	return nil, nil, errors.New("subscribe not supported by mock")
+}
+
+func (m *mockRedisClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 func (m *mockRedisClient) Close() error {
 	return nil
 }
@@ -1106,6 +1484,10 @@ func TestCreateSession(t *testing.T) {
 	assert.Equal(t, ipAddress, sessionInfo.IPAddress, "IP address should match")
 	assert.Equal(t, SessionStatusActive, sessionInfo.Status, "Status should be active")
 	assert.NotEmpty(t, sessionInfo.LastSeen, "Last seen should not be empty")
+	assert.Equal(t, userID, sessionInfo.UserID, "User ID should match")
+	assert.Equal(t, agentID, sessionInfo.AgentID, "Agent ID should match")
+	assert.Equal(t, agentType, sessionInfo.AgentType, "Agent type should match")
+	assert.Equal(t, sessionInfo.LastSeen, sessionInfo.CreatedAt, "Created at should match initial last seen")
 
 	// Verify session ID format
 	assert.Contains(t, sessionID, userID, "Session ID should contain user ID")
@@ -1124,14 +1506,24 @@ func TestGetSession(t *testing.T) {
 	mock.ExpectExists(sessionKey).SetVal(1)
 
 	// Mock the HMGet call
+	expectedUserID := "user123"
+	expectedAgentID := "agent123"
+	expectedAgentType := "IATA"
 	expectedDeviceInfo := "Chrome/91.0"
 	expectedIPAddress := "192.168.1.1"
+	expectedCreatedAt := "2023-10-04T11:00:00Z"
 	expectedLastSeen := "2023-10-04T12:00:00Z"
 	expectedStatus := SessionStatusActive
 
-	mock.ExpectHMGet(sessionKey, "device_info", "ip_address", "last_seen", "status").SetVal([]interface{}{
+	mock.ExpectHMGet(sessionKey,
+		"user_id", "agent_id", "agent_type", "device_info", "ip_address", "created_at", "last_seen", "status",
+	).SetVal([]interface{}{
+		expectedUserID,
+		expectedAgentID,
+		expectedAgentType,
 		expectedDeviceInfo,
 		expectedIPAddress,
+		expectedCreatedAt,
 		expectedLastSeen,
 		expectedStatus,
 	})
@@ -1141,8 +1533,12 @@ func TestGetSession(t *testing.T) {
 	require.NotNil(t, sessionInfo, "Session info should not be nil")
 
 	// Verify session info
+	assert.Equal(t, expectedUserID, sessionInfo.UserID, "User ID should match")
+	assert.Equal(t, expectedAgentID, sessionInfo.AgentID, "Agent ID should match")
+	assert.Equal(t, expectedAgentType, sessionInfo.AgentType, "Agent type should match")
 	assert.Equal(t, expectedDeviceInfo, sessionInfo.DeviceInfo, "Device info should match")
 	assert.Equal(t, expectedIPAddress, sessionInfo.IPAddress, "IP address should match")
+	assert.Equal(t, expectedCreatedAt, sessionInfo.CreatedAt, "Created at should match")
 	assert.Equal(t, expectedLastSeen, sessionInfo.LastSeen, "Last seen should match")
 	assert.Equal(t, expectedStatus, sessionInfo.Status, "Status should match")
 
@@ -1183,20 +1579,48 @@ func TestEndSession(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
 }
 
+// sessionHash returns HMGet values for a session in sessionFields order
+func sessionHash(userID, status string) []interface{} {
+	return []interface{}{userID, "agent123", "IATA", "Chrome", "192.168.1.1", "2023-10-04T11:00:00Z", "2023-10-04T12:00:00Z", status}
+}
+
+func TestEndAllUserSessions(t *testing.T) {
+	jwtClient, mock := setupMockJWTClientWithRedis(t)
+
+	ctx := context.Background()
+	userID := "user123"
+
+	// Sessions are found through the user's set rather than by scanning every session
+	mock.ExpectSScan(UserSessionsKeyPrefix+userID, 0, "", DefaultSessionPageSize).SetVal([]string{"user123_1", "user123_2", "user123_3"}, 0)
+	mock.ExpectHMGet("session:user123_1", sessionFields...).SetVal(sessionHash("user123", SessionStatusActive))
+	mock.ExpectHMGet("session:user123_2", sessionFields...).SetVal(make([]interface{}, len(sessionFields)))
+	mock.ExpectHMGet("session:user123_3", sessionFields...).SetVal(sessionHash("user123", SessionStatusActive))
+	// The expired session is dropped from the set
+	mock.ExpectSRem(UserSessionsKeyPrefix+userID, "user123_2").SetVal(1)
+
+	// Only existing sessions are ended
+	mock.ExpectHSet("session:user123_1", "status", SessionStatusInactive).SetVal(0)
+	mock.ExpectHSet("session:user123_3", "status", SessionStatusInactive).SetVal(0)
+
+	err := jwtClient.EndAllUserSessions(ctx, userID)
+	require.NoError(t, err, "EndAllUserSessions() should not fail")
+
+	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+}
+
 func TestGetUserSessions(t *testing.T) {
 	jwtClient, mock := setupMockJWTClientWithRedis(t)
 
 	ctx := context.Background()
 	userID := "user123"
 
-	// Mock the Keys call to return session keys
-	sessionKeys := []string{"session:user123_1234567890", "session:user123_1234567891", "session:otheruser_1234567892"}
-	mock.ExpectKeys("session:*").SetVal(sessionKeys)
+	// Mock the SScan call to return the user's session IDs
+	sessionIDs := []string{"user123_1234567890", "user123_1234567891"}
+	mock.ExpectSScan(UserSessionsKeyPrefix+userID, 0, "", DefaultSessionPageSize).SetVal(sessionIDs, 0)
 
-	// Mock HGet calls for each session key
-	mock.ExpectHGet("session:user123_1234567890", "user_id").SetVal("user123")
-	mock.ExpectHGet("session:user123_1234567891", "user_id").SetVal("user123")
-	mock.ExpectHGet("session:otheruser_1234567892", "user_id").SetVal("otheruser")
+	// Mock HMGet calls for each session key
+	mock.ExpectHMGet("session:user123_1234567890", sessionFields...).SetVal(sessionHash("user123", SessionStatusActive))
+	mock.ExpectHMGet("session:user123_1234567891", sessionFields...).SetVal(sessionHash("user123", SessionStatusInactive))
 
 	sessions, err := jwtClient.GetUserSessions(ctx, userID)
 	require.NoError(t, err, "GetUserSessions() should not fail")
@@ -1209,6 +1633,186 @@ func TestGetUserSessions(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
 }
 
+func TestListUserSessions_Paging(t *testing.T) {
+	jwtClient, mock := setupMockJWTClientWithRedis(t)
+
+	ctx := context.Background()
+	userID := "user123"
+	limit := 3
+
+	// Ten sessions spread over SSCAN batches; expired sessions are interleaved
+	batches := [][]string{
+		{"user123_0", "user123_expired0", "user123_1"},
+		{"user123_2", "user123_3", "user123_4"},
+		{"user123_expired1", "user123_5", "user123_6"},
+		{"user123_7", "user123_8", "user123_9"},
+	}
+	cursors := []uint64{0, 11, 22, 33}
+
+	for i, sessionIDs := range batches {
+		next := uint64(0)
+		if i+1 < len(cursors) {
+			next = cursors[i+1]
+		}
+		mock.ExpectSScan(UserSessionsKeyPrefix+userID, cursors[i], "", int64(limit)).SetVal(sessionIDs, next)
+		var expired []interface{}
+		for _, sessionID := range sessionIDs {
+			if strings.Contains(sessionID, "expired") {
+				mock.ExpectHMGet(SessionKeyPrefix+sessionID, sessionFields...).SetVal(make([]interface{}, len(sessionFields)))
+				expired = append(expired, sessionID)
+				continue
+			}
+			mock.ExpectHMGet(SessionKeyPrefix+sessionID, sessionFields...).SetVal(sessionHash(userID, SessionStatusActive))
+		}
+		if len(expired) > 0 {
+			mock.ExpectSRem(UserSessionsKeyPrefix+userID, expired...).SetVal(int64(len(expired)))
+		}
+	}
+
+	var seen []string
+	opts := SessionListOptions{Limit: limit}
+	pages := 0
+	for {
+		page, err := jwtClient.ListUserSessions(ctx, userID, opts)
+		require.NoError(t, err, "ListUserSessions() should not fail")
+		pages++
+
+		assert.LessOrEqual(t, len(page.Sessions), 2*limit, "Page should be bounded")
+		for _, session := range page.Sessions {
+			assert.Equal(t, userID, session.UserID, "Only the user's sessions should be returned")
+			seen = append(seen, session.SessionID)
+		}
+
+		if page.NextCursor == 0 {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	assert.Greater(t, pages, 1, "Sessions should span multiple pages")
+	assert.Len(t, seen, 10, "All sessions should be returned across pages")
+	for i := 0; i < 10; i++ {
+		assert.Contains(t, seen, fmt.Sprintf("user123_%d", i), "Session should be returned")
+	}
+
+	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+}
+
+func TestListUserSessions_ActiveOnly(t *testing.T) {
+	jwtClient, mock := setupMockJWTClientWithRedis(t)
+
+	ctx := context.Background()
+
+	mock.ExpectSScan(UserSessionsKeyPrefix+"user123", 0, "", DefaultSessionPageSize).SetVal([]string{"user123_1", "user123_2", "user123_3"}, 0)
+	mock.ExpectHMGet("session:user123_1", sessionFields...).SetVal(sessionHash("user123", SessionStatusActive))
+	mock.ExpectHMGet("session:user123_2", sessionFields...).SetVal(sessionHash("user123", SessionStatusInactive))
+	mock.ExpectHMGet("session:user123_3", sessionFields...).SetVal(sessionHash("user123", SessionStatusActive))
+
+	page, err := jwtClient.ListUserSessions(ctx, "user123", SessionListOptions{ActiveOnly: true})
+	require.NoError(t, err, "ListUserSessions() should not fail")
+
+	require.Len(t, page.Sessions, 2, "Only active sessions should be returned")
+	assert.Equal(t, "user123_1", page.Sessions[0].SessionID, "Expected first active session")
+	assert.Equal(t, "user123_3", page.Sessions[1].SessionID, "Expected second active session")
+	assert.Equal(t, "agent123", page.Sessions[0].AgentID, "Session info should be populated")
+	assert.Equal(t, uint64(0), page.NextCursor, "Expected no further pages")
+
+	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+}
+
+func TestStoreHealthy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stateless", func(t *testing.T) {
+		jwtManager := createTestJWTManager(t)
+		assert.NoError(t, jwtManager.StoreHealthy(ctx), "Stateless mode has no store to check")
+	})
+
+	t.Run("reachable with tokens", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectScan(0, RefreshTokenKeyPattern, 1000).SetVal([]string{}, 7)
+		mock.ExpectScan(7, RefreshTokenKeyPattern, 1000).SetVal([]string{"refresh_token:user123:token1"}, 0)
+
+		assert.NoError(t, jwtClient.StoreHealthy(ctx), "Reachable store with tokens should be healthy")
+		require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+	})
+
+	t.Run("scan stops at the first token", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		mock.ExpectPing().SetVal("PONG")
+		// The cursor is not finished, but no further SCAN is expected
+		mock.ExpectScan(0, RefreshTokenKeyPattern, 1000).SetVal([]string{"refresh_token:user123:token1"}, 7)
+
+		assert.NoError(t, jwtClient.StoreHealthy(ctx), "Reachable store with tokens should be healthy")
+		require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+	})
+
+	t.Run("reachable but empty", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectScan(0, RefreshTokenKeyPattern, 1000).SetVal([]string{}, 0)
+
+		err := jwtClient.StoreHealthy(ctx)
+		assert.ErrorIs(t, err, ErrStoreEmptyErr, "Empty store should be reported")
+		require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		connErr := errors.New("connection refused")
+		mock.ExpectPing().SetErr(connErr)
+
+		err := jwtClient.StoreHealthy(ctx)
+		assert.ErrorIs(t, err, ErrStoreUnreachableErr, "Unreachable store should be reported")
+		assert.ErrorIs(t, err, connErr, "Underlying error should be wrapped")
+		require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+	})
+}
+
+func TestCleanupInactiveSessions(t *testing.T) {
+	jwtClient, mock := setupMockJWTClientWithRedis(t)
+
+	ctx := context.Background()
+	stale := time.Now().Add(-2 * DefaultSessionGracePeriod).Format(time.RFC3339)
+	recent := time.Now().Format(time.RFC3339)
+
+	sessionKeys := []string{"session:active", "session:recent", "session:stale"}
+	mock.ExpectScan(0, SessionKeyPattern, scanBatchSize).SetVal(sessionKeys, 0)
+	mock.ExpectHMGet("session:active", "status", "last_seen").SetVal([]interface{}{SessionStatusActive, stale})
+	mock.ExpectHMGet("session:recent", "status", "last_seen").SetVal([]interface{}{SessionStatusInactive, recent})
+	mock.ExpectHMGet("session:stale", "status", "last_seen").SetVal([]interface{}{SessionStatusInactive, stale})
+	mock.ExpectDel("session:stale").SetVal(1)
+
+	removed, err := jwtClient.(*Client).cleanupInactiveSessions(ctx)
+	require.NoError(t, err, "cleanupInactiveSessions() should not fail")
+	assert.Equal(t, 1, removed, "Only the stale inactive session should be removed")
+
+	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+}
+
+func TestStartSessionJanitor(t *testing.T) {
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		jwtClient, mock := setupMockJWTClientWithRedis(t)
+		mock.MatchExpectationsInOrder(false)
+		for i := 0; i < 100; i++ {
+			mock.ExpectScan(0, SessionKeyPattern, scanBatchSize).SetVal([]string{}, 0)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		jwtClient.StartSessionJanitor(ctx, 10*time.Millisecond)
+		time.Sleep(35 * time.Millisecond)
+		cancel()
+	})
+
+	t.Run("no-op in stateless mode", func(t *testing.T) {
+		jwtClient := createTestJWTManager(t)
+		assert.NotPanics(t, func() {
+			jwtClient.StartSessionJanitor(context.Background(), 10*time.Millisecond)
+		}, "StartSessionJanitor should be a no-op in stateless mode")
+	})
+}
+
 func TestGenerateTokensWithSession(t *testing.T) {
 	jwtClient := setupSimpleJWTClientWithRedis(t)
 
@@ -1254,6 +1858,7 @@ func TestSessionNotFound(t *testing.T) {
 	_, err = jwtManager.GetSession(ctx, "non-existent-session")
 	require.Error(t, err, "GetSession should return error for non-existent session")
 	assert.Contains(t, err.Error(), ErrSessionNotFound, "Error should indicate session not found")
+	assert.ErrorIs(t, err, ErrSessionNotFoundErr, "Error should match ErrSessionNotFoundErr")
 }
 
 func TestSessionRequiresStatefulRedis(t *testing.T) {
@@ -1270,6 +1875,7 @@ func TestSessionRequiresStatefulRedis(t *testing.T) {
 	)
 	require.Error(t, err, "CreateSession should fail in stateless mode")
 	assert.Contains(t, err.Error(), ErrSessionRequiresStatefulRedis, "Error should indicate stateful Redis requirement")
+	assert.ErrorIs(t, err, ErrSessionRequiresStatefulRedisErr, "Error should match ErrSessionRequiresStatefulRedisErr")
 }
 
 func TestRedisClientNotConfigured(t *testing.T) {
@@ -1291,6 +1897,7 @@ func TestRedisClientNotConfigured(t *testing.T) {
 	_, err = jwtManager.GetSession(ctx, "session123")
 	require.Error(t, err, "GetSession should fail without Redis client")
 	assert.Contains(t, err.Error(), ErrRedisClientNotConfigured, "Error should indicate Redis client not configured")
+	assert.ErrorIs(t, err, ErrRedisClientNotConfiguredErr, "Error should match ErrRedisClientNotConfiguredErr")
 }
 
 func TestRedisStore_DeleteAll_NoKeys(t *testing.T) {
@@ -1300,7 +1907,7 @@ func TestRedisStore_DeleteAll_NoKeys(t *testing.T) {
 	pattern := fmt.Sprintf("refresh_token:%s:*", userID)
 
 	// Mock empty keys result
-	mock.ExpectKeys(pattern).SetVal([]string{})
+	mock.ExpectScan(0, pattern, scanBatchSize).SetVal([]string{}, 0)
 
 	err := store.DeleteAll(userID)
 	require.NoError(t, err, "DeleteAll should not fail when no keys exist")
@@ -1319,7 +1926,7 @@ func TestRedisStore_DeleteAll_Error(t *testing.T) {
 		"refresh_token:user123:token2",
 	}
 
-	mock.ExpectKeys(pattern).SetVal(keys)
+	mock.ExpectScan(0, pattern, scanBatchSize).SetVal(keys, 0)
 	mock.ExpectDel(keys[0], keys[1]).SetErr(fmt.Errorf("Redis error"))
 
 	err := store.DeleteAll(userID)
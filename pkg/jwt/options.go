@@ -41,3 +41,43 @@ func WithStateful(stateful bool) Option {
 		c.Stateful = stateful
 	}
 }
+
+// WithSessionGracePeriod sets how long inactive sessions are kept before being cleaned up
+// Non-positive values keep the default
+func WithSessionGracePeriod(grace time.Duration) Option {
+	return func(c *TokenConfig) {
+		if grace > 0 {
+			c.SessionGracePeriod = grace
+		}
+	}
+}
+
+// WithSigningKeys sets the access token secrets keyed by key ID and the key ID used for signing
+// Tokens are verified with the secret matching their kid header; tokens without one
+// fall back to AccessTokenSecret
+func WithSigningKeys(keys map[string]string, activeKID string) Option {
+	return func(c *TokenConfig) {
+		if len(keys) == 0 {
+			return
+		}
+		c.SigningKeys = make(map[string]string, len(keys))
+		for kid, secret := range keys {
+			c.SigningKeys[kid] = secret
+		}
+		c.ActiveKID = activeKID
+	}
+}
+
+// WithAudience sets the audiences written to the aud claim of generated tokens
+func WithAudience(aud ...string) Option {
+	return func(c *TokenConfig) {
+		c.Audience = aud
+	}
+}
+
+// WithExpectedAudience sets the audience that validated tokens must be issued for
+func WithExpectedAudience(aud string) Option {
+	return func(c *TokenConfig) {
+		c.ExpectedAudience = aud
+	}
+}
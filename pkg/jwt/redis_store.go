@@ -67,9 +67,12 @@ func (s *RedisStore) DeleteAll(userID string) error {
 	// Find all keys matching the pattern for this user
 	pattern := fmt.Sprintf("refresh_token:%s:*", userID)
 
-	// Get all matching keys using the underlying Redis client
-	underlyingClient := s.client.GetClient()
-	keys, err := underlyingClient.Keys(s.ctx, pattern).Result()
+	// Collect matching keys with SCAN so the Redis event loop is not blocked
+	var keys []string
+	err := s.client.Scan(s.ctx, pattern, scanBatchSize, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to find refresh tokens for user %s: %w", userID, err)
 	}
@@ -2,6 +2,10 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
 )
@@ -9,44 +13,125 @@ import (
 // KafkaClient defines the interface for Kafka operations
 type KafkaClient interface {
 	Produce(ctx context.Context, topic string, value []byte) error
+	ProduceWithKey(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+	ProduceBatch(ctx context.Context, topic string, values [][]byte) error
+	PublishJSON(ctx context.Context, topic string, event interface{}) error
 	ProduceAsync(ctx context.Context, topic string, value []byte)
 	Consume(topics ...string) <-chan *kgo.Record
+	ConsumeWithHandler(ctx context.Context, topics []string, handler RecordHandler, opts ...ConsumerOption) error
 	Close() error
 	GetClient() *kgo.Client
 }
 
+// DefaultFlushTimeout bounds how long Close waits for buffered records when WithFlushTimeout is not used
+const DefaultFlushTimeout = 10 * time.Second
+
 // Client represents a Kafka client wrapper that handles both producing and consuming
 type Client struct {
-	opts   []kgo.Opt
-	client *kgo.Client
+	opts         []kgo.Opt
+	client       *kgo.Client
+	flushTimeout time.Duration
 }
 
 // New creates a new Kafka client with the provided options
 func New(opts ...kgo.Opt) (KafkaClient, error) {
+	client := &Client{
+		flushTimeout: DefaultFlushTimeout,
+	}
+
+	// Options of this package are applied to the wrapper and not passed to franz-go
+	kgoOpts := make([]kgo.Opt, 0, len(opts))
+	hasResetOffset := false
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case flushTimeoutOpt:
+			client.flushTimeout = o.timeout
+			continue
+		case resetOffsetOpt:
+			hasResetOffset = true
+		}
+		kgoOpts = append(kgoOpts, opt)
+	}
+
 	// Create the actual Kafka client with the provided options
-	kafkaClient, err := kgo.NewClient(opts...)
+	kafkaClient, err := kgo.NewClient(kgoOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	client := &Client{
-		opts:   opts,
-		client: kafkaClient,
+	if group, _ := kafkaClient.OptValue(kgo.ConsumerGroup).(string); hasResetOffset && group == "" {
+		kafkaClient.Close()
+		return nil, ErrResetOffsetWithoutGroup
 	}
 
+	client.opts = kgoOpts
+	client.client = kafkaClient
+
 	return client, nil
 }
 
 // Produce sends a message to a Kafka topic
 func (k *Client) Produce(ctx context.Context, topic string, value []byte) error {
+	return k.ProduceWithKey(ctx, topic, nil, value, nil)
+}
+
+// ProduceWithKey sends a message with a key and headers to a Kafka topic
+// Records with the same key are sent to the same partition, so they are delivered in order
+func (k *Client) ProduceWithKey(ctx context.Context, topic string, key, value []byte, headers map[string]string) error {
 	record := &kgo.Record{
-		Topic: topic,
-		Value: value,
+		Topic:   topic,
+		Key:     key,
+		Value:   value,
+		Headers: recordHeaders(headers),
 	}
 
 	return k.client.ProduceSync(ctx, record).FirstErr()
 }
 
+// recordHeaders converts a header map to record headers, sorted by key for a stable order
+func recordHeaders(headers map[string]string) []kgo.RecordHeader {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	recordHeaders := make([]kgo.RecordHeader, 0, len(keys))
+	for _, key := range keys {
+		recordHeaders = append(recordHeaders, kgo.RecordHeader{Key: key, Value: []byte(headers[key])})
+	}
+	return recordHeaders
+}
+
+// ProduceBatch sends messages to a Kafka topic and waits once for all of them to be acknowledged
+// The returned error joins one error per failed message, identified by its index in values
+func (k *Client) ProduceBatch(ctx context.Context, topic string, values [][]byte) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	records := make([]*kgo.Record, len(values))
+	for i, value := range values {
+		records[i] = &kgo.Record{
+			Topic: topic,
+			Value: value,
+		}
+	}
+
+	// Results are returned in the order the records were given
+	var errs []error
+	for i, result := range k.client.ProduceSync(ctx, records...) {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("record %d: %w", i, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // ProduceAsync sends a message to a Kafka topic asynchronously
 func (k *Client) ProduceAsync(ctx context.Context, topic string, value []byte) {
 	record := &kgo.Record{
@@ -93,10 +178,20 @@ func (k *Client) Consume(topics ...string) <-chan *kgo.Record {
 	return recordsChan
 }
 
-// Close closes the Kafka client
+// Close delivers buffered records, waiting up to the flush timeout, then closes the Kafka client
+// The client is closed even if flushing fails; records still buffered at that point are lost
 func (k *Client) Close() error {
-	if k.client != nil {
-		k.client.Close()
+	if k.client == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.flushTimeout)
+	defer cancel()
+
+	flushErr := k.client.Flush(ctx)
+	k.client.Close()
+	if flushErr != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", flushErr)
 	}
 	return nil
 }
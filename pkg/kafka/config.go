@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
@@ -11,6 +12,8 @@ import (
 type Config struct {
 	Brokers                []string
 	ConsumerGroup          string
+	DisableAutoCommit      bool
+	BlockRebalanceOnPoll   bool
 	ClientID               string
 	MaxConcurrentFetches   int
 	AllowAutoTopicCreation bool
@@ -20,6 +23,8 @@ type Config struct {
 	RetryTimeout           time.Duration
 	ConnIdleTimeout        time.Duration
 	SASLMechanism          sasl.Mechanism
+	TLSConfig              *tls.Config
+	FlushTimeout           time.Duration
 }
 
 // NewWithConfig creates a new Kafka client from a config struct
@@ -32,6 +37,14 @@ func NewWithConfig(config Config) (KafkaClient, error) {
 		opts = append(opts, WithConsumerGroup(config.ConsumerGroup))
 	}
 
+	if config.DisableAutoCommit {
+		opts = append(opts, WithDisableAutoCommit())
+	}
+
+	if config.BlockRebalanceOnPoll {
+		opts = append(opts, WithBlockRebalanceOnPoll())
+	}
+
 	if config.ClientID != "" {
 		opts = append(opts, WithClientID(config.ClientID))
 	}
@@ -68,5 +81,13 @@ func NewWithConfig(config Config) (KafkaClient, error) {
 		opts = append(opts, WithSASL(config.SASLMechanism))
 	}
 
+	if config.FlushTimeout > 0 {
+		opts = append(opts, WithFlushTimeout(config.FlushTimeout))
+	}
+
+	if config.TLSConfig != nil {
+		opts = append(opts, WithTLSConfig(config.TLSConfig))
+	}
+
 	return New(opts...)
 }
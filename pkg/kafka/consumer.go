@@ -0,0 +1,182 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ErrAutoCommitEnabled is returned by ConsumeWithHandler in consumer-group mode when the client autocommits,
+// since autocommitting would also commit records the handler failed to process
+var ErrAutoCommitEnabled = errors.New("consumer group client must be created with auto commit disabled")
+
+// ErrRebalanceNotBlocked is returned by ConsumeWithHandler in consumer-group mode when the client does not
+// block rebalances while polled records are handled, since partitions could be revoked mid-batch
+var ErrRebalanceNotBlocked = errors.New("consumer group client must be created with rebalances blocked on poll")
+
+// Headers added to records produced to a dead-letter topic
+const (
+	// DLQErrorHeader holds the error returned by the last failed handler attempt
+	DLQErrorHeader = "dlq_error"
+	// DLQTopicHeader holds the topic the record was consumed from
+	DLQTopicHeader = "dlq_original_topic"
+	// DLQPartitionHeader holds the partition the record was consumed from
+	DLQPartitionHeader = "dlq_original_partition"
+	// DLQOffsetHeader holds the offset of the record in its original partition
+	DLQOffsetHeader = "dlq_original_offset"
+)
+
+// RecordHandler processes a single consumed record
+type RecordHandler func(ctx context.Context, record *kgo.Record) error
+
+// ConsumerOption configures ConsumeWithHandler
+type ConsumerOption func(*consumerOptions)
+
+// consumerOptions holds the ConsumeWithHandler settings
+type consumerOptions struct {
+	dlqTopic      string
+	dlqMaxRetries int
+}
+
+// WithDLQ sends a record to the dead-letter topic once the handler has failed on it maxRetries times,
+// then treats it as handled so the partition advances
+// The dead-letter record keeps the original key, value and headers and adds the DLQ*Header headers
+func WithDLQ(topic string, maxRetries int) ConsumerOption {
+	return func(o *consumerOptions) {
+		o.dlqTopic = topic
+		o.dlqMaxRetries = max(maxRetries, 1)
+	}
+}
+
+// ConsumeWithHandler consumes the given topics and calls handler for each record, in order per partition
+// In consumer-group mode the offsets of successfully handled records are committed after each poll,
+// which requires the client to be created with WithDisableAutoCommit and WithBlockRebalanceOnPoll
+// A handler error stops consumption and is returned; the failed record is not committed, and the client
+// is rewound to it so calling ConsumeWithHandler again redelivers it
+// With WithDLQ, failing records are retried and then dead-lettered instead
+// It returns nil once ctx is cancelled or the client is closed
+func (k *Client) ConsumeWithHandler(ctx context.Context, topics []string, handler RecordHandler, opts ...ConsumerOption) error {
+	var options consumerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	group, _ := k.client.OptValue(kgo.ConsumerGroup).(string)
+	autoCommitDisabled, _ := k.client.OptValue(kgo.DisableAutoCommit).(bool)
+	rebalanceBlocked, _ := k.client.OptValue(kgo.BlockRebalanceOnPoll).(bool)
+	commit := group != ""
+	if commit && !autoCommitDisabled {
+		return ErrAutoCommitEnabled
+	}
+	if commit && !rebalanceBlocked {
+		return ErrRebalanceNotBlocked
+	}
+
+	k.client.AddConsumeTopics(topics...)
+
+	for {
+		fetches := k.client.PollFetches(ctx)
+		if fetches.IsClientClosed() || ctx.Err() != nil {
+			return nil
+		}
+		// Remaining fetch errors are retried by the client on the next poll
+
+		var handled []*kgo.Record
+		var handlerErr error
+		iter := fetches.RecordIter()
+		for !iter.Done() && ctx.Err() == nil {
+			record := iter.Next()
+			if err := k.handleRecord(ctx, record, handler, options); err != nil {
+				// Rewind to the failed record, which the iterator has already moved past
+				k.rewind(record, iter)
+				handlerErr = err
+				break
+			}
+			handled = append(handled, record)
+		}
+		if handlerErr == nil && !iter.Done() {
+			// Cancelled mid-batch; the rest of the batch must not be skipped if consumption resumes
+			k.rewind(iter.Next(), iter)
+		}
+
+		if commit && len(handled) > 0 {
+			// Commit what was handled even if ctx was cancelled mid-batch, so it is not redelivered
+			if err := k.client.CommitRecords(context.WithoutCancel(ctx), handled...); err != nil {
+				return fmt.Errorf("failed to commit offsets: %w", err)
+			}
+		}
+		k.client.AllowRebalance()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if handlerErr != nil {
+			return handlerErr
+		}
+	}
+}
+
+// rewind moves the client back to the first unhandled record of every partition in the batch,
+// starting with record; the client has already advanced past all fetched records
+// It must run before AllowRebalance, while the partitions are still assigned
+func (k *Client) rewind(record *kgo.Record, rest *kgo.FetchesRecordIter) {
+	offsets := make(map[string]map[int32]kgo.EpochOffset)
+	for {
+		if offsets[record.Topic] == nil {
+			offsets[record.Topic] = make(map[int32]kgo.EpochOffset)
+		}
+		if _, ok := offsets[record.Topic][record.Partition]; !ok {
+			offsets[record.Topic][record.Partition] = kgo.EpochOffset{Epoch: record.LeaderEpoch, Offset: record.Offset}
+		}
+		if rest.Done() {
+			break
+		}
+		record = rest.Next()
+	}
+	k.client.SetOffsets(offsets)
+}
+
+// handleRecord runs handler on record, retrying and dead-lettering it when a DLQ is configured
+func (k *Client) handleRecord(ctx context.Context, record *kgo.Record, handler RecordHandler, options consumerOptions) error {
+	attempts := 1
+	if options.dlqTopic != "" {
+		attempts = options.dlqMaxRetries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts && ctx.Err() == nil; attempt++ {
+		if err = handler(ctx, record); err == nil {
+			return nil
+		}
+	}
+	if ctx.Err() != nil || options.dlqTopic == "" {
+		return fmt.Errorf("failed to handle record %s[%d]@%d: %w", record.Topic, record.Partition, record.Offset, err)
+	}
+
+	if dlqErr := k.client.ProduceSync(ctx, deadLetterRecord(options.dlqTopic, record, err)).FirstErr(); dlqErr != nil {
+		return fmt.Errorf("failed to dead-letter record %s[%d]@%d: %w", record.Topic, record.Partition, record.Offset, dlqErr)
+	}
+	return nil
+}
+
+// deadLetterRecord copies record for the dead-letter topic, adding the failure reason and origin headers
+func deadLetterRecord(topic string, record *kgo.Record, handlerErr error) *kgo.Record {
+	headers := make([]kgo.RecordHeader, 0, len(record.Headers)+4)
+	headers = append(headers, record.Headers...)
+	headers = append(headers,
+		kgo.RecordHeader{Key: DLQErrorHeader, Value: []byte(handlerErr.Error())},
+		kgo.RecordHeader{Key: DLQTopicHeader, Value: []byte(record.Topic)},
+		kgo.RecordHeader{Key: DLQPartitionHeader, Value: []byte(strconv.FormatInt(int64(record.Partition), 10))},
+		kgo.RecordHeader{Key: DLQOffsetHeader, Value: []byte(strconv.FormatInt(record.Offset, 10))},
+	)
+
+	return &kgo.Record{
+		Topic:   topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}
+}
@@ -0,0 +1,38 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// PublishJSON serializes event as JSON and sends it to a Kafka topic
+func (k *Client) PublishJSON(ctx context.Context, topic string, event interface{}) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return k.Produce(ctx, topic, value)
+}
+
+// DecodeRecord unmarshals the JSON value of record into dest
+func DecodeRecord(record *kgo.Record, dest interface{}) error {
+	if err := json.Unmarshal(record.Value, dest); err != nil {
+		return fmt.Errorf("failed to decode record %s[%d]@%d: %w", record.Topic, record.Partition, record.Offset, err)
+	}
+	return nil
+}
+
+// ConsumeJSON consumes the given topics with ConsumeWithHandler, decoding each record value as JSON into T
+// A record that cannot be decoded is treated as a handler failure, so it is retried and dead-lettered with WithDLQ
+func ConsumeJSON[T any](ctx context.Context, client KafkaClient, topics []string, handler func(ctx context.Context, event T) error, opts ...ConsumerOption) error {
+	return client.ConsumeWithHandler(ctx, topics, func(ctx context.Context, record *kgo.Record) error {
+		var event T
+		if err := DecodeRecord(record, &event); err != nil {
+			return err
+		}
+		return handler(ctx, event)
+	}, opts...)
+}
@@ -2,6 +2,8 @@ package kafka
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"testing"
 	"time"
 
@@ -89,6 +91,21 @@ func TestClient_Close(t *testing.T) {
 	assert.NoError(t, err, "Multiple Close() calls should be safe")
 }
 
+func TestClient_Close_FlushTimeout(t *testing.T) {
+	kafkaClient, err := New(kgo.SeedBrokers("unreachable:9092"), WithFlushTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	client := kafkaClient.(*Client)
+	assert.Equal(t, 50*time.Millisecond, client.flushTimeout, "Flush timeout should be taken from the option")
+
+	// A buffered record that cannot be delivered makes the flush time out
+	client.ProduceAsync(context.Background(), "test-topic", []byte("test message"))
+
+	start := time.Now()
+	err = client.Close()
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Close() should report the undelivered records")
+	assert.Less(t, time.Since(start), 5*time.Second, "Close() should be bounded by the flush timeout")
+}
+
 func TestClient_Produce_Error(t *testing.T) {
 	opts := []kgo.Opt{
 		kgo.SeedBrokers("unreachable:9092"),
@@ -113,9 +130,86 @@ func TestClient_Produce_Error(t *testing.T) {
 	}
 }
 
+func TestClient_ProduceWithKey_Error(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"), kgo.DialTimeout(10*time.Millisecond))
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.ProduceWithKey(ctx, "test-topic", []byte("user-1"), []byte("test message"), map[string]string{"correlation_id": "abc"})
+	assert.Error(t, err, "ProduceWithKey() should return an error when broker is unreachable")
+}
+
+func TestClient_ProduceBatch_Error(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"), kgo.DialTimeout(10*time.Millisecond))
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.ProduceBatch(context.Background(), "test-topic", nil), "Empty batch should be a no-op")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.ProduceBatch(ctx, "test-topic", [][]byte{[]byte("first"), []byte("second")})
+	require.Error(t, err, "ProduceBatch() should return an error when broker is unreachable")
+	assert.Contains(t, err.Error(), "record 0:", "Error should identify the first record")
+	assert.Contains(t, err.Error(), "record 1:", "Error should identify the second record")
+}
+
+func TestClient_PublishJSON_MarshalError(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.PublishJSON(context.Background(), "test-topic", map[string]interface{}{"invalid": make(chan int)})
+	assert.ErrorContains(t, err, "failed to marshal event", "PublishJSON() should fail for values that cannot be marshaled")
+}
+
+func TestDecodeRecord(t *testing.T) {
+	type event struct {
+		Email string `json:"email"`
+	}
+
+	var decoded event
+	err := DecodeRecord(&kgo.Record{Value: []byte(`{"email":"user@example.com"}`)}, &decoded)
+	require.NoError(t, err, "DecodeRecord() should not fail for valid JSON")
+	assert.Equal(t, "user@example.com", decoded.Email)
+
+	err = DecodeRecord(&kgo.Record{Topic: "test-topic", Partition: 1, Offset: 3, Value: []byte("not json")}, &decoded)
+	assert.ErrorContains(t, err, "test-topic[1]@3", "DecodeRecord() should identify the record")
+}
+
+func TestConsumeJSON_ContextCancelled(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = ConsumeJSON(ctx, client, []string{"test-topic"}, func(ctx context.Context, event map[string]string) error {
+		t.Fatal("handler should not be called without records")
+		return nil
+	})
+	assert.NoError(t, err, "ConsumeJSON() should return cleanly on context cancellation")
+}
+
+func TestRecordHeaders(t *testing.T) {
+	assert.Nil(t, recordHeaders(nil), "No headers should produce nil")
+
+	headers := recordHeaders(map[string]string{"b": "2", "a": "1"})
+	assert.Equal(t, []kgo.RecordHeader{
+		{Key: "a", Value: []byte("1")},
+		{Key: "b", Value: []byte("2")},
+	}, headers, "Headers should be sorted by key")
+}
+
 func TestClient_ProduceAsync(t *testing.T) {
 	opts := []kgo.Opt{
 		kgo.SeedBrokers("unreachable:9092"),
+		WithFlushTimeout(50 * time.Millisecond), // Undeliverable records should not stall Close
 	}
 
 	client, err := New(opts...)
@@ -154,6 +248,146 @@ func TestClient_Consume(t *testing.T) {
 	}
 }
 
+func TestClient_ConsumeWithHandler_ContextCancelled(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.ConsumeWithHandler(ctx, []string{"test-topic"}, func(ctx context.Context, record *kgo.Record) error {
+		t.Fatal("handler should not be called without records")
+		return nil
+	})
+	assert.NoError(t, err, "ConsumeWithHandler() should return cleanly on context cancellation")
+}
+
+func TestClient_ConsumeWithHandler_AutoCommitEnabled(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"), WithConsumerGroup("test-group"))
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.ConsumeWithHandler(context.Background(), []string{"test-topic"}, func(ctx context.Context, record *kgo.Record) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrAutoCommitEnabled, "Consumer groups should require auto commit to be disabled")
+}
+
+func TestClient_ConsumeWithHandler_RebalanceNotBlocked(t *testing.T) {
+	client, err := New(kgo.SeedBrokers("unreachable:9092"), WithConsumerGroup("test-group"), WithDisableAutoCommit())
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.ConsumeWithHandler(context.Background(), []string{"test-topic"}, func(ctx context.Context, record *kgo.Record) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrRebalanceNotBlocked, "Consumer groups should require rebalances to be blocked on poll")
+}
+
+func TestClient_ConsumeWithHandler_ConsumerGroup(t *testing.T) {
+	client, err := NewWithConfig(Config{
+		Brokers:              []string{"unreachable:9092"},
+		ConsumerGroup:        "test-group",
+		DisableAutoCommit:    true,
+		BlockRebalanceOnPoll: true,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.ConsumeWithHandler(ctx, []string{"test-topic"}, func(ctx context.Context, record *kgo.Record) error {
+		return nil
+	})
+	assert.NoError(t, err, "ConsumeWithHandler() should return cleanly on context cancellation")
+}
+
+func TestClient_HandleRecord_DLQ(t *testing.T) {
+	kafkaClient, err := New(kgo.SeedBrokers("unreachable:9092"))
+	require.NoError(t, err)
+	defer kafkaClient.Close()
+	client := kafkaClient.(*Client)
+
+	record := &kgo.Record{Topic: "test-topic", Partition: 2, Offset: 7, Value: []byte("poison")}
+
+	t.Run("retries until the handler succeeds", func(t *testing.T) {
+		calls := 0
+		err := client.handleRecord(context.Background(), record, func(ctx context.Context, r *kgo.Record) error {
+			calls++
+			if calls < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		}, consumerOptions{dlqTopic: "test-topic.dlq", dlqMaxRetries: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls, "Handler should be retried")
+	})
+
+	t.Run("without DLQ the first failure is returned", func(t *testing.T) {
+		calls := 0
+		err := client.handleRecord(context.Background(), record, func(ctx context.Context, r *kgo.Record) error {
+			calls++
+			return errors.New("boom")
+		}, consumerOptions{})
+		assert.ErrorContains(t, err, "test-topic[2]@7")
+		assert.Equal(t, 1, calls, "Handler should not be retried without a DLQ")
+	})
+
+	t.Run("dead-letter produce failure is returned", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		calls := 0
+		err := client.handleRecord(ctx, record, func(ctx context.Context, r *kgo.Record) error {
+			calls++
+			return errors.New("boom")
+		}, consumerOptions{dlqTopic: "test-topic.dlq", dlqMaxRetries: 2})
+		assert.Error(t, err, "Record should not be treated as handled when the DLQ is unreachable")
+		assert.Equal(t, 2, calls, "Handler should be tried maxRetries times")
+	})
+}
+
+func TestDeadLetterRecord(t *testing.T) {
+	record := &kgo.Record{
+		Topic:     "test-topic",
+		Partition: 1,
+		Offset:    42,
+		Key:       []byte("user-1"),
+		Value:     []byte("payload"),
+		Headers:   []kgo.RecordHeader{{Key: "correlation_id", Value: []byte("abc")}},
+	}
+
+	dlq := deadLetterRecord("test-topic.dlq", record, errors.New("invalid payload"))
+
+	assert.Equal(t, "test-topic.dlq", dlq.Topic)
+	assert.Equal(t, record.Key, dlq.Key)
+	assert.Equal(t, record.Value, dlq.Value)
+	assert.Equal(t, []kgo.RecordHeader{
+		{Key: "correlation_id", Value: []byte("abc")},
+		{Key: DLQErrorHeader, Value: []byte("invalid payload")},
+		{Key: DLQTopicHeader, Value: []byte("test-topic")},
+		{Key: DLQPartitionHeader, Value: []byte("1")},
+		{Key: DLQOffsetHeader, Value: []byte("42")},
+	}, dlq.Headers, "Original headers should be kept and failure headers added")
+	assert.Len(t, record.Headers, 1, "Original record should not be modified")
+}
+
+func TestWithDLQ(t *testing.T) {
+	var options consumerOptions
+	WithDLQ("test-topic.dlq", 0)(&options)
+
+	assert.Equal(t, "test-topic.dlq", options.dlqTopic)
+	assert.Equal(t, 1, options.dlqMaxRetries, "At least one attempt should be made")
+}
+
+func TestWithDisableAutoCommit(t *testing.T) {
+	opt := WithDisableAutoCommit()
+
+	require.NotNil(t, opt, "WithDisableAutoCommit should return a valid option")
+}
+
 func TestWithBrokers(t *testing.T) {
 	brokers := []string{"localhost:9092", "localhost:9093"}
 	opt := WithBrokers(brokers...)
@@ -183,6 +417,50 @@ func TestWithSASL(t *testing.T) {
 	require.NotNil(t, opt, "WithSASL should return a valid option")
 }
 
+func TestWithSASLSCRAM(t *testing.T) {
+	for _, sha := range []int{256, 512} {
+		opt, err := WithSASLSCRAM("user", "pass", sha)
+		require.NoError(t, err, "WithSASLSCRAM should accept SHA-%d", sha)
+		require.NotNil(t, opt, "WithSASLSCRAM should return a valid option")
+	}
+
+	opt, err := WithSASLSCRAM("user", "pass", 1)
+	assert.ErrorIs(t, err, ErrInvalidSCRAMSHA, "WithSASLSCRAM should reject unsupported SHA variants")
+	assert.Nil(t, opt)
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	opt := WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12})
+
+	require.NotNil(t, opt, "WithTLSConfig should return a valid option")
+}
+
+func TestWithFlushTimeout(t *testing.T) {
+	opt := WithFlushTimeout(5 * time.Second)
+
+	require.NotNil(t, opt, "WithFlushTimeout should return a valid option")
+}
+
+func TestWithConsumeResetOffset(t *testing.T) {
+	for _, earliest := range []bool{true, false} {
+		client, err := New(kgo.SeedBrokers("unreachable:9092"), WithConsumerGroup("test-group"), WithConsumeResetOffset(earliest))
+		require.NoError(t, err, "New() should accept a reset offset with a consumer group")
+
+		offset, ok := client.GetClient().OptValue(kgo.ConsumeResetOffset).(kgo.Offset)
+		require.True(t, ok, "Reset offset should be applied to the client")
+		if earliest {
+			assert.Equal(t, kgo.NewOffset().AtStart(), offset, "Expected reset to the earliest offset")
+		} else {
+			assert.Equal(t, kgo.NewOffset().AtEnd(), offset, "Expected reset to the latest offset")
+		}
+		client.Close()
+	}
+
+	client, err := New(kgo.SeedBrokers("unreachable:9092"), WithConsumeResetOffset(true))
+	assert.ErrorIs(t, err, ErrResetOffsetWithoutGroup, "New() should reject a reset offset without a consumer group")
+	assert.Nil(t, client)
+}
+
 func TestWithMaxConcurrentFetches(t *testing.T) {
 	max := 10
 	opt := WithMaxConcurrentFetches(max)
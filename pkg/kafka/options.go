@@ -1,10 +1,20 @@
 package kafka
 
 import (
+	"crypto/tls"
+	"errors"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+var (
+	// ErrInvalidSCRAMSHA is returned by WithSASLSCRAM when sha is neither 256 nor 512
+	ErrInvalidSCRAMSHA = errors.New("SCRAM SHA must be 256 or 512")
+	// ErrResetOffsetWithoutGroup is returned by New when WithConsumeResetOffset is used without a consumer group
+	ErrResetOffsetWithoutGroup = errors.New("consume reset offset requires a consumer group")
 )
 
 // WithBrokers sets the Kafka brokers
@@ -27,6 +37,25 @@ func WithSASL(mechanism sasl.Mechanism) kgo.Opt {
 	return kgo.SASL(mechanism)
 }
 
+// WithSASLSCRAM sets SASL/SCRAM authentication with SHA-256 or SHA-512, selected by sha
+// Returns ErrInvalidSCRAMSHA for any other sha
+func WithSASLSCRAM(user, pass string, sha int) (kgo.Opt, error) {
+	auth := scram.Auth{User: user, Pass: pass}
+	switch sha {
+	case 256:
+		return kgo.SASL(auth.AsSha256Mechanism()), nil
+	case 512:
+		return kgo.SASL(auth.AsSha512Mechanism()), nil
+	default:
+		return nil, ErrInvalidSCRAMSHA
+	}
+}
+
+// WithTLSConfig enables TLS for broker connections with the given configuration
+func WithTLSConfig(tlsConfig *tls.Config) kgo.Opt {
+	return kgo.DialTLSConfig(tlsConfig)
+}
+
 // WithMaxConcurrentFetches sets the maximum number of concurrent fetches
 func WithMaxConcurrentFetches(max int) kgo.Opt {
 	return kgo.MaxConcurrentFetches(max)
@@ -61,3 +90,43 @@ func WithRetryTimeout(timeout time.Duration) kgo.Opt {
 func WithConnIdleTimeout(timeout time.Duration) kgo.Opt {
 	return kgo.ConnIdleTimeout(timeout)
 }
+
+// WithDisableAutoCommit disables periodic offset commits so offsets are only committed explicitly
+// Required by ConsumeWithHandler in consumer-group mode
+func WithDisableAutoCommit() kgo.Opt {
+	return kgo.DisableAutoCommit()
+}
+
+// WithBlockRebalanceOnPoll keeps polled partitions assigned until the records are handled
+// Required by ConsumeWithHandler in consumer-group mode; do not use it with Consume, which never allows rebalances
+func WithBlockRebalanceOnPoll() kgo.Opt {
+	return kgo.BlockRebalanceOnPoll()
+}
+
+// flushTimeoutOpt carries the Close flush timeout through New; it is not passed to franz-go
+type flushTimeoutOpt struct {
+	kgo.Opt
+	timeout time.Duration
+}
+
+// WithFlushTimeout sets how long Close waits for buffered records, such as those from ProduceAsync, to be delivered
+func WithFlushTimeout(timeout time.Duration) kgo.Opt {
+	return flushTimeoutOpt{timeout: timeout}
+}
+
+// resetOffsetOpt marks the option set by WithConsumeResetOffset, so New can check a consumer group is configured
+type resetOffsetOpt struct {
+	kgo.Opt
+}
+
+// WithConsumeResetOffset sets where a consumer group without committed offsets starts reading:
+// the earliest available record when earliest is set, otherwise only records produced from now on
+// Without this option franz-go starts from the earliest record
+// New returns ErrResetOffsetWithoutGroup if no consumer group is configured
+func WithConsumeResetOffset(earliest bool) kgo.Opt {
+	offset := kgo.NewOffset().AtEnd()
+	if earliest {
+		offset = kgo.NewOffset().AtStart()
+	}
+	return resetOffsetOpt{Opt: kgo.ConsumeResetOffset(offset)}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// CorrelationIDAttr is the log attribute holding the correlation ID of the request being logged
+	CorrelationIDAttr = "correlation_id"
+	// CorrelationIDHeader is the HTTP header carrying the correlation ID between services
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// correlationIDKey is the context key holding the correlation ID of a request
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying the correlation ID
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationID returns the correlation ID stored in ctx
+// It falls back to the request ID set by chi's RequestID middleware, or "" if neither is set
+func CorrelationID(ctx context.Context) string {
+	if correlationID, ok := ctx.Value(correlationIDKey{}).(string); ok && correlationID != "" {
+		return correlationID
+	}
+	return middleware.GetReqID(ctx)
+}
+
+// CorrelationIDMiddleware stores the correlation ID of the request in its context and echoes it in the response
+// The ID is taken from the X-Correlation-ID header, falling back to the request ID set by chi's RequestID middleware,
+// so it must be registered after RequestID
+func CorrelationIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = middleware.GetReqID(r.Context())
+		}
+		if correlationID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set(CorrelationIDHeader, correlationID)
+		next.ServeHTTP(w, r.WithContext(WithCorrelationID(r.Context(), correlationID)))
+	})
+}
+
+// correlationHandler implements slog.Handler, adding the correlation ID of the context to each record
+// Records of a logger already bound to a correlation ID by WithContext are left as is,
+// so the attribute is never written twice
+type correlationHandler struct {
+	handler slog.Handler
+	// bound reports whether the correlation ID was added with WithAttrs
+	bound bool
+}
+
+func (h *correlationHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil && !h.bound {
+		if correlationID := CorrelationID(ctx); correlationID != "" {
+			r.AddAttrs(slog.String(CorrelationIDAttr, correlationID))
+		}
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *correlationHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *correlationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	bound := h.bound
+	for _, attr := range attrs {
+		if attr.Key == CorrelationIDAttr {
+			bound = true
+		}
+	}
+	return &correlationHandler{handler: h.handler.WithAttrs(attrs), bound: bound}
+}
+
+func (h *correlationHandler) WithGroup(name string) slog.Handler {
+	return &correlationHandler{handler: h.handler.WithGroup(name), bound: h.bound}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// NewFileWriter returns a writer appending to the file at path, rotating it once it reaches maxSizeMB
+// At most maxBackups rotated files are kept, for at most maxAgeDays; zero keeps them all
+// Writes are serialized, so the writer is safe for concurrent use
+func NewFileWriter(path string, maxSizeMB, maxBackups, maxAgeDays int) io.WriteCloser {
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+	}
+}
+
+// NewJSONFile creates a new info-level JSON logger writing to a size-rotated file, see NewFileWriter
+// The file is opened on the first write and stays open for the lifetime of the process
+func NewJSONFile(path string, maxSizeMB, maxBackups, maxAgeDays int) LoggerInterface {
+	return NewJSON(NewFileWriter(path, maxSizeMB, maxBackups, maxAgeDays), slog.LevelInfo)
+}
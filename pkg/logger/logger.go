@@ -19,11 +19,16 @@ type LoggerInterface interface {
 	ErrorContext(ctx context.Context, msg string, args ...any)
 	WarnContext(ctx context.Context, msg string, args ...any)
 	DebugContext(ctx context.Context, msg string, args ...any)
+	With(args ...any) LoggerInterface
+	SetLevel(level slog.Level)
+	Level() slog.Level
 }
 
 // Logger wraps slog.Logger with additional functionality
 type Logger struct {
 	*slog.Logger
+	// level is the minimum level logged; shared with the handler so SetLevel applies immediately
+	level *slog.LevelVar
 }
 
 // Config holds logger configuration
@@ -34,6 +39,8 @@ type Config struct {
 	AddSource  bool
 	WithTime   bool
 	TimeFormat string
+	// RedactKeys are attribute keys whose values are replaced with RedactedValue, compared case-insensitively
+	RedactKeys []string
 }
 
 // DefaultConfig returns a default configuration
@@ -52,11 +59,17 @@ func DefaultConfig() Config {
 func New(config Config) LoggerInterface {
 	var handler slog.Handler
 
+	level := new(slog.LevelVar)
+	level.Set(config.Level)
+
 	// Set up options
 	opts := &slog.HandlerOptions{
-		Level:     config.Level,
+		Level:     level,
 		AddSource: config.AddSource,
 	}
+	if len(config.RedactKeys) > 0 {
+		opts.ReplaceAttr = redactAttr(config.RedactKeys)
+	}
 
 	// Choose handler based on format
 	switch config.Format {
@@ -74,8 +87,12 @@ func New(config Config) LoggerInterface {
 		}
 	}
 
+	// Attach the correlation ID of the context to records logged with the *Context methods
+	handler = &correlationHandler{handler: handler}
+
 	return &Logger{
 		Logger: slog.New(handler),
+		level:  level,
 	}
 }
 
@@ -136,6 +153,11 @@ func NewJSON(output io.Writer, level slog.Level) LoggerInterface {
 	return NewWithFormat(output, level, "json")
 }
 
+// NewJSONWithLevel creates a new JSON logger writing to stdout at the given level
+func NewJSONWithLevel(level slog.Level) LoggerInterface {
+	return NewJSON(os.Stdout, level)
+}
+
 // NewText creates a new text logger
 func NewText(output io.Writer, level slog.Level) LoggerInterface {
 	return NewWithFormat(output, level, "text")
@@ -160,12 +182,43 @@ func NewTextDefault() LoggerInterface {
 	return New(config)
 }
 
-// WithContext returns a Logger that includes the provided context attributes
+// WithContext returns a Logger that includes the correlation ID of ctx on every record,
+// for code that logs without passing the context
+// The logger is returned as is when ctx has no correlation ID
 func WithContext(ctx context.Context, logger LoggerInterface) LoggerInterface {
-	// This would typically extract request IDs or other context values
-	// For now, we'll just return the logger as is
-	// In a real implementation, you might extract values from context
-	return logger
+	l, ok := logger.(*Logger)
+	if !ok {
+		return logger
+	}
+	correlationID := CorrelationID(ctx)
+	if correlationID == "" {
+		return logger
+	}
+	return l.With(CorrelationIDAttr, correlationID)
+}
+
+// With returns a child logger that adds the given key/value pairs to every record, like slog.Logger.With
+// The child shares the level of its parent
+func (l *Logger) With(args ...any) LoggerInterface {
+	return &Logger{
+		Logger: l.Logger.With(args...),
+		level:  l.level,
+	}
+}
+
+// SetLevel changes the minimum level logged, taking effect immediately for this logger and those derived from it
+func (l *Logger) SetLevel(level slog.Level) {
+	if l.level != nil {
+		l.level.Set(level)
+	}
+}
+
+// Level returns the minimum level logged
+func (l *Logger) Level() slog.Level {
+	if l.level == nil {
+		return slog.LevelInfo
+	}
+	return l.level.Level()
 }
 
 // InfoContext logs at the info level with context
@@ -195,6 +248,11 @@ func NoOpLogger() LoggerInterface {
 	}
 }
 
+// NewNop returns a logger that discards everything, for tests and callers that don't need logs
+func NewNop() LoggerInterface {
+	return NoOpLogger()
+}
+
 // noOpHandler is a no-op implementation of slog.Handler
 type noOpHandler struct{}
 
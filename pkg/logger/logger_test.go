@@ -3,11 +3,18 @@ package logger
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -49,6 +56,32 @@ func TestNewJSON(t *testing.T) {
 	require.NotNil(t, logger, "NewJSON() should not return nil")
 }
 
+func TestNewJSONWithLevel(t *testing.T) {
+	logger := NewJSONWithLevel(slog.LevelWarn)
+	require.NotNil(t, logger, "NewJSONWithLevel() should not return nil")
+	assert.Equal(t, slog.LevelWarn, logger.Level(), "Expected the requested level")
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewJSON(buf, slog.LevelInfo)
+	child := logger.(*Logger).With("component", "test")
+
+	logger.Debug("hidden")
+	assert.Empty(t, buf.String(), "Debug should be filtered at info level")
+
+	logger.SetLevel(slog.LevelDebug)
+	assert.Equal(t, slog.LevelDebug, logger.Level())
+
+	logger.Debug("visible")
+	assert.Contains(t, buf.String(), "visible", "Debug should be logged after SetLevel")
+
+	child.Debug("child visible")
+	assert.Contains(t, buf.String(), "child visible", "Derived loggers should follow the new level")
+
+	NoOpLogger().SetLevel(slog.LevelDebug)
+}
+
 func TestNewText(t *testing.T) {
 	buf := &bytes.Buffer{}
 	logger := NewText(buf, slog.LevelInfo)
@@ -61,6 +94,10 @@ func TestNewDefault(t *testing.T) {
 	require.NotNil(t, logger, "NewDefault() should not return nil")
 }
 
+func TestNewJSONDefault_InfoLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelInfo, NewJSONDefault().Level(), "NewJSONDefault() should log at info level")
+}
+
 func TestNewJSONDefault(t *testing.T) {
 	logger := NewJSONDefault()
 	require.NotNil(t, logger, "NewJSONDefault() should not return nil")
@@ -152,6 +189,15 @@ func TestNoOpLogger(t *testing.T) {
 	logger.Debug("test")
 }
 
+func TestNewNop(t *testing.T) {
+	logger := NewNop()
+	require.NotNil(t, logger, "NewNop() should not return nil")
+
+	logger.InfoContext(WithCorrelationID(context.Background(), "correlation-1"), "test", "key", "value")
+	logger.SetLevel(slog.LevelDebug)
+	logger.Debug("test")
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
@@ -225,7 +271,7 @@ func TestWithStderr(t *testing.T) {
 
 func TestLogger_HandlerInterface(t *testing.T) {
 	buf := &bytes.Buffer{}
-	concreteLogger := &Logger{slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))}
+	concreteLogger := &Logger{Logger: slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))}
 
 	// Test Enabled method - this is covered by the embedded slog.Logger
 	ctx := context.Background()
@@ -285,3 +331,158 @@ func TestNewWithOptions_Stderr(t *testing.T) {
 	logger := NewWithOptions(WithStderr())
 	require.NotNil(t, logger, "WithStderr option should work")
 }
+
+func TestCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, CorrelationID(ctx), "Empty context should have no correlation ID")
+
+	reqCtx := context.WithValue(ctx, middleware.RequestIDKey, "request-1")
+	assert.Equal(t, "request-1", CorrelationID(reqCtx), "Should fall back to the chi request ID")
+
+	corrCtx := WithCorrelationID(reqCtx, "correlation-1")
+	assert.Equal(t, "correlation-1", CorrelationID(corrCtx), "Explicit correlation ID should take precedence")
+}
+
+func TestWithRedactKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewWithOptions(WithOutput(buf), WithRedactKeys("supplier_secret"))
+
+	logger.Info("login",
+		"email", "user@example.com",
+		"Password", "hunter2",
+		"Authorization", "Bearer abc",
+		"supplier_secret", "s3cr3t",
+		slog.Group("request", "token", "xyz"),
+	)
+
+	output := buf.String()
+	assert.Contains(t, output, "user@example.com", "Unlisted keys should be logged as is")
+	assert.NotContains(t, output, "hunter2", "Password should be redacted regardless of case")
+	assert.NotContains(t, output, "Bearer abc", "Authorization should be redacted")
+	assert.NotContains(t, output, "s3cr3t", "Custom keys should be redacted")
+	assert.NotContains(t, output, "xyz", "Keys inside groups should be redacted")
+	assert.Contains(t, output, `"Password":"***"`, "Redacted value should be replaced")
+}
+
+func TestNew_NoRedaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewJSON(buf, slog.LevelInfo)
+
+	logger.Info("login", "token", "xyz")
+	assert.Contains(t, buf.String(), "xyz", "Redaction should be opt-in")
+}
+
+func TestLogger_ContextCorrelationID(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewJSON(buf, slog.LevelInfo)
+	ctx := WithCorrelationID(context.Background(), "correlation-1")
+
+	logger.InfoContext(ctx, "with context")
+	assert.Contains(t, buf.String(), `"correlation_id":"correlation-1"`, "Context methods should attach the correlation ID")
+
+	buf.Reset()
+	logger.InfoContext(context.Background(), "without id")
+	assert.NotContains(t, buf.String(), CorrelationIDAttr, "No attribute should be added without a correlation ID")
+
+	buf.Reset()
+	WithContext(ctx, logger).Info("without context")
+	assert.Contains(t, buf.String(), `"correlation_id":"correlation-1"`, "WithContext should bind the correlation ID")
+
+	buf.Reset()
+	WithContext(ctx, logger).InfoContext(ctx, "bound and with context")
+	assert.Equal(t, 1, strings.Count(buf.String(), CorrelationIDAttr), "The correlation ID should be added once")
+
+	buf.Reset()
+	WithContext(ctx, logger).With("user", "jane").InfoContext(ctx, "bound child")
+	assert.Equal(t, 1, strings.Count(buf.String(), CorrelationIDAttr), "Child loggers should not add the correlation ID again")
+}
+
+func TestCorrelationIDMiddleware(t *testing.T) {
+	var got string
+	handler := middleware.RequestID(CorrelationIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = CorrelationID(r.Context())
+	})))
+
+	t.Run("uses the incoming header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(CorrelationIDHeader, "upstream-1")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "upstream-1", got)
+		assert.Equal(t, "upstream-1", rec.Header().Get(CorrelationIDHeader), "Correlation ID should be echoed")
+	})
+
+	t.Run("falls back to the request ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.NotEmpty(t, got, "Request ID should be used as correlation ID")
+		assert.Equal(t, got, rec.Header().Get(CorrelationIDHeader))
+	})
+}
+
+func TestNewJSONFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	logger := NewJSONFile(path, 1, 3, 7)
+	require.NotNil(t, logger, "NewJSONFile() should not return nil")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger.Info("concurrent write", "worker", i)
+		}(i)
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "Log file should be created")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 10, "Every record should be written on its own line")
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), "Each line should be valid JSON")
+	}
+}
+
+func TestNewFileWriter_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewFileWriter(filepath.Join(dir, "service.log"), 1, 2, 0)
+	defer writer.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for i := 0; i < 3; i++ {
+		_, err := writer.Write(chunk)
+		require.NoError(t, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Greater(t, len(entries), 1, "Writer should rotate once the size limit is reached")
+}
+
+func TestLogger_With(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewJSON(buf, slog.LevelInfo)
+	child := logger.With("user_id", "user-1")
+
+	child.Info("first")
+	child.InfoContext(context.Background(), "second", "extra", "value")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Contains(t, line, `"user_id":"user-1"`, "Child fields should be on every record")
+	}
+
+	buf.Reset()
+	logger.Info("parent")
+	assert.NotContains(t, buf.String(), "user_id", "Parent logger should not be affected")
+
+	logger.SetLevel(slog.LevelError)
+	buf.Reset()
+	child.Info("filtered")
+	assert.Empty(t, buf.String(), "Child should share the parent level")
+}
@@ -70,3 +70,14 @@ func WithStdout() Option {
 func WithStderr() Option {
 	return WithOutput(os.Stderr)
 }
+
+// WithRedactKeys enables redaction of DefaultRedactKeys plus the given attribute keys
+// Matching is case-insensitive and the value is replaced with RedactedValue, including inside groups
+func WithRedactKeys(keys ...string) Option {
+	return func(c *Config) {
+		if len(c.RedactKeys) == 0 {
+			c.RedactKeys = append(c.RedactKeys, DefaultRedactKeys...)
+		}
+		c.RedactKeys = append(c.RedactKeys, keys...)
+	}
+}
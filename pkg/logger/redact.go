@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// RedactedValue replaces the value of redacted attributes
+const RedactedValue = "***"
+
+// DefaultRedactKeys are the attribute keys always redacted when redaction is enabled
+var DefaultRedactKeys = []string{
+	"password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"authorization",
+	"credentials",
+	"secret",
+	"api_key",
+}
+
+// redactAttr returns a ReplaceAttr function replacing the value of attributes whose key
+// matches one of keys, compared case-insensitively, with RedactedValue
+func redactAttr(keys []string) func(groups []string, a slog.Attr) slog.Attr {
+	redacted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = struct{}{}
+	}
+
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if _, ok := redacted[strings.ToLower(a.Key)]; ok {
+			return slog.String(a.Key, RedactedValue)
+		}
+		return a
+	}
+}
@@ -2,14 +2,33 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	stdlog "log"
+	"os"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
+
+	"monorepo/pkg/logger"
+)
+
+const (
+	// defaultConnectRetryDelay is the initial retry delay when ConnectRetryDelay is not set
+	defaultConnectRetryDelay = time.Second
+	// maxConnectRetryDelay caps the backoff between connection attempts
+	maxConnectRetryDelay = 30 * time.Second
 )
 
+// DefaultBatchSize is the number of rows inserted per statement by BulkCreate when no batch size is given
+const DefaultBatchSize = 500
+
 // PostgresClient defines the interface for PostgreSQL database operations
 // It provides methods for database migration, getting the database instance, and closing connections
 type PostgresClient interface {
@@ -17,9 +36,34 @@ type PostgresClient interface {
 	// It takes optional model instances to migrate
 	// Returns an error if the migration fails
 	Migrate(dst ...any) error
+	// MigrateWithContext runs auto-migration while holding a Postgres advisory lock
+	// Concurrent instances serialize their migrations instead of racing on CREATE TABLE
+	MigrateWithContext(ctx context.Context, dst ...any) error
+	// RunMigrations applies versioned migrations that have not been applied yet, in order
+	// Applied migration IDs are recorded in the schema_migrations table
+	// It holds the migration advisory lock, so concurrent instances do not apply the same migration twice
+	RunMigrations(ctx context.Context, migrations []Migration) error
+	// Rollback reverts the last steps applied migrations, most recent first, holding the migration advisory lock
+	Rollback(ctx context.Context, steps int) error
 	// GetDB returns the underlying gorm.DB instance
 	// This allows direct access to the GORM database for custom operations
 	GetDB() *gorm.DB
+	// GetReadDB returns a gorm.DB session pinned to the read replicas
+	// It returns the primary when no replicas are configured
+	GetReadDB() *gorm.DB
+	// BulkCreate inserts a slice of records in batches of batchSize rows
+	// A batchSize of zero or less uses DefaultBatchSize
+	BulkCreate(ctx context.Context, records any, batchSize int) error
+	// WithinTransaction runs fn in a transaction carried by the context passed to fn
+	// Use DBFromContext in repositories to pick up the transaction
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Ping verifies the database is reachable, for readiness probes
+	// Returns an error if the connection cannot be established within ctx
+	Ping(ctx context.Context) error
+	// Stats returns connection pool statistics such as open and idle connection counts
+	Stats() sql.DBStats
+	// RegisterMetrics registers connection pool metrics and samples them until Close
+	RegisterMetrics(registerer prometheus.Registerer) error
 	// Close closes the database connection
 	// Returns an error if closing the connection fails
 	Close() error
@@ -29,36 +73,68 @@ type PostgresClient interface {
 type postgresClient struct {
 	// DB is the GORM database instance
 	DB *gorm.DB
+	// migrations are the versioned migrations registered by RunMigrations
+	migrations []Migration
+	// hasReplicas is set when reads are routed to read replicas
+	hasReplicas bool
+	// metricsMu guards stopMetrics
+	metricsMu sync.Mutex
+	// stopMetrics stops the pool metrics sampler started by RegisterMetrics
+	stopMetrics chan struct{}
 }
 
 // NewPostgresClient creates a new database client based on the configuration
 // It takes a Config struct with database connection parameters
 // Returns a PostgresClient interface and an error if initialization fails
 func NewPostgresClient(cfg Config) (PostgresClient, error) {
+	return NewPostgresClientWithLogger(cfg, nil)
+}
+
+// NewPostgresClientWithLogger creates a new database client whose GORM output goes through log
+// Connection errors, executed statements in debug mode, failed statements, and statements slower
+// than cfg.SlowQueryThreshold are logged as structured entries
+// Returns a PostgresClient interface and an error if initialization fails
+func NewPostgresClientWithLogger(cfg Config, log logger.LoggerInterface) (PostgresClient, error) {
+	sslMode, err := cfg.sslMode()
+	if err != nil {
+		return nil, err
+	}
+
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s search_path=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.Schema, cfg.SSLMode)
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.Schema, sslMode)
 
 	// Add connect timeout if specified
 	if cfg.ConnectTimeout > 0 {
 		dsn += fmt.Sprintf(" connect_timeout=%d", cfg.ConnectTimeout)
 	}
 
-	// Set appropriate log level based on config
-	var loggerInterface logger.Interface
-	if cfg.Debug {
-		loggerInterface = logger.Default.LogMode(logger.Info)
-	} else {
-		loggerInterface = logger.Default.LogMode(logger.Silent)
-	}
-
 	// Open database connection with the configured logger
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: loggerInterface,
-	})
+	db, err := openWithRetry(cfg, postgres.Open(dsn), &gorm.Config{
+		Logger: gormLoggerFor(cfg, log),
+	}, log)
 	if err != nil {
 		return nil, err
 	}
 
+	// Route reads to replicas and writes to the primary
+	if len(cfg.ReplicaDSNs) > 0 {
+		replicas := make([]gorm.Dialector, len(cfg.ReplicaDSNs))
+		for i, replicaDSN := range cfg.ReplicaDSNs {
+			replicas[i] = postgres.Open(replicaDSN)
+		}
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		}).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetMaxOpenConns(cfg.MaxOpenConns).
+			SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Minute).
+			SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Minute)
+		if err := db.Use(resolver); err != nil {
+			return nil, fmt.Errorf("failed to configure read replicas: %w", err)
+		}
+	}
+
 	// Configure connection pool
 	dbSQL, err := db.DB()
 	if err != nil {
@@ -76,17 +152,80 @@ func NewPostgresClient(cfg Config) (PostgresClient, error) {
 	}
 
 	return &postgresClient{
-		DB: db,
+		DB:          db,
+		hasReplicas: len(cfg.ReplicaDSNs) > 0,
 	}, nil
 }
 
+// openWithRetry opens the database, retrying the connection up to cfg.ConnectRetries times
+// The delay starts at cfg.ConnectRetryDelay and doubles after each failed attempt, up to maxConnectRetryDelay
+func openWithRetry(cfg Config, dialector gorm.Dialector, gormCfg *gorm.Config, log logger.LoggerInterface) (*gorm.DB, error) {
+	delay := cfg.ConnectRetryDelay
+	if delay <= 0 {
+		delay = defaultConnectRetryDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, gormCfg)
+		if err == nil {
+			return db, nil
+		}
+		if attempt > cfg.ConnectRetries {
+			if cfg.ConnectRetries > 0 {
+				return nil, fmt.Errorf("failed to connect after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		if log != nil {
+			log.Warn("Database connection failed, retrying", "attempt", attempt, "retries", cfg.ConnectRetries, "delay", delay, "error", err)
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, maxConnectRetryDelay)
+	}
+}
+
+// gormLoggerFor returns the GORM logger for the configuration, writing through log when it is set
+// Without log, statements are printed to stdout in debug mode and otherwise discarded
+func gormLoggerFor(cfg Config, log logger.LoggerInterface) gormlogger.Interface {
+	if log != nil {
+		return newLoggerAdapter(cfg, log)
+	}
+	return newGormLogger(cfg, stdlog.New(os.Stdout, "\r\n", stdlog.LstdFlags))
+}
+
+// newGormLogger builds the GORM logger for the given configuration
+// Statements are logged only in debug mode, and bind parameters are replaced
+// by their placeholders when DebugHideParams is set
+func newGormLogger(cfg Config, writer gormlogger.Writer) gormlogger.Interface {
+	if !cfg.Debug {
+		return gormlogger.Default.LogMode(gormlogger.Silent)
+	}
+
+	return gormlogger.New(writer, gormlogger.Config{
+		SlowThreshold:        200 * time.Millisecond,
+		LogLevel:             gormlogger.Info,
+		Colorful:             true,
+		ParameterizedQueries: cfg.DebugHideParams,
+	})
+}
+
 // Migrate runs auto-migration for all models
+// AutoMigrate cannot drop columns or roll back; use RunMigrations for production schema changes
 // Returns an error if the migration fails
 func (c *postgresClient) Migrate(dst ...any) error {
-	if err := c.DB.AutoMigrate(dst...); err != nil {
-		return fmt.Errorf("failed to auto-migrate models: %w", err)
-	}
-	return nil
+	return c.MigrateWithContext(context.Background(), dst...)
+}
+
+// MigrateWithContext runs auto-migration for all models while holding the migration advisory lock
+// Returns an error if the lock cannot be acquired or the migration fails
+func (c *postgresClient) MigrateWithContext(ctx context.Context, dst ...any) error {
+	return c.withMigrationLock(ctx, func() error {
+		if err := c.DB.WithContext(ctx).AutoMigrate(dst...); err != nil {
+			return fmt.Errorf("failed to auto-migrate models: %w", err)
+		}
+		return nil
+	})
 }
 
 // GetDB returns the underlying gorm.DB instance
@@ -95,9 +234,52 @@ func (c *postgresClient) GetDB() *gorm.DB {
 	return c.DB
 }
 
+// GetReadDB returns a gorm.DB session pinned to the read replicas
+// It returns the primary when no replicas are configured
+func (c *postgresClient) GetReadDB() *gorm.DB {
+	if !c.hasReplicas {
+		return c.DB
+	}
+	return c.DB.Clauses(dbresolver.Read)
+}
+
+// BulkCreate inserts records, a pointer to or a slice of models, in batches of batchSize rows
+// The inserts run in the transaction carried by ctx, if any
+// Returns an error if any batch fails
+func (c *postgresClient) BulkCreate(ctx context.Context, records any, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if err := DBFromContext(ctx, c.DB).WithContext(ctx).CreateInBatches(records, batchSize).Error; err != nil {
+		return fmt.Errorf("failed to bulk create records: %w", err)
+	}
+	return nil
+}
+
+// Ping verifies the database is reachable, for readiness probes
+// Returns an error if the connection cannot be established within ctx
+func (c *postgresClient) Ping(ctx context.Context) error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats returns connection pool statistics such as open and idle connection counts
+func (c *postgresClient) Stats() sql.DBStats {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
 // Close closes the database connection
 // Returns an error if closing the connection fails
 func (c *postgresClient) Close() error {
+	c.stopMetricsSampler()
+
 	sqlDB, err := c.DB.DB()
 	if err != nil {
 		return err
@@ -1,6 +1,20 @@
 // Package postgres provides PostgreSQL database infrastructure components
 package postgres
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSSLMode is returned when Config.SSLMode is not a libpq sslmode
+var ErrInvalidSSLMode = errors.New("invalid sslmode")
+
+// validSSLModes lists the sslmode values accepted by libpq
+var validSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
 // Config holds the PostgreSQL database configuration
 // It contains all the necessary parameters to establish a database connection
 type Config struct {
@@ -17,6 +31,7 @@ type Config struct {
 	// Schema specifies the database schema
 	Schema string
 	// SSLMode specifies the SSL mode for database connection
+	// One of disable, allow, prefer, require, verify-ca or verify-full; empty means disable
 	SSLMode string
 	// MaxIdleConns specifies the maximum number of idle connections in the pool
 	MaxIdleConns int
@@ -28,6 +43,29 @@ type Config struct {
 	ConnMaxLifetime int
 	// Debug enables or disables debug mode for database operations
 	Debug bool
+	// DebugHideParams logs statements in debug mode with placeholders instead of bind parameter values
+	DebugHideParams bool
+	// SlowQueryThreshold logs statements slower than this duration as warnings; zero disables it
+	// Slow queries are reported through the logger passed to NewPostgresClientWithLogger
+	SlowQueryThreshold time.Duration
 	// ConnectTimeout specifies the connection timeout in seconds
 	ConnectTimeout int
+	// ConnectRetries specifies how many times a failed initial connection is retried; zero fails fast
+	ConnectRetries int
+	// ConnectRetryDelay specifies the delay before the first retry, doubled after each attempt; defaults to one second
+	ConnectRetryDelay time.Duration
+	// ReplicaDSNs lists read replica connection strings; reads are routed to them when set
+	ReplicaDSNs []string
+}
+
+// sslMode returns the configured SSL mode, defaulting to disable when empty
+// Returns ErrInvalidSSLMode naming the value and the valid options when it is unknown
+func (c Config) sslMode() (string, error) {
+	if c.SSLMode == "" {
+		return "disable", nil
+	}
+	if !slices.Contains(validSSLModes, c.SSLMode) {
+		return "", fmt.Errorf("%w %q: must be one of %s", ErrInvalidSSLMode, c.SSLMode, strings.Join(validSSLModes, ", "))
+	}
+	return c.SSLMode, nil
 }
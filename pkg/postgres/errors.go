@@ -0,0 +1,23 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// UniqueViolationCode is the SQLSTATE of unique constraint violations
+const UniqueViolationCode = "23505"
+
+// IsUniqueViolation reports whether err was caused by a unique constraint or index violation
+// If constraint is not empty, the violated constraint or index must also have that name
+// Repositories use it to report duplicates that slip past a read-before-write check under concurrency
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == UniqueViolationCode && (constraint == "" || pgErr.ConstraintName == constraint)
+	}
+	// Translated by GORM when TranslateError is enabled; the constraint name is lost
+	return constraint == "" && errors.Is(err, gorm.ErrDuplicatedKey)
+}
@@ -0,0 +1,94 @@
+// Package postgres provides PostgreSQL database infrastructure components
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"monorepo/pkg/logger"
+)
+
+// gormLogger adapts logger.LoggerInterface to GORM's logger.Interface
+// Statements are logged at info level in debug mode, failed statements as errors,
+// and statements slower than the slow query threshold as warnings
+type gormLogger struct {
+	log        logger.LoggerInterface
+	level      gormlogger.LogLevel
+	threshold  time.Duration
+	hideParams bool
+}
+
+// newLoggerAdapter builds a GORM logger that writes through log for the given configuration
+func newLoggerAdapter(cfg Config, log logger.LoggerInterface) gormlogger.Interface {
+	level := gormlogger.Warn
+	if cfg.Debug {
+		level = gormlogger.Info
+	}
+	return &gormLogger{
+		log:        log,
+		level:      level,
+		threshold:  cfg.SlowQueryThreshold,
+		hideParams: cfg.DebugHideParams,
+	}
+}
+
+// LogMode returns a copy of the logger with the given level
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info logs GORM informational messages at info level
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Info {
+		l.log.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs GORM warnings at warn level
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Warn {
+		l.log.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs GORM errors, such as connection failures, at error level
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= gormlogger.Error {
+		l.log.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// ParamsFilter drops bind parameter values from logged statements when DebugHideParams is set
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, params ...any) (string, []any) {
+	if l.hideParams {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// Trace logs an executed statement with its duration and affected rows
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.log.ErrorContext(ctx, "Query failed", "sql", sql, "duration", elapsed, "rows", rows, "error", err)
+	case l.threshold > 0 && elapsed > l.threshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.log.WarnContext(ctx, "Slow query", "sql", sql, "duration", elapsed, "rows", rows, "threshold", l.threshold)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.log.InfoContext(ctx, "Query executed", "sql", sql, "duration", elapsed, "rows", rows)
+	}
+}
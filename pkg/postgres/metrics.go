@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsSampleInterval is how often connection pool statistics are sampled
+const metricsSampleInterval = 15 * time.Second
+
+// poolMetrics holds the Prometheus collectors for connection pool statistics
+type poolMetrics struct {
+	openConnections prometheus.Gauge
+	inUse           prometheus.Gauge
+	idle            prometheus.Gauge
+	waitCount       prometheus.Counter
+	waitDuration    prometheus.Counter
+
+	// lastWaitCount and lastWaitDuration are the cumulative values of the previous sample
+	lastWaitCount    int64
+	lastWaitDuration time.Duration
+}
+
+// newPoolMetrics creates the pool collectors
+func newPoolMetrics() *poolMetrics {
+	return &poolMetrics{
+		openConnections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "postgres",
+			Subsystem: "pool",
+			Name:      "open_connections",
+			Help:      "Number of established connections, both in use and idle",
+		}),
+		inUse: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "postgres",
+			Subsystem: "pool",
+			Name:      "in_use_connections",
+			Help:      "Number of connections currently in use",
+		}),
+		idle: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "postgres",
+			Subsystem: "pool",
+			Name:      "idle_connections",
+			Help:      "Number of idle connections",
+		}),
+		waitCount: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "postgres",
+			Subsystem: "pool",
+			Name:      "wait_count_total",
+			Help:      "Total number of connections waited for",
+		}),
+		waitDuration: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "postgres",
+			Subsystem: "pool",
+			Name:      "wait_duration_seconds_total",
+			Help:      "Total time blocked waiting for a new connection",
+		}),
+	}
+}
+
+// collectors returns every collector to register
+func (m *poolMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.openConnections, m.inUse, m.idle, m.waitCount, m.waitDuration}
+}
+
+// RegisterMetrics registers connection pool metrics with registerer and starts sampling them
+// Statistics are sampled every metricsSampleInterval until Close is called
+// Returns an error if the metrics are already registered
+func (c *postgresClient) RegisterMetrics(registerer prometheus.Registerer) error {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	if c.stopMetrics != nil {
+		return errors.New("postgres metrics are already registered")
+	}
+
+	metrics := newPoolMetrics()
+	for _, collector := range metrics.collectors() {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+
+	c.stopMetrics = make(chan struct{})
+	c.sampleMetrics(metrics)
+	go c.runMetricsSampler(metrics, metricsSampleInterval, c.stopMetrics)
+	return nil
+}
+
+// runMetricsSampler samples pool statistics every interval until stop is closed
+func (c *postgresClient) runMetricsSampler(metrics *poolMetrics, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.sampleMetrics(metrics)
+		}
+	}
+}
+
+// sampleMetrics updates the collectors from the current pool statistics
+// The cumulative wait statistics are added to the counters as deltas
+func (c *postgresClient) sampleMetrics(metrics *poolMetrics) {
+	stats := c.Stats()
+
+	metrics.openConnections.Set(float64(stats.OpenConnections))
+	metrics.inUse.Set(float64(stats.InUse))
+	metrics.idle.Set(float64(stats.Idle))
+
+	if delta := stats.WaitCount - metrics.lastWaitCount; delta > 0 {
+		metrics.waitCount.Add(float64(delta))
+	}
+	if delta := stats.WaitDuration - metrics.lastWaitDuration; delta > 0 {
+		metrics.waitDuration.Add(delta.Seconds())
+	}
+	metrics.lastWaitCount = stats.WaitCount
+	metrics.lastWaitDuration = stats.WaitDuration
+}
+
+// stopMetricsSampler stops the sampler goroutine started by RegisterMetrics, if any
+func (c *postgresClient) stopMetricsSampler() {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	if c.stopMetrics != nil {
+		close(c.stopMetrics)
+		c.stopMetrics = nil
+	}
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MigrationsTable is the table recording the IDs of applied migrations
+const MigrationsTable = "schema_migrations"
+
+// migrationLockID is the pg_advisory_lock key held while migrating
+const migrationLockID int64 = 0x6d6f6e6f7265706f // "monorepo"
+
+// Migration is a versioned, reversible schema change
+type Migration struct {
+	// ID uniquely identifies the migration, e.g. "20240101_add_users_phone"
+	ID string
+	// Up applies the schema change
+	Up func(tx *gorm.DB) error
+	// Down reverts the schema change; nil marks the migration as irreversible
+	Down func(tx *gorm.DB) error
+}
+
+// RunMigrations applies the migrations that have not been applied yet, in the given order
+// Each migration runs in its own transaction together with the record of its ID in schema_migrations
+// The migration advisory lock is held throughout, so concurrent instances apply each migration once
+// The list is remembered so Rollback can find the Down functions of applied migrations
+func (c *postgresClient) RunMigrations(ctx context.Context, migrations []Migration) error {
+	if err := validateMigrations(migrations); err != nil {
+		return err
+	}
+	c.migrations = migrations
+
+	return c.withMigrationLock(ctx, func() error {
+		db := c.DB.WithContext(ctx)
+		if err := ensureMigrationsTable(db); err != nil {
+			return err
+		}
+
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if applied[m.ID] {
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := m.Up(tx); err != nil {
+					return err
+				}
+				return tx.Exec("INSERT INTO "+MigrationsTable+" (id) VALUES (?)", m.ID).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %s: %w", m.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Rollback reverts the last steps applied migrations, most recent first, holding the migration advisory lock
+// Migrations are ordered as in the list passed to RunMigrations, which must have been called before
+func (c *postgresClient) Rollback(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return nil
+	}
+	if len(c.migrations) == 0 {
+		return errors.New("no migrations registered: call RunMigrations before Rollback")
+	}
+
+	return c.withMigrationLock(ctx, func() error {
+		db := c.DB.WithContext(ctx)
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+
+		for i := len(c.migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := c.migrations[i]
+			if !applied[m.ID] {
+				continue
+			}
+			if m.Down == nil {
+				return fmt.Errorf("migration %s is not reversible", m.ID)
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := m.Down(tx); err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM "+MigrationsTable+" WHERE id = ?", m.ID).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to roll back migration %s: %w", m.ID, err)
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// validateMigrations checks that every migration has a unique ID and an Up function
+func validateMigrations(migrations []Migration) error {
+	seen := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		if m.ID == "" {
+			return errors.New("migration ID is required")
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("duplicate migration ID %s", m.ID)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("migration %s has no Up function", m.ID)
+		}
+		seen[m.ID] = true
+	}
+	return nil
+}
+
+// ensureMigrationsTable creates the schema_migrations table if it does not exist
+func ensureMigrationsTable(db *gorm.DB) error {
+	err := db.Exec("CREATE TABLE IF NOT EXISTS " + MigrationsTable + " (id VARCHAR(255) PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW())").Error
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", MigrationsTable, err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the set of applied migration IDs
+func appliedMigrations(db *gorm.DB) (map[string]bool, error) {
+	var ids []string
+	if err := db.Raw("SELECT id FROM " + MigrationsTable).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	applied := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock on a dedicated connection
+// The lock is session-scoped, so the connection is discarded rather than returned to the pool
+// if it cannot be unlocked
+func (c *postgresClient) withMigrationLock(ctx context.Context, fn func() error) error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return fn()
+}
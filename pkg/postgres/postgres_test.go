@@ -1,14 +1,26 @@
 package postgres
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"monorepo/pkg/logger"
 )
 
 func createMockPostgresClient(t *testing.T, db *sql.DB, config Config) PostgresClient {
@@ -110,8 +122,19 @@ func setupMockPostgres(t *testing.T) (PostgresClient, sqlmock.Sqlmock) {
 	return client, mock
 }
 
+// expectMigrationLock expects the advisory lock taken before auto-migration
+func expectMigrationLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectMigrationUnlock expects the advisory lock release after auto-migration
+func expectMigrationUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(migrationLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
 func TestPostgresClient_Migrate(t *testing.T) {
 	client, mock := setupMockPostgres(t)
+	expectMigrationLock(mock)
 
 	// Mock GORM's table existence check for users
 	mock.ExpectQuery(`SELECT count\(\*\) FROM information_schema\.tables WHERE table_schema = CURRENT_SCHEMA\(\) AND table_name = \$1 AND table_type = \$2`).
@@ -144,6 +167,8 @@ func TestPostgresClient_Migrate(t *testing.T) {
 		User   User `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	}
 
+	expectMigrationUnlock(mock)
+
 	err := client.Migrate(&User{}, &Post{})
 	require.NoError(t, err, "Migrate() should not fail")
 
@@ -152,6 +177,7 @@ func TestPostgresClient_Migrate(t *testing.T) {
 
 func TestPostgresClient_Migrate_Error(t *testing.T) {
 	client, mock := setupMockPostgres(t)
+	expectMigrationLock(mock)
 
 	// Mock a migration error
 	mock.ExpectExec(`CREATE TABLE "users"`).
@@ -162,6 +188,8 @@ func TestPostgresClient_Migrate_Error(t *testing.T) {
 		Name string `gorm:"size:100"`
 	}
 
+	expectMigrationUnlock(mock)
+
 	err := client.Migrate(&User{})
 	assert.Error(t, err, "Migrate() should fail with database error")
 	assert.Contains(t, err.Error(), "failed to auto-migrate", "Error should mention migration failure")
@@ -171,8 +199,11 @@ func TestPostgresClient_Migrate_Error(t *testing.T) {
 
 func TestPostgresClient_Migrate_EmptyModels(t *testing.T) {
 	client, mock := setupMockPostgres(t)
+	expectMigrationLock(mock)
 
 	// Test migrating with no models
+	expectMigrationUnlock(mock)
+
 	err := client.Migrate()
 	assert.NoError(t, err, "Migrate() should succeed with no models")
 
@@ -181,6 +212,7 @@ func TestPostgresClient_Migrate_EmptyModels(t *testing.T) {
 
 func TestPostgresClient_Migrate_SingleModel(t *testing.T) {
 	client, mock := setupMockPostgres(t)
+	expectMigrationLock(mock)
 
 	// Mock table existence check
 	mock.ExpectQuery(`SELECT count\(\*\) FROM information_schema\.tables WHERE table_schema = CURRENT_SCHEMA\(\) AND table_name = \$1 AND table_type = \$2`).
@@ -196,6 +228,8 @@ func TestPostgresClient_Migrate_SingleModel(t *testing.T) {
 		Name string `gorm:"size:100"`
 	}
 
+	expectMigrationUnlock(mock)
+
 	err := client.Migrate(&User{})
 	require.NoError(t, err, "Migrate() should not fail")
 
@@ -233,6 +267,26 @@ func TestPostgresClient_Close_Error(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
 }
 
+func TestPostgresClient_Ping(t *testing.T) {
+	client, mock := setupMockPostgres(t)
+
+	mock.ExpectPing()
+	require.NoError(t, client.Ping(context.Background()), "Ping() should succeed")
+
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	assert.Error(t, client.Ping(context.Background()), "Ping() should fail when the database is unreachable")
+
+	require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
+}
+
+func TestPostgresClient_Stats(t *testing.T) {
+	client, _ := setupMockPostgres(t)
+
+	stats := client.Stats()
+	assert.GreaterOrEqual(t, stats.OpenConnections, 0, "Stats() should report open connections")
+	assert.GreaterOrEqual(t, stats.Idle, 0, "Stats() should report idle connections")
+}
+
 func TestConfig(t *testing.T) {
 	config := Config{
 		Host:            "localhost",
@@ -563,3 +617,609 @@ func TestNewPostgresClient_DebugMode(t *testing.T) {
 	assert.Error(t, err, "NewPostgresClient() should fail with invalid host even in debug mode")
 	assert.Nil(t, client, "Client should be nil on error")
 }
+
+// bufferWriter collects GORM log output for assertions
+type bufferWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *bufferWriter) Printf(format string, args ...any) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+func TestNewGormLogger_DebugHideParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		hideParams  bool
+		expectValue bool
+	}{
+		{name: "parameters visible", hideParams: false, expectValue: true},
+		{name: "parameters hidden", hideParams: true, expectValue: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			writer := &bufferWriter{}
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn:                 sqlDB,
+				PreferSimpleProtocol: true,
+			}), &gorm.Config{
+				Logger: newGormLogger(Config{Debug: true, DebugHideParams: tt.hideParams}, writer),
+			})
+			require.NoError(t, err)
+
+			mock.ExpectExec("UPDATE users").WithArgs("secret@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+
+			err = db.Exec("UPDATE users SET email = ?", "secret@example.com").Error
+			require.NoError(t, err)
+
+			output := writer.buf.String()
+			assert.Contains(t, output, "UPDATE users SET email =", "Statement should be logged")
+			if tt.expectValue {
+				assert.Contains(t, output, "secret@example.com", "Parameter value should be logged")
+			} else {
+				assert.NotContains(t, output, "secret@example.com", "Parameter value should be redacted")
+				assert.Contains(t, output, "$1", "Placeholder should be logged")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// testMigrations returns two reversible migrations executing recognizable statements
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			ID:   "001_create_widgets",
+			Up:   func(tx *gorm.DB) error { return tx.Exec("CREATE TABLE widgets (id INT)").Error },
+			Down: func(tx *gorm.DB) error { return tx.Exec("DROP TABLE widgets").Error },
+		},
+		{
+			ID:   "002_add_widgets_name",
+			Up:   func(tx *gorm.DB) error { return tx.Exec("ALTER TABLE widgets ADD COLUMN name TEXT").Error },
+			Down: func(tx *gorm.DB) error { return tx.Exec("ALTER TABLE widgets DROP COLUMN name").Error },
+		},
+	}
+}
+
+func expectMigrationsTable(mock sqlmock.Sqlmock, applied ...string) {
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectAppliedMigrations(mock, applied...)
+}
+
+func expectAppliedMigrations(mock sqlmock.Sqlmock, applied ...string) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, id := range applied {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT id FROM schema_migrations`).WillReturnRows(rows)
+}
+
+func TestPostgresClient_RunMigrations(t *testing.T) {
+	t.Run("applies and records pending migrations", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		expectMigrationLock(mock)
+		expectMigrationsTable(mock, "001_create_widgets")
+		mock.ExpectBegin()
+		mock.ExpectExec(`ALTER TABLE widgets ADD COLUMN name TEXT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO schema_migrations \(id\) VALUES \(\$1\)`).
+			WithArgs("002_add_widgets_name").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		expectMigrationUnlock(mock)
+
+		require.NoError(t, client.RunMigrations(context.Background(), testMigrations()), "RunMigrations() should not fail")
+		require.NoError(t, mock.ExpectationsWereMet(), "Only the pending migration should run")
+	})
+
+	t.Run("failed migration is not recorded", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		expectMigrationLock(mock)
+		expectMigrationsTable(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec(`CREATE TABLE widgets`).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectMigrationUnlock(mock)
+
+		err := client.RunMigrations(context.Background(), testMigrations())
+		require.Error(t, err, "RunMigrations() should fail")
+		assert.Contains(t, err.Error(), "001_create_widgets", "Error should name the failed migration")
+		require.NoError(t, mock.ExpectationsWereMet(), "Later migrations should not run")
+	})
+
+	t.Run("fails without the lock", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(migrationLockID).WillReturnError(context.DeadlineExceeded)
+
+		err := client.RunMigrations(context.Background(), testMigrations())
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "RunMigrations() should fail without the lock")
+		require.NoError(t, mock.ExpectationsWereMet(), "No migration should run without the lock")
+	})
+
+	t.Run("invalid migrations", func(t *testing.T) {
+		client, _ := setupMockPostgres(t)
+		noop := func(tx *gorm.DB) error { return nil }
+
+		assert.Error(t, client.RunMigrations(context.Background(), []Migration{{ID: "", Up: noop}}), "Empty ID should be rejected")
+		assert.Error(t, client.RunMigrations(context.Background(), []Migration{{ID: "a", Up: noop}, {ID: "a", Up: noop}}), "Duplicate IDs should be rejected")
+		assert.Error(t, client.RunMigrations(context.Background(), []Migration{{ID: "a"}}), "Missing Up should be rejected")
+	})
+}
+
+func TestPostgresClient_Rollback(t *testing.T) {
+	t.Run("reverts the most recent migrations", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		expectMigrationLock(mock)
+		expectMigrationsTable(mock, "001_create_widgets", "002_add_widgets_name")
+		expectMigrationUnlock(mock)
+		require.NoError(t, client.RunMigrations(context.Background(), testMigrations()), "RunMigrations() should not fail")
+
+		expectMigrationLock(mock)
+		expectAppliedMigrations(mock, "001_create_widgets", "002_add_widgets_name")
+		mock.ExpectBegin()
+		mock.ExpectExec(`ALTER TABLE widgets DROP COLUMN name`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`DELETE FROM schema_migrations WHERE id = \$1`).
+			WithArgs("002_add_widgets_name").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectMigrationUnlock(mock)
+
+		require.NoError(t, client.Rollback(context.Background(), 1), "Rollback() should not fail")
+		require.NoError(t, mock.ExpectationsWereMet(), "Only the latest migration should be rolled back")
+	})
+
+	t.Run("irreversible migration", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+		migrations := []Migration{{ID: "001_irreversible", Up: func(tx *gorm.DB) error { return nil }}}
+
+		expectMigrationLock(mock)
+		expectMigrationsTable(mock, "001_irreversible")
+		expectMigrationUnlock(mock)
+		require.NoError(t, client.RunMigrations(context.Background(), migrations), "RunMigrations() should not fail")
+
+		expectMigrationLock(mock)
+		expectAppliedMigrations(mock, "001_irreversible")
+		expectMigrationUnlock(mock)
+		assert.Error(t, client.Rollback(context.Background(), 1), "Rollback() should fail without a Down function")
+		require.NoError(t, mock.ExpectationsWereMet(), "Lock should be released")
+	})
+
+	t.Run("requires registered migrations", func(t *testing.T) {
+		client, _ := setupMockPostgres(t)
+		assert.Error(t, client.Rollback(context.Background(), 1), "Rollback() should fail before RunMigrations")
+	})
+}
+
+func TestPostgresClient_GetReadDB(t *testing.T) {
+	t.Run("returns primary without replicas", func(t *testing.T) {
+		client, _ := setupMockPostgres(t)
+		assert.Same(t, client.GetDB(), client.GetReadDB(), "GetReadDB() should return the primary without replicas")
+	})
+
+	t.Run("routes reads to replicas", func(t *testing.T) {
+		client, primaryMock := setupMockPostgres(t)
+
+		replicaSQL, replicaMock, err := sqlmock.New()
+		require.NoError(t, err, "Failed to create replica sqlmock")
+		t.Cleanup(func() { replicaSQL.Close() })
+
+		db := client.GetDB()
+		err = db.Use(dbresolver.Register(dbresolver.Config{
+			Replicas: []gorm.Dialector{postgres.New(postgres.Config{Conn: replicaSQL, PreferSimpleProtocol: true})},
+		}))
+		require.NoError(t, err, "Failed to register resolver")
+		client.(*postgresClient).hasReplicas = true
+
+		replicaMock.ExpectQuery(`SELECT name FROM widgets`).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("from-replica"))
+		primaryMock.ExpectExec(`INSERT INTO widgets`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		var names []string
+		require.NoError(t, client.GetReadDB().Raw("SELECT name FROM widgets").Scan(&names).Error, "Read should succeed")
+		assert.Equal(t, []string{"from-replica"}, names, "Read should be served by the replica")
+
+		require.NoError(t, client.GetDB().Exec("INSERT INTO widgets (name) VALUES ('a')").Error, "Write should succeed")
+
+		require.NoError(t, replicaMock.ExpectationsWereMet(), "Replica expectations should be met")
+		require.NoError(t, primaryMock.ExpectationsWereMet(), "Writes should go to the primary")
+	})
+}
+
+func TestLoggerAdapter_SlowQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   time.Duration
+		hideParams  bool
+		expectWarn  bool
+		expectValue bool
+	}{
+		{name: "disabled without threshold", threshold: 0, expectWarn: false},
+		{name: "slow statement logged", threshold: time.Nanosecond, expectWarn: true, expectValue: true},
+		{name: "slow statement logged without parameters", threshold: time.Nanosecond, hideParams: true, expectWarn: true},
+		{name: "fast statement not logged", threshold: time.Hour, expectWarn: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			var buf bytes.Buffer
+			cfg := Config{SlowQueryThreshold: tt.threshold, DebugHideParams: tt.hideParams}
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn:                 sqlDB,
+				PreferSimpleProtocol: true,
+			}), &gorm.Config{
+				Logger: newLoggerAdapter(cfg, logger.NewJSON(&buf, slog.LevelDebug)),
+			})
+			require.NoError(t, err)
+
+			mock.ExpectExec("UPDATE users").WithArgs("secret@example.com").WillReturnResult(sqlmock.NewResult(0, 1))
+
+			err = db.Exec("UPDATE users SET email = ?", "secret@example.com").Error
+			require.NoError(t, err)
+
+			output := buf.String()
+			if !tt.expectWarn {
+				assert.Empty(t, output, "No slow query should be logged")
+				return
+			}
+			assert.Contains(t, output, `"level":"WARN"`, "Slow query should be logged as a warning")
+			assert.Contains(t, output, "Slow query", "Slow query message should be logged")
+			assert.Contains(t, output, "UPDATE users SET email =", "Statement should be logged")
+			assert.Contains(t, output, `"duration"`, "Duration should be logged")
+			if tt.expectValue {
+				assert.Contains(t, output, "secret@example.com", "Parameter value should be logged")
+			} else {
+				assert.NotContains(t, output, "secret@example.com", "Parameter value should be redacted")
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLoggerAdapter(t *testing.T) {
+	t.Run("debug mode logs statements at info level", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		var buf bytes.Buffer
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn:                 sqlDB,
+			PreferSimpleProtocol: true,
+		}), &gorm.Config{
+			Logger: newLoggerAdapter(Config{Debug: true}, logger.NewJSON(&buf, slog.LevelDebug)),
+		})
+		require.NoError(t, err)
+
+		mock.ExpectExec("DELETE FROM sessions").WillReturnResult(sqlmock.NewResult(0, 3))
+		require.NoError(t, db.Exec("DELETE FROM sessions").Error)
+
+		output := buf.String()
+		assert.Contains(t, output, `"level":"INFO"`, "Statement should be logged at info level")
+		assert.Contains(t, output, "DELETE FROM sessions", "Statement should be logged")
+		assert.Contains(t, output, `"rows":3`, "Affected rows should be logged")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed statements are logged as errors", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+
+		var buf bytes.Buffer
+		db, err := gorm.Open(postgres.New(postgres.Config{
+			Conn:                 sqlDB,
+			PreferSimpleProtocol: true,
+		}), &gorm.Config{
+			Logger: newLoggerAdapter(Config{}, logger.NewJSON(&buf, slog.LevelDebug)),
+		})
+		require.NoError(t, err)
+
+		mock.ExpectExec("DELETE FROM sessions").WillReturnError(errors.New("relation does not exist"))
+		require.Error(t, db.Exec("DELETE FROM sessions").Error)
+
+		output := buf.String()
+		assert.Contains(t, output, `"level":"ERROR"`, "Failure should be logged at error level")
+		assert.Contains(t, output, "relation does not exist", "Error should be logged")
+		assert.NotContains(t, output, "Query executed", "Statements should not be logged outside debug mode")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("GORM messages go through the logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		adapter := newLoggerAdapter(Config{}, logger.NewJSON(&buf, slog.LevelDebug))
+
+		adapter.Error(context.Background(), "failed to initialize database, got error %v", errors.New("connection refused"))
+		adapter.Info(context.Background(), "not logged outside debug mode")
+
+		output := buf.String()
+		assert.Contains(t, output, "failed to initialize database, got error connection refused", "Error message should be formatted")
+		assert.NotContains(t, output, "not logged outside debug mode", "Info should be suppressed outside debug mode")
+	})
+}
+
+func TestPostgresClient_WithinTransaction(t *testing.T) {
+	t.Run("commits and exposes the transaction", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO widgets`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := client.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			tx, ok := TxFromContext(ctx)
+			require.True(t, ok, "Context should carry the transaction")
+			assert.Same(t, tx, DBFromContext(ctx, client.GetDB()), "DBFromContext should return the transaction")
+			return tx.Exec("INSERT INTO widgets (name) VALUES ('a')").Error
+		})
+
+		require.NoError(t, err, "Transaction should commit")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back when fn fails", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		fnErr := errors.New("boom")
+		err := client.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			return fnErr
+		})
+
+		assert.ErrorIs(t, err, fnErr, "Error from fn should be returned")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nested calls reuse the transaction through a savepoint", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := client.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			nestedErr := client.WithinTransaction(ctx, func(context.Context) error {
+				return errors.New("nested failure")
+			})
+			assert.Error(t, nestedErr, "Nested error should be returned")
+			return nil
+		})
+
+		require.NoError(t, err, "Outer transaction should commit")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("without transaction DBFromContext returns the fallback", func(t *testing.T) {
+		client, _ := setupMockPostgres(t)
+
+		_, ok := TxFromContext(context.Background())
+		assert.False(t, ok, "Plain context should not carry a transaction")
+		assert.Same(t, client.GetDB(), DBFromContext(context.Background(), client.GetDB()))
+	})
+}
+
+func TestOpenWithRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		retries       int
+		failedPings   int
+		expectError   bool
+		expectRetries int
+	}{
+		{name: "fails fast without retries", retries: 0, failedPings: 1, expectError: true},
+		{name: "succeeds after retries", retries: 3, failedPings: 2, expectRetries: 2},
+		{name: "gives up after retries", retries: 2, failedPings: 3, expectError: true, expectRetries: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			for range tt.failedPings {
+				mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+			}
+			if !tt.expectError {
+				mock.ExpectPing()
+			}
+
+			var buf bytes.Buffer
+			cfg := Config{ConnectRetries: tt.retries, ConnectRetryDelay: time.Millisecond}
+			dialector := postgres.New(postgres.Config{Conn: sqlDB})
+			db, err := openWithRetry(cfg, dialector, &gorm.Config{Logger: newGormLogger(Config{}, &bufferWriter{})}, logger.NewJSON(&buf, slog.LevelDebug))
+
+			if tt.expectError {
+				require.Error(t, err, "Connection should fail")
+				assert.Contains(t, err.Error(), "connection refused", "Last error should be returned")
+				assert.Nil(t, db)
+			} else {
+				require.NoError(t, err, "Connection should succeed")
+				assert.NotNil(t, db)
+			}
+			assert.Equal(t, tt.expectRetries, strings.Count(buf.String(), "Database connection failed, retrying"), "Unexpected number of retries")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresClient_MigrateWithContext(t *testing.T) {
+	t.Run("fails when the lock cannot be acquired", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(migrationLockID).WillReturnError(context.DeadlineExceeded)
+
+		err := client.MigrateWithContext(context.Background())
+		require.Error(t, err, "MigrateWithContext() should fail without the lock")
+		assert.Contains(t, err.Error(), "failed to acquire migration lock", "Error should mention the lock")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
+	})
+
+	t.Run("releases the lock when migration fails", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		expectMigrationLock(mock)
+		expectMigrationUnlock(mock)
+
+		fnErr := errors.New("boom")
+		err := client.(*postgresClient).withMigrationLock(context.Background(), func() error { return fnErr })
+		assert.ErrorIs(t, err, fnErr, "Migration error should be returned")
+		require.NoError(t, mock.ExpectationsWereMet(), "Lock should be released")
+	})
+}
+
+func TestPostgresClient_BulkCreate(t *testing.T) {
+	type Widget struct {
+		ID   uint `gorm:"primaryKey"`
+		Name string
+	}
+
+	t.Run("inserts in batches", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "widgets" \("name"\) VALUES \(\$1\),\(\$2\) RETURNING "id"`).
+			WithArgs("a", "b").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectQuery(`INSERT INTO "widgets" \("name"\) VALUES \(\$1\) RETURNING "id"`).
+			WithArgs("c").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectCommit()
+
+		widgets := []Widget{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+		err := client.BulkCreate(context.Background(), &widgets, 2)
+		require.NoError(t, err, "BulkCreate() should not fail")
+		assert.Equal(t, uint(3), widgets[2].ID, "Generated IDs should be set")
+		require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
+	})
+
+	t.Run("uses the default batch size", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "widgets" \("name"\) VALUES \(\$1\),\(\$2\),\(\$3\) RETURNING "id"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+		mock.ExpectCommit()
+
+		widgets := []Widget{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+		require.NoError(t, client.BulkCreate(context.Background(), &widgets, 0), "BulkCreate() should not fail")
+		require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
+	})
+
+	t.Run("wraps errors", func(t *testing.T) {
+		client, mock := setupMockPostgres(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "widgets"`).WillReturnError(errors.New("duplicate key"))
+		mock.ExpectRollback()
+
+		widgets := []Widget{{Name: "a"}}
+		err := client.BulkCreate(context.Background(), &widgets, 10)
+		require.Error(t, err, "BulkCreate() should fail")
+		assert.Contains(t, err.Error(), "failed to bulk create records", "Error should be wrapped")
+		assert.Contains(t, err.Error(), "duplicate key", "Cause should be kept")
+		require.NoError(t, mock.ExpectationsWereMet(), "SQL expectations should be met")
+	})
+}
+
+func TestPostgresClient_RegisterMetrics(t *testing.T) {
+	client, mock := setupMockPostgres(t)
+	registry := prometheus.NewRegistry()
+
+	require.NoError(t, client.RegisterMetrics(registry), "RegisterMetrics() should not fail")
+	assert.Error(t, client.RegisterMetrics(registry), "Registering twice should fail")
+
+	families, err := registry.Gather()
+	require.NoError(t, err, "Gather() should not fail")
+	names := make([]string, 0, len(families))
+	values := make(map[string]float64, len(families))
+	for _, family := range families {
+		names = append(names, family.GetName())
+		metric := family.GetMetric()[0]
+		if gauge := metric.GetGauge(); gauge != nil {
+			values[family.GetName()] = gauge.GetValue()
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"postgres_pool_open_connections",
+		"postgres_pool_in_use_connections",
+		"postgres_pool_idle_connections",
+		"postgres_pool_wait_count_total",
+		"postgres_pool_wait_duration_seconds_total",
+	}, names, "All pool metrics should be registered")
+
+	stats := client.Stats()
+	assert.Equal(t, float64(stats.OpenConnections), values["postgres_pool_open_connections"], "Open connections should be sampled on registration")
+	assert.Equal(t, float64(stats.Idle), values["postgres_pool_idle_connections"], "Idle connections should be sampled on registration")
+
+	mock.ExpectClose()
+	require.NoError(t, client.Close(), "Close() should not fail")
+	assert.Nil(t, client.(*postgresClient).stopMetrics, "Close() should stop the sampler")
+}
+
+func TestConfig_SSLMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		sslMode     string
+		expected    string
+		expectError bool
+	}{
+		{name: "empty defaults to disable", sslMode: "", expected: "disable"},
+		{name: "disable", sslMode: "disable", expected: "disable"},
+		{name: "allow", sslMode: "allow", expected: "allow"},
+		{name: "prefer", sslMode: "prefer", expected: "prefer"},
+		{name: "require", sslMode: "require", expected: "require"},
+		{name: "verify-ca", sslMode: "verify-ca", expected: "verify-ca"},
+		{name: "verify-full", sslMode: "verify-full", expected: "verify-full"},
+		{name: "typo", sslMode: "diable", expectError: true},
+		{name: "wrong case", sslMode: "DISABLE", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := Config{SSLMode: tt.sslMode}.sslMode()
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrInvalidSSLMode, "Unknown sslmode should be rejected")
+				assert.Contains(t, err.Error(), fmt.Sprintf("%q", tt.sslMode), "Error should name the invalid value")
+				assert.Contains(t, err.Error(), "disable, allow, prefer, require, verify-ca, verify-full", "Error should list valid values")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
+
+func TestNewPostgresClient_InvalidSSLMode(t *testing.T) {
+	client, err := NewPostgresClient(Config{Host: "localhost", Port: 5432, SSLMode: "diable"})
+	require.ErrorIs(t, err, ErrInvalidSSLMode, "Invalid sslmode should fail before connecting")
+	assert.Nil(t, client)
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	violation := &pgconn.PgError{Code: UniqueViolationCode, ConstraintName: "idx_users_email"}
+	wrapped := fmt.Errorf("failed to create user: %w", violation)
+
+	assert.True(t, IsUniqueViolation(wrapped, ""), "Expected any unique violation to match")
+	assert.True(t, IsUniqueViolation(wrapped, "idx_users_email"), "Expected matching constraint to match")
+	assert.False(t, IsUniqueViolation(wrapped, "idx_agents_email"), "Expected other constraint not to match")
+	assert.False(t, IsUniqueViolation(&pgconn.PgError{Code: "23503"}, ""), "Expected foreign key violation not to match")
+	assert.True(t, IsUniqueViolation(gorm.ErrDuplicatedKey, ""), "Expected translated GORM error to match")
+	assert.False(t, IsUniqueViolation(errors.New("boom"), ""), "Expected unrelated error not to match")
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// WithinTransaction runs fn in a database transaction carried by the context passed to fn
+// The transaction is committed when fn returns nil and rolled back otherwise
+// Calls nested inside fn reuse the active transaction through a savepoint
+func (c *postgresClient) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return RunInTransaction(ctx, c.DB, fn)
+}
+
+// RunInTransaction runs fn in a transaction on db, or on the transaction already carried by ctx
+// Repositories that hold a *gorm.DB use it to share the transaction propagation of WithinTransaction
+func RunInTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return DBFromContext(ctx, db).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// TxFromContext returns the transaction started by WithinTransaction or RunInTransaction, if any
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
+
+// DBFromContext returns the transaction carried by ctx, or db when there is none
+func DBFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,10 +12,16 @@ import (
 type RedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
+	SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	GetJSON(ctx context.Context, key string, dest interface{}) (bool, error)
 	Del(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
 	Expire(ctx context.Context, key string, expiration time.Duration) error
 	TTL(ctx context.Context, key string) (time.Duration, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrBy(ctx context.Context, key string, n int64) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+	IncrWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error)
 	HSet(ctx context.Context, key string, field string, value any) error
 	HGet(ctx context.Context, key string, field string) (string, error)
 	HMSet(ctx context.Context, key string, fields map[string]interface{}) error
@@ -23,6 +30,12 @@ type RedisClient interface {
 	SMembers(ctx context.Context, key string) ([]string, error)
 	LPush(ctx context.Context, key string, values ...interface{}) error
 	RPop(ctx context.Context, key string) (string, error)
+	Ping(ctx context.Context) error
+	Pipeline(ctx context.Context, fn func(Pipe) error) error
+	Scan(ctx context.Context, match string, count int64, fn func(key string) error) error
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+	Publish(ctx context.Context, channel string, message interface{}) error
+	Subscribe(ctx context.Context, channels ...string) (<-chan Message, func() error, error)
 	Close() error
 	GetClient() redis.UniversalClient
 	Addrs() []string
@@ -34,13 +47,25 @@ type RedisClient interface {
 	PoolSize() int
 }
 
+var (
+	// ErrClusterOptionWithoutCluster is returned by New when RouteRandomly or ReadOnly is set outside cluster mode
+	ErrClusterOptionWithoutCluster = errors.New("route randomly and read only options require cluster mode")
+	// ErrMultipleAddrsWithoutCluster is returned by New when several addresses are given with cluster mode disabled
+	ErrMultipleAddrsWithoutCluster = errors.New("multiple addresses require cluster mode")
+	// ErrNil is returned by Get and HGet when the key does not exist
+	ErrNil = redis.Nil
+)
+
 // Option is a function that configures a Client
 type Option func(*Client)
 
 // Client represents a Redis client wrapper
 type Client struct {
-	opts   *redis.UniversalOptions
-	client redis.UniversalClient
+	opts          *redis.UniversalOptions
+	client        redis.UniversalClient
+	pingOnConnect bool
+	// clusterMode is the explicitly selected mode; nil infers it from the number of addresses
+	clusterMode *bool
 }
 
 // New creates a new Redis client with the provided options
@@ -53,6 +78,7 @@ func New(opts ...Option) (RedisClient, error) {
 			WriteTimeout: 3 * time.Second,
 			PoolSize:     10,
 		},
+		pingOnConnect: true,
 	}
 
 	// Apply options
@@ -60,20 +86,50 @@ func New(opts ...Option) (RedisClient, error) {
 		opt(client)
 	}
 
+	if err := client.validateMode(); err != nil {
+		return nil, err
+	}
+
 	// Create the actual Redis client with the configured options
-	client.client = redis.NewUniversalClient(client.opts)
+	if client.clusterMode != nil && !*client.clusterMode {
+		client.client = redis.NewClient(client.opts.Simple())
+	} else {
+		client.client = redis.NewUniversalClient(client.opts)
+	}
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// Test connection unless disabled, so bad addresses or credentials fail at startup
+	if client.pingOnConnect {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 
-	if err := client.client.Ping(ctx).Err(); err != nil {
-		return nil, err
+		if err := client.Ping(ctx); err != nil {
+			_ = client.client.Close()
+			return nil, err
+		}
 	}
 
 	return client, nil
 }
 
+// isCluster reports whether the client routes commands as a cluster client
+func (r *Client) isCluster() bool {
+	if r.clusterMode != nil {
+		return *r.clusterMode
+	}
+	return len(r.opts.Addrs) > 1
+}
+
+// validateMode checks that the addresses and routing options match the selected mode
+func (r *Client) validateMode() error {
+	if r.clusterMode != nil && !*r.clusterMode && len(r.opts.Addrs) > 1 {
+		return ErrMultipleAddrsWithoutCluster
+	}
+	if (r.opts.RouteRandomly || r.opts.ReadOnly) && !r.isCluster() {
+		return ErrClusterOptionWithoutCluster
+	}
+	return nil
+}
+
 // NewWithConfig creates a new Redis client from a config struct
 func NewWithConfig(config Config) (RedisClient, error) {
 	opts := []Option{
@@ -85,6 +141,17 @@ func NewWithConfig(config Config) (RedisClient, error) {
 		WithReadTimeout(config.ReadTimeout),
 		WithWriteTimeout(config.WriteTimeout),
 		WithPoolSize(config.PoolSize),
+		WithRouteRandomly(config.RouteRandomly),
+		WithReadOnly(config.ReadOnly),
+	}
+	if config.ClusterMode != nil {
+		opts = append(opts, WithClusterMode(*config.ClusterMode))
+	}
+	if config.SkipPingOnConnect {
+		opts = append(opts, WithPingOnConnect(false))
+	}
+	if config.TLSEnabled {
+		opts = append(opts, WithTLSInsecureSkipVerify(config.TLSInsecureSkipVerify))
 	}
 
 	return New(opts...)
@@ -164,6 +231,11 @@ func (r *Client) RPop(ctx context.Context, key string) (string, error) {
 	return r.client.RPop(ctx, key).Result()
 }
 
+// Ping checks that the Redis server is reachable and the credentials are accepted
+func (r *Client) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Close closes the Redis client
 func (r *Client) Close() error {
 	return r.client.Close()
@@ -14,4 +14,16 @@ type Config struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	PoolSize     int           `mapstructure:"pool_size"`
+	TLSEnabled   bool          `mapstructure:"tls_enabled"`
+	// SkipPingOnConnect stops NewWithConfig from pinging the server, so it does not fail when the server is unreachable
+	SkipPingOnConnect bool `mapstructure:"skip_ping_on_connect"`
+	// TLSInsecureSkipVerify skips server certificate verification when TLSEnabled is set
+	TLSInsecureSkipVerify bool `mapstructure:"tls_insecure_skip_verify"`
+	// ClusterMode selects a cluster client; when false a single-node client is used and only one address is allowed
+	// When nil the mode is inferred from the number of addresses, as with New
+	ClusterMode *bool `mapstructure:"cluster_mode"`
+	// RouteRandomly spreads read-only commands across cluster nodes; requires ClusterMode
+	RouteRandomly bool `mapstructure:"route_randomly"`
+	// ReadOnly allows replicas to serve read-only commands; requires ClusterMode
+	ReadOnly bool `mapstructure:"read_only"`
 }
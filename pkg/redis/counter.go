@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrWithExpiryScript increments a counter and sets its TTL only when the increment created it
+var incrWithExpiryScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if value == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+
+// Incr atomically increments the counter stored at key by one and returns the new value
+func (r *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// IncrBy atomically increments the counter stored at key by n and returns the new value
+func (r *Client) IncrBy(ctx context.Context, key string, n int64) (int64, error) {
+	return r.client.IncrBy(ctx, key, n).Result()
+}
+
+// Decr atomically decrements the counter stored at key by one and returns the new value
+func (r *Client) Decr(ctx context.Context, key string) (int64, error) {
+	return r.client.Decr(ctx, key).Result()
+}
+
+// IncrWithExpiry atomically increments the counter stored at key and returns the new value
+// The TTL is set only when the counter is created, so a fixed window is not extended by later increments
+func (r *Client) IncrWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrWithExpiryScript.Run(ctx, r.client, []string{key}, ttl.Milliseconds()).Int64()
+}
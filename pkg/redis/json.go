@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SetJSON stores value encoded as JSON under key with expiration
+func (r *Client) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value for key %s: %w", key, err)
+	}
+	return r.client.Set(ctx, key, data, expiration).Err()
+}
+
+// GetJSON decodes the JSON value stored under key into dest
+// Returns found=false without an error when the key does not exist
+func (r *Client) GetJSON(ctx context.Context, key string, dest interface{}) (bool, error) {
+	data, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return false, fmt.Errorf("failed to unmarshal value for key %s: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotAcquired is returned by AcquireLock when another owner holds the lock
+	ErrLockNotAcquired = errors.New("lock is held by another owner")
+	// ErrLockNotHeld is returned by Release and Refresh when the lock expired or was taken over
+	ErrLockNotHeld = errors.New("lock is no longer held")
+)
+
+// releaseScript deletes the lock key only if it still holds the owner's token
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends the lock TTL only if the key still holds the owner's token
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Lock is a distributed lock held on a Redis key
+type Lock interface {
+	// Key returns the locked key
+	Key() string
+	// Release frees the lock if it is still owned
+	// Returns ErrLockNotHeld if it expired or was acquired by another owner
+	Release(ctx context.Context) error
+	// Refresh resets the lock TTL, for jobs running longer than the initial TTL
+	// Returns ErrLockNotHeld if it expired or was acquired by another owner
+	Refresh(ctx context.Context, ttl time.Duration) error
+}
+
+// redisLock implements Lock with a random owner token stored as the key value
+type redisLock struct {
+	client redis.Cmdable
+	key    string
+	token  string
+}
+
+// AcquireLock takes a lock on key that expires after ttl, using SET NX PX
+// Returns ErrLockNotAcquired if the lock is already held
+func (r *Client) AcquireLock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := r.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	return &redisLock{client: r.client, key: key, token: token}, nil
+}
+
+// Key returns the locked key
+func (l *redisLock) Key() string {
+	return l.key
+}
+
+// Release deletes the lock key if it still holds this lock's token
+func (l *redisLock) Release(ctx context.Context) error {
+	deleted, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Refresh resets the lock TTL if the key still holds this lock's token
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	refreshed, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if refreshed == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// randomToken returns a random hex token, used to identify lock owners and rate limit entries
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"crypto/tls"
 	"time"
 )
 
@@ -59,3 +60,56 @@ func WithPoolSize(poolSize int) Option {
 		c.opts.PoolSize = poolSize
 	}
 }
+
+// WithTLS enables TLS with the given configuration; nil leaves TLS disabled
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *Client) {
+		c.opts.TLSConfig = tlsConfig
+	}
+}
+
+// WithTLSInsecureSkipVerify enables TLS and sets whether the server certificate is verified
+// Skipping verification is only meant for development against self-signed certificates
+func WithTLSInsecureSkipVerify(skip bool) Option {
+	return func(c *Client) {
+		if c.opts.TLSConfig == nil {
+			c.opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			c.opts.TLSConfig = c.opts.TLSConfig.Clone()
+		}
+		c.opts.TLSConfig.InsecureSkipVerify = skip
+	}
+}
+
+// WithPingOnConnect sets whether New pings the server and fails if it is unreachable
+// Enabled by default; disable it to create a client before the server is available
+func WithPingOnConnect(enabled bool) Option {
+	return func(c *Client) {
+		c.pingOnConnect = enabled
+	}
+}
+
+// WithClusterMode selects the client type explicitly instead of inferring it from the number of addresses
+// When enabled, a single address is used as a cluster seed; when disabled, exactly one address is allowed
+func WithClusterMode(enabled bool) Option {
+	return func(c *Client) {
+		c.clusterMode = &enabled
+		c.opts.IsClusterMode = enabled
+	}
+}
+
+// WithRouteRandomly spreads read-only commands randomly across master and replica nodes
+// Only valid in cluster mode
+func WithRouteRandomly(enabled bool) Option {
+	return func(c *Client) {
+		c.opts.RouteRandomly = enabled
+	}
+}
+
+// WithReadOnly allows read-only commands to be served by replica nodes
+// Only valid in cluster mode
+func WithReadOnly(enabled bool) Option {
+	return func(c *Client) {
+		c.opts.ReadOnly = enabled
+	}
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Pipe queues commands to be sent in a single round trip
+// The returned commands hold their results once the pipeline has executed
+type Pipe interface {
+	Get(key string) *redis.StringCmd
+	Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(keys ...string) *redis.IntCmd
+	Expire(key string, expiration time.Duration) *redis.BoolCmd
+	HGet(key string, field string) *redis.StringCmd
+	HMGet(key string, fields ...string) *redis.SliceCmd
+	HSet(key string, field string, value any) *redis.IntCmd
+}
+
+// pipe implements Pipe on top of a go-redis pipeliner
+type pipe struct {
+	ctx       context.Context
+	pipeliner redis.Pipeliner
+}
+
+// Get queues a GET
+func (p *pipe) Get(key string) *redis.StringCmd {
+	return p.pipeliner.Get(p.ctx, key)
+}
+
+// Set queues a SET with expiration
+func (p *pipe) Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	return p.pipeliner.Set(p.ctx, key, value, expiration)
+}
+
+// Del queues a DEL
+func (p *pipe) Del(keys ...string) *redis.IntCmd {
+	return p.pipeliner.Del(p.ctx, keys...)
+}
+
+// Expire queues an EXPIRE
+func (p *pipe) Expire(key string, expiration time.Duration) *redis.BoolCmd {
+	return p.pipeliner.Expire(p.ctx, key, expiration)
+}
+
+// HGet queues an HGET
+func (p *pipe) HGet(key string, field string) *redis.StringCmd {
+	return p.pipeliner.HGet(p.ctx, key, field)
+}
+
+// HMGet queues an HMGET
+func (p *pipe) HMGet(key string, fields ...string) *redis.SliceCmd {
+	return p.pipeliner.HMGet(p.ctx, key, fields...)
+}
+
+// HSet queues an HSET of a single field
+func (p *pipe) HSet(key string, field string, value any) *redis.IntCmd {
+	return p.pipeliner.HSet(p.ctx, key, field, value)
+}
+
+// Pipeline queues the commands issued by fn and sends them in a single round trip
+func (r *Client) Pipeline(ctx context.Context, fn func(Pipe) error) error {
+	return ExecPipeline(ctx, r.client, fn)
+}
+
+// ExecPipeline runs fn against a pipeline on client and executes the queued commands
+// Nothing is sent if fn returns an error; a missing key is reported on its command only
+// It lets code holding a go-redis client, such as the one from GetClient, share the Pipe API
+func ExecPipeline(ctx context.Context, client redis.Cmdable, fn func(Pipe) error) error {
+	pipeliner := client.Pipeline()
+	if err := fn(&pipe{ctx: ctx, pipeliner: pipeliner}); err != nil {
+		pipeliner.Discard()
+		return err
+	}
+	if _, err := pipeliner.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
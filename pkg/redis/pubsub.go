@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is a message received on a subscribed channel
+type Message struct {
+	// Channel is the channel the message was published to
+	Channel string
+	// Payload is the message body
+	Payload string
+}
+
+// Publish posts a message to a channel
+func (r *Client) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe subscribes to channels and delivers their messages on the returned channel
+// The subscription is confirmed before Subscribe returns, so later publishes are not missed
+// The returned function unsubscribes and closes the subscription; cancelling ctx does the same
+// The message channel is closed once the subscription ends
+func (r *Client) Subscribe(ctx context.Context, channels ...string) (<-chan Message, func() error, error) {
+	pubsub := r.client.Subscribe(ctx, channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	var closeErr error
+	closeFn := func() error {
+		once.Do(func() {
+			close(done)
+			closeErr = pubsub.Close()
+		})
+		return closeErr
+	}
+
+	messages := make(chan Message)
+	source := pubsub.Channel()
+	go func() {
+		defer close(messages)
+		for {
+			select {
+			case <-ctx.Done():
+				_ = closeFn()
+				return
+			case <-done:
+				return
+			case msg, ok := <-source:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- Message{Channel: msg.Channel, Payload: msg.Payload}:
+				case <-ctx.Done():
+					_ = closeFn()
+					return
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, closeFn, nil
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitKeyPrefix is the prefix of the Redis keys holding rate limit windows
+const RateLimitKeyPrefix = "rate_limit:"
+
+// slidingWindowScript records an event in a sorted set scored by time, unless the
+// window already holds limit events, and returns {allowed, remaining}
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count >= limit then
+	return {0, 0}
+end
+redis.call("ZADD", KEYS[1], now, ARGV[4])
+redis.call("PEXPIRE", KEYS[1], window)
+return {1, limit - count - 1}
+`)
+
+// checkWindowScript reports whether the window of KEYS[1] holds fewer than limit events
+// without recording one, and returns {allowed, milliseconds until an event expires}
+var checkWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+if count < limit then
+	return {1, 0}
+end
+local oldest = redis.call("ZRANGE", KEYS[1], count - limit, count - limit, "WITHSCORES")
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// RateLimiter limits events per key with a sliding window kept in a Redis sorted set
+type RateLimiter struct {
+	client RedisClient
+	// now returns the current time; replaced in tests
+	now func() time.Time
+}
+
+// NewRateLimiter creates a new sliding-window rate limiter using the given Redis client
+func NewRateLimiter(client RedisClient) *RateLimiter {
+	return &RateLimiter{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Allow records an event for key and reports whether it is within limit events per window
+// remaining is the number of events still allowed in the current window
+// Denied events are not recorded, so a client retrying too fast is let through once the window slides
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, remaining int, err error) {
+	member, err := randomToken()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to generate rate limit entry: %w", err)
+	}
+
+	result, err := slidingWindowScript.Run(ctx, l.client.GetClient(), []string{RateLimitKeyPrefix + key},
+		l.now().UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	return result[0] == 1, int(result[1]), nil
+}
+
+// Check reports whether an event for key would be within limit events per window, without recording it
+// When denied, retryAfter is how long until enough events expire from the window for one to be allowed
+// Use it with Allow to count only some events, e.g. check every login but record only failed ones
+func (l *RateLimiter) Check(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	result, err := checkWindowScript.Run(ctx, l.client.GetClient(), []string{RateLimitKeyPrefix + key},
+		l.now().UnixMilli(), window.Milliseconds(), limit).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
@@ -2,9 +2,12 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redismock/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -318,14 +321,15 @@ func TestClient_LPush_RPop(t *testing.T) {
 }
 
 func TestNew(t *testing.T) {
-	client, err := New(WithAddrs([]string{"localhost:6379"}))
+	client, err := New(WithAddrs([]string{"localhost:6379"}), WithPingOnConnect(false))
 	require.NoError(t, err, "New() should not fail")
 	require.NotNil(t, client, "New() should return a client")
 }
 
 func TestNewWithConfig(t *testing.T) {
 	config := Config{
-		Addrs: []string{"localhost:6379"},
+		Addrs:             []string{"localhost:6379"},
+		SkipPingOnConnect: true,
 	}
 	client, err := NewWithConfig(config)
 	require.NoError(t, err, "NewWithConfig() should not fail")
@@ -340,3 +344,548 @@ func TestClient_Getters(t *testing.T) {
 	assert.Equal(t, 0, client.DB(), "DB() should return default DB")
 	assert.Equal(t, 0, client.PoolSize(), "PoolSize() should return pool size")
 }
+
+func TestTokenStore_SingleUse(t *testing.T) {
+	client, mock := setupMockRedis()
+	store := NewTokenStore(client)
+	ctx := context.Background()
+
+	mock.Regexp().ExpectSetNX(`token:password_reset:[0-9a-f]{64}`, "user-1", time.Minute).SetVal(true)
+
+	token, err := store.IssueToken(ctx, "password_reset", "user-1", time.Minute)
+	require.NoError(t, err, "IssueToken() should not fail")
+	assert.Len(t, token, 64, "Expected hex-encoded 32 byte token")
+
+	key := tokenKey("password_reset", token)
+	assert.NotContains(t, key, token, "Token should only be stored hashed")
+
+	mock.ExpectEvalSha(consumeTokenScript.Hash(), []string{key}).SetVal("user-1")
+	mock.ExpectEvalSha(consumeTokenScript.Hash(), []string{key}).RedisNil()
+
+	subject, err := store.ConsumeToken(ctx, "password_reset", token)
+	require.NoError(t, err, "First ConsumeToken() should succeed")
+	assert.Equal(t, "user-1", subject, "Expected token subject")
+
+	_, err = store.ConsumeToken(ctx, "password_reset", token)
+	assert.ErrorIs(t, err, ErrTokenNotFound, "Reusing a token should fail")
+
+	require.NoError(t, mock.ExpectationsWereMet(), "Redis expectations should be met")
+}
+
+func TestTokenStore_Expired(t *testing.T) {
+	client, server := setupMiniRedis(t)
+	store := NewTokenStore(client)
+	ctx := context.Background()
+	const ttl = time.Minute
+
+	token, err := store.IssueToken(ctx, "password_reset", "user-1", ttl)
+	require.NoError(t, err, "IssueToken() should not fail")
+	assert.Equal(t, ttl, server.TTL(tokenKey("password_reset", token)), "The token should expire after its TTL")
+
+	server.FastForward(ttl)
+
+	_, err = store.ConsumeToken(ctx, "password_reset", token)
+	assert.ErrorIs(t, err, ErrTokenNotFound, "Expired token should not be consumable")
+}
+
+func TestTokenStore_PurposeIsolation(t *testing.T) {
+	client, _ := setupMiniRedis(t)
+	store := NewTokenStore(client)
+	ctx := context.Background()
+
+	token, err := store.IssueToken(ctx, "password_reset", "user-1", time.Minute)
+	require.NoError(t, err, "IssueToken() should not fail")
+
+	_, err = store.ConsumeToken(ctx, "email_verification", token)
+	assert.ErrorIs(t, err, ErrTokenNotFound, "A token should not be consumable for another purpose")
+
+	subject, err := store.ConsumeToken(ctx, "password_reset", token)
+	require.NoError(t, err, "The token should still be consumable for its purpose")
+	assert.Equal(t, "user-1", subject, "Expected token subject")
+}
+
+// setupMiniRedis starts an in-memory Redis server for commands redismock cannot emulate
+func setupMiniRedis(t *testing.T) (*Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := &Client{
+		opts:   &redis.UniversalOptions{Addrs: []string{server.Addr()}},
+		client: redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{server.Addr()}}),
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client, server
+}
+
+func TestClient_Publish(t *testing.T) {
+	client, mock := setupMockRedis()
+	ctx := context.Background()
+
+	mock.ExpectPublish("cache-invalidation", "agents").SetVal(1)
+
+	err := client.Publish(ctx, "cache-invalidation", "agents")
+	assert.NoError(t, err, "Publish should not fail")
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	t.Run("receives published messages", func(t *testing.T) {
+		client, _ := setupMiniRedis(t)
+		ctx := context.Background()
+
+		messages, closeFn, err := client.Subscribe(ctx, "invalidate-a", "invalidate-b")
+		require.NoError(t, err, "Subscribe should not fail")
+		defer closeFn()
+
+		require.NoError(t, client.Publish(ctx, "invalidate-b", "agents"))
+
+		select {
+		case msg := <-messages:
+			assert.Equal(t, Message{Channel: "invalidate-b", Payload: "agents"}, msg)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for message")
+		}
+	})
+
+	t.Run("close func ends the subscription", func(t *testing.T) {
+		client, server := setupMiniRedis(t)
+
+		messages, closeFn, err := client.Subscribe(context.Background(), "invalidate")
+		require.NoError(t, err, "Subscribe should not fail")
+		require.Eventually(t, func() bool { return server.PubSubNumSub("invalidate")["invalidate"] == 1 }, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, closeFn(), "Close should not fail")
+		assert.NoError(t, closeFn(), "Close should be idempotent")
+		assertClosed(t, messages)
+		assert.Eventually(t, func() bool { return server.PubSubNumSub("invalidate")["invalidate"] == 0 }, time.Second, 10*time.Millisecond, "Close should unsubscribe")
+	})
+
+	t.Run("context cancellation ends the subscription", func(t *testing.T) {
+		client, server := setupMiniRedis(t)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		messages, _, err := client.Subscribe(ctx, "invalidate")
+		require.NoError(t, err, "Subscribe should not fail")
+
+		cancel()
+		assertClosed(t, messages)
+		assert.Eventually(t, func() bool { return server.PubSubNumSub("invalidate")["invalidate"] == 0 }, time.Second, 10*time.Millisecond, "Cancellation should unsubscribe")
+	})
+}
+
+// assertClosed waits for the message channel to be closed
+func assertClosed(t *testing.T, messages <-chan Message) {
+	t.Helper()
+	select {
+	case _, ok := <-messages:
+		assert.False(t, ok, "Message channel should be closed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the message channel to close")
+	}
+}
+
+func TestClient_Pipeline(t *testing.T) {
+	t.Run("executes queued commands", func(t *testing.T) {
+		client, mock := setupMockRedis()
+		ctx := context.Background()
+
+		mock.ExpectSet("key1", "value1", time.Minute).SetVal("OK")
+		mock.ExpectHGet("session:1", "user_id").SetVal("user123")
+		mock.ExpectDel("key1").SetVal(1)
+		mock.ExpectGet("missing").RedisNil()
+
+		var hget, get *redis.StringCmd
+		err := client.Pipeline(ctx, func(pipe Pipe) error {
+			pipe.Set("key1", "value1", time.Minute)
+			hget = pipe.HGet("session:1", "user_id")
+			pipe.Del("key1")
+			get = pipe.Get("missing")
+			return nil
+		})
+		require.NoError(t, err, "Pipeline should not fail on a missing key")
+		assert.Equal(t, "user123", hget.Val(), "Queued command should hold its result")
+		assert.ErrorIs(t, get.Err(), redis.Nil, "Missing key should be reported on its command")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("discards commands when fn fails", func(t *testing.T) {
+		client, mock := setupMockRedis()
+		fnErr := errors.New("boom")
+
+		err := client.Pipeline(context.Background(), func(pipe Pipe) error {
+			pipe.Del("key1")
+			return fnErr
+		})
+		assert.ErrorIs(t, err, fnErr, "Error from fn should be returned")
+		assert.NoError(t, mock.ExpectationsWereMet(), "No command should be sent")
+	})
+}
+
+func TestClient_Scan(t *testing.T) {
+	t.Run("follows the cursor", func(t *testing.T) {
+		client, mock := setupMockRedis()
+
+		mock.ExpectScan(0, "session:*", 100).SetVal([]string{"session:1", "session:2"}, 42)
+		mock.ExpectScan(42, "session:*", 100).SetVal([]string{"session:3"}, 0)
+
+		var keys []string
+		err := client.Scan(context.Background(), "session:*", 100, func(key string) error {
+			keys = append(keys, key)
+			return nil
+		})
+		require.NoError(t, err, "Scan should not fail")
+		assert.Equal(t, []string{"session:1", "session:2", "session:3"}, keys, "Every key should be visited")
+		assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+	})
+
+	t.Run("stops at the first callback error", func(t *testing.T) {
+		client, mock := setupMockRedis()
+		stop := errors.New("stop")
+
+		mock.ExpectScan(0, "session:*", 0).SetVal([]string{"session:1", "session:2"}, 42)
+
+		visited := 0
+		err := client.Scan(context.Background(), "session:*", 0, func(string) error {
+			visited++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop, "Callback error should be returned")
+		assert.Equal(t, 1, visited, "Iteration should stop after the error")
+		assert.NoError(t, mock.ExpectationsWereMet(), "No further SCAN should be issued")
+	})
+
+	t.Run("returns scan errors", func(t *testing.T) {
+		client, mock := setupMockRedis()
+
+		mock.ExpectScan(0, "session:*", 0).SetErr(errors.New("connection lost"))
+
+		err := client.Scan(context.Background(), "session:*", 0, func(string) error { return nil })
+		assert.EqualError(t, err, "connection lost")
+	})
+}
+
+func TestClient_AcquireLock(t *testing.T) {
+	t.Run("excludes other owners until released", func(t *testing.T) {
+		client, server := setupMiniRedis(t)
+		ctx := context.Background()
+
+		lock, err := client.AcquireLock(ctx, "lock:cleanup", time.Minute)
+		require.NoError(t, err, "First acquire should succeed")
+		assert.Equal(t, "lock:cleanup", lock.Key())
+		assert.Equal(t, time.Minute, server.TTL("lock:cleanup"), "Lock should expire after the TTL")
+
+		_, err = client.AcquireLock(ctx, "lock:cleanup", time.Minute)
+		assert.ErrorIs(t, err, ErrLockNotAcquired, "Second acquire should fail while held")
+
+		require.NoError(t, lock.Release(ctx), "Release should succeed")
+		assert.False(t, server.Exists("lock:cleanup"), "Release should delete the key")
+
+		_, err = client.AcquireLock(ctx, "lock:cleanup", time.Minute)
+		assert.NoError(t, err, "Acquire should succeed after release")
+	})
+
+	t.Run("does not release a lock taken over by another owner", func(t *testing.T) {
+		client, server := setupMiniRedis(t)
+		ctx := context.Background()
+
+		lock, err := client.AcquireLock(ctx, "lock:sync", time.Second)
+		require.NoError(t, err)
+
+		server.FastForward(2 * time.Second)
+		other, err := client.AcquireLock(ctx, "lock:sync", time.Minute)
+		require.NoError(t, err, "Expired lock should be acquirable")
+
+		assert.ErrorIs(t, lock.Release(ctx), ErrLockNotHeld, "Stale owner should not release")
+		assert.ErrorIs(t, lock.Refresh(ctx, time.Minute), ErrLockNotHeld, "Stale owner should not refresh")
+		assert.True(t, server.Exists("lock:sync"), "New owner's lock should remain")
+		assert.NoError(t, other.Release(ctx), "New owner should release")
+	})
+
+	t.Run("refresh extends the TTL", func(t *testing.T) {
+		client, server := setupMiniRedis(t)
+		ctx := context.Background()
+
+		lock, err := client.AcquireLock(ctx, "lock:job", time.Second)
+		require.NoError(t, err)
+
+		require.NoError(t, lock.Refresh(ctx, time.Minute), "Refresh should succeed")
+		assert.Equal(t, time.Minute, server.TTL("lock:job"), "Refresh should reset the TTL")
+	})
+}
+
+func TestWithTLS(t *testing.T) {
+	client := &Client{
+		opts: &redis.UniversalOptions{},
+	}
+	tlsConfig := &tls.Config{ServerName: "cache.example.com", MinVersion: tls.VersionTLS12}
+
+	opt := WithTLS(tlsConfig)
+	opt(client)
+
+	assert.Same(t, tlsConfig, client.opts.TLSConfig, "Expected TLS config to be set")
+}
+
+func TestWithTLSInsecureSkipVerify(t *testing.T) {
+	t.Run("enables TLS", func(t *testing.T) {
+		client := &Client{
+			opts: &redis.UniversalOptions{},
+		}
+
+		WithTLSInsecureSkipVerify(false)(client)
+
+		require.NotNil(t, client.opts.TLSConfig, "Expected TLS to be enabled")
+		assert.False(t, client.opts.TLSConfig.InsecureSkipVerify, "Expected certificate verification")
+		assert.Equal(t, uint16(tls.VersionTLS12), client.opts.TLSConfig.MinVersion, "Expected TLS 1.2 minimum")
+	})
+
+	t.Run("keeps an existing config without mutating it", func(t *testing.T) {
+		tlsConfig := &tls.Config{ServerName: "cache.example.com"}
+		client := &Client{
+			opts: &redis.UniversalOptions{},
+		}
+
+		WithTLS(tlsConfig)(client)
+		WithTLSInsecureSkipVerify(true)(client)
+
+		assert.True(t, client.opts.TLSConfig.InsecureSkipVerify, "Expected verification to be skipped")
+		assert.Equal(t, "cache.example.com", client.opts.TLSConfig.ServerName, "Expected existing settings to be kept")
+		assert.False(t, tlsConfig.InsecureSkipVerify, "Caller's config should not be modified")
+	})
+
+	t.Run("TLS stays off by default", func(t *testing.T) {
+		client := &Client{
+			opts: &redis.UniversalOptions{},
+		}
+
+		WithPoolSize(5)(client)
+
+		assert.Nil(t, client.opts.TLSConfig, "Expected TLS to be disabled")
+	})
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	client, server := setupMiniRedis(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	limiter := NewRateLimiter(client)
+	limiter.now = func() time.Time { return now }
+
+	for expected := 2; expected >= 0; expected-- {
+		allowed, remaining, err := limiter.Allow(ctx, "login:10.0.0.1", 3, time.Minute)
+		require.NoError(t, err, "Allow should not fail")
+		assert.True(t, allowed, "Requests within the limit should be allowed")
+		assert.Equal(t, expected, remaining, "Unexpected remaining count")
+	}
+
+	allowed, remaining, err := limiter.Allow(ctx, "login:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, allowed, "Request over the limit should be denied")
+	assert.Equal(t, 0, remaining)
+
+	allowed, _, err = limiter.Allow(ctx, "login:10.0.0.2", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed, "Keys should be limited independently")
+	assert.True(t, server.Exists(RateLimitKeyPrefix+"login:10.0.0.2"), "Window should be stored under the prefix")
+
+	now = now.Add(61 * time.Second)
+	allowed, remaining, err = limiter.Allow(ctx, "login:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed, "Request should be allowed once the window has slid")
+	assert.Equal(t, 2, remaining, "Expired events should not count")
+}
+
+func TestRateLimiter_Check(t *testing.T) {
+	client, _ := setupMiniRedis(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	limiter := NewRateLimiter(client)
+	limiter.now = func() time.Time { return now }
+
+	allowed, retryAfter, err := limiter.Check(ctx, "login:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err, "Check should not fail")
+	assert.True(t, allowed, "An empty window should be allowed")
+	assert.Zero(t, retryAfter)
+
+	_, _, err = limiter.Allow(ctx, "login:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	now = now.Add(20 * time.Second)
+	_, _, err = limiter.Allow(ctx, "login:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		allowed, retryAfter, err = limiter.Check(ctx, "login:10.0.0.1", 2, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, allowed, "A full window should be denied")
+		assert.Equal(t, 40*time.Second, retryAfter, "Retry should wait until the oldest event expires")
+	}
+
+	now = now.Add(41 * time.Second)
+	allowed, _, err = limiter.Check(ctx, "login:10.0.0.1", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed, "Check should allow once the oldest event has expired")
+}
+
+func TestWithPingOnConnect(t *testing.T) {
+	server := miniredis.RunT(t)
+	unreachable := server.Addr()
+	server.Close()
+
+	t.Run("fails by default when the server is unreachable", func(t *testing.T) {
+		client, err := New(WithAddrs([]string{unreachable}))
+		assert.Error(t, err, "New() should fail when the ping fails")
+		assert.Nil(t, client, "New() should not return a client")
+
+		_, err = NewWithConfig(Config{Addrs: []string{unreachable}})
+		assert.Error(t, err, "NewWithConfig() should fail when the ping fails")
+	})
+
+	t.Run("can be disabled", func(t *testing.T) {
+		client, err := New(WithAddrs([]string{unreachable}), WithPingOnConnect(false))
+		require.NoError(t, err, "New() should not ping when disabled")
+		t.Cleanup(func() { _ = client.Close() })
+
+		client, err = NewWithConfig(Config{Addrs: []string{unreachable}, SkipPingOnConnect: true})
+		require.NoError(t, err, "NewWithConfig() should not ping when skipped")
+		t.Cleanup(func() { _ = client.Close() })
+	})
+
+	t.Run("succeeds when the server is reachable", func(t *testing.T) {
+		server := miniredis.RunT(t)
+
+		client, err := NewWithConfig(Config{Addrs: []string{server.Addr()}})
+		require.NoError(t, err, "NewWithConfig() should not fail")
+		t.Cleanup(func() { _ = client.Close() })
+	})
+}
+
+func TestClient_Ping(t *testing.T) {
+	client, mock := setupMockRedis()
+
+	mock.ExpectPing().SetVal("PONG")
+	assert.NoError(t, client.Ping(context.Background()), "Ping should succeed")
+
+	mock.ExpectPing().SetErr(errors.New("NOAUTH Authentication required"))
+	assert.EqualError(t, client.Ping(context.Background()), "NOAUTH Authentication required")
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestClient_SetJSON_GetJSON(t *testing.T) {
+	type cachedAgent struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	client, mock := setupMockRedis()
+	ctx := context.Background()
+
+	mock.ExpectSet("agent:1", []byte(`{"id":"1","name":"Acme Travel"}`), time.Minute).SetVal("OK")
+	err := client.SetJSON(ctx, "agent:1", cachedAgent{ID: "1", Name: "Acme Travel"}, time.Minute)
+	require.NoError(t, err, "SetJSON should not fail")
+
+	mock.ExpectGet("agent:1").SetVal(`{"id":"1","name":"Acme Travel"}`)
+	var agent cachedAgent
+	found, err := client.GetJSON(ctx, "agent:1", &agent)
+	require.NoError(t, err, "GetJSON should not fail")
+	assert.True(t, found, "Key should be found")
+	assert.Equal(t, cachedAgent{ID: "1", Name: "Acme Travel"}, agent)
+
+	mock.ExpectGet("agent:2").RedisNil()
+	found, err = client.GetJSON(ctx, "agent:2", &agent)
+	assert.NoError(t, err, "Missing key should not be an error")
+	assert.False(t, found, "Missing key should not be found")
+
+	mock.ExpectGet("agent:3").SetVal("not json")
+	found, err = client.GetJSON(ctx, "agent:3", &agent)
+	assert.Error(t, err, "Invalid JSON should fail")
+	assert.False(t, found)
+
+	err = client.SetJSON(ctx, "agent:4", make(chan int), time.Minute)
+	assert.Error(t, err, "Unencodable value should fail")
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestClient_Incr_Decr(t *testing.T) {
+	client, mock := setupMockRedis()
+	ctx := context.Background()
+
+	mock.ExpectIncr("counter").SetVal(1)
+	mock.ExpectIncrBy("counter", 5).SetVal(6)
+	mock.ExpectDecr("counter").SetVal(5)
+
+	value, err := client.Incr(ctx, "counter")
+	require.NoError(t, err, "Incr should not fail")
+	assert.Equal(t, int64(1), value)
+
+	value, err = client.IncrBy(ctx, "counter", 5)
+	require.NoError(t, err, "IncrBy should not fail")
+	assert.Equal(t, int64(6), value)
+
+	value, err = client.Decr(ctx, "counter")
+	require.NoError(t, err, "Decr should not fail")
+	assert.Equal(t, int64(5), value)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "All expectations should be met")
+}
+
+func TestClient_IncrWithExpiry(t *testing.T) {
+	client, server := setupMiniRedis(t)
+	ctx := context.Background()
+
+	value, err := client.IncrWithExpiry(ctx, "failed_logins:user123", time.Minute)
+	require.NoError(t, err, "IncrWithExpiry should not fail")
+	assert.Equal(t, int64(1), value)
+	assert.Equal(t, time.Minute, server.TTL("failed_logins:user123"), "TTL should be set on creation")
+
+	server.FastForward(30 * time.Second)
+	value, err = client.IncrWithExpiry(ctx, "failed_logins:user123", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value)
+	assert.Equal(t, 30*time.Second, server.TTL("failed_logins:user123"), "Later increments should not extend the TTL")
+
+	server.FastForward(31 * time.Second)
+	value, err = client.IncrWithExpiry(ctx, "failed_logins:user123", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value, "Counter should restart after expiry")
+}
+
+func TestNew_ClusterMode(t *testing.T) {
+	t.Run("single mode uses a single-node client", func(t *testing.T) {
+		client, err := New(WithAddrs([]string{"localhost:6379"}), WithClusterMode(false), WithPingOnConnect(false))
+		require.NoError(t, err, "New() should not fail")
+		_, ok := client.GetClient().(*redis.Client)
+		assert.True(t, ok, "Expected a single-node client")
+	})
+
+	t.Run("cluster mode uses a single address as seed", func(t *testing.T) {
+		client, err := New(WithAddrs([]string{"localhost:7000"}), WithClusterMode(true), WithReadOnly(true), WithRouteRandomly(true), WithPingOnConnect(false))
+		require.NoError(t, err, "New() should not fail")
+		_, ok := client.GetClient().(*redis.ClusterClient)
+		assert.True(t, ok, "Expected a cluster client")
+	})
+
+	t.Run("single mode rejects multiple addresses", func(t *testing.T) {
+		_, err := New(WithAddrs([]string{"localhost:7000", "localhost:7001"}), WithClusterMode(false))
+		assert.ErrorIs(t, err, ErrMultipleAddrsWithoutCluster)
+	})
+
+	t.Run("cluster options require cluster mode", func(t *testing.T) {
+		_, err := New(WithAddrs([]string{"localhost:6379"}), WithReadOnly(true))
+		assert.ErrorIs(t, err, ErrClusterOptionWithoutCluster)
+
+		_, err = NewWithConfig(Config{Addrs: []string{"localhost:6379"}, RouteRandomly: true})
+		assert.ErrorIs(t, err, ErrClusterOptionWithoutCluster)
+	})
+
+	t.Run("config without cluster mode infers it from the addresses", func(t *testing.T) {
+		client, err := NewWithConfig(Config{Addrs: []string{"localhost:7000", "localhost:7001"}, SkipPingOnConnect: true})
+		require.NoError(t, err, "NewWithConfig() should accept multiple addresses when cluster mode is unset")
+		_, ok := client.GetClient().(*redis.ClusterClient)
+		assert.True(t, ok, "Expected a cluster client")
+
+		clusterMode := false
+		_, err = NewWithConfig(Config{Addrs: []string{"localhost:7000", "localhost:7001"}, ClusterMode: &clusterMode})
+		assert.ErrorIs(t, err, ErrMultipleAddrsWithoutCluster, "An explicit single mode should still be enforced")
+	})
+}
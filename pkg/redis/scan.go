@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Scan iterates over the keys matching match with a SCAN cursor and calls fn for each key
+// count is a hint for the number of keys per SCAN batch; zero lets Redis decide
+// Iteration stops at the first error returned by fn, which is returned
+// Keys may be reported more than once if the keyspace changes during the scan
+func (r *Client) Scan(ctx context.Context, match string, count int64, fn func(key string) error) error {
+	return ScanKeys(ctx, r.client, match, count, fn)
+}
+
+// ScanKeys runs the Scan key iteration against a go-redis client
+// On a cluster client every master node is scanned; fn is never called concurrently
+func ScanKeys(ctx context.Context, client redis.Cmdable, match string, count int64, fn func(key string) error) error {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		// Masters are scanned in parallel, so serialize the callbacks
+		var mu sync.Mutex
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanNode(ctx, node, match, count, func(key string) error {
+				mu.Lock()
+				defer mu.Unlock()
+				return fn(key)
+			})
+		})
+	}
+	return scanNode(ctx, client, match, count, fn)
+}
+
+// scanNode runs the SCAN cursor loop against a single node
+func scanNode(ctx context.Context, client redis.Cmdable, match string, count int64, fn func(key string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, match, count).Result()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenKeyPrefix is the prefix of the Redis keys holding single-use tokens
+const TokenKeyPrefix = "token:"
+
+// ErrTokenNotFound is returned when a single-use token is unknown, already consumed or expired
+var ErrTokenNotFound = errors.New("token not found or expired")
+
+// consumeTokenScript returns the subject stored for a token and deletes it in one atomic step
+var consumeTokenScript = redis.NewScript(`
+local subject = redis.call("GET", KEYS[1])
+if subject then
+	redis.call("DEL", KEYS[1])
+end
+return subject
+`)
+
+// TokenStore defines the interface for single-use tokens such as password-reset links
+type TokenStore interface {
+	IssueToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error)
+	ConsumeToken(ctx context.Context, purpose, token string) (string, error)
+}
+
+// RedisTokenStore implements TokenStore on top of a RedisClient
+// Only a hash of each token is stored so leaked keys cannot be replayed
+type RedisTokenStore struct {
+	client RedisClient
+}
+
+// NewTokenStore creates a new single-use token store using the given Redis client
+func NewTokenStore(client RedisClient) *RedisTokenStore {
+	return &RedisTokenStore{
+		client: client,
+	}
+}
+
+// IssueToken generates a random token for the subject that is valid for ttl
+// The purpose namespaces tokens so a reset token cannot be used for another flow
+func (s *RedisTokenStore) IssueToken(ctx context.Context, purpose, subject string, ttl time.Duration) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	ok, err := s.client.GetClient().SetNX(ctx, tokenKey(purpose, token), subject, ttl).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+	if !ok {
+		return "", errors.New("failed to store token: token already exists")
+	}
+
+	return token, nil
+}
+
+// ConsumeToken validates the token and deletes it atomically, returning its subject
+// A token can be consumed only once, even by concurrent callers
+func (s *RedisTokenStore) ConsumeToken(ctx context.Context, purpose, token string) (string, error) {
+	subject, err := consumeTokenScript.Run(ctx, s.client.GetClient(), []string{tokenKey(purpose, token)}).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", ErrTokenNotFound
+		}
+		return "", fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	return subject, nil
+}
+
+// tokenKey builds the Redis key for a token from its purpose and SHA-256 hash
+func tokenKey(purpose, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return TokenKeyPrefix + purpose + ":" + hex.EncodeToString(hash[:])
+}
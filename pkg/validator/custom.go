@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"regexp"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// TagStrongPassword is the tag of the password strength validator
+	TagStrongPassword = "strongpassword"
+	// TagPhone is the tag of the E.164 phone number validator
+	TagPhone = "phone"
+
+	// MinPasswordLength is the minimum length of a strong password
+	MinPasswordLength = 8
+)
+
+// e164Pattern matches phone numbers in E.164 format, e.g. +14155552671
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// registerCustomValidations registers the validators for the custom tags of this package
+func registerCustomValidations(validate *validator.Validate) {
+	// Registration only fails for empty tags or nil functions
+	_ = validate.RegisterValidation(TagStrongPassword, validateStrongPassword)
+	_ = validate.RegisterValidation(TagPhone, validatePhone)
+}
+
+// validateStrongPassword checks that a password has at least MinPasswordLength characters
+// and contains an upper case letter, a lower case letter, a digit and a symbol
+func validateStrongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len([]rune(password)) < MinPasswordLength {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	return hasUpper && hasLower && hasDigit && hasSymbol
+}
+
+// validatePhone checks that a phone number is in E.164 format
+func validatePhone(fl validator.FieldLevel) bool {
+	return e164Pattern.MatchString(fl.Field().String())
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+// messages holds the message templates registered with RegisterMessage and RegisterLocaleMessage
+// Templates are keyed by locale, then tag; the default templates use the "" locale
+var messages = struct {
+	sync.RWMutex
+	templates map[string]map[string]string
+}{templates: make(map[string]map[string]string)}
+
+// RegisterMessage sets the default message template of a validation tag, replacing the built-in English message
+// The template is a fmt format receiving the field name and then the tag parameter,
+// e.g. "%s is mandatory" or "%[1]s must have at least %[2]s characters"
+func RegisterMessage(tag, template string) {
+	RegisterLocaleMessage("", tag, template)
+}
+
+// RegisterLocaleMessage sets the message template of a validation tag for a locale, e.g. "id" or "pt-BR"
+// Templates use the same format as RegisterMessage
+func RegisterLocaleMessage(locale, tag, template string) {
+	messages.Lock()
+	defer messages.Unlock()
+
+	locale = normalizeLocale(locale)
+	if messages.templates[locale] == nil {
+		messages.templates[locale] = make(map[string]string)
+	}
+	messages.templates[locale][tag] = template
+}
+
+// ResetMessages removes all registered message templates, restoring the built-in English messages
+func ResetMessages() {
+	messages.Lock()
+	defer messages.Unlock()
+
+	messages.templates = make(map[string]map[string]string)
+}
+
+// PreferredLocale returns the first locale of an Accept-Language header value, or "" if there is none
+// The result can be passed to ValidateStructLocale
+func PreferredLocale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+	return tags[0].String()
+}
+
+// localizedMessage returns the message of a field error for a locale
+// The template of the locale is used first, then the one of its base language, then the default template,
+// falling back to the built-in English message
+func localizedMessage(err validator.FieldError, fieldName, locale string) string {
+	template, ok := lookupTemplate(err.Tag(), locale)
+	if !ok {
+		return formatValidationError(err, fieldName)
+	}
+
+	// Only pass the parameter to templates referring to it, so fmt doesn't report it as extra
+	if strings.Contains(template, "%[2]") || strings.Count(template, "%s") > 1 {
+		return fmt.Sprintf(template, fieldName, err.Param())
+	}
+	return fmt.Sprintf(template, fieldName)
+}
+
+// lookupTemplate returns the registered template of a tag for a locale
+func lookupTemplate(tag, locale string) (string, bool) {
+	messages.RLock()
+	defer messages.RUnlock()
+
+	locale = normalizeLocale(locale)
+	candidates := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, "")
+
+	for _, candidate := range candidates {
+		if template, ok := messages.templates[candidate][tag]; ok {
+			return template, true
+		}
+	}
+	return "", false
+}
+
+// normalizeLocale lower-cases a locale and uses "-" as separator, so "pt_BR" and "pt-br" match
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
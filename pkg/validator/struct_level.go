@@ -0,0 +1,46 @@
+package validator
+
+import (
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// StructLevel gives struct-level validators access to the validated struct and lets them report field errors
+// Errors reported with ReportError are returned by ValidateStruct like field tag errors,
+// keyed by the field path and worded after the reported tag, e.g. "required" or "eq"
+type StructLevel = validator.StructLevel
+
+// StructLevelFunc validates rules spanning several fields of a struct
+type StructLevelFunc func(sl StructLevel)
+
+// structValidation is a struct-level validator and the types it applies to
+type structValidation struct {
+	fn    StructLevelFunc
+	types []any
+}
+
+// structValidations holds the struct-level validators registered with RegisterStructValidation
+var structValidations = struct {
+	sync.RWMutex
+	list []structValidation
+}{}
+
+// RegisterStructValidation registers a struct-level validator for the types of the given values
+// It applies to every validator, including the package-level ValidateStruct; it is meant to be called from init
+func RegisterStructValidation(fn StructLevelFunc, types ...any) {
+	structValidations.Lock()
+	defer structValidations.Unlock()
+
+	structValidations.list = append(structValidations.list, structValidation{fn: fn, types: types})
+}
+
+// registerStructValidations registers the struct-level validators with a go-playground validator
+func registerStructValidations(validate *validator.Validate) {
+	structValidations.RLock()
+	defer structValidations.RUnlock()
+
+	for _, sv := range structValidations.list {
+		validate.RegisterStructValidation(validator.StructLevelFunc(sv.fn), sv.types...)
+	}
+}
@@ -1,6 +1,11 @@
 package validator
 
 import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -9,6 +14,10 @@ import (
 // Validator defines the interface for validation operations
 type Validator interface {
 	ValidateStruct(s any) map[string]string
+	// ValidateStructLocale validates a struct like ValidateStruct, with messages for the given locale
+	ValidateStructLocale(s any, locale string) map[string]string
+	// ValidateVar validates a single value against a validation tag, e.g. "required,ulid"
+	ValidateVar(value any, tag string) error
 }
 
 // validatorImpl implements the Validator interface
@@ -17,14 +26,28 @@ type validatorImpl struct {
 }
 
 // NewValidator creates a new instance of the go-playground validator
+// Fields are reported by their JSON name, falling back to the Go field name
 func NewValidator() Validator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
+	registerCustomValidations(validate)
+	registerStructValidations(validate)
+
 	return &validatorImpl{
-		validate: validator.New(),
+		validate: validate,
 	}
 }
 
 // ValidateStruct validates a struct and returns field-specific errors
+// Errors are keyed by the dotted path of the field from the validated struct, e.g. "user.email" for a nested field
+// Messages use the default templates registered with RegisterMessage
 func (v *validatorImpl) ValidateStruct(s any) map[string]string {
+	return v.ValidateStructLocale(s, "")
+}
+
+// ValidateStructLocale validates a struct and returns field-specific errors with messages for the given locale
+// Tags without a template for the locale use the default messages
+func (v *validatorImpl) ValidateStructLocale(s any, locale string) map[string]string {
 	err := v.validate.Struct(s)
 	if err == nil {
 		return nil
@@ -32,19 +55,63 @@ func (v *validatorImpl) ValidateStruct(s any) map[string]string {
 
 	validationErrors := make(map[string]string)
 	for _, fieldErr := range err.(validator.ValidationErrors) {
-		fieldName := prettifyFieldName(fieldErr.Field())
-		validationErrors[fieldErr.Field()] = formatValidationError(fieldErr, fieldName)
+		fieldName := prettifyFieldName(fieldErr.StructField())
+		validationErrors[fieldPath(fieldErr)] = localizedMessage(fieldErr, fieldName, locale)
 	}
 
 	return validationErrors
 }
 
+// ValidateVar validates a single value, such as a URL parameter, against a validation tag
+// The returned error describes the first failing rule, using "Value" as the field name
+func (v *validatorImpl) ValidateVar(value any, tag string) error {
+	err := v.validate.Var(value, tag)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) == 0 {
+		return err
+	}
+	return errors.New(localizedMessage(validationErrors[0], "Value", ""))
+}
+
+// jsonFieldName returns the JSON name of a struct field, or "" to use the Go field name
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fieldPath returns the dotted path of the failing field without the name of the validated struct
+func fieldPath(fieldErr validator.FieldError) string {
+	if _, path, ok := strings.Cut(fieldErr.Namespace(), "."); ok {
+		return path
+	}
+	return fieldErr.Field()
+}
+
 // ValidateStruct validates a struct and returns field-specific errors (package-level function for backward compatibility)
 func ValidateStruct(s any) map[string]string {
 	v := NewValidator()
 	return v.ValidateStruct(s)
 }
 
+// ValidateVar validates a single value against a validation tag (package-level function)
+func ValidateVar(value any, tag string) error {
+	v := NewValidator()
+	return v.ValidateVar(value, tag)
+}
+
+// ValidateStructLocale validates a struct and returns field-specific errors with messages for the given locale
+func ValidateStructLocale(s any, locale string) map[string]string {
+	v := NewValidator()
+	return v.ValidateStructLocale(s, locale)
+}
+
 // formatValidationError returns a more descriptive error message based on the validation tag
 func formatValidationError(err validator.FieldError, fieldName string) string {
 	switch err.Tag() {
@@ -80,6 +147,13 @@ func formatValidationError(err validator.FieldError, fieldName string) string {
 		return fieldName + " must be one of the following: " + err.Param()
 	case "required_if":
 		return fieldName + " is required when " + err.Param()
+	case "ulid":
+		return fieldName + " must be a valid ULID"
+	case TagStrongPassword:
+		return fieldName + " must be at least " + strconv.Itoa(MinPasswordLength) +
+			" characters long and contain an upper case letter, a lower case letter, a digit and a symbol"
+	case TagPhone:
+		return fieldName + " must be a valid phone number in E.164 format, e.g. +14155552671"
 	default:
 		return fieldName + " is invalid"
 	}
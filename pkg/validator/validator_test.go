@@ -137,3 +137,139 @@ func TestPrettifyFieldName(t *testing.T) {
 		assert.Equal(t, test.expected, result, "prettifyFieldName(%s) should return %s", test.input, test.expected)
 	}
 }
+
+func TestValidateStruct_NestedPaths(t *testing.T) {
+	type Account struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	type Request struct {
+		AgentEmail string  `json:"agent_email" validate:"required,email"`
+		User       Account `json:"user"`
+		Backup     Account `json:"backup"`
+	}
+
+	errors := NewValidator().ValidateStruct(Request{
+		AgentEmail: "invalid",
+		User:       Account{Email: "invalid"},
+		Backup:     Account{},
+	})
+	require.Len(t, errors, 3, "Expected one error per field")
+
+	assert.Equal(t, "Agent Email must be a valid email address", errors["agent_email"], "Top-level fields should use the JSON name")
+	assert.Equal(t, "Email must be a valid email address", errors["user.email"], "Nested fields should use a dotted path")
+	assert.Equal(t, "Email is required", errors["backup.email"], "Nested fields of the same name should not collide")
+}
+
+func TestValidateStruct_StrongPassword(t *testing.T) {
+	type Request struct {
+		Password string `json:"password" validate:"required,strongpassword"`
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"strong", "S3cure!pass", true},
+		{"too short", "S3c!r", false},
+		{"no upper case", "s3cure!pass", false},
+		{"no lower case", "S3CURE!PASS", false},
+		{"no digit", "Secure!pass", false},
+		{"no symbol", "S3curepass", false},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := v.ValidateStruct(Request{Password: tt.password})
+			if tt.valid {
+				assert.Empty(t, errors, "Expected password to be accepted")
+				return
+			}
+			assert.Equal(t, "Password must be at least 8 characters long and contain an upper case letter, a lower case letter, a digit and a symbol",
+				errors["password"], "Expected password strength error")
+		})
+	}
+}
+
+func TestValidateStruct_Phone(t *testing.T) {
+	type Request struct {
+		Phone string `json:"phone" validate:"omitempty,phone"`
+	}
+
+	v := NewValidator()
+	assert.Empty(t, v.ValidateStruct(Request{Phone: "+14155552671"}), "Expected E.164 number to be accepted")
+	assert.Empty(t, v.ValidateStruct(Request{}), "Expected empty optional number to be accepted")
+
+	for _, phone := range []string{"14155552671", "+0123456", "+1415555267123456", "+1 415 555 2671"} {
+		errors := v.ValidateStruct(Request{Phone: phone})
+		assert.Equal(t, "Phone must be a valid phone number in E.164 format, e.g. +14155552671", errors["phone"], "Expected %q to be rejected", phone)
+	}
+}
+
+func TestValidateStructLocale(t *testing.T) {
+	type Request struct {
+		Name     string `json:"name" validate:"required"`
+		Password string `json:"password" validate:"min=8"`
+	}
+	t.Cleanup(ResetMessages)
+
+	RegisterMessage("required", "%s is mandatory")
+	RegisterLocaleMessage("id", "required", "%s wajib diisi")
+	RegisterLocaleMessage("id", "min", "%[1]s minimal %[2]s karakter")
+
+	req := Request{Password: "short"}
+
+	errors := ValidateStruct(req)
+	assert.Equal(t, "Name is mandatory", errors["name"], "Expected default template")
+	assert.Equal(t, "Password must be at least 8 characters long", errors["password"], "Expected built-in message for tags without template")
+
+	errors = ValidateStructLocale(req, "id_ID")
+	assert.Equal(t, "Name wajib diisi", errors["name"], "Expected base language template")
+	assert.Equal(t, "Password minimal 8 karakter", errors["password"], "Expected template with parameter")
+
+	errors = ValidateStructLocale(req, "fr")
+	assert.Equal(t, "Name is mandatory", errors["name"], "Expected default template for unknown locale")
+
+	ResetMessages()
+	errors = ValidateStruct(req)
+	assert.Equal(t, "Name is required", errors["name"], "Expected built-in message after reset")
+}
+
+func TestPreferredLocale(t *testing.T) {
+	assert.Equal(t, "id-ID", PreferredLocale("id-ID,id;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", PreferredLocale("fr;q=0.5, en"))
+	assert.Equal(t, "", PreferredLocale(""))
+}
+
+func TestRegisterStructValidation(t *testing.T) {
+	type Request struct {
+		Type     string  `json:"type" validate:"required"`
+		ParentID *string `json:"parent_id,omitempty"`
+	}
+	RegisterStructValidation(func(sl StructLevel) {
+		req := sl.Current().Interface().(Request)
+		if req.ParentID != nil && req.Type != "CHILD" {
+			sl.ReportError(req.Type, "type", "Type", "eq", "CHILD")
+		}
+	}, Request{})
+
+	parentID := "parent-1"
+	errors := ValidateStruct(Request{Type: "ROOT", ParentID: &parentID})
+	assert.Equal(t, map[string]string{"type": "Type must be equal to CHILD"}, errors, "Expected struct-level error keyed by field")
+
+	assert.Empty(t, ValidateStruct(Request{Type: "CHILD", ParentID: &parentID}), "Expected valid struct to pass")
+	assert.Empty(t, ValidateStruct(Request{Type: "ROOT"}), "Expected valid struct to pass")
+}
+
+func TestValidateVar(t *testing.T) {
+	assert.NoError(t, ValidateVar("01ARZ3NDEKTSV4RRFFQ69G5FAV", "required,ulid"), "Expected valid ULID to pass")
+
+	err := ValidateVar("", "required,ulid")
+	require.Error(t, err, "Expected empty value to fail")
+	assert.Equal(t, "Value is required", err.Error())
+
+	err = ValidateVar("not-a-ulid", "required,ulid")
+	require.Error(t, err, "Expected malformed value to fail")
+	assert.Equal(t, "Value must be a valid ULID", err.Error())
+}
@@ -3,12 +3,7 @@
 package main
 
 import (
-	"context"
-	"net/http"
 	"os"
-	"os/signal"
-	"strconv"
-	"syscall"
 	"time"
 
 	"agent-service/config"
@@ -16,6 +11,7 @@ import (
 	"agent-service/domain/model"
 	pgRepository "agent-service/repository/postgres"
 	"agent-service/usecase"
+	"monorepo/pkg/bootstrap"
 	"monorepo/pkg/jwt"
 	"monorepo/pkg/kafka"
 	"monorepo/pkg/logger"
@@ -27,14 +23,13 @@ import (
 // It performs the following steps:
 // 1. Initializes the logger
 // 2. Loads configuration from files or environment variables
-// 3. Sets up the database connection
-// 4. Runs database migrations
-// 5. Initializes the repository, usecase, and handler layers
-// 6. Sets up HTTP routes
-// 7. Starts the HTTP server with graceful shutdown
+// 3. Sets up infrastructure (database with migrations, Redis, Kafka, JWT) via bootstrap
+// 4. Initializes the repository, usecase, and handler layers
+// 5. Sets up HTTP routes
+// 6. Starts the HTTP server with graceful shutdown
 func main() {
-	// configure logger
-	appLogger := logger.NewJSONDefault()
+	// configure logger, redacting secrets such as passwords and tokens from log attributes
+	appLogger := logger.NewWithOptions(logger.WithJSONFormat(), logger.WithRedactKeys())
 
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -43,155 +38,129 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize PostgreSQL client
-	postgresClient, err := postgres.NewPostgresClient(postgres.Config{
-		Host:            cfg.Infrastructure.Postgres.Host,
-		Port:            cfg.Infrastructure.Postgres.Port,
-		User:            cfg.Infrastructure.Postgres.User,
-		Password:        cfg.Infrastructure.Postgres.Password,
-		DBName:          cfg.Infrastructure.Postgres.DBName,
-		Schema:          cfg.Infrastructure.Postgres.Schema,
-		SSLMode:         cfg.Infrastructure.Postgres.SSLMode,
-		MaxIdleConns:    cfg.Infrastructure.Postgres.MaxIdleConns,
-		MaxOpenConns:    cfg.Infrastructure.Postgres.MaxOpenConns,
-		ConnMaxIdleTime: cfg.Infrastructure.Postgres.ConnMaxIdleTime,
-		ConnMaxLifetime: cfg.Infrastructure.Postgres.ConnMaxLifetime,
-		Debug:           cfg.Infrastructure.Postgres.Debug,
-	})
+	// Apply the configured log level; it can be changed again at runtime with SetLevel
+	logLevel, err := cfg.Application.SlogLevel()
 	if err != nil {
-		appLogger.Error("Failed to connect to database", "error", err)
+		appLogger.Error("Failed to parse log level", "error", err)
 		os.Exit(1)
 	}
+	appLogger.SetLevel(logLevel)
 
-	if cfg.Infrastructure.Postgres.IsUseMigrate {
-		// Run database migrations
-		err = postgresClient.Migrate(
-			&model.User{},
-			&model.Agent{},
-		)
-		if err != nil {
-			appLogger.Error("Failed to migrate database", "error", err)
-			os.Exit(1)
-		}
-	}
-
-	// Initialize Redis client
-	redisClient, redisErr := redis.New(
-		redis.WithAddrs(cfg.Infrastructure.Redis.Addrs),
-		redis.WithUsername(cfg.Infrastructure.Redis.Username),
-		redis.WithPassword(cfg.Infrastructure.Redis.Password),
-		redis.WithDB(cfg.Infrastructure.Redis.DB),
-		redis.WithPoolSize(cfg.Infrastructure.Redis.PoolSize),
-	)
-	if redisErr != nil {
-		appLogger.Error("Failed to initialize Redis client", "error", redisErr)
-		os.Exit(1)
-	}
-
-	// Initialize Kafka client
-	kafkaClient, kafkaErr := kafka.New(
-		kafka.WithBrokers(cfg.Infrastructure.Kafka.Brokers...),
-		kafka.WithClientID(cfg.Application.Name),
-		kafka.WithAllowAutoTopicCreation(),
-		kafka.WithMetadataMaxAge(10*time.Minute),
-		kafka.WithRequestRetries(5),
-	)
-	if kafkaErr != nil {
-		appLogger.Error("Failed to initialize Kafka client", "error", kafkaErr)
+	trustedProxies, err := cfg.Server.TrustedProxyPrefixes()
+	if err != nil {
+		appLogger.Error("Failed to parse trusted proxies", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize JWT client
-	var jwtClient jwt.JWTClient
-	if cfg.Security.JWT.Stateful {
-		// Initialize JWT client with Redis for stateful mode
-		jwtClient, err = jwt.NewStatefulWithRedis(redisClient,
-			jwt.WithAccessTokenSecret(cfg.Security.JWT.AccessTokenSecret),
-			jwt.WithRefreshTokenSecret(cfg.Security.JWT.RefreshTokenSecret),
-			jwt.WithAccessTokenExpiry(time.Duration(cfg.Security.JWT.AccessTokenExpiry)*time.Minute),
-			jwt.WithRefreshTokenExpiry(time.Duration(cfg.Security.JWT.RefreshTokenExpiry)*time.Hour),
-			jwt.WithStateful(true),
-		)
-	} else {
-		// Initialize JWT client for stateless mode
-		jwtClient, err = jwt.NewWithConfig(jwt.TokenConfig{
-			AccessTokenSecret:  cfg.Security.JWT.AccessTokenSecret,
-			RefreshTokenSecret: cfg.Security.JWT.RefreshTokenSecret,
-			AccessTokenExpiry:  time.Duration(cfg.Security.JWT.AccessTokenExpiry) * time.Minute,
-			RefreshTokenExpiry: time.Duration(cfg.Security.JWT.RefreshTokenExpiry) * time.Hour,
-			Stateful:           false,
-		})
-	}
-
+	// Initialize infrastructure
+	app, err := bootstrap.New(bootstrapConfig(cfg), appLogger)
 	if err != nil {
-		appLogger.Error("Failed to initialize JWT client", "error", err)
+		appLogger.Error("Failed to initialize infrastructure", "error", err)
 		os.Exit(1)
 	}
 
 	// Initialize repository
-	userRepo := pgRepository.NewUserRepository(postgresClient.GetDB(), appLogger)
-	agentRepo := pgRepository.NewAgentRepository(postgresClient.GetDB(), appLogger)
+	userRepo := pgRepository.NewUserRepository(app.Postgres.GetDB(), appLogger)
+	agentRepo := pgRepository.NewAgentRepository(app.Postgres.GetDB(), appLogger)
 
 	// Initialize usecase
-	userUsecase := usecase.NewUserUseCase(userRepo, appLogger)
+	userUsecase := usecase.NewUserUseCase(userRepo, app.JWT, appLogger)
 	agentUsecase := usecase.NewAgentUseCase(agentRepo, userRepo, appLogger)
 
 	// Initialize auth usecase
-	authUsecase := usecase.NewAuthUseCase(userRepo, agentRepo, jwtClient, redisClient, kafkaClient, cfg.Infrastructure.Kafka.Topics.PasswordReset, appLogger)
+	authUsecase := usecase.NewAuthUseCase(userRepo, agentRepo, app.JWT, app.Redis, app.Kafka, cfg.Infrastructure.Kafka.Topics.PasswordReset,
+		usecase.LoginRateLimitConfig{
+			MaxAttempts: cfg.Security.Login.MaxAttemptsPerIP,
+			Window:      time.Duration(cfg.Security.Login.AttemptWindow) * time.Minute,
+		},
+		usecase.LoginLockoutConfig{
+			MaxFailedAttempts: cfg.Security.Login.MaxFailedAttempts,
+			Duration:          time.Duration(cfg.Security.Login.LockoutDuration) * time.Minute,
+		}, appLogger)
 
 	// Initialize handlers
 	userHandler := httpDelivery.NewUserHandler(userUsecase, appLogger)
 	agentHandler := httpDelivery.NewAgentHandler(agentUsecase, appLogger)
-	healthHandler := httpDelivery.NewHealthHandler(appLogger)
-	authHandler := httpDelivery.NewAuthHandler(authUsecase, appLogger)
+	healthHandler := httpDelivery.NewHealthHandler(appLogger, app.Postgres, app.Redis)
+	authHandler := httpDelivery.NewAuthHandler(authUsecase, trustedProxies, appLogger)
 
 	// Initialize router
-	router := httpDelivery.NewRouter(userHandler, agentHandler, healthHandler, authHandler, jwtClient, appLogger)
-
-	// Setup routes
-	httpHandler := router.SetupRoutes()
+	router := httpDelivery.NewRouter(userHandler, agentHandler, healthHandler, authHandler, app.JWT, cfg.Server.MaxBodySize, appLogger)
 
-	// Start server
-	server := &http.Server{
-		Addr:         ":" + strconv.Itoa(cfg.Server.Port),
-		Handler:      httpHandler,
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
-	}
-
-	// Create channel to listen for interrupt signal
-	quit := make(chan os.Signal, 1)
-
-	// Register the channel to receive specific signals
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	// Start HTTP server in a separate goroutine
-	go func() {
-		appLogger.Info("Service starting", "name", cfg.Application.Name, "version", cfg.Application.Version, "port", cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			appLogger.Error("Failed to start server", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	// Block until a signal is received
-	<-quit
-	appLogger.Info("Shutting down server...")
-
-	// Create a context with timeout for graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
-	defer cancel()
-
-	// Shutdown the server gracefully
-	if err := server.Shutdown(ctx); err != nil {
-		appLogger.Error("Server forced to shutdown", "error", err)
+	// Start server and block until shutdown
+	if err := app.Run(router.SetupRoutes()); err != nil {
+		appLogger.Error("Server exited with error", "error", err)
 		os.Exit(1)
 	}
+}
 
-	// Close database connection
-	if err := postgresClient.Close(); err != nil {
-		appLogger.Warn("Error closing database connection", "error", err)
+// bootstrapConfig maps the service configuration to the shared infrastructure configuration
+func bootstrapConfig(cfg *config.Config) bootstrap.Config {
+	return bootstrap.Config{
+		Name:    cfg.Application.Name,
+		Version: cfg.Application.Version,
+		Server: bootstrap.ServerConfig{
+			Port:            cfg.Server.Port,
+			ReadTimeout:     time.Duration(cfg.Server.ReadTimeout) * time.Second,
+			WriteTimeout:    time.Duration(cfg.Server.WriteTimeout) * time.Second,
+			ShutdownTimeout: time.Duration(cfg.Server.ShutdownTimeout) * time.Second,
+			Protocols:       cfg.Server.Protocols(),
+			TLSCertFile:     cfg.Server.TLSCertFile,
+			TLSKeyFile:      cfg.Server.TLSKeyFile,
+		},
+		Postgres: &postgres.Config{
+			Host:               cfg.Infrastructure.Postgres.Host,
+			Port:               cfg.Infrastructure.Postgres.Port,
+			User:               cfg.Infrastructure.Postgres.User,
+			Password:           cfg.Infrastructure.Postgres.Password,
+			DBName:             cfg.Infrastructure.Postgres.DBName,
+			Schema:             cfg.Infrastructure.Postgres.Schema,
+			SSLMode:            cfg.Infrastructure.Postgres.SSLMode,
+			MaxIdleConns:       cfg.Infrastructure.Postgres.MaxIdleConns,
+			MaxOpenConns:       cfg.Infrastructure.Postgres.MaxOpenConns,
+			ConnMaxIdleTime:    cfg.Infrastructure.Postgres.ConnMaxIdleTime,
+			ConnMaxLifetime:    cfg.Infrastructure.Postgres.ConnMaxLifetime,
+			Debug:              cfg.Infrastructure.Postgres.Debug,
+			DebugHideParams:    cfg.Infrastructure.Postgres.DebugHideParams,
+			SlowQueryThreshold: time.Duration(cfg.Infrastructure.Postgres.SlowQueryThreshold) * time.Millisecond,
+			ReplicaDSNs:        cfg.Infrastructure.Postgres.ReplicaDSNs,
+			ConnectRetries:     cfg.Infrastructure.Postgres.ConnectRetries,
+			ConnectRetryDelay:  time.Duration(cfg.Infrastructure.Postgres.ConnectRetryDelay) * time.Second,
+		},
+		Migrate: cfg.Infrastructure.Postgres.IsUseMigrate,
+		Models: []any{
+			&model.User{},
+			&model.Agent{},
+		},
+		Redis: &redis.Config{
+			Addrs:                 cfg.Infrastructure.Redis.Addrs,
+			Username:              cfg.Infrastructure.Redis.Username,
+			Password:              cfg.Infrastructure.Redis.Password,
+			DB:                    cfg.Infrastructure.Redis.DB,
+			PoolSize:              cfg.Infrastructure.Redis.PoolSize,
+			TLSEnabled:            cfg.Infrastructure.Redis.TLSEnabled,
+			TLSInsecureSkipVerify: cfg.Infrastructure.Redis.TLSInsecureSkipVerify,
+			ClusterMode:           cfg.Infrastructure.Redis.ClusterMode,
+			RouteRandomly:         cfg.Infrastructure.Redis.RouteRandomly,
+			ReadOnly:              cfg.Infrastructure.Redis.ReadOnly,
+		},
+		Kafka: &kafka.Config{
+			Brokers:                cfg.Infrastructure.Kafka.Brokers,
+			ClientID:               cfg.Application.Name,
+			AllowAutoTopicCreation: true,
+			MetadataMaxAge:         10 * time.Minute,
+			RequestRetries:         5,
+			FlushTimeout:           10 * time.Second,
+		},
+		JWT: &jwt.TokenConfig{
+			AccessTokenSecret:  cfg.Security.JWT.AccessTokenSecret,
+			RefreshTokenSecret: cfg.Security.JWT.RefreshTokenSecret,
+			AccessTokenExpiry:  time.Duration(cfg.Security.JWT.AccessTokenExpiry) * time.Minute,
+			RefreshTokenExpiry: time.Duration(cfg.Security.JWT.RefreshTokenExpiry) * time.Hour,
+			Stateful:           cfg.Security.JWT.Stateful,
+			SessionGracePeriod: time.Duration(cfg.Security.JWT.SessionGracePeriod) * time.Minute,
+		},
+		SessionJanitorInterval: time.Duration(cfg.Security.JWT.SessionJanitorInterval) * time.Minute,
+		VerifyTokenStore:       cfg.Security.JWT.VerifyStoreOnStartup,
 	}
-
-	appLogger.Info("Server exited")
 }
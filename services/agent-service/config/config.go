@@ -3,7 +3,12 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -28,6 +33,17 @@ type ApplicationConfig struct {
 	Name string `mapstructure:"name"`
 	// Version specifies the version of the application
 	Version string `mapstructure:"version"`
+	// LogLevel specifies the minimum log level: debug, info, warn or error
+	LogLevel string `mapstructure:"log_level"`
+}
+
+// SlogLevel parses LogLevel into a slog level
+func (c ApplicationConfig) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", c.LogLevel, err)
+	}
+	return level, nil
 }
 
 // ServerConfig holds the server configuration
@@ -41,6 +57,56 @@ type ServerConfig struct {
 	WriteTimeout int `mapstructure:"write_timeout"` // in seconds
 	// ShutdownTimeout defines the maximum duration the server will wait for active connections to finish during shutdown, in seconds
 	ShutdownTimeout int `mapstructure:"shutdown_timeout"` // in seconds
+	// MaxBodySize is the maximum size of request bodies in bytes; larger requests are rejected with a 413
+	MaxBodySize int64 `mapstructure:"max_body_size"` // in bytes
+	// EnableH2C enables HTTP/2 over cleartext (h2c), e.g. behind proxies that speak HTTP/2 to the backend
+	EnableH2C bool `mapstructure:"enable_h2c"`
+	// DisableHTTP2 restricts the server to HTTP/1.1
+	DisableHTTP2 bool `mapstructure:"disable_http2"`
+	// TLSCertFile specifies the TLS certificate file; TLS is enabled when both cert and key files are set
+	TLSCertFile string `mapstructure:"tls_cert_file"`
+	// TLSKeyFile specifies the TLS private key file
+	TLSKeyFile string `mapstructure:"tls_key_file"`
+	// TrustedProxies lists the IPs or CIDRs of proxies whose X-Forwarded-For header is trusted for the client IP
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// TLSEnabled reports whether the server should serve TLS
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// TrustedProxyPrefixes parses TrustedProxies into network prefixes
+// A bare IP address is treated as a single-host prefix
+func (c ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Protocols returns the HTTP protocols accepted by the server
+// By default HTTP/1.1 is served, plus HTTP/2 when TLS is enabled
+func (c ServerConfig) Protocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(!c.DisableHTTP2)
+	protocols.SetUnencryptedHTTP2(c.EnableH2C && !c.DisableHTTP2)
+	return protocols
 }
 
 // InfrastructureConfig holds the infrastructure configuration
@@ -59,6 +125,21 @@ type InfrastructureConfig struct {
 type SecurityConfig struct {
 	// JWT contains JWT token configuration
 	JWT JWTConfig `mapstructure:"jwt"`
+	// Login contains login protection configuration
+	Login LoginConfig `mapstructure:"login"`
+}
+
+// LoginConfig holds the login protection configuration
+// It contains settings for throttling failed logins per IP and locking accounts after repeated failed logins
+type LoginConfig struct {
+	// MaxAttemptsPerIP is the number of failed logins allowed per client IP within AttemptWindow; zero disables the limit
+	MaxAttemptsPerIP int `mapstructure:"max_attempts_per_ip"`
+	// AttemptWindow is the sliding window failed logins per client IP are counted over, in minutes
+	AttemptWindow int `mapstructure:"attempt_window"` // in minutes
+	// MaxFailedAttempts is the number of failed logins after which an account is locked; zero disables lockout
+	MaxFailedAttempts int `mapstructure:"max_failed_attempts"`
+	// LockoutDuration is how long an account stays locked, and the window failed logins are counted over, in minutes
+	LockoutDuration int `mapstructure:"lockout_duration"` // in minutes
 }
 
 // JWTConfig holds the JWT configuration
@@ -74,6 +155,12 @@ type JWTConfig struct {
 	RefreshTokenExpiry int `mapstructure:"refresh_token_expiry"` // in hours
 	// Stateful indicates whether to use stateful token management
 	Stateful bool `mapstructure:"stateful"`
+	// SessionJanitorInterval is how often inactive sessions are cleaned up in stateful mode, in minutes
+	SessionJanitorInterval int `mapstructure:"session_janitor_interval"` // in minutes
+	// SessionGracePeriod is how long inactive sessions are kept before cleanup, in minutes
+	SessionGracePeriod int `mapstructure:"session_grace_period"` // in minutes
+	// VerifyStoreOnStartup checks the refresh token store on startup in stateful mode and warns if it is empty
+	VerifyStoreOnStartup bool `mapstructure:"verify_store_on_startup"`
 }
 
 // RedisConfig holds the Redis configuration
@@ -89,6 +176,17 @@ type RedisConfig struct {
 	DB int `mapstructure:"db"`
 	// PoolSize specifies the maximum number of socket connections
 	PoolSize int `mapstructure:"pool_size"`
+	// TLSEnabled specifies whether to connect to Redis over TLS
+	TLSEnabled bool `mapstructure:"tls_enabled"`
+	// TLSInsecureSkipVerify skips server certificate verification; for development only
+	TLSInsecureSkipVerify bool `mapstructure:"tls_insecure_skip_verify"`
+	// ClusterMode selects a Redis Cluster client; when false only one address is allowed
+	// When unset the mode is inferred from the number of addresses
+	ClusterMode *bool `mapstructure:"cluster_mode"`
+	// RouteRandomly spreads read-only commands across cluster nodes; requires ClusterMode
+	RouteRandomly bool `mapstructure:"route_randomly"`
+	// ReadOnly allows cluster replicas to serve read-only commands; requires ClusterMode
+	ReadOnly bool `mapstructure:"read_only"`
 }
 
 // KafkaConfig holds the Kafka configuration
@@ -133,6 +231,16 @@ type PostgresConfig struct {
 	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"` // in minutes
 	// Debug enables or disables debug mode for database operations
 	Debug bool `mapstructure:"debug"`
+	// DebugHideParams logs statements in debug mode without bind parameter values
+	DebugHideParams bool `mapstructure:"debug_hide_params"`
+	// SlowQueryThreshold logs statements slower than this as warnings, in milliseconds; zero disables it
+	SlowQueryThreshold int `mapstructure:"slow_query_threshold"` // in milliseconds
+	// ReplicaDSNs lists read replica connection strings; reads are routed to them when set
+	ReplicaDSNs []string `mapstructure:"replica_dsns"`
+	// ConnectRetries specifies how many times a failed initial connection is retried; zero fails fast
+	ConnectRetries int `mapstructure:"connect_retries"`
+	// ConnectRetryDelay specifies the delay before the first retry, doubled after each attempt, in seconds
+	ConnectRetryDelay int `mapstructure:"connect_retry_delay"` // in seconds
 	// IsUseMigrate specifies whether to use database migration
 	IsUseMigrate bool `mapstructure:"is_use_migrate"`
 }
@@ -154,6 +262,9 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.read_timeout", 15)     // seconds
 	viper.SetDefault("server.write_timeout", 15)    // seconds
 	viper.SetDefault("server.shutdown_timeout", 30) // seconds
+	viper.SetDefault("server.max_body_size", 1<<20) // bytes (1 MiB)
+	viper.SetDefault("server.enable_h2c", false)
+	viper.SetDefault("server.disable_http2", false)
 	viper.SetDefault("infrastructure.postgres.host", "localhost")
 	viper.SetDefault("infrastructure.postgres.port", 5432)
 	// No defaults for user and password - they must be provided
@@ -165,17 +276,33 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("infrastructure.postgres.conn_max_idle_time", 5) // minutes
 	viper.SetDefault("infrastructure.postgres.conn_max_lifetime", 60) // minutes
 	viper.SetDefault("infrastructure.postgres.debug", false)
+	viper.SetDefault("infrastructure.postgres.debug_hide_params", false)
+	viper.SetDefault("infrastructure.postgres.slow_query_threshold", 0)
+	viper.SetDefault("infrastructure.postgres.connect_retries", 5)
+	viper.SetDefault("infrastructure.postgres.connect_retry_delay", 1)
 	viper.SetDefault("application.name", "Application Service")
 	viper.SetDefault("application.version", "1.0")
+	viper.SetDefault("application.log_level", "info")
 	// No defaults for JWT secrets - they must be provided via config or env
 	viper.SetDefault("security.jwt.access_token_expiry", 15)    // minutes
 	viper.SetDefault("security.jwt.refresh_token_expiry", 24*7) // hours (7 days)
 	viper.SetDefault("security.jwt.stateful", false)
+	viper.SetDefault("security.jwt.session_janitor_interval", 10) // minutes
+	viper.SetDefault("security.jwt.session_grace_period", 60)     // minutes
+	viper.SetDefault("security.jwt.verify_store_on_startup", true)
+	viper.SetDefault("security.login.max_attempts_per_ip", 10)
+	viper.SetDefault("security.login.attempt_window", 15) // minutes
+	viper.SetDefault("security.login.max_failed_attempts", 5)
+	viper.SetDefault("security.login.lockout_duration", 15) // minutes
 	viper.SetDefault("infrastructure.redis.addrs", []string{"localhost:6379"})
 	viper.SetDefault("infrastructure.redis.username", "")
 	viper.SetDefault("infrastructure.redis.password", "")
 	viper.SetDefault("infrastructure.redis.db", 0)
 	viper.SetDefault("infrastructure.redis.pool_size", 10)
+	viper.SetDefault("infrastructure.redis.tls_enabled", false)
+	viper.SetDefault("infrastructure.redis.tls_insecure_skip_verify", false)
+	viper.SetDefault("infrastructure.redis.route_randomly", false)
+	viper.SetDefault("infrastructure.redis.read_only", false)
 	viper.SetDefault("infrastructure.kafka.brokers", []string{"localhost:9092"})
 	viper.SetDefault("infrastructure.kafka.topics.password_reset", "agent.password.reset")
 
@@ -204,6 +331,9 @@ func LoadConfig() (*Config, error) {
 	if config.Infrastructure.Postgres.Password == "" {
 		return nil, errors.New("database password is required")
 	}
+	if _, err := config.Server.TrustedProxyPrefixes(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
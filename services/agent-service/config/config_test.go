@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerConfig_Protocols(t *testing.T) {
+	tests := []struct {
+		name                       string
+		cfg                        ServerConfig
+		wantHTTP2, wantUnencrypted bool
+	}{
+		{name: "default", cfg: ServerConfig{}, wantHTTP2: true},
+		{name: "h2c", cfg: ServerConfig{EnableH2C: true}, wantHTTP2: true, wantUnencrypted: true},
+		{name: "http2 disabled", cfg: ServerConfig{DisableHTTP2: true}},
+		{name: "http2 disabled overrides h2c", cfg: ServerConfig{EnableH2C: true, DisableHTTP2: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocols := tt.cfg.Protocols()
+			assert.True(t, protocols.HTTP1(), "HTTP/1.1 should always be served")
+			assert.Equal(t, tt.wantHTTP2, protocols.HTTP2(), "Unexpected HTTP/2 over TLS setting")
+			assert.Equal(t, tt.wantUnencrypted, protocols.UnencryptedHTTP2(), "Unexpected h2c setting")
+		})
+	}
+}
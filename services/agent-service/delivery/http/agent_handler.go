@@ -45,7 +45,7 @@ func (h *AgentHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.CreateAgentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for agent creation", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -61,17 +61,22 @@ func (h *AgentHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	if err := h.AgentUseCase.CreateAgent(ctx, agent); err != nil {
 		switch {
 		case err.Error() == domain.ErrEmailRequired.Message:
-			h.API.BadRequest(ctx, w, err.Error())
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "email", Message: err.Error()}})
 		case err.Error() == domain.ErrAgentNameRequired.Message:
-			h.API.BadRequest(ctx, w, err.Error())
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_name", Message: err.Error()}})
 		case err.Error() == domain.ErrAgentTypeRequired.Message:
-			h.API.BadRequest(ctx, w, err.Error())
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_type", Message: err.Error()}})
 		case err.Error() == domain.ErrInvalidAgentType.Message:
-			h.API.BadRequest(ctx, w, err.Error())
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_type", Message: err.Error()}})
 		case err.Error() == domain.ErrParentAgentNotFound.Message:
 			h.API.NotFound(ctx, w, err.Error())
 		case err.Error() == domain.ErrCircularReference.Message:
 			h.API.BadRequest(ctx, w, err.Error())
+		case err.Error() == domain.ErrAgentHierarchyTooDeep.Message:
+			h.API.BadRequest(ctx, w, err.Error())
+		case domain.IsContextError(err):
+			h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 		default:
 			h.Logger.ErrorContext(ctx, "Unexpected error during agent creation", "email", agent.Email, "error", err)
 			h.API.InternalServerError(ctx, w, "Failed to create agent")
@@ -91,19 +96,24 @@ func (h *AgentHandler) handleAgentError(ctx context.Context, w http.ResponseWrit
 	case errors.Is(err, domain.ErrInvalidID):
 		h.API.BadRequest(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrEmailRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "email", Message: err.Error()}})
 	case errors.Is(err, domain.ErrAgentNameRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_name", Message: err.Error()}})
 	case errors.Is(err, domain.ErrAgentTypeRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_type", Message: err.Error()}})
 	case errors.Is(err, domain.ErrInvalidAgentType):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "agent_type", Message: err.Error()}})
 	case errors.Is(err, domain.ErrParentAgentNotFound):
 		h.API.NotFound(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrCircularReference):
 		h.API.BadRequest(ctx, w, err.Error())
+	case errors.Is(err, domain.ErrAgentHierarchyTooDeep):
+		h.API.BadRequest(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrAgentHasChildren):
 		h.API.BadRequest(ctx, w, err.Error())
+	case domain.IsContextError(err):
+		h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+		h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 	default:
 		h.Logger.ErrorContext(ctx, "Unexpected error", "error", err)
 		h.API.InternalServerError(ctx, w, "An unexpected error occurred")
@@ -115,21 +125,21 @@ func (h *AgentHandler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Get agent by ID handler called")
 
-	req := agent_service.GetAgentByIDRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for get agent by ID", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for get agent by ID", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
-	agent, err := h.AgentUseCase.GetAgentByID(ctx, req.ID)
+	agent, err := h.AgentUseCase.GetAgentByID(ctx, id)
 	if err != nil {
 		h.handleAgentError(ctx, w, err)
 		return
 	}
 
 	h.Logger.InfoContext(ctx, "Agent retrieved by ID in handler", "id", agent.ID, "email", agent.Email)
-	h.API.Success(ctx, w, agent_service.AgentModelToResponse(agent))
+	h.API.SuccessWithETag(ctx, w, r, agent_service.AgentModelToResponse(agent))
 }
 
 // UpdateHandler handles HTTP requests to update an existing agent
@@ -140,7 +150,7 @@ func (h *AgentHandler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.UpdateAgentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for agent update", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -193,22 +203,43 @@ func (h *AgentHandler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Delete agent handler called")
 
-	req := agent_service.DeleteAgentRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for delete agent", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for delete agent", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
-	if err := h.AgentUseCase.DeleteAgent(ctx, req.ID); err != nil {
+	if err := h.AgentUseCase.DeleteAgent(ctx, id); err != nil {
 		h.handleAgentError(ctx, w, err)
 		return
 	}
 
-	h.Logger.InfoContext(ctx, "Agent deleted successfully in handler", "id", req.ID)
+	h.Logger.InfoContext(ctx, "Agent deleted successfully in handler", "id", id)
 	h.API.Success(ctx, w, map[string]string{"message": "Agent deleted successfully"})
 }
 
+// RestoreHandler handles HTTP requests to restore a deleted agent
+func (h *AgentHandler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Restore agent handler called")
+
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for restore agent", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
+		return
+	}
+
+	if err := h.AgentUseCase.RestoreAgent(ctx, id); err != nil {
+		h.handleAgentError(ctx, w, err)
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Agent restored successfully in handler", "id", id)
+	h.API.Success(ctx, w, map[string]string{"message": "Agent restored successfully"})
+}
+
 // ListHandler handles HTTP requests to list agents with pagination
 func (h *AgentHandler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -232,6 +263,11 @@ func (h *AgentHandler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	// Get agents and real total from usecase
 	agents, total, err := h.AgentUseCase.ListAgents(ctx, offset, limit)
 	if err != nil {
+		if domain.IsContextError(err) {
+			h.Logger.WarnContext(ctx, "Error listing agents: request cancelled", "offset", offset, "limit", limit, "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
 		h.Logger.ErrorContext(ctx, "Error listing agents", "offset", offset, "limit", limit, "error", err)
 		h.API.InternalServerError(ctx, w, "Failed to list agents")
 		return
@@ -293,7 +329,7 @@ func (h *AgentHandler) CreateSubAgentHandler(w http.ResponseWriter, r *http.Requ
 	var req agent_service.CreateSubAgentWithUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for sub-agent with user creation", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -313,6 +349,11 @@ func (h *AgentHandler) CreateSubAgentHandler(w http.ResponseWriter, r *http.Requ
 			h.API.BadRequest(ctx, w, err.Error())
 		case errors.Is(err, domain.ErrParentAgentNotFound):
 			h.API.NotFound(ctx, w, err.Error())
+		case errors.Is(err, domain.ErrEmailAlreadyExists):
+			h.API.Conflict(ctx, w, domain.ErrEmailAlreadyExists.Message)
+		case domain.IsContextError(err):
+			h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 		default:
 			h.Logger.ErrorContext(ctx, "Unexpected error during sub-agent with user creation", "parent_id", parentID, "error", err)
 			h.API.InternalServerError(ctx, w, "Failed to create sub-agent with user")
@@ -337,10 +378,9 @@ func (h *AgentHandler) ListSubAgentsHandler(w http.ResponseWriter, r *http.Reque
 	h.Logger.InfoContext(ctx, "List sub-agents handler called", "parent_id", parentID)
 
 	// Validate parent ID
-	req := agent_service.GetAgentByIDRequest{ID: parentID}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for list sub-agents", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	if err := validator.ValidateVar(parentID, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for list sub-agents", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
@@ -354,6 +394,11 @@ func (h *AgentHandler) ListSubAgentsHandler(w http.ResponseWriter, r *http.Reque
 	// Get sub-agents
 	subAgents, err := h.AgentUseCase.GetAgentsByParentID(ctx, parentID)
 	if err != nil {
+		if domain.IsContextError(err) {
+			h.Logger.WarnContext(ctx, "Error listing sub-agents: request cancelled", "parent_id", parentID, "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
 		h.Logger.ErrorContext(ctx, "Error listing sub-agents", "parent_id", parentID, "error", err)
 		h.API.InternalServerError(ctx, w, "Failed to list sub-agents")
 		return
@@ -363,14 +408,41 @@ func (h *AgentHandler) ListSubAgentsHandler(w http.ResponseWriter, r *http.Reque
 	h.API.Success(ctx, w, agent_service.AgentModelsToResponses(subAgents))
 }
 
+// GetTreeHandler handles HTTP requests to get an agent with all its descendants
+// The optional max_depth query parameter limits the number of levels returned below the agent
+func (h *AgentHandler) GetTreeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rootID := chi.URLParam(r, "id")
+	h.Logger.InfoContext(ctx, "Get agent tree handler called", "root_id", rootID)
+
+	if err := validator.ValidateVar(rootID, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for get agent tree", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
+		return
+	}
+
+	maxDepth := 0
+	if raw := r.URL.Query().Get("max_depth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 1 {
+			h.Logger.WarnContext(ctx, "Invalid max_depth for get agent tree", "max_depth", raw)
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "max_depth", Message: "Max Depth must be a positive integer"}})
+			return
+		}
+		maxDepth = depth
+	}
+
+	tree, err := h.AgentUseCase.GetAgentTree(ctx, rootID, maxDepth)
+	if err != nil {
+		h.handleAgentError(ctx, w, err)
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Agent tree retrieved successfully in handler", "root_id", rootID)
+	h.API.Success(ctx, w, agent_service.AgentNodeToResponse(tree))
+}
+
 // convertValidationErrors converts validation errors to API format
 func (h *AgentHandler) convertValidationErrors(validationErrors map[string]string) []api.ErrorDetail {
-	details := make([]api.ErrorDetail, 0, len(validationErrors))
-	for field, message := range validationErrors {
-		details = append(details, api.ErrorDetail{
-			Field:   field,
-			Message: message,
-		})
-	}
-	return details
+	return api.ValidationDetails(validationErrors)
 }
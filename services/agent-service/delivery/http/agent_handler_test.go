@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/usecase"
+	"monorepo/pkg/api"
+	"monorepo/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgentUseCase returns err from CreateAgent
+// Methods not overridden panic through the nil embedded interface
+type fakeAgentUseCase struct {
+	usecase.AgentUseCase
+	err error
+}
+
+func (uc *fakeAgentUseCase) CreateAgent(_ context.Context, _ *model.Agent) error {
+	return uc.err
+}
+
+func TestAgentHandler_CreateHandler_StatusCodes(t *testing.T) {
+	const validBody = `{"agent_name":"Agent","agent_type":"IATA","email":"agent@example.com"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+		wantFields []string
+	}{
+		{name: "malformed body", body: `{"agent_name":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid fields", body: `{"agent_type":"OTHER"}`, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"agent_name", "agent_type", "email"}},
+		{name: "name rejected by the usecase", body: validBody, err: domain.ErrAgentNameRequired, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"agent_name"}},
+		{name: "type rejected by the usecase", body: validBody, err: domain.ErrInvalidAgentType, wantStatus: http.StatusUnprocessableEntity, wantFields: []string{"agent_type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAgentHandler(&fakeAgentUseCase{err: tt.err}, logger.NoOpLogger())
+			w := httptest.NewRecorder()
+			handler.CreateHandler(w, httptest.NewRequest(http.MethodPost, "/agents", strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantStatus, w.Code, "Unexpected status code")
+
+			var response api.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+			require.NotNil(t, response.Error, "Response should carry an error")
+
+			fields := make([]string, 0, len(response.Error.Details))
+			for _, detail := range response.Error.Details {
+				fields = append(fields, detail.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields, "Details should name the invalid JSON fields")
+		})
+	}
+}
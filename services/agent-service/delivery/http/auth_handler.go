@@ -3,14 +3,19 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"strings"
+	"time"
 
 	"agent-service/domain"
 	"agent-service/usecase"
 	"monorepo/contracts/agent_service"
 	"monorepo/pkg/api"
+	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
 	"monorepo/pkg/validator"
 )
@@ -23,24 +28,29 @@ type AuthHandler struct {
 	Logger logger.LoggerInterface
 	// API provides standardized API response patterns
 	API api.Api
+	// TrustedProxies are the networks of proxies whose X-Forwarded-For header is trusted for the client IP
+	TrustedProxies []netip.Prefix
 }
 
 // NewAuthHandler creates a new instance of AuthHandler
-// It takes an AuthUseCase implementation and a logger instance
+// It takes an AuthUseCase implementation, the trusted proxy networks, and a logger instance
 // Returns a pointer to an AuthHandler
-func NewAuthHandler(authUseCase usecase.AuthUseCase, logger logger.LoggerInterface) *AuthHandler {
+func NewAuthHandler(authUseCase usecase.AuthUseCase, trustedProxies []netip.Prefix, logger logger.LoggerInterface) *AuthHandler {
 	return &AuthHandler{
-		AuthUseCase: authUseCase,
-		Logger:      logger,
-		API:         api.New(),
+		AuthUseCase:    authUseCase,
+		Logger:         logger,
+		API:            api.New(),
+		TrustedProxies: trustedProxies,
 	}
 }
 
 // LoginHandler handles HTTP requests for user login
 // It expects a JSON payload with email and password in the request body
 // Returns a 200 status code with access and refresh tokens on success
-// Returns a 400 status code for invalid request data
+// Returns a 400 status code for a malformed request body
+// Returns a 422 status code for validation errors
 // Returns a 401 status code for invalid credentials
+// Returns a 429 status code with a Retry-After header for too many attempts or a locked account
 // Returns a 500 status code for internal server errors
 func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -49,7 +59,7 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Failed to decode login request", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -69,17 +79,40 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.Logger.WarnContext(ctx, "Login failed", "email", req.Email, "error", err)
 
+		// Throttling errors carry how long the client should wait
+		var retryAfter time.Duration
+		var retryErr *domain.RetryAfterError
+		if errors.As(err, &retryErr) {
+			retryAfter = retryErr.RetryAfter
+		}
+
+		// Locked accounts keep their own error code so clients can tell them from IP throttling
+		if errors.Is(err, domain.ErrAccountLocked) {
+			api.SetRetryAfter(w, retryAfter)
+			h.API.Error(ctx, w, http.StatusTooManyRequests, &api.Error{Code: "ACCOUNT_LOCKED", Message: domain.ErrAccountLocked.Message})
+			return
+		}
+
 		// Check if it's a domain error with status code
-		if appErr, ok := err.(*domain.AppError); ok {
+		var appErr *domain.AppError
+		if errors.As(err, &appErr) {
 			switch appErr.Code {
 			case 401:
 				h.API.Unauthorized(ctx, w, appErr.Message)
+			case 429:
+				h.API.TooManyRequests(ctx, w, appErr.Message, retryAfter)
 			default:
 				h.API.BadRequest(ctx, w, appErr.Message)
 			}
 			return
 		}
 
+		// Request was cancelled or timed out before completing
+		if domain.IsContextError(err) {
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
+
 		// Generic error
 		h.API.InternalServerError(ctx, w, "Login failed")
 		return
@@ -92,7 +125,8 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 // RefreshHandler handles HTTP requests for token refresh
 // It expects a JSON payload with refresh_token in the request body
 // Returns a 200 status code with new access token on success
-// Returns a 400 status code for invalid request data
+// Returns a 400 status code for a malformed request body
+// Returns a 422 status code for validation errors
 // Returns a 401 status code for invalid refresh token
 // Returns a 500 status code for internal server errors
 func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
@@ -102,7 +136,7 @@ func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.RefreshTokenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Failed to decode refresh request", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -129,6 +163,12 @@ func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Request was cancelled or timed out before completing
+		if domain.IsContextError(err) {
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
+
 		// Generic error
 		h.API.InternalServerError(ctx, w, "Token refresh failed")
 		return
@@ -138,6 +178,107 @@ func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	h.API.Success(ctx, w, response)
 }
 
+// LogoutHandler handles HTTP requests for logout
+// It revokes the refresh token and ends the session given in the request body
+// Returns a 200 status code with a success message on success
+// Returns a 400 status code for a malformed request body
+// Returns a 401 status code for an invalid or already revoked refresh token
+// Returns a 404 status code if the session is not found
+// Returns a 422 status code for validation errors
+// Returns a 500 status code for internal server errors
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Logout handler called")
+
+	var req agent_service.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.ErrorContext(ctx, "Failed to decode logout request", "error", err)
+		h.API.InvalidBody(ctx, w, err)
+		return
+	}
+
+	// Validate request
+	if validationErrors := validator.ValidateStruct(req); validationErrors != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for logout request", "errors", validationErrors)
+		h.API.ValidationError(ctx, w, h.convertValidationErrors(validationErrors))
+		return
+	}
+
+	// Call usecase
+	if err := h.AuthUseCase.Logout(ctx, req.RefreshToken, req.SessionID); err != nil {
+		h.Logger.WarnContext(ctx, "Logout failed", "error", err)
+
+		switch {
+		case errors.Is(err, domain.ErrInvalidRefreshToken):
+			h.API.Unauthorized(ctx, w, err.Error())
+		case errors.Is(err, domain.ErrSessionNotFound):
+			h.API.NotFound(ctx, w, err.Error())
+		case domain.IsContextError(err):
+			// Request was cancelled or timed out before completing
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+		default:
+			h.API.InternalServerError(ctx, w, "Logout failed")
+		}
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Logout successful")
+	h.API.Success(ctx, w, agent_service.LogoutResponse{Message: "Logged out successfully"})
+}
+
+// SessionsHandler handles HTTP requests listing the authenticated user's active sessions
+// The optional cursor and limit query parameters page through the sessions
+// Returns a 200 status code with a page of sessions on success
+// Returns a 400 status code if sessions are not tracked
+// Returns a 401 status code for unauthorized access
+// Returns a 422 status code for an invalid cursor
+// Returns a 500 status code for internal server errors
+func (h *AuthHandler) SessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Sessions handler called")
+
+	var cursor uint64
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			h.Logger.WarnContext(ctx, "Invalid cursor for sessions request", "cursor", raw)
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "cursor", Message: "Cursor must be a non-negative integer"}})
+			return
+		}
+		cursor = parsed
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = jwt.DefaultSessionPageSize
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	// Call usecase (user ID is extracted from JWT middleware)
+	response, err := h.AuthUseCase.Sessions(ctx, cursor, limit)
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Listing sessions failed", "error", err)
+
+		switch {
+		case errors.Is(err, domain.ErrUnauthorized):
+			h.API.Unauthorized(ctx, w, err.Error())
+		case errors.Is(err, domain.ErrSessionsNotSupported):
+			h.API.BadRequest(ctx, w, err.Error())
+		case domain.IsContextError(err):
+			// Request was cancelled or timed out before completing
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+		default:
+			h.API.InternalServerError(ctx, w, "Failed to list sessions")
+		}
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Sessions listed successfully", "count", len(response.Sessions))
+	h.API.Success(ctx, w, response)
+}
+
 // ProfileHandler handles HTTP requests for authenticated user profile
 // It retrieves the user profile information from the authenticated user's context
 // Returns a 200 status code with user profile data on success
@@ -166,12 +307,18 @@ func (h *AuthHandler) ProfileHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Check for specific error messages
-		if err.Error() == "unauthorized: user ID not found" {
+		// Check for specific errors
+		if errors.Is(err, domain.ErrUnauthorized) {
 			h.API.Unauthorized(ctx, w, "Unauthorized")
 			return
 		}
 
+		// Request was cancelled or timed out before completing
+		if domain.IsContextError(err) {
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
+
 		// Generic error
 		h.API.InternalServerError(ctx, w, "Profile retrieval failed")
 		return
@@ -184,7 +331,8 @@ func (h *AuthHandler) ProfileHandler(w http.ResponseWriter, r *http.Request) {
 // ForgotPasswordHandler handles HTTP requests for forgot password
 // It initiates the password reset process by generating a reset token
 // Returns a 200 status code with a success message on success
-// Returns a 400 status code for invalid request data
+// Returns a 400 status code for a malformed request body
+// Returns a 422 status code for validation errors
 // Returns a 500 status code for internal server errors
 func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -193,7 +341,7 @@ func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Reque
 	var req agent_service.ForgotPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Failed to decode forgot password request", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -207,6 +355,11 @@ func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Reque
 	// Call usecase
 	response, err := h.AuthUseCase.ForgotPassword(ctx, req)
 	if err != nil {
+		if domain.IsContextError(err) {
+			h.Logger.WarnContext(ctx, "Forgot password failed: request cancelled", "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
 		h.Logger.ErrorContext(ctx, "Forgot password failed", "error", err)
 		h.API.InternalServerError(ctx, w, "Failed to process forgot password request")
 		return
@@ -219,7 +372,8 @@ func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Reque
 // ResetPasswordHandler handles HTTP requests for reset password
 // It validates the reset token and updates the user's password
 // Returns a 200 status code with a success message on success
-// Returns a 400 status code for invalid request data or token
+// Returns a 400 status code for a malformed request body or invalid token
+// Returns a 422 status code for validation errors
 // Returns a 500 status code for internal server errors
 func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -228,7 +382,7 @@ func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Reques
 	var req agent_service.ResetPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Failed to decode reset password request", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -244,16 +398,22 @@ func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		h.Logger.WarnContext(ctx, "Reset password failed", "error", err)
 
-		// Check for specific error messages
-		if err.Error() == "invalid or expired reset token" {
+		// Check for specific errors
+		if errors.Is(err, domain.ErrInvalidResetToken) {
 			h.API.BadRequest(ctx, w, "Invalid or expired reset token")
 			return
 		}
-		if err.Error() == "user account is not active" {
+		if errors.Is(err, domain.ErrUserInactive) {
 			h.API.BadRequest(ctx, w, "User account is not active")
 			return
 		}
 
+		// Request was cancelled or timed out before completing
+		if domain.IsContextError(err) {
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
+
 		// Generic error
 		h.API.InternalServerError(ctx, w, "Failed to reset password")
 		return
@@ -265,39 +425,47 @@ func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Reques
 
 // convertValidationErrors converts validator errors to API error details
 func (h *AuthHandler) convertValidationErrors(validationErrors map[string]string) []api.ErrorDetail {
-	details := make([]api.ErrorDetail, 0, len(validationErrors))
-	for field, message := range validationErrors {
-		details = append(details, api.ErrorDetail{
-			Field:   field,
-			Message: message,
-		})
-	}
-	return details
+	return api.ValidationDetails(validationErrors)
 }
 
 // getClientIP extracts the real client IP address from the request
-// It checks X-Forwarded-For, X-Real-IP headers, and falls back to RemoteAddr
+// X-Forwarded-For is only trusted when the request comes from a trusted proxy, since clients can set it freely
+// It is read right to left, skipping trusted proxies, so the first untrusted hop is the client
 func (h *AuthHandler) getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header (common with proxies/load balancers)
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if idx := strings.Index(xff, ","); idx > 0 {
-			return strings.TrimSpace(xff[:idx])
-		}
-		return strings.TrimSpace(xff)
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	if !h.isTrustedProxy(remoteIP) {
+		return remoteIP
 	}
 
-	// Check X-Real-IP header (used by some proxies)
-	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
-		return strings.TrimSpace(xri)
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !h.isTrustedProxy(hop) {
+			return hop
+		}
+		remoteIP = hop
 	}
+	// Every hop is a trusted proxy, so the leftmost one is the closest to the client
+	return remoteIP
+}
 
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+// isTrustedProxy reports whether ip belongs to one of the trusted proxy networks
+func (h *AuthHandler) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
 	if err != nil {
-		return r.RemoteAddr
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range h.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
 	}
-	return ip
+	return false
 }
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"monorepo/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name           string
+		trustedProxies []netip.Prefix
+		remoteAddr     string
+		forwardedFor   []string
+		expected       string
+	}{
+		{
+			name:         "ignores X-Forwarded-For without trusted proxies",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "203.0.113.7",
+		},
+		{
+			name:           "ignores X-Forwarded-For from an untrusted peer",
+			trustedProxies: trusted,
+			remoteAddr:     "203.0.113.7:51234",
+			forwardedFor:   []string{"198.51.100.1"},
+			expected:       "203.0.113.7",
+		},
+		{
+			name:           "uses the hop before the trusted proxy",
+			trustedProxies: trusted,
+			remoteAddr:     "10.0.0.2:51234",
+			forwardedFor:   []string{"198.51.100.1, 203.0.113.7"},
+			expected:       "203.0.113.7",
+		},
+		{
+			name:           "skips chained trusted proxies",
+			trustedProxies: trusted,
+			remoteAddr:     "10.0.0.2:51234",
+			forwardedFor:   []string{"203.0.113.7, 10.0.0.3", "10.0.0.4"},
+			expected:       "203.0.113.7",
+		},
+		{
+			name:           "uses the peer when a trusted proxy sends no header",
+			trustedProxies: trusted,
+			remoteAddr:     "10.0.0.2:51234",
+			expected:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuthHandler(nil, tt.trustedProxies, logger.NoOpLogger())
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			assert.Equal(t, tt.expected, handler.getClientIP(req), "Unexpected client IP")
+		})
+	}
+}
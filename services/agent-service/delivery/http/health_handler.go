@@ -2,33 +2,49 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"monorepo/pkg/api"
 	"monorepo/pkg/logger"
+	"monorepo/pkg/postgres"
+	"monorepo/pkg/redis"
 )
 
+// databasePingTimeout bounds the database ping performed by the health check
+const databasePingTimeout = 2 * time.Second
+
+// cachePingTimeout bounds the Redis ping performed by the health check
+const cachePingTimeout = 2 * time.Second
+
 // HealthHandler handles HTTP requests for health check operations
 type HealthHandler struct {
 	// Logger is used for logging operations within the handler
 	Logger logger.LoggerInterface
 	// API provides standardized API response patterns
 	API api.Api
+	// DB is the database checked by the health endpoint; nil skips the check
+	DB postgres.PostgresClient
+	// Cache is the Redis client checked by the health endpoint; nil skips the check
+	Cache redis.RedisClient
 }
 
 // NewHealthHandler creates a new instance of HealthHandler
-// It takes a logger instance
+// It takes a logger instance and the database and Redis clients to check
 // Returns a pointer to a HealthHandler
-func NewHealthHandler(appLogger logger.LoggerInterface) *HealthHandler {
+func NewHealthHandler(appLogger logger.LoggerInterface, db postgres.PostgresClient, cache redis.RedisClient) *HealthHandler {
 	return &HealthHandler{
 		Logger: appLogger,
 		API:    api.New(),
+		DB:     db,
+		Cache:  cache,
 	}
 }
 
 // HealthCheckHandler handles HTTP requests to check the health of the service
-// It returns a JSON response indicating the service status
-// Returns a 200 status code with health information
+// It pings the database and Redis and returns a JSON response indicating the service status
+// Returns a 200 status code with health information, or 503 if the database or Redis is unreachable
 func (h *HealthHandler) HealthCheckHandler(w http.ResponseWriter, req *http.Request) {
 	ctx := req.Context()
 	h.Logger.InfoContext(ctx, "Health check endpoint called")
@@ -38,5 +54,45 @@ func (h *HealthHandler) HealthCheckHandler(w http.ResponseWriter, req *http.Requ
 		"message": "Service is running",
 	}
 
+	if h.DB != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, databasePingTimeout)
+		defer cancel()
+
+		if err := h.DB.Ping(pingCtx); err != nil {
+			h.Logger.ErrorContext(ctx, "Database health check failed", "error", err)
+			h.API.Error(ctx, w, http.StatusServiceUnavailable, &api.Error{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: "Database is unreachable",
+			})
+			return
+		}
+
+		stats := h.DB.Stats()
+		healthData["database"] = map[string]interface{}{
+			"status":           "up",
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}
+	}
+
+	if h.Cache != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, cachePingTimeout)
+		defer cancel()
+
+		if err := h.Cache.Ping(pingCtx); err != nil {
+			h.Logger.ErrorContext(ctx, "Redis health check failed", "error", err)
+			h.API.Error(ctx, w, http.StatusServiceUnavailable, &api.Error{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: "Cache is unreachable",
+			})
+			return
+		}
+
+		healthData["cache"] = map[string]interface{}{
+			"status": "up",
+		}
+	}
+
 	h.API.Success(ctx, w, healthData)
 }
@@ -3,32 +3,33 @@ package http
 
 import (
 	"agent-service/domain/model"
-	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"monorepo/pkg/api"
+	"monorepo/pkg/auth"
+	"monorepo/pkg/contextkeys"
 	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
-
-	"github.com/go-chi/chi/v5/middleware"
 )
 
 // LoggingMiddleware adds detailed request logging
 // It takes a logger instance and returns a middleware function
-// The middleware logs information about each HTTP request including method, path, status, duration, and client information
+// The middleware logs information about each HTTP request including method, path, status, bytes written, duration, and client information
 func LoggingMiddleware(logger logger.LoggerInterface) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			rec := api.NewResponseRecorder(w)
 
-			next.ServeHTTP(ww, r)
+			next.ServeHTTP(rec, r)
 
 			logger.InfoContext(r.Context(), "HTTP request completed",
 				"method", r.Method,
 				"path", r.URL.Path,
-				"status", ww.Status(),
+				"status", rec.Status(),
+				"bytes", rec.BytesWritten(),
 				"duration", time.Since(start).String(),
 				"remote_addr", r.RemoteAddr,
 				"user_agent", r.UserAgent(),
@@ -46,35 +47,33 @@ func JWTMiddleware(jwtClient jwt.JWTClient, logger logger.LoggerInterface, apiCl
 			ctx := r.Context()
 
 			// Extract token from Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				logger.WarnContext(ctx, "Missing Authorization header")
-				apiClient.Unauthorized(ctx, w, "Missing Authorization header")
+			tokenString, ok := auth.BearerToken(w, r, logger, apiClient)
+			if !ok {
 				return
 			}
 
-			// Check for Bearer token format
-			const bearerPrefix = "Bearer "
-			if len(authHeader) <= len(bearerPrefix) || authHeader[:len(bearerPrefix)] != bearerPrefix {
-				logger.WarnContext(ctx, "Invalid Authorization header format")
-				apiClient.Unauthorized(ctx, w, "Invalid Authorization header format")
-				return
-			}
-
-			tokenString := authHeader[len(bearerPrefix):]
-
 			// Validate the access token
 			claims, err := jwtClient.ValidateAccessToken(tokenString)
 			if err != nil {
+				if errors.Is(err, jwt.ErrInvalidTokenTypeErr) {
+					logger.WarnContext(ctx, "Refresh token used as access token")
+					apiClient.Unauthorized(ctx, w, "Invalid token type")
+					return
+				}
+				if errors.Is(err, jwt.ErrMissingTokenTypeErr) || errors.Is(err, jwt.ErrUnknownTokenTypeErr) {
+					logger.WarnContext(ctx, "Access token with missing or unknown token type", "error", err)
+					apiClient.Unauthorized(ctx, w, "Invalid token type")
+					return
+				}
 				logger.WarnContext(ctx, "Invalid access token", "error", err)
 				apiClient.Unauthorized(ctx, w, "Invalid access token")
 				return
 			}
 
 			// Add claims to context for use in handlers
-			ctx = context.WithValue(ctx, "user_id", claims.UserID)
-			ctx = context.WithValue(ctx, "agent_id", claims.AgentID)
-			ctx = context.WithValue(ctx, "agent_type", claims.AgentType)
+			ctx = contextkeys.WithUserID(ctx, claims.UserID)
+			ctx = contextkeys.WithAgentID(ctx, claims.AgentID)
+			ctx = contextkeys.WithAgentType(ctx, claims.AgentType)
 
 			// Update request with new context
 			r = r.WithContext(ctx)
@@ -93,7 +92,7 @@ func AgentTypeMiddleware(requiredAgentType string, logger logger.LoggerInterface
 			ctx := r.Context()
 
 			// Get agent_type from context (set by JWTMiddleware)
-			agentType, ok := ctx.Value("agent_type").(string)
+			agentType, ok := contextkeys.AgentType(ctx)
 			if !ok || agentType != requiredAgentType {
 				logger.WarnContext(ctx, "Access denied: agent type does not match required type", "agent_type", agentType, "required_type", requiredAgentType)
 				apiClient.Forbidden(ctx, w, "Access denied: insufficient agent permissions")
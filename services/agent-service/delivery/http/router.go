@@ -1,6 +1,7 @@
 package http
 
 import (
+	"monorepo/pkg/api"
 	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
 	"net/http"
@@ -15,16 +16,18 @@ type Router struct {
 	HealthHandler *HealthHandler
 	AuthHandler   *AuthHandler
 	JWTClient     jwt.JWTClient
+	MaxBodySize   int64
 	AppLogger     logger.LoggerInterface
 }
 
-func NewRouter(userHandler *UserHandler, agentHandler *AgentHandler, healthHandler *HealthHandler, authHandler *AuthHandler, jwtClient jwt.JWTClient, appLogger logger.LoggerInterface) *Router {
+func NewRouter(userHandler *UserHandler, agentHandler *AgentHandler, healthHandler *HealthHandler, authHandler *AuthHandler, jwtClient jwt.JWTClient, maxBodySize int64, appLogger logger.LoggerInterface) *Router {
 	return &Router{
 		Handler:       userHandler,
 		AgentHandler:  agentHandler,
 		HealthHandler: healthHandler,
 		AuthHandler:   authHandler,
 		JWTClient:     jwtClient,
+		MaxBodySize:   maxBodySize,
 		AppLogger:     appLogger,
 	}
 }
@@ -35,7 +38,10 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Add middleware
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.RequestID)
+	router.Use(logger.CorrelationIDMiddleware)
 	router.Use(middleware.Heartbeat("/ping"))
+	router.Use(LoggingMiddleware(r.AppLogger))
+	router.Use(api.MaxBodySize(r.MaxBodySize))
 
 	// Health check endpoint
 	router.Get("/health", r.HealthHandler.HealthCheckHandler)
@@ -46,11 +52,14 @@ func (r *Router) SetupRoutes() http.Handler {
 		api.Route("/auth", func(auth chi.Router) {
 			auth.Post("/login", r.AuthHandler.LoginHandler)
 			auth.Post("/refresh", r.AuthHandler.RefreshHandler)
+			auth.Post("/logout", r.AuthHandler.LogoutHandler)
 			auth.Post("/forgot-password", r.AuthHandler.ForgotPasswordHandler)
 			auth.Post("/reset-password", r.AuthHandler.ResetPasswordHandler)
 			// Protected auth routes
 			auth.With(JWTMiddleware(r.JWTClient, r.AppLogger, r.AuthHandler.API)).
 				Get("/profile", r.AuthHandler.ProfileHandler)
+			auth.With(JWTMiddleware(r.JWTClient, r.AppLogger, r.AuthHandler.API)).
+				Get("/sessions", r.AuthHandler.SessionsHandler)
 		})
 
 		// Agent routes
@@ -62,6 +71,11 @@ func (r *Router) SetupRoutes() http.Handler {
 					subagents.Post("/", r.AgentHandler.CreateSubAgentHandler)
 					subagents.Get("/", r.AgentHandler.ListSubAgentsHandler)
 				})
+
+			// Agent hierarchy tree (protected by JWT and IATA agent type check)
+			agents.With(JWTMiddleware(r.JWTClient, r.AppLogger, r.AuthHandler.API)).
+				With(IATAAgentMiddleware(r.AppLogger, r.AuthHandler.API)).
+				Get("/{id}/tree", r.AgentHandler.GetTreeHandler)
 		})
 	})
 
@@ -73,15 +87,18 @@ func (r *Router) SetupRoutes() http.Handler {
 			agents.Get("/{id}", r.AgentHandler.GetByIDHandler)
 			agents.Put("/{id}", r.AgentHandler.UpdateHandler)
 			agents.Delete("/{id}", r.AgentHandler.DeleteHandler)
+			agents.Post("/{id}/restore", r.AgentHandler.RestoreHandler)
 		})
 
 		internal.Route("/users", func(users chi.Router) {
 			users.Post("/", r.Handler.CreateHandler)
+			users.Post("/bulk", r.Handler.BulkCreateHandler)
 			users.Get("/", r.Handler.ListHandler)
 			users.Get("/{id}", r.Handler.GetByIDHandler)
 			users.Put("/{id}", r.Handler.UpdateHandler)
 			users.Patch("/{id}/status", r.Handler.UpdateStatusHandler)
 			users.Delete("/{id}", r.Handler.DeleteHandler)
+			users.Post("/{id}/restore", r.Handler.RestoreHandler)
 			users.Get("/email/{email}", r.Handler.GetByEmailHandler)
 		})
 	})
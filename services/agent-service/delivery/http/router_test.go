@@ -0,0 +1,67 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"monorepo/pkg/api"
+	"monorepo/pkg/logger"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_MaxBodySize(t *testing.T) {
+	const maxBodySize = 256
+	appLogger := logger.NoOpLogger()
+	router := NewRouter(
+		NewUserHandler(&fakeUserUseCase{}, appLogger),
+		NewAgentHandler(&fakeAgentUseCase{}, appLogger),
+		NewHealthHandler(appLogger, nil, nil),
+		NewAuthHandler(nil, nil, appLogger),
+		nil,
+		maxBodySize,
+		appLogger,
+	).SetupRoutes()
+
+	userBody := func(name string) string {
+		return `{"name":"` + name + `","email":"jane@example.com","password":"Str0ng!Passw0rd","password_confirm":"Str0ng!Passw0rd"}`
+	}
+	oversized := strings.Repeat("a", maxBodySize)
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		unknownLength bool
+		wantStatus    int
+	}{
+		{name: "create within limit", method: http.MethodPost, target: "/internal/users", body: userBody("Jane"), wantStatus: http.StatusCreated},
+		{name: "create over limit", method: http.MethodPost, target: "/internal/users", body: userBody(oversized), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "streamed create over limit", method: http.MethodPost, target: "/internal/users", body: userBody(oversized), unknownLength: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "update over limit", method: http.MethodPut, target: "/internal/agents/" + ulid.Make().String(), body: `{"agent_name":"` + oversized + `"}`, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, "Unexpected status code")
+
+			if tt.wantStatus == http.StatusRequestEntityTooLarge {
+				var response api.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+				assert.Equal(t, "PAYLOAD_TOO_LARGE", response.Error.Code, "Unexpected error code")
+			}
+		})
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 
 	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/domain/repository"
 	"agent-service/usecase"
 	"monorepo/contracts/agent_service"
 	"monorepo/pkg/api"
@@ -42,8 +46,9 @@ func NewUserHandler(userUseCase usecase.UserUseCase, logger logger.LoggerInterfa
 // CreateHandler handles HTTP requests to create a new user
 // It expects a JSON payload with user data in the request body
 // Returns a 201 status code with the created user on success
-// Returns a 400 status code for invalid request data
+// Returns a 400 status code for a malformed request body
 // Returns a 422 status code for validation errors
+// Returns a 409 status code if the email is already in use
 // Returns a 500 status code for internal server errors
 func (h *UserHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -52,7 +57,7 @@ func (h *UserHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for user creation", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -67,10 +72,13 @@ func (h *UserHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	user := agent_service.CreateUserRequestToModel(&req)
 	if err := h.UserUseCase.CreateUser(ctx, user); err != nil {
 		switch {
-		case err.Error() == domain.ErrEmailRequired.Message:
-			h.API.BadRequest(ctx, w, err.Error())
-		case err.Error() == domain.ErrEmailAlreadyExists.Message:
+		case errors.Is(err, domain.ErrEmailRequired):
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "email", Message: domain.ErrEmailRequired.Message}})
+		case errors.Is(err, domain.ErrEmailAlreadyExists):
 			h.API.Conflict(ctx, w, domain.ErrEmailAlreadyExists.Message)
+		case domain.IsContextError(err):
+			h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 		default:
 			h.Logger.ErrorContext(ctx, "Unexpected error during user creation", "email", user.Email, "error", err)
 			h.API.InternalServerError(ctx, w, "Failed to create user")
@@ -82,6 +90,72 @@ func (h *UserHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	h.API.Created(ctx, w, agent_service.UserModelToResponse(user))
 }
 
+// BulkCreateHandler handles HTTP requests to import several users at once
+// It expects a JSON payload with a list of users in the request body
+// Each row is validated and created on its own, so a rejected row does not fail the batch
+// Returns a 200 status code with the created users and the rejected rows with their reasons
+// Returns a 400 status code for a malformed request body
+// Returns a 422 status code for an empty or oversized batch
+// Returns a 500 status code for internal server errors
+func (h *UserHandler) BulkCreateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Bulk create users handler called")
+
+	var req agent_service.BulkCreateUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.ErrorContext(ctx, "Invalid request body for bulk user creation", "error", err)
+		h.API.InvalidBody(ctx, w, err)
+		return
+	}
+
+	if validationErrors := validator.ValidateStruct(&req); validationErrors != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for bulk user creation", "errors", validationErrors)
+		h.API.ValidationError(ctx, w, h.convertValidationErrors(validationErrors))
+		return
+	}
+
+	// Validate rows one by one, keeping the batch position of the valid ones
+	var invalid []model.BulkFailure
+	users := make([]*model.User, 0, len(req.Users))
+	positions := make([]int, 0, len(req.Users))
+	for i := range req.Users {
+		if validationErrors := validator.ValidateStruct(&req.Users[i]); validationErrors != nil {
+			invalid = append(invalid, model.BulkFailure{Index: i, Email: req.Users[i].Email, Reason: joinValidationErrors(validationErrors)})
+			continue
+		}
+		users = append(users, agent_service.CreateUserRequestToModel(&req.Users[i]))
+		positions = append(positions, i)
+	}
+
+	result, err := h.UserUseCase.BulkCreateUsers(ctx, users)
+	if err != nil {
+		h.handleUserError(ctx, w, err)
+		return
+	}
+
+	// Report usecase failures at their position in the request and merge them with the invalid rows
+	for i := range result.Failed {
+		result.Failed[i].Index = positions[result.Failed[i].Index]
+	}
+	result.Failed = append(result.Failed, invalid...)
+	sort.Slice(result.Failed, func(i, j int) bool {
+		return result.Failed[i].Index < result.Failed[j].Index
+	})
+
+	h.Logger.InfoContext(ctx, "Bulk user creation completed in handler", "created", len(result.Created), "failed", len(result.Failed))
+	h.API.Success(ctx, w, agent_service.BulkResultToResponse(result))
+}
+
+// joinValidationErrors flattens validation errors into a single reason, ordered by field
+func joinValidationErrors(validationErrors map[string]string) string {
+	details := api.ValidationDetails(validationErrors)
+	messages := make([]string, len(details))
+	for i, detail := range details {
+		messages[i] = detail.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
 // handleUserError handles user-related errors consistently
 func (h *UserHandler) handleUserError(ctx context.Context, w http.ResponseWriter, err error) {
 	switch {
@@ -90,9 +164,12 @@ func (h *UserHandler) handleUserError(ctx context.Context, w http.ResponseWriter
 	case errors.Is(err, domain.ErrInvalidID):
 		h.API.BadRequest(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrEmailRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "email", Message: err.Error()}})
 	case errors.Is(err, domain.ErrEmailAlreadyExists):
-		h.API.BadRequest(ctx, w, domain.ErrEmailAlreadyExists.Message)
+		h.API.Conflict(ctx, w, domain.ErrEmailAlreadyExists.Message)
+	case domain.IsContextError(err):
+		h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+		h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 	default:
 		h.Logger.ErrorContext(ctx, "Unexpected error", "error", err)
 		h.API.InternalServerError(ctx, w, "An unexpected error occurred")
@@ -101,35 +178,47 @@ func (h *UserHandler) handleUserError(ctx context.Context, w http.ResponseWriter
 
 // GetByIDHandler handles HTTP requests to retrieve a user by their ID
 // It expects the user ID as a URL parameter
-// Returns a 200 status code with the user data on success
-// Returns a 400 status code for invalid ID format
+// The optional include_inactive and include_deleted query parameters also return deactivated and deleted users
+// Returns a 200 status code with the user data and an ETag on success
+// Returns a 304 status code if If-None-Match matches the current ETag
+// Returns a 422 status code for invalid ID format
 // Returns a 404 status code if the user is not found
 // Returns a 500 status code for internal server errors
 func (h *UserHandler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Get user by ID handler called")
 
-	req := agent_service.GetUserByIDRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for get user by ID", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for get user by ID", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
+		return
+	}
+
+	var opts repository.GetByIDOptions
+	var details []api.ErrorDetail
+	opts.IncludeInactive, details = parseBoolQuery(r, "include_inactive", details)
+	opts.IncludeDeleted, details = parseBoolQuery(r, "include_deleted", details)
+	if len(details) > 0 {
+		h.Logger.WarnContext(ctx, "Validation failed for get user by ID", "errors", details)
+		h.API.ValidationError(ctx, w, details)
 		return
 	}
 
-	user, err := h.UserUseCase.GetUserByID(ctx, req.ID)
+	user, err := h.UserUseCase.GetUserByIDWithOptions(ctx, id, opts)
 	if err != nil {
 		h.handleUserError(ctx, w, err)
 		return
 	}
 
 	h.Logger.InfoContext(ctx, "User retrieved by ID in handler", "id", user.ID, "email", user.Email)
-	h.API.Success(ctx, w, agent_service.UserModelToResponse(user))
+	h.API.SuccessWithETag(ctx, w, r, agent_service.UserModelToResponse(user))
 }
 
 // GetByEmailHandler handles HTTP requests to retrieve a user by their email
 // It expects the email as a URL parameter
 // Returns a 200 status code with the user data on success
-// Returns a 400 status code if the email parameter is missing
+// Returns a 422 status code if the email parameter is missing or invalid
 // Returns a 404 status code if the user is not found
 // Returns a 500 status code for internal server errors
 func (h *UserHandler) GetByEmailHandler(w http.ResponseWriter, r *http.Request) {
@@ -146,10 +235,13 @@ func (h *UserHandler) GetByEmailHandler(w http.ResponseWriter, r *http.Request)
 	user, err := h.UserUseCase.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		switch {
-		case err.Error() == domain.ErrUserNotFound.Message:
-			h.API.NotFound(ctx, w, err.Error())
-		case err.Error() == domain.ErrEmailRequired.Message:
-			h.API.BadRequest(ctx, w, err.Error())
+		case errors.Is(err, domain.ErrUserNotFound):
+			h.API.NotFound(ctx, w, domain.ErrUserNotFound.Message)
+		case errors.Is(err, domain.ErrEmailRequired):
+			h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "email", Message: domain.ErrEmailRequired.Message}})
+		case domain.IsContextError(err):
+			h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 		default:
 			h.Logger.ErrorContext(ctx, "Unexpected error getting user by email", "email", req.Email, "error", err)
 			h.API.InternalServerError(ctx, w, "Failed to retrieve user")
@@ -164,9 +256,10 @@ func (h *UserHandler) GetByEmailHandler(w http.ResponseWriter, r *http.Request)
 // UpdateHandler handles HTTP requests to update an existing user
 // It expects the user ID as a URL parameter and user data in the request body
 // Returns a 200 status code with the updated user on success
-// Returns a 400 status code for invalid ID format or request data
+// Returns a 400 status code for a malformed request body
 // Returns a 422 status code for validation errors
 // Returns a 404 status code if the user is not found
+// Returns a 409 status code if the email is already in use
 // Returns a 500 status code for internal server errors
 func (h *UserHandler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -175,7 +268,7 @@ func (h *UserHandler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	var req agent_service.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for user update", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -226,7 +319,7 @@ func (h *UserHandler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 // UpdateStatusHandler handles HTTP requests to update user active status
 // It expects the user ID as a URL parameter and status data in the request body
 // Returns a 200 status code with the updated user on success
-// Returns a 400 status code for invalid ID format or request data
+// Returns a 400 status code for a missing ID or malformed request body
 // Returns a 422 status code for validation errors
 // Returns a 404 status code if the user is not found
 // Returns a 500 status code for internal server errors
@@ -237,7 +330,7 @@ func (h *UserHandler) UpdateStatusHandler(w http.ResponseWriter, r *http.Request
 	var req agent_service.UpdateUserStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for user status update", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -249,10 +342,9 @@ func (h *UserHandler) UpdateStatusHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Validate the user ID
-	idReq := agent_service.GetUserByIDRequest{ID: userID}
-	if err := validator.ValidateStruct(&idReq); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for user ID", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	if err := validator.ValidateVar(userID, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for user ID", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
@@ -282,29 +374,55 @@ func (h *UserHandler) UpdateStatusHandler(w http.ResponseWriter, r *http.Request
 // DeleteHandler handles HTTP requests to delete a user
 // It expects the user ID as a URL parameter
 // Returns a 200 status code with a success message on success
-// Returns a 400 status code for invalid ID format
+// Returns a 422 status code for invalid ID format
 // Returns a 404 status code if the user is not found
 // Returns a 500 status code for internal server errors
 func (h *UserHandler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Delete user handler called")
 
-	req := agent_service.DeleteUserRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for delete user", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for delete user", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
-	if err := h.UserUseCase.DeleteUser(ctx, req.ID); err != nil {
+	if err := h.UserUseCase.DeleteUser(ctx, id); err != nil {
 		h.handleUserError(ctx, w, err)
 		return
 	}
 
-	h.Logger.InfoContext(ctx, "User deleted successfully in handler", "id", req.ID)
+	h.Logger.InfoContext(ctx, "User deleted successfully in handler", "id", id)
 	h.API.Success(ctx, w, map[string]string{"message": "User deleted successfully"})
 }
 
+// RestoreHandler handles HTTP requests to restore a deleted user
+// It expects the user ID as a URL parameter
+// Returns a 200 status code with a success message on success
+// Returns a 422 status code for invalid ID format
+// Returns a 404 status code if no deleted user has that ID
+// Returns a 500 status code for internal server errors
+func (h *UserHandler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Restore user handler called")
+
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for restore user", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
+		return
+	}
+
+	if err := h.UserUseCase.RestoreUser(ctx, id); err != nil {
+		h.handleUserError(ctx, w, err)
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "User restored successfully in handler", "id", id)
+	h.API.Success(ctx, w, map[string]string{"message": "User restored successfully"})
+}
+
 // ListHandler handles HTTP requests to list users with pagination
 // It expects optional 'offset' and 'limit' query parameters
 // Returns a 200 status code with a list of users on success
@@ -331,6 +449,11 @@ func (h *UserHandler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	// Get users and real total from usecase
 	users, total, err := h.UserUseCase.ListUsers(ctx, offset, limit)
 	if err != nil {
+		if domain.IsContextError(err) {
+			h.Logger.WarnContext(ctx, "Error listing users: request cancelled", "offset", offset, "limit", limit, "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
 		h.Logger.ErrorContext(ctx, "Error listing users", "offset", offset, "limit", limit, "error", err)
 		h.API.InternalServerError(ctx, w, "Failed to list users")
 		return
@@ -383,14 +506,21 @@ func (h *UserHandler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	h.API.SuccessWithMeta(ctx, w, agent_service.UserModelsToResponses(users), meta)
 }
 
+// parseBoolQuery returns the boolean value of an optional query parameter, false when it is absent
+// An invalid value is appended to details as a validation error
+func parseBoolQuery(r *http.Request, name string, details []api.ErrorDetail) (bool, []api.ErrorDetail) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, details
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, append(details, api.ErrorDetail{Field: name, Message: name + " must be true or false"})
+	}
+	return value, details
+}
+
 // convertValidationErrors converts validator errors to API error details
 func (h *UserHandler) convertValidationErrors(validationErrors map[string]string) []api.ErrorDetail {
-	details := make([]api.ErrorDetail, 0, len(validationErrors))
-	for field, message := range validationErrors {
-		details = append(details, api.ErrorDetail{
-			Field:   field,
-			Message: message,
-		})
-	}
-	return details
+	return api.ValidationDetails(validationErrors)
 }
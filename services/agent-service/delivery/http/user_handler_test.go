@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/usecase"
+	"monorepo/pkg/api"
+	"monorepo/pkg/logger"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUserUseCase returns err from every write and an existing user from GetUserByID
+// Methods not overridden panic through the nil embedded interface
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+	err error
+}
+
+func (uc *fakeUserUseCase) CreateUser(_ context.Context, _ *model.User) error {
+	return uc.err
+}
+
+func (uc *fakeUserUseCase) GetUserByID(_ context.Context, id string) (*model.User, error) {
+	return &model.User{ID: id, Name: "Existing", Email: "existing@example.com"}, nil
+}
+
+func (uc *fakeUserUseCase) UpdateUser(_ context.Context, _ *model.User) error {
+	return uc.err
+}
+
+// serveUser routes req through a user handler backed by a fake usecase returning err
+func serveUser(err error, req *http.Request) *httptest.ResponseRecorder {
+	handler := NewUserHandler(&fakeUserUseCase{err: err}, logger.NoOpLogger())
+	router := chi.NewRouter()
+	router.Post("/users", handler.CreateHandler)
+	router.Put("/users/{id}", handler.UpdateHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUserHandler_DuplicateEmailIsConflict(t *testing.T) {
+	// Repositories wrap the domain error, so the handler has to unwrap it
+	duplicate := fmt.Errorf("failed to create user: %w", domain.ErrEmailAlreadyExists)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{
+			name: "create",
+			req: httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(
+				`{"name":"Jane","email":"jane@example.com","password":"Str0ng!Passw0rd","password_confirm":"Str0ng!Passw0rd"}`)),
+		},
+		{
+			name: "update",
+			req: httptest.NewRequest(http.MethodPut, "/users/"+ulid.Make().String(), strings.NewReader(
+				`{"email":"jane@example.com"}`)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveUser(duplicate, tt.req)
+			require.Equal(t, http.StatusConflict, w.Code, "Duplicate email should be a conflict")
+
+			var response api.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+			assert.Equal(t, domain.ErrEmailAlreadyExists.Message, response.Error.Message, "Unexpected error message")
+		})
+	}
+}
+
+func TestUserHandler_BadRequestVsValidationError(t *testing.T) {
+	userPath := "/users/" + ulid.Make().String()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name:   "create with malformed body",
+			method: http.MethodPost, target: "/users", body: `{"email":`,
+			wantStatus: http.StatusBadRequest, wantCode: "BAD_REQUEST",
+		},
+		{
+			name:   "create with invalid fields",
+			method: http.MethodPost, target: "/users", body: `{"email":"not-an-email"}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: api.CodeValidationFailed,
+			wantFields: []string{"email", "name", "password", "password_confirm"},
+		},
+		{
+			name:   "create rejected by the usecase",
+			method: http.MethodPost, target: "/users",
+			body: `{"name":"Jane","email":"jane@example.com","password":"Str0ng!Passw0rd","password_confirm":"Str0ng!Passw0rd"}`,
+			err:  domain.ErrEmailRequired, wantStatus: http.StatusUnprocessableEntity, wantCode: api.CodeValidationFailed,
+			wantFields: []string{"email"},
+		},
+		{
+			name:   "update with malformed body",
+			method: http.MethodPut, target: userPath, body: `not json`,
+			wantStatus: http.StatusBadRequest, wantCode: "BAD_REQUEST",
+		},
+		{
+			name:   "update with invalid fields",
+			method: http.MethodPut, target: userPath, body: `{"email":"not-an-email"}`,
+			wantStatus: http.StatusUnprocessableEntity, wantCode: api.CodeValidationFailed,
+			wantFields: []string{"email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveUser(tt.err, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			require.Equal(t, tt.wantStatus, w.Code, "Unexpected status code")
+
+			var response api.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+			require.NotNil(t, response.Error, "Response should carry an error")
+			assert.Equal(t, tt.wantCode, response.Error.Code, "Unexpected error code")
+
+			fields := make([]string, 0, len(response.Error.Details))
+			for _, detail := range response.Error.Details {
+				fields = append(fields, detail.Field)
+			}
+			assert.ElementsMatch(t, tt.wantFields, fields, "Details should name the invalid JSON fields")
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 // Error types with HTTP status codes
 type AppError struct {
@@ -20,6 +24,22 @@ func (e *AppError) Is(target error) bool {
 	return false
 }
 
+// RetryAfterError wraps an error with how long the client should wait before retrying
+// Handlers send RetryAfter in the Retry-After header of 429 responses
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error, so errors.Is matches it
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
 // Custom error types
 var (
 	ErrEmailAlreadyExists = &AppError{
@@ -66,6 +86,10 @@ var (
 		Message: "circular reference detected in agent hierarchy",
 		Code:    400, // StatusBadRequest
 	}
+	ErrAgentHierarchyTooDeep = &AppError{
+		Message: "agent hierarchy is too deep",
+		Code:    400, // StatusBadRequest
+	}
 	ErrAgentHasChildren = &AppError{
 		Message: "cannot delete agent with children",
 		Code:    400, // StatusBadRequest
@@ -74,9 +98,47 @@ var (
 		Message: "invalid email or password",
 		Code:    401, // StatusUnauthorized
 	}
+	ErrInvalidRefreshToken = &AppError{
+		Message: "invalid refresh token",
+		Code:    401, // StatusUnauthorized
+	}
+	ErrUnauthorized = &AppError{
+		Message: "unauthorized: user ID not found",
+		Code:    401, // StatusUnauthorized
+	}
+	ErrSessionNotFound = &AppError{
+		Message: "session not found",
+		Code:    404, // StatusNotFound
+	}
+	ErrSessionsNotSupported = &AppError{
+		Message: "session management is not enabled",
+		Code:    400, // StatusBadRequest
+	}
+	ErrUserInactive = &AppError{
+		Message: "user account is not active",
+		Code:    400, // StatusBadRequest
+	}
+	ErrInvalidResetToken = &AppError{
+		Message: "invalid or expired reset token",
+		Code:    400, // StatusBadRequest
+	}
+	ErrAccountLocked = &AppError{
+		Message: "account is temporarily locked after too many failed login attempts",
+		Code:    429, // StatusTooManyRequests
+	}
+	ErrTooManyLoginAttempts = &AppError{
+		Message: "too many login attempts, please try again later",
+		Code:    429, // StatusTooManyRequests
+	}
 )
 
 // Standard error types for repositories
 var (
 	ErrNotFound = errors.New("not found")
 )
+
+// IsContextError reports whether err was caused by the request context being
+// cancelled or timing out rather than by a genuine failure
+func IsContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
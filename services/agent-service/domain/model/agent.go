@@ -27,6 +27,12 @@ type Agent struct {
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
 }
 
+// AgentNode is an agent with its descendants, as returned by an agent tree lookup
+type AgentNode struct {
+	Agent    *Agent
+	Children []*AgentNode
+}
+
 func (a *Agent) BeforeCreate(tx *gorm.DB) error {
 	a.ID = ulid.Make().String()
 	return nil
@@ -20,7 +20,7 @@ type User struct {
 	// Name is the user's full name
 	Name string `gorm:"not null"`
 	// Email is the user's email address which must be unique
-	Email string `gorm:"uniqueIndex;not null"`
+	Email string `gorm:"uniqueIndex:idx_users_email;not null"`
 	// Password is the hashed password for the user
 	Password string `gorm:"not null"`
 	// IsActive indicates whether the user is active
@@ -37,3 +37,21 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	u.ID = ulid.Make().String()
 	return nil
 }
+
+// BulkFailure describes a row of a bulk import that was not created
+type BulkFailure struct {
+	// Index is the position of the row in the imported batch
+	Index int
+	// Email is the email of the row, to help identify it
+	Email string
+	// Reason explains why the row was rejected
+	Reason string
+}
+
+// BulkResult reports the outcome of a bulk user import
+type BulkResult struct {
+	// Created holds the users that were created, in batch order
+	Created []*User
+	// Failed holds the rows that were rejected, in batch order
+	Failed []BulkFailure
+}
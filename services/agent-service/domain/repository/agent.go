@@ -14,6 +14,7 @@ type Agent interface {
 	GetByParentID(ctx context.Context, parentID string) ([]*model.Agent, error)
 	Update(ctx context.Context, agent *model.Agent) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	List(ctx context.Context, offset, limit int) ([]*model.Agent, int, error)
 }
 
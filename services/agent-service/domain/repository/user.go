@@ -6,16 +6,28 @@ import (
 	"context"
 )
 
+// GetByIDOptions widens the users a lookup by ID can return
+// The zero value only returns active users that are not deleted, like GetByID
+type GetByIDOptions struct {
+	// IncludeInactive also returns deactivated users
+	IncludeInactive bool
+	// IncludeDeleted also returns soft-deleted users
+	IncludeDeleted bool
+}
+
 // User defines the contract for user-related database operations
 type User interface {
 	Create(ctx context.Context, user *model.User) error
 	GetByID(ctx context.Context, id string) (*model.User, error)
+	GetByIDWithOptions(ctx context.Context, id string, opts GetByIDOptions) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByAgentID(ctx context.Context, agentID string) ([]*model.User, error)
 	GetActiveUsers(ctx context.Context) ([]*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	UpdatePassword(ctx context.Context, id string, hashedPassword string) error
+	UpdateStatus(ctx context.Context, id string, isActive bool) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 	List(ctx context.Context, offset, limit int) ([]*model.User, int, error)
 }
 
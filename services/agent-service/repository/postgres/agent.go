@@ -9,6 +9,7 @@ import (
 	"agent-service/domain/model"
 	"agent-service/domain/repository"
 	"monorepo/pkg/logger"
+	"monorepo/pkg/postgres"
 
 	"gorm.io/gorm"
 )
@@ -35,13 +36,14 @@ func NewAgentRepository(db *gorm.DB, logger logger.LoggerInterface) repository.T
 func (r *agentRepository) Create(ctx context.Context, agent *model.Agent) error {
 	r.logger.InfoContext(ctx, "Creating agent", "email", agent.Email)
 
-	// Check if there's a transaction in the context
-	db := r.db
-	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
-		db = tx
-	}
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
 
 	if err := db.WithContext(ctx).Create(agent).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to create agent: request cancelled", "email", agent.Email, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to create agent", "email", agent.Email, "error", err)
 		return fmt.Errorf("failed to create agent: %w", err)
 	}
@@ -55,11 +57,17 @@ func (r *agentRepository) Create(ctx context.Context, agent *model.Agent) error
 func (r *agentRepository) GetByID(ctx context.Context, id string) (*model.Agent, error) {
 	r.logger.InfoContext(ctx, "Getting agent by ID", "id", id)
 	var agent model.Agent
-	if err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").Where("id = ? AND deleted_at IS NULL", id).First(&agent).Error; err != nil {
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+	if err := db.WithContext(ctx).Preload("Parent").Preload("Children").Where("id = ? AND deleted_at IS NULL", id).First(&agent).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "Agent not found by ID", "id", id)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get agent by ID: request cancelled", "id", id, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get agent by ID", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
@@ -71,11 +79,17 @@ func (r *agentRepository) GetByID(ctx context.Context, id string) (*model.Agent,
 func (r *agentRepository) GetByEmail(ctx context.Context, email string) (*model.Agent, error) {
 	r.logger.InfoContext(ctx, "Getting agent by email", "email", email)
 	var agent model.Agent
-	if err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").Where("email = ? AND deleted_at IS NULL", email).First(&agent).Error; err != nil {
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+	if err := db.WithContext(ctx).Preload("Parent").Preload("Children").Where("email = ? AND deleted_at IS NULL", email).First(&agent).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "Agent not found by email", "email", email)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get agent by email: request cancelled", "email", email, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get agent by email", "email", email, "error", err)
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
@@ -86,7 +100,15 @@ func (r *agentRepository) GetByEmail(ctx context.Context, email string) (*model.
 // Update modifies an existing agent in the database
 func (r *agentRepository) Update(ctx context.Context, agent *model.Agent) error {
 	r.logger.InfoContext(ctx, "Updating agent", "id", agent.ID, "email", agent.Email)
-	if err := r.db.WithContext(ctx).Model(&model.Agent{}).Where("id = ?", agent.ID).Updates(agent).Error; err != nil {
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	if err := db.WithContext(ctx).Model(&model.Agent{}).Where("id = ?", agent.ID).Updates(agent).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update agent: request cancelled", "id", agent.ID, "email", agent.Email, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to update agent", "id", agent.ID, "email", agent.Email, "error", err)
 		return fmt.Errorf("failed to update agent: %w", err)
 	}
@@ -99,17 +121,25 @@ func (r *agentRepository) Update(ctx context.Context, agent *model.Agent) error
 // Returns an error if the operation fails
 func (r *agentRepository) Delete(ctx context.Context, id string) error {
 	r.logger.InfoContext(ctx, "Deleting agent", "id", id)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	agent := &model.Agent{ID: id}
 
 	// Use soft delete
-	if err := r.db.WithContext(ctx).Delete(agent).Error; err != nil {
+	if err := db.WithContext(ctx).Delete(agent).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete agent: request cancelled", "id", id, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to delete agent", "id", id, "error", err)
 		return fmt.Errorf("failed to delete agent: %w", err)
 	}
 
 	// Check if record was actually deleted
 	var count int64
-	r.db.WithContext(ctx).Model(&model.Agent{}).Where("id = ? AND deleted_at IS NULL", id).Count(&count)
+	db.WithContext(ctx).Model(&model.Agent{}).Where("id = ? AND deleted_at IS NULL", id).Count(&count)
 	if count > 0 {
 		r.logger.WarnContext(ctx, "Agent not found for deletion", "id", id)
 		return domain.ErrNotFound
@@ -119,22 +149,61 @@ func (r *agentRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Restore recovers a soft-deleted agent by clearing its deleted_at timestamp
+// It takes a context for request-scoped values and the agent ID
+// Returns domain.ErrNotFound if no deleted agent has that ID
+func (r *agentRepository) Restore(ctx context.Context, id string) error {
+	r.logger.InfoContext(ctx, "Restoring agent", "id", id)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	result := db.WithContext(ctx).Unscoped().Model(&model.Agent{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if err := result.Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to restore agent: request cancelled", "id", id, "error", err)
+			return err
+		}
+		r.logger.ErrorContext(ctx, "Failed to restore agent", "id", id, "error", err)
+		return fmt.Errorf("failed to restore agent: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		r.logger.WarnContext(ctx, "Deleted agent not found for restore", "id", id)
+		return domain.ErrNotFound
+	}
+
+	r.logger.InfoContext(ctx, "Agent restored successfully", "id", id)
+	return nil
+}
+
 // List retrieves a paginated list of agents from the database
 // It takes a context for request-scoped values, offset for pagination, and limit for page size
 // Returns a slice of agent pointers, the real total count, and an error if the operation fails
 func (r *agentRepository) List(ctx context.Context, offset, limit int) ([]*model.Agent, int, error) {
 	r.logger.InfoContext(ctx, "Listing agents", "offset", offset, "limit", limit)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var agents []*model.Agent
 	var total int64
 
 	// Get total count
-	if err := r.db.WithContext(ctx).Model(&model.Agent{}).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&model.Agent{}).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to count agents: request cancelled", "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to count agents", "error", err)
 		return nil, 0, fmt.Errorf("failed to count agents: %w", err)
 	}
 
 	// Get paginated agents
-	if err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").Where("deleted_at IS NULL").Offset(offset).Limit(limit).Order("id ASC").Find(&agents).Error; err != nil {
+	if err := db.WithContext(ctx).Preload("Parent").Preload("Children").Where("deleted_at IS NULL").Offset(offset).Limit(limit).Order("id ASC").Find(&agents).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to list agents: request cancelled", "offset", offset, "limit", limit, "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to list agents", "offset", offset, "limit", limit, "error", err)
 		return nil, 0, fmt.Errorf("failed to list agents: %w", err)
 	}
@@ -148,8 +217,16 @@ func (r *agentRepository) List(ctx context.Context, offset, limit int) ([]*model
 // Returns a slice of agent pointers and an error if the operation fails
 func (r *agentRepository) GetByParentID(ctx context.Context, parentID string) ([]*model.Agent, error) {
 	r.logger.InfoContext(ctx, "Getting agents by parent ID", "parentID", parentID)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var agents []*model.Agent
-	if err := r.db.WithContext(ctx).Preload("Parent").Preload("Children").Where("parent_agent_id = ? AND deleted_at IS NULL", parentID).Find(&agents).Error; err != nil {
+	if err := db.WithContext(ctx).Preload("Parent").Preload("Children").Where("parent_agent_id = ? AND deleted_at IS NULL", parentID).Find(&agents).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get agents by parent ID: request cancelled", "parentID", parentID, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get agents by parent ID", "parentID", parentID, "error", err)
 		return nil, fmt.Errorf("failed to get agents by parent ID: %w", err)
 	}
@@ -162,9 +239,5 @@ func (r *agentRepository) GetByParentID(ctx context.Context, parentID string) ([
 // Returns an error if the transaction fails or if the function returns an error
 func (r *agentRepository) ExecuteInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
 	r.logger.InfoContext(ctx, "Executing operation in transaction")
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Create a context that carries the transaction
-		txCtx := context.WithValue(ctx, "tx", tx)
-		return fn(txCtx)
-	})
+	return postgres.RunInTransaction(ctx, r.db, fn)
 }
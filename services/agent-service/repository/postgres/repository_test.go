@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/domain/repository"
+	"monorepo/pkg/logger"
+	pgclient "monorepo/pkg/postgres"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newMockDB returns a GORM database backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err, "Failed to create sqlmock")
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err, "Failed to open GORM database")
+	return db, mock
+}
+
+// contextErrorCases returns a cancelled and an expired context with the error each should yield
+func contextErrorCases(t *testing.T) map[string]struct {
+	ctx  context.Context
+	want error
+} {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	t.Cleanup(cancelExpired)
+
+	return map[string]struct {
+		ctx  context.Context
+		want error
+	}{
+		"cancelled": {ctx: cancelled, want: context.Canceled},
+		"expired":   {ctx: expired, want: context.DeadlineExceeded},
+	}
+}
+
+func TestUserRepository_ContextErrors(t *testing.T) {
+	calls := map[string]func(ctx context.Context, repo repository.TransactionalUser) error{
+		"Create": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.Create(ctx, &model.User{Email: "user@example.com"})
+		},
+		"GetByID": func(ctx context.Context, repo repository.TransactionalUser) error {
+			_, err := repo.GetByID(ctx, "user-id")
+			return err
+		},
+		"GetByEmail": func(ctx context.Context, repo repository.TransactionalUser) error {
+			_, err := repo.GetByEmail(ctx, "user@example.com")
+			return err
+		},
+		"Update": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.Update(ctx, &model.User{ID: "user-id", Name: "User"})
+		},
+		"UpdatePassword": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.UpdatePassword(ctx, "user-id", "hashed")
+		},
+		"UpdateStatus": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.UpdateStatus(ctx, "user-id", false)
+		},
+		"Delete": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.Delete(ctx, "user-id")
+		},
+		"Restore": func(ctx context.Context, repo repository.TransactionalUser) error {
+			return repo.Restore(ctx, "user-id")
+		},
+		"List": func(ctx context.Context, repo repository.TransactionalUser) error {
+			_, _, err := repo.List(ctx, 0, 10)
+			return err
+		},
+		"GetByAgentID": func(ctx context.Context, repo repository.TransactionalUser) error {
+			_, err := repo.GetByAgentID(ctx, "agent-id")
+			return err
+		},
+		"GetActiveUsers": func(ctx context.Context, repo repository.TransactionalUser) error {
+			_, err := repo.GetActiveUsers(ctx)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		for ctxName, tc := range contextErrorCases(t) {
+			t.Run(name+"/"+ctxName, func(t *testing.T) {
+				db, mock := newMockDB(t)
+				repo := NewUserRepository(db, logger.NoOpLogger())
+
+				err := call(tc.ctx, repo)
+				assert.Equal(t, tc.want, err, "The context error should be returned unwrapped")
+				assert.NoError(t, mock.ExpectationsWereMet(), "No query should reach the database")
+			})
+		}
+	}
+}
+
+func TestAgentRepository_ContextErrors(t *testing.T) {
+	calls := map[string]func(ctx context.Context, repo repository.TransactionalAgent) error{
+		"Create": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			return repo.Create(ctx, &model.Agent{Email: "agent@example.com"})
+		},
+		"GetByID": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			_, err := repo.GetByID(ctx, "agent-id")
+			return err
+		},
+		"GetByEmail": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			_, err := repo.GetByEmail(ctx, "agent@example.com")
+			return err
+		},
+		"GetByParentID": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			_, err := repo.GetByParentID(ctx, "parent-id")
+			return err
+		},
+		"Update": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			return repo.Update(ctx, &model.Agent{ID: "agent-id", AgentName: "Agent"})
+		},
+		"Delete": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			return repo.Delete(ctx, "agent-id")
+		},
+		"Restore": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			return repo.Restore(ctx, "agent-id")
+		},
+		"List": func(ctx context.Context, repo repository.TransactionalAgent) error {
+			_, _, err := repo.List(ctx, 0, 10)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		for ctxName, tc := range contextErrorCases(t) {
+			t.Run(name+"/"+ctxName, func(t *testing.T) {
+				db, mock := newMockDB(t)
+				repo := NewAgentRepository(db, logger.NoOpLogger())
+
+				err := call(tc.ctx, repo)
+				assert.Equal(t, tc.want, err, "The context error should be returned unwrapped")
+				assert.NoError(t, mock.ExpectationsWereMet(), "No query should reach the database")
+			})
+		}
+	}
+}
+
+func TestRepositories_UseTransactionFromContext(t *testing.T) {
+	calls := map[string]func(ctx context.Context, db *gorm.DB) error{
+		"user Update": func(ctx context.Context, db *gorm.DB) error {
+			return NewUserRepository(db, logger.NoOpLogger()).Update(ctx, &model.User{ID: "user-id", Name: "User"})
+		},
+		"user UpdatePassword": func(ctx context.Context, db *gorm.DB) error {
+			return NewUserRepository(db, logger.NoOpLogger()).UpdatePassword(ctx, "user-id", "hashed")
+		},
+		"agent Update": func(ctx context.Context, db *gorm.DB) error {
+			return NewAgentRepository(db, logger.NoOpLogger()).Update(ctx, &model.Agent{ID: "agent-id", AgentName: "Agent"})
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			// The repository database expects no queries, so only the transaction can serve them
+			repoDB, repoMock := newMockDB(t)
+			txDB, txMock := newMockDB(t)
+			txMock.ExpectBegin()
+			txMock.ExpectExec(`UPDATE`).WillReturnResult(sqlmock.NewResult(0, 1))
+			txMock.ExpectCommit()
+
+			err := pgclient.RunInTransaction(context.Background(), txDB, func(txCtx context.Context) error {
+				return call(txCtx, repoDB)
+			})
+			require.NoError(t, err, "The call should run in the transaction")
+			assert.NoError(t, txMock.ExpectationsWereMet(), "The query should run in the transaction")
+			assert.NoError(t, repoMock.ExpectationsWereMet(), "No query should bypass the transaction")
+		})
+	}
+}
+
+func TestUserRepository_WritesInactiveStatus(t *testing.T) {
+	// GORM skips zero values when updating from a struct, so is_active=false must be written explicitly
+	t.Run("UpdateStatus", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET "is_active"=\$1,"updated_at"=\$2 WHERE`).
+			WithArgs(false, sqlmock.AnyArg(), "user-id").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := NewUserRepository(db, logger.NoOpLogger()).UpdateStatus(context.Background(), "user-id", false)
+		require.NoError(t, err, "UpdateStatus should not return error")
+		assert.NoError(t, mock.ExpectationsWereMet(), "is_active should be written")
+	})
+
+	t.Run("UpdateStatus of a missing user", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET "is_active"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := NewUserRepository(db, logger.NoOpLogger()).UpdateStatus(context.Background(), "user-id", false)
+		assert.ErrorIs(t, err, domain.ErrNotFound, "A missing user should not be found")
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET .*"is_active"=\$\d.* WHERE`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		user := &model.User{ID: "user-id", Name: "User", Email: "user@example.com", Password: "hashed", IsActive: false}
+		require.NoError(t, NewUserRepository(db, logger.NoOpLogger()).Update(context.Background(), user), "Update should not return error")
+		assert.NoError(t, mock.ExpectationsWereMet(), "is_active should be written")
+	})
+}
@@ -9,10 +9,14 @@ import (
 	"agent-service/domain/model"
 	"agent-service/domain/repository"
 	"monorepo/pkg/logger"
+	"monorepo/pkg/postgres"
 
 	"gorm.io/gorm"
 )
 
+// userEmailIndex is the unique index on users.email, declared on the User model
+const userEmailIndex = "idx_users_email"
+
 // userRepository implements the User repository interface using PostgreSQL
 type userRepository struct {
 	// db is the GORM database instance for database operations
@@ -37,13 +41,18 @@ func NewUserRepository(db *gorm.DB, logger logger.LoggerInterface) repository.Tr
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 	r.logger.InfoContext(ctx, "Creating user", "email", user.Email)
 
-	// Check if there's a transaction in the context
-	db := r.db
-	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
-		db = tx
-	}
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
 
 	if err := db.WithContext(ctx).Create(user).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to create user: request cancelled", "email", user.Email, "error", err)
+			return err
+		}
+		if postgres.IsUniqueViolation(err, userEmailIndex) {
+			r.logger.WarnContext(ctx, "Failed to create user: email already exists", "email", user.Email)
+			return domain.ErrEmailAlreadyExists
+		}
 		r.logger.ErrorContext(ctx, "Failed to create user", "email", user.Email, "error", err)
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -55,13 +64,38 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 // It takes a context for request-scoped values and the user ID
 // Returns the user model and an error if the operation fails
 func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, error) {
-	r.logger.InfoContext(ctx, "Getting user by ID", "id", id)
+	return r.GetByIDWithOptions(ctx, id, repository.GetByIDOptions{})
+}
+
+// GetByIDWithOptions retrieves a user by their unique identifier, optionally including inactive and deleted users
+// It takes a context for request-scoped values, the user ID, and the lookup options
+// Returns the user model and an error if the operation fails
+func (r *userRepository) GetByIDWithOptions(ctx context.Context, id string, opts repository.GetByIDOptions) (*model.User, error) {
+	r.logger.InfoContext(ctx, "Getting user by ID", "id", id, "includeInactive", opts.IncludeInactive, "includeDeleted", opts.IncludeDeleted)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var user model.User
-	if err := r.db.WithContext(ctx).Preload("Agent").Where("id = ? AND is_active = ? AND deleted_at IS NULL", id, true).First(&user).Error; err != nil {
+	query := db.WithContext(ctx).Preload("Agent").Where("id = ?", id)
+	if !opts.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if opts.IncludeDeleted {
+		// Unscoped lifts GORM's soft delete filter
+		query = query.Unscoped()
+	} else {
+		query = query.Where("deleted_at IS NULL")
+	}
+	if err := query.First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "User not found by ID", "id", id)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get user by ID: request cancelled", "id", id, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get user by ID", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -74,12 +108,20 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*model.User, e
 // Returns the user model and an error if the operation fails
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	r.logger.InfoContext(ctx, "Getting user by email", "email", email)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var user model.User
-	if err := r.db.WithContext(ctx).Preload("Agent").Where("email = ? AND is_active = ? AND deleted_at IS NULL", email, true).First(&user).Error; err != nil {
+	if err := db.WithContext(ctx).Preload("Agent").Where("email = ? AND is_active = ? AND deleted_at IS NULL", email, true).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			r.logger.WarnContext(ctx, "User not found by email", "email", email)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get user by email: request cancelled", "email", email, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get user by email", "email", email, "error", err)
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -87,12 +129,29 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 	return &user, nil
 }
 
+// userUpdateColumns are the columns written by Update
+// They are selected explicitly because GORM skips zero values, like is_active=false, when updating from a struct
+var userUpdateColumns = []string{"AgentID", "Name", "Email", "Password", "IsActive", "UpdatedAt"}
+
 // Update modifies an existing user in the database
 // It takes a context for request-scoped values and a pointer to a User model
+// Every column in userUpdateColumns is written, so the user should be loaded before it is changed
 // Returns an error if the operation fails
 func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 	r.logger.InfoContext(ctx, "Updating user", "id", user.ID, "email", user.Email)
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", user.ID).Updates(user).Error; err != nil {
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	if err := db.WithContext(ctx).Model(&model.User{}).Where("id = ?", user.ID).Select(userUpdateColumns).Updates(user).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update user: request cancelled", "id", user.ID, "email", user.Email, "error", err)
+			return err
+		}
+		if postgres.IsUniqueViolation(err, userEmailIndex) {
+			r.logger.WarnContext(ctx, "Failed to update user: email already exists", "id", user.ID, "email", user.Email)
+			return domain.ErrEmailAlreadyExists
+		}
 		r.logger.ErrorContext(ctx, "Failed to update user", "id", user.ID, "email", user.Email, "error", err)
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -105,7 +164,15 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 // Returns an error if the operation fails
 func (r *userRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
 	r.logger.InfoContext(ctx, "Updating user password", "id", id)
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", hashedPassword).Error; err != nil {
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	if err := db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", hashedPassword).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update user password: request cancelled", "id", id, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to update user password", "id", id, "error", err)
 		return fmt.Errorf("failed to update user password: %w", err)
 	}
@@ -113,22 +180,56 @@ func (r *userRepository) UpdatePassword(ctx context.Context, id string, hashedPa
 	return nil
 }
 
+// UpdateStatus activates or deactivates a user
+// It takes a context for request-scoped values, user ID, and the new status
+// Returns domain.ErrNotFound if no user has that ID
+func (r *userRepository) UpdateStatus(ctx context.Context, id string, isActive bool) error {
+	r.logger.InfoContext(ctx, "Updating user status", "id", id, "isActive", isActive)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	result := db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", id).Update("is_active", isActive)
+	if err := result.Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update user status: request cancelled", "id", id, "error", err)
+			return err
+		}
+		r.logger.ErrorContext(ctx, "Failed to update user status", "id", id, "error", err)
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		r.logger.WarnContext(ctx, "User not found for status update", "id", id)
+		return domain.ErrNotFound
+	}
+	r.logger.InfoContext(ctx, "User status updated successfully", "id", id, "isActive", isActive)
+	return nil
+}
+
 // Delete removes a user from the database (soft delete)
 // It takes a context for request-scoped values and the user ID
 // Returns an error if the operation fails
 func (r *userRepository) Delete(ctx context.Context, id string) error {
 	r.logger.InfoContext(ctx, "Deleting user", "id", id)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	user := &model.User{ID: id}
 
 	// Use soft delete
-	if err := r.db.WithContext(ctx).Delete(user).Error; err != nil {
+	if err := db.WithContext(ctx).Delete(user).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete user: request cancelled", "id", id, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to delete user", "id", id, "error", err)
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	// Check if record was actually deleted
 	var count int64
-	r.db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", id).Count(&count)
+	db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", id).Count(&count)
 	if count > 0 {
 		r.logger.WarnContext(ctx, "User not found for deletion", "id", id)
 		return domain.ErrNotFound
@@ -138,22 +239,61 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// Restore recovers a soft-deleted user by clearing its deleted_at timestamp
+// It takes a context for request-scoped values and the user ID
+// Returns domain.ErrNotFound if no deleted user has that ID
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	r.logger.InfoContext(ctx, "Restoring user", "id", id)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
+	result := db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if err := result.Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to restore user: request cancelled", "id", id, "error", err)
+			return err
+		}
+		r.logger.ErrorContext(ctx, "Failed to restore user", "id", id, "error", err)
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		r.logger.WarnContext(ctx, "Deleted user not found for restore", "id", id)
+		return domain.ErrNotFound
+	}
+
+	r.logger.InfoContext(ctx, "User restored successfully", "id", id)
+	return nil
+}
+
 // List retrieves a paginated list of users from the database
 // It takes a context for request-scoped values, offset for pagination, and limit for page size
 // Returns a slice of user pointers, the real total count, and an error if the operation fails
 func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.User, int, error) {
 	r.logger.InfoContext(ctx, "Listing users", "offset", offset, "limit", limit)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var users []*model.User
 	var total int64
 
 	// Get total count
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("is_active = ? AND deleted_at IS NULL", true).Count(&total).Error; err != nil {
+	if err := db.WithContext(ctx).Model(&model.User{}).Where("is_active = ? AND deleted_at IS NULL", true).Count(&total).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to count users: request cancelled", "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to count users", "error", err)
 		return nil, 0, fmt.Errorf("failed to count users: %w", err)
 	}
 
 	// Get paginated users
-	if err := r.db.WithContext(ctx).Where("is_active = ? AND deleted_at IS NULL", true).Offset(offset).Limit(limit).Order("id ASC").Find(&users).Error; err != nil {
+	if err := db.WithContext(ctx).Where("is_active = ? AND deleted_at IS NULL", true).Offset(offset).Limit(limit).Order("id ASC").Find(&users).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to list users: request cancelled", "offset", offset, "limit", limit, "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to list users", "offset", offset, "limit", limit, "error", err)
 		return nil, 0, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -167,8 +307,16 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.
 // Returns a slice of user pointers and an error if the operation fails
 func (r *userRepository) GetByAgentID(ctx context.Context, agentID string) ([]*model.User, error) {
 	r.logger.InfoContext(ctx, "Getting users by agent ID", "agentID", agentID)
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var users []*model.User
-	if err := r.db.WithContext(ctx).Where("agent_id = ? AND is_active = ? AND deleted_at IS NULL", agentID, true).Find(&users).Error; err != nil {
+	if err := db.WithContext(ctx).Where("agent_id = ? AND is_active = ? AND deleted_at IS NULL", agentID, true).Find(&users).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get users by agent ID: request cancelled", "agentID", agentID, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get users by agent ID", "agentID", agentID, "error", err)
 		return nil, fmt.Errorf("failed to get users by agent ID: %w", err)
 	}
@@ -181,8 +329,16 @@ func (r *userRepository) GetByAgentID(ctx context.Context, agentID string) ([]*m
 // Returns a slice of user pointers and an error if the operation fails
 func (r *userRepository) GetActiveUsers(ctx context.Context) ([]*model.User, error) {
 	r.logger.InfoContext(ctx, "Getting active users")
+
+	// Use the transaction from the context, if any
+	db := postgres.DBFromContext(ctx, r.db)
+
 	var users []*model.User
-	if err := r.db.WithContext(ctx).Preload("Agent").Where("is_active = ? AND deleted_at IS NULL", true).Find(&users).Error; err != nil {
+	if err := db.WithContext(ctx).Preload("Agent").Where("is_active = ? AND deleted_at IS NULL", true).Find(&users).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get active users: request cancelled", "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get active users", "error", err)
 		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
@@ -195,9 +351,5 @@ func (r *userRepository) GetActiveUsers(ctx context.Context) ([]*model.User, err
 // Returns an error if the transaction fails or if the function returns an error
 func (r *userRepository) ExecuteInTransaction(ctx context.Context, fn func(txCtx context.Context) error) error {
 	r.logger.InfoContext(ctx, "Executing operation in transaction")
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Create a context that carries the transaction
-		txCtx := context.WithValue(ctx, "tx", tx)
-		return fn(txCtx)
-	})
+	return postgres.RunInTransaction(ctx, r.db, fn)
 }
@@ -15,13 +15,18 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// maxAgentHierarchyDepth is the maximum number of ancestors walked when checking a parent agent
+const maxAgentHierarchyDepth = 32
+
 // AgentUseCase defines business operations for agents
 type AgentUseCase interface {
 	CreateAgent(ctx context.Context, agent *model.Agent) error
 	GetAgentByID(ctx context.Context, id string) (*model.Agent, error)
 	UpdateAgent(ctx context.Context, agent *model.Agent) error
 	DeleteAgent(ctx context.Context, id string) error
+	RestoreAgent(ctx context.Context, id string) error
 	GetAgentsByParentID(ctx context.Context, parentID string) ([]*model.Agent, error)
+	GetAgentTree(ctx context.Context, rootID string, maxDepth int) (*model.AgentNode, error)
 	ListAgents(ctx context.Context, offset, limit int) ([]*model.Agent, int, error)
 	CreateSubAgentWithUser(ctx context.Context, parentID string, req *agent_service.CreateSubAgentWithUserRequest) (*model.Agent, *model.User, error)
 }
@@ -94,9 +99,8 @@ func (uc *agentUseCase) CreateAgent(ctx context.Context, agent *model.Agent) err
 		}
 
 		// Prevent circular reference
-		if parentAgent.ParentAgentID != nil && *parentAgent.ParentAgentID == agent.ID {
-			uc.logger.WarnContext(ctx, "Circular reference detected in agent hierarchy", "agentID", agent.ID, "parentID", *agent.ParentAgentID)
-			return domain.ErrCircularReference
+		if err := uc.checkAncestors(ctx, uc.logger, agent.ID, parentAgent); err != nil {
+			return err
 		}
 	}
 
@@ -111,63 +115,65 @@ func (uc *agentUseCase) CreateAgent(ctx context.Context, agent *model.Agent) err
 
 // GetAgentByID retrieves an agent by ID
 func (uc *agentUseCase) GetAgentByID(ctx context.Context, id string) (*model.Agent, error) {
-	uc.logger.InfoContext(ctx, "Getting agent by ID in usecase", "id", id)
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Getting agent by ID in usecase")
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid agent ID provided", "id", id)
+		log.WarnContext(ctx, "Invalid agent ID provided")
 		return nil, domain.ErrInvalidID
 	}
 
 	agent, err := uc.agentRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Agent not found by ID", "id", id)
+			log.WarnContext(ctx, "Agent not found by ID")
 			return nil, domain.ErrAgentNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error getting agent by ID", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error getting agent by ID", "error", err)
 		return nil, fmt.Errorf("error getting agent: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Agent retrieved by ID in usecase", "id", agent.ID, "email", agent.Email)
+	log.InfoContext(ctx, "Agent retrieved by ID in usecase", "email", agent.Email)
 	return agent, nil
 }
 
 // UpdateAgent updates an existing agent
 func (uc *agentUseCase) UpdateAgent(ctx context.Context, agent *model.Agent) error {
-	uc.logger.InfoContext(ctx, "Updating agent in usecase", "id", agent.ID, "email", agent.Email)
+	log := uc.logger.With("id", agent.ID)
+	log.InfoContext(ctx, "Updating agent in usecase", "email", agent.Email)
 	if agent.ID == "" {
-		uc.logger.WarnContext(ctx, "Invalid agent ID for update", "id", agent.ID)
+		log.WarnContext(ctx, "Invalid agent ID for update")
 		return domain.ErrInvalidID
 	}
 
 	if agent.Email == "" {
-		uc.logger.WarnContext(ctx, "Email is required for agent update", "id", agent.ID)
+		log.WarnContext(ctx, "Email is required for agent update")
 		return domain.ErrEmailRequired
 	}
 
 	if agent.AgentName == "" {
-		uc.logger.WarnContext(ctx, "Agent name is required for agent update", "id", agent.ID)
+		log.WarnContext(ctx, "Agent name is required for agent update")
 		return domain.ErrAgentNameRequired
 	}
 
 	if agent.AgentType == "" {
-		uc.logger.WarnContext(ctx, "Agent type is required for agent update", "id", agent.ID)
+		log.WarnContext(ctx, "Agent type is required for agent update")
 		return domain.ErrAgentTypeRequired
 	}
 
 	// Validate agent type
 	if agent.AgentType != model.AgentTypeIATA && agent.AgentType != model.AgentTypeSubAgent {
-		uc.logger.WarnContext(ctx, "Invalid agent type", "agentType", agent.AgentType)
+		log.WarnContext(ctx, "Invalid agent type", "agentType", agent.AgentType)
 		return domain.ErrInvalidAgentType
 	}
 
 	// Check if email already exists for another agent
 	existingAgent, err := uc.agentRepo.GetByEmail(ctx, agent.Email)
 	if err != nil && !errors.Is(err, domain.ErrNotFound) {
-		uc.logger.ErrorContext(ctx, "Error checking email uniqueness", "email", agent.Email, "error", err)
+		log.ErrorContext(ctx, "Error checking email uniqueness", "email", agent.Email, "error", err)
 		return fmt.Errorf("error checking email uniqueness: %w", err)
 	}
 	if existingAgent != nil && existingAgent.ID != agent.ID {
-		uc.logger.WarnContext(ctx, "Agent with this email already exists", "email", agent.Email, "existingAgentID", existingAgent.ID)
+		log.WarnContext(ctx, "Agent with this email already exists", "email", agent.Email, "existingAgentID", existingAgent.ID)
 		return domain.ErrAgentEmailAlreadyExists
 	}
 
@@ -176,60 +182,135 @@ func (uc *agentUseCase) UpdateAgent(ctx context.Context, agent *model.Agent) err
 		parentAgent, err := uc.agentRepo.GetByID(ctx, *agent.ParentAgentID)
 		if err != nil {
 			if errors.Is(err, domain.ErrNotFound) {
-				uc.logger.WarnContext(ctx, "Parent agent not found", "parentID", *agent.ParentAgentID)
+				log.WarnContext(ctx, "Parent agent not found", "parentID", *agent.ParentAgentID)
 				return domain.ErrParentAgentNotFound
 			}
-			uc.logger.ErrorContext(ctx, "Error checking parent agent", "parentID", *agent.ParentAgentID, "error", err)
+			log.ErrorContext(ctx, "Error checking parent agent", "parentID", *agent.ParentAgentID, "error", err)
 			return fmt.Errorf("error checking parent agent: %w", err)
 		}
 
 		// Prevent circular reference
-		if parentAgent.ParentAgentID != nil && *parentAgent.ParentAgentID == agent.ID {
-			uc.logger.WarnContext(ctx, "Circular reference detected in agent hierarchy", "agentID", agent.ID, "parentID", *agent.ParentAgentID)
-			return domain.ErrCircularReference
+		if err := uc.checkAncestors(ctx, log, agent.ID, parentAgent); err != nil {
+			return err
 		}
 	}
 
 	if err := uc.agentRepo.Update(ctx, agent); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to update agent in repository", "id", agent.ID, "email", agent.Email, "error", err)
+		log.ErrorContext(ctx, "Failed to update agent in repository", "email", agent.Email, "error", err)
 		return err
 	}
 
-	uc.logger.InfoContext(ctx, "Agent updated successfully in usecase", "id", agent.ID, "email", agent.Email)
+	log.InfoContext(ctx, "Agent updated successfully in usecase", "email", agent.Email)
 	return nil
 }
 
+// checkAncestors walks up the hierarchy from parent and rejects it if agentID is parent or one of its ancestors
+// A cycle already present among the ancestors is reported as ErrCircularReference,
+// and hierarchies deeper than maxAgentHierarchyDepth as ErrAgentHierarchyTooDeep
+func (uc *agentUseCase) checkAncestors(ctx context.Context, log logger.LoggerInterface, agentID string, parent *model.Agent) error {
+	visited := make(map[string]bool)
+	current := parent
+	for depth := 0; ; depth++ {
+		if (agentID != "" && current.ID == agentID) || visited[current.ID] {
+			log.WarnContext(ctx, "Circular reference detected in agent hierarchy", "agentID", agentID, "parentID", parent.ID, "ancestorID", current.ID)
+			return domain.ErrCircularReference
+		}
+		if depth >= maxAgentHierarchyDepth {
+			log.WarnContext(ctx, "Agent hierarchy too deep", "agentID", agentID, "parentID", parent.ID, "maxDepth", maxAgentHierarchyDepth)
+			return domain.ErrAgentHierarchyTooDeep
+		}
+		visited[current.ID] = true
+
+		if current.ParentAgentID == nil {
+			return nil
+		}
+		next, err := uc.agentRepo.GetByID(ctx, *current.ParentAgentID)
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				// The chain ends at a deleted ancestor
+				return nil
+			}
+			log.ErrorContext(ctx, "Error walking agent hierarchy", "ancestorID", *current.ParentAgentID, "error", err)
+			return fmt.Errorf("error walking agent hierarchy: %w", err)
+		}
+		current = next
+	}
+}
+
 // DeleteAgent deletes an agent
 func (uc *agentUseCase) DeleteAgent(ctx context.Context, id string) error {
-	uc.logger.InfoContext(ctx, "Deleting agent in usecase", "id", id)
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Deleting agent in usecase")
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid agent ID for deletion", "id", id)
+		log.WarnContext(ctx, "Invalid agent ID for deletion")
 		return domain.ErrInvalidID
 	}
 
 	// Check if agent has children
 	children, err := uc.agentRepo.GetByParentID(ctx, id)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error checking agent children", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error checking agent children", "error", err)
 		return fmt.Errorf("error checking agent children: %w", err)
 	}
 
 	if len(children) > 0 {
-		uc.logger.WarnContext(ctx, "Cannot delete agent with children", "id", id, "children_count", len(children))
+		log.WarnContext(ctx, "Cannot delete agent with children", "children_count", len(children))
 		return domain.ErrAgentHasChildren
 	}
 
 	err = uc.agentRepo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Agent not found for deletion", "id", id)
+			log.WarnContext(ctx, "Agent not found for deletion")
 			return domain.ErrAgentNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error deleting agent", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error deleting agent", "error", err)
 		return fmt.Errorf("error deleting agent: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Agent deleted successfully in usecase", "id", id)
+	log.InfoContext(ctx, "Agent deleted successfully in usecase")
+	return nil
+}
+
+// RestoreAgent recovers a soft-deleted agent
+// A sub-agent can only be restored while its parent exists, so the hierarchy never holds orphans
+func (uc *agentUseCase) RestoreAgent(ctx context.Context, id string) error {
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Restoring agent in usecase")
+	if id == "" {
+		log.WarnContext(ctx, "Invalid agent ID for restore")
+		return domain.ErrInvalidID
+	}
+
+	// Restore and check the parent in a transaction, so an orphan restore is rolled back
+	err := uc.agentRepo.ExecuteInTransaction(ctx, func(txCtx context.Context) error {
+		if err := uc.agentRepo.Restore(txCtx, id); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				log.WarnContext(ctx, "Deleted agent not found for restore")
+				return domain.ErrAgentNotFound
+			}
+			log.ErrorContext(ctx, "Error restoring agent", "error", err)
+			return fmt.Errorf("error restoring agent: %w", err)
+		}
+
+		agent, err := uc.agentRepo.GetByID(txCtx, id)
+		if err != nil {
+			log.ErrorContext(ctx, "Error getting restored agent", "error", err)
+			return fmt.Errorf("error getting restored agent: %w", err)
+		}
+
+		// The parent is not preloaded when it is deleted
+		if agent.ParentAgentID != nil && agent.Parent == nil {
+			log.WarnContext(ctx, "Parent agent of restored agent is deleted", "parent_id", *agent.ParentAgentID)
+			return domain.ErrParentAgentNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.InfoContext(ctx, "Agent restored successfully in usecase")
 	return nil
 }
 
@@ -258,47 +339,106 @@ func (uc *agentUseCase) ListAgents(ctx context.Context, offset, limit int) ([]*m
 
 // GetAgentsByParentID retrieves agents by parent ID
 func (uc *agentUseCase) GetAgentsByParentID(ctx context.Context, parentID string) ([]*model.Agent, error) {
-	uc.logger.InfoContext(ctx, "Getting agents by parent ID in usecase", "parentID", parentID)
+	log := uc.logger.With("parentID", parentID)
+	log.InfoContext(ctx, "Getting agents by parent ID in usecase")
 	if parentID == "" {
-		uc.logger.WarnContext(ctx, "Parent ID is required for agent lookup by parent")
+		log.WarnContext(ctx, "Parent ID is required for agent lookup by parent")
 		return nil, domain.ErrInvalidID
 	}
 
 	agents, err := uc.agentRepo.GetByParentID(ctx, parentID)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error getting agents by parent ID", "parentID", parentID, "error", err)
+		log.ErrorContext(ctx, "Error getting agents by parent ID", "error", err)
 		return nil, fmt.Errorf("error getting agents by parent ID: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Agents retrieved by parent ID in usecase", "count", len(agents), "parentID", parentID)
+	log.InfoContext(ctx, "Agents retrieved by parent ID in usecase", "count", len(agents))
 	return agents, nil
 }
 
+// GetAgentTree returns the agent rootID with its descendants nested up to maxDepth levels below it
+// maxDepth is capped at maxAgentHierarchyDepth, which is also used when it is zero or negative
+// An agent reached twice while walking down the hierarchy is reported as ErrCircularReference
+func (uc *agentUseCase) GetAgentTree(ctx context.Context, rootID string, maxDepth int) (*model.AgentNode, error) {
+	log := uc.logger.With("rootID", rootID)
+	log.InfoContext(ctx, "Getting agent tree in usecase", "maxDepth", maxDepth)
+
+	if maxDepth <= 0 || maxDepth > maxAgentHierarchyDepth {
+		maxDepth = maxAgentHierarchyDepth
+	}
+
+	root, err := uc.GetAgentByID(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{root.ID: true}
+	tree, err := uc.buildAgentTree(ctx, log, root, maxDepth, visited)
+	if err != nil {
+		return nil, err
+	}
+
+	log.InfoContext(ctx, "Agent tree retrieved in usecase", "count", len(visited))
+	return tree, nil
+}
+
+// buildAgentTree returns the node of agent with its descendants down to depth levels below it
+// visited holds the IDs of the agents already in the tree
+func (uc *agentUseCase) buildAgentTree(ctx context.Context, log logger.LoggerInterface, agent *model.Agent, depth int, visited map[string]bool) (*model.AgentNode, error) {
+	node := &model.AgentNode{Agent: agent}
+	if depth == 0 {
+		return node, nil
+	}
+
+	children, err := uc.agentRepo.GetByParentID(ctx, agent.ID)
+	if err != nil {
+		log.ErrorContext(ctx, "Error getting agents by parent ID", "parentID", agent.ID, "error", err)
+		return nil, fmt.Errorf("error getting agents by parent ID: %w", err)
+	}
+
+	for _, child := range children {
+		if visited[child.ID] {
+			log.WarnContext(ctx, "Circular reference detected in agent hierarchy", "agentID", child.ID, "parentID", agent.ID)
+			return nil, domain.ErrCircularReference
+		}
+		visited[child.ID] = true
+
+		childNode, err := uc.buildAgentTree(ctx, log, child, depth-1, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	return node, nil
+}
+
 // CreateSubAgentWithUser creates a sub-agent with user
 func (uc *agentUseCase) CreateSubAgentWithUser(ctx context.Context, parentID string, req *agent_service.CreateSubAgentWithUserRequest) (*model.Agent, *model.User, error) {
-	uc.logger.InfoContext(ctx, "Creating sub-agent with user in usecase", "parentID", parentID, "agentEmail", req.AgentEmail, "userEmail", req.UserEmail)
+	log := uc.logger.With("parentID", parentID)
+	log.InfoContext(ctx, "Creating sub-agent with user in usecase", "agentEmail", req.AgentEmail, "userEmail", req.UserEmail)
 
 	// Validate parent ID
 	if parentID == "" {
-		uc.logger.WarnContext(ctx, "Parent ID is required for sub-agent creation")
+		log.WarnContext(ctx, "Parent ID is required for sub-agent creation")
 		return nil, nil, domain.ErrInvalidID
 	}
 
 	// Check if parent agent exists
 	parentAgent, err := uc.agentRepo.GetByID(ctx, parentID)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error checking parent agent", "parentID", parentID, "error", err)
+		log.ErrorContext(ctx, "Error checking parent agent", "error", err)
 		return nil, nil, fmt.Errorf("error checking parent agent: %w", err)
 	}
 	if parentAgent == nil {
-		uc.logger.WarnContext(ctx, "Parent agent not found", "parentID", parentID)
+		log.WarnContext(ctx, "Parent agent not found")
 		return nil, nil, domain.ErrParentAgentNotFound
 	}
 
 	// Hash the user password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.UserPassword), bcrypt.DefaultCost)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error hashing password", "error", err)
+		log.ErrorContext(ctx, "Error hashing password", "error", err)
 		return nil, nil, fmt.Errorf("error hashing password: %w", err)
 	}
 
@@ -325,7 +465,7 @@ func (uc *agentUseCase) CreateSubAgentWithUser(ctx context.Context, parentID str
 	err = uc.agentRepo.ExecuteInTransaction(ctx, func(txCtx context.Context) error {
 		// Create the agent within the transaction
 		if err := uc.agentRepo.Create(txCtx, agent); err != nil {
-			uc.logger.ErrorContext(ctx, "Error creating agent in transaction", "email", agent.Email, "error", err)
+			log.ErrorContext(ctx, "Error creating agent in transaction", "email", agent.Email, "error", err)
 			return fmt.Errorf("error creating agent: %w", err)
 		}
 
@@ -334,7 +474,7 @@ func (uc *agentUseCase) CreateSubAgentWithUser(ctx context.Context, parentID str
 
 		// Create the user within the same transaction
 		if err := uc.userRepo.Create(txCtx, user); err != nil {
-			uc.logger.ErrorContext(ctx, "Error creating user in transaction", "email", user.Email, "error", err)
+			log.ErrorContext(ctx, "Error creating user in transaction", "email", user.Email, "error", err)
 			return fmt.Errorf("error creating user: %w", err)
 		}
 
@@ -342,10 +482,10 @@ func (uc *agentUseCase) CreateSubAgentWithUser(ctx context.Context, parentID str
 	})
 
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Transaction failed for sub-agent with user creation", "parentID", parentID, "error", err)
+		log.ErrorContext(ctx, "Transaction failed for sub-agent with user creation", "error", err)
 		return nil, nil, err
 	}
 
-	uc.logger.InfoContext(ctx, "Sub-agent with user created successfully in usecase", "agentID", agent.ID, "userID", user.ID)
+	log.InfoContext(ctx, "Sub-agent with user created successfully in usecase", "agentID", agent.ID, "userID", user.ID)
 	return agent, user, nil
 }
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/domain/repository"
+	"monorepo/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgentRepo is an in-memory agent repository keyed by ID
+// Methods not overridden panic through the nil embedded interface
+type fakeAgentRepo struct {
+	repository.TransactionalAgent
+	agents  map[string]*model.Agent
+	updated []string
+}
+
+func (r *fakeAgentRepo) GetByID(_ context.Context, id string) (*model.Agent, error) {
+	agent, ok := r.agents[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *agent
+	return &copied, nil
+}
+
+func (r *fakeAgentRepo) GetByEmail(_ context.Context, email string) (*model.Agent, error) {
+	for _, agent := range r.agents {
+		if agent.Email == email {
+			copied := *agent
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeAgentRepo) Update(_ context.Context, agent *model.Agent) error {
+	copied := *agent
+	r.agents[agent.ID] = &copied
+	r.updated = append(r.updated, agent.ID)
+	return nil
+}
+
+// addChain stores agents with the given IDs, each one the parent of the next
+func (r *fakeAgentRepo) addChain(ids ...string) {
+	var parentID *string
+	for _, id := range ids {
+		r.agents[id] = &model.Agent{
+			ID:            id,
+			AgentName:     "Agent " + id,
+			Email:         id + "@example.com",
+			AgentType:     model.AgentTypeSubAgent,
+			ParentAgentID: parentID,
+		}
+		parentID = &id
+	}
+}
+
+func newTestAgentUseCase() (AgentUseCase, *fakeAgentRepo) {
+	repo := &fakeAgentRepo{agents: map[string]*model.Agent{}}
+	return NewAgentUseCase(repo, nil, logger.NoOpLogger()), repo
+}
+
+// reparent returns the stored agent id with its parent set to parentID
+func reparent(repo *fakeAgentRepo, id, parentID string) *model.Agent {
+	agent := *repo.agents[id]
+	agent.ParentAgentID = &parentID
+	return &agent
+}
+
+func TestAgentUseCase_UpdateAgent_CircularReference(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("closing a three-level loop is rejected", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		// A is the parent of B, which is the parent of C
+		repo.addChain("A", "B", "C")
+
+		err := uc.UpdateAgent(ctx, reparent(repo, "A", "C"))
+		assert.ErrorIs(t, err, domain.ErrCircularReference, "A descendant should not become the parent")
+		assert.Empty(t, repo.updated, "The agent should not be updated")
+	})
+
+	t.Run("the agent cannot be its own parent", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		repo.addChain("A")
+
+		err := uc.UpdateAgent(ctx, reparent(repo, "A", "A"))
+		assert.ErrorIs(t, err, domain.ErrCircularReference, "An agent should not parent itself")
+	})
+
+	t.Run("an existing cycle among the ancestors is rejected", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		repo.addChain("A", "B", "C")
+		repo.addChain("D")
+		repo.agents["A"].ParentAgentID = &repo.agents["C"].ID
+
+		err := uc.UpdateAgent(ctx, reparent(repo, "D", "A"))
+		assert.ErrorIs(t, err, domain.ErrCircularReference, "Walking a cycle should stop and report it")
+	})
+
+	t.Run("a non-descendant parent is accepted", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		repo.addChain("A", "B", "C")
+		repo.addChain("D")
+
+		require.NoError(t, uc.UpdateAgent(ctx, reparent(repo, "D", "C")), "UpdateAgent should not return error")
+		assert.Equal(t, []string{"D"}, repo.updated, "The agent should be updated")
+	})
+}
+
+func TestAgentUseCase_UpdateAgent_HierarchyDepth(t *testing.T) {
+	ctx := context.Background()
+
+	// chain returns IDs for a hierarchy of n agents, root first
+	chain := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("ancestor-%d", i)
+		}
+		return ids
+	}
+
+	t.Run("parent with the maximum number of ancestors is accepted", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		ids := chain(maxAgentHierarchyDepth)
+		repo.addChain(ids...)
+		repo.addChain("child")
+
+		err := uc.UpdateAgent(ctx, reparent(repo, "child", ids[len(ids)-1]))
+		require.NoError(t, err, "A hierarchy at the limit should be accepted")
+	})
+
+	t.Run("deeper hierarchy is rejected", func(t *testing.T) {
+		uc, repo := newTestAgentUseCase()
+		ids := chain(maxAgentHierarchyDepth + 1)
+		repo.addChain(ids...)
+		repo.addChain("child")
+
+		err := uc.UpdateAgent(ctx, reparent(repo, "child", ids[len(ids)-1]))
+		assert.ErrorIs(t, err, domain.ErrAgentHierarchyTooDeep, "A hierarchy over the limit should be rejected")
+		assert.Empty(t, repo.updated, "The agent should not be updated")
+	})
+}
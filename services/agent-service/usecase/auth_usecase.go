@@ -3,16 +3,17 @@ package usecase
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"agent-service/domain"
 	"agent-service/domain/repository"
 	"monorepo/contracts/agent_service"
+	"monorepo/pkg/contextkeys"
 	"monorepo/pkg/jwt"
 	"monorepo/pkg/kafka"
 	"monorepo/pkg/logger"
@@ -21,6 +22,23 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// passwordResetTokenPurpose namespaces password reset tokens in the token store
+	passwordResetTokenPurpose = "password_reset"
+	// passwordResetTokenTTL is how long a password reset token stays valid
+	passwordResetTokenTTL = 15 * time.Minute
+	// bearerTokenType is the token type reported in login and refresh responses
+	bearerTokenType = "Bearer"
+	// loginRateLimitKeyPrefix namespaces per-IP failed login windows in the rate limiter
+	loginRateLimitKeyPrefix = "login:"
+	// loginFailuresKeyPrefix namespaces the per-email failed login windows in the rate limiter
+	loginFailuresKeyPrefix = "login_failures:"
+	// loginLockKeyPrefix namespaces the per-email account locks, holding the unix time the lock ends
+	loginLockKeyPrefix = "login_locked:"
+	// correlationIDHeader is the Kafka header carrying the correlation ID of the originating request
+	correlationIDHeader = "correlation_id"
+)
+
 // AuthUseCase defines the interface for authentication-related business operations
 type AuthUseCase interface {
 	// Login authenticates a user with email and password
@@ -37,6 +55,14 @@ type AuthUseCase interface {
 	// It takes a context for request-scoped values with user claims
 	// Returns a UserResponse with user profile data, or an error if retrieval fails
 	Profile(ctx context.Context) (*agent_service.UserResponse, error)
+	// Sessions lists the active sessions of the authenticated user, one page at a time
+	// cursor is the NextCursor of the previous page, or zero for the first page
+	// Returns ErrSessionsNotSupported in stateless mode, where no sessions are tracked
+	Sessions(ctx context.Context, cursor uint64, limit int) (*agent_service.SessionsResponse, error)
+	// Logout ends a login by revoking its refresh token and, in stateful mode, ending its session
+	// sessionID is optional; the session must belong to the owner of the refresh token
+	// Returns ErrInvalidRefreshToken if the token is invalid or already revoked
+	Logout(ctx context.Context, refreshToken, sessionID string) error
 	// ForgotPassword initiates the password reset process for a user
 	// It generates a reset token and stores it in Redis
 	// It takes a context and a ForgotPasswordRequest
@@ -49,6 +75,22 @@ type AuthUseCase interface {
 	ResetPassword(ctx context.Context, req agent_service.ResetPasswordRequest) (*agent_service.ResetPasswordResponse, error)
 }
 
+// LoginRateLimitConfig controls throttling failed logins per client IP address
+type LoginRateLimitConfig struct {
+	// MaxAttempts is the number of failed logins allowed per IP address within Window; zero disables the limit
+	MaxAttempts int
+	// Window is the sliding window failed logins are counted over
+	Window time.Duration
+}
+
+// LoginLockoutConfig controls locking accounts after repeated failed logins
+type LoginLockoutConfig struct {
+	// MaxFailedAttempts is the number of failed logins after which an account is locked; zero disables lockout
+	MaxFailedAttempts int
+	// Duration is how long an account stays locked, and the sliding window failed logins are counted over
+	Duration time.Duration
+}
+
 // authUseCase implements the AuthUseCase interface
 type authUseCase struct {
 	// userRepo is the repository interface for user database operations
@@ -57,8 +99,16 @@ type authUseCase struct {
 	agentRepo repository.Agent
 	// jwtClient is the JWT client for token generation and validation
 	jwtClient jwt.JWTClient
-	// redisClient is the Redis client for storing reset tokens
+	// redisClient is the Redis client backing the token store
 	redisClient redis.RedisClient
+	// tokenStore issues and consumes single-use password reset tokens
+	tokenStore redis.TokenStore
+	// loginLimiter throttles failed logins per IP address
+	loginLimiter *redis.RateLimiter
+	// rateLimit controls throttling failed logins per IP address
+	rateLimit LoginRateLimitConfig
+	// lockout controls locking accounts after repeated failed logins
+	lockout LoginLockoutConfig
 	// kafkaClient is the Kafka client for producing messages
 	kafkaClient kafka.KafkaClient
 	// passwordResetTopic is the Kafka topic for password reset messages
@@ -68,14 +118,19 @@ type authUseCase struct {
 }
 
 // NewAuthUseCase creates a new instance of authUseCase
-// It takes a User repository implementation, Agent repository implementation, JWT client, Redis client, Kafka client, password reset topic, and a logger instance
+// It takes a User repository implementation, Agent repository implementation, JWT client, Redis client, Kafka client, password reset topic,
+// login rate limit and lockout configuration, and a logger instance
 // Returns an implementation of the AuthUseCase interface
-func NewAuthUseCase(userRepo repository.User, agentRepo repository.Agent, jwtClient jwt.JWTClient, redisClient redis.RedisClient, kafkaClient kafka.KafkaClient, passwordResetTopic string, appLogger logger.LoggerInterface) AuthUseCase {
+func NewAuthUseCase(userRepo repository.User, agentRepo repository.Agent, jwtClient jwt.JWTClient, redisClient redis.RedisClient, kafkaClient kafka.KafkaClient, passwordResetTopic string, rateLimit LoginRateLimitConfig, lockout LoginLockoutConfig, appLogger logger.LoggerInterface) AuthUseCase {
 	return &authUseCase{
 		userRepo:           userRepo,
 		agentRepo:          agentRepo,
 		jwtClient:          jwtClient,
 		redisClient:        redisClient,
+		tokenStore:         redis.NewTokenStore(redisClient),
+		loginLimiter:       redis.NewRateLimiter(redisClient),
+		rateLimit:          rateLimit,
+		lockout:            lockout,
 		kafkaClient:        kafkaClient,
 		passwordResetTopic: passwordResetTopic,
 		logger:             appLogger,
@@ -88,11 +143,25 @@ func NewAuthUseCase(userRepo repository.User, agentRepo repository.Agent, jwtCli
 func (uc *authUseCase) Login(ctx context.Context, req agent_service.LoginRequest, userAgent, ipAddress string) (*agent_service.LoginResponse, error) {
 	uc.logger.InfoContext(ctx, "Login attempt", "email", req.Email)
 
+	// Throttle brute force attempts per client IP; only failed logins are counted
+	if retryAfter, limited := uc.isLoginRateLimited(ctx, ipAddress); limited {
+		uc.logger.WarnContext(ctx, "Too many login attempts", "ipAddress", ipAddress)
+		return nil, &domain.RetryAfterError{Err: domain.ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+
+	// Reject locked accounts before checking credentials
+	if retryAfter, locked := uc.isAccountLocked(ctx, req.Email); locked {
+		uc.logger.WarnContext(ctx, "Login attempt on locked account", "email", req.Email)
+		return nil, &domain.RetryAfterError{Err: domain.ErrAccountLocked, RetryAfter: retryAfter}
+	}
+
 	// Get user by email
 	user, err := uc.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			uc.logger.WarnContext(ctx, "User not found", "email", req.Email)
+			// Count unknown emails too, so lockout doesn't reveal which accounts exist
+			uc.recordFailedLogin(ctx, req.Email, ipAddress)
 			return nil, domain.ErrInvalidCredentials
 		}
 		uc.logger.ErrorContext(ctx, "Error retrieving user", "email", req.Email, "error", err)
@@ -102,15 +171,17 @@ func (uc *authUseCase) Login(ctx context.Context, req agent_service.LoginRequest
 	// Check if user is active
 	if !user.IsActive {
 		uc.logger.WarnContext(ctx, "User is not active", "email", req.Email)
-		return nil, errors.New("user account is not active")
+		return nil, domain.ErrUserInactive
 	}
 
 	// Verify password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
 		uc.logger.WarnContext(ctx, "Invalid password", "email", req.Email)
+		uc.recordFailedLogin(ctx, req.Email, ipAddress)
 		return nil, domain.ErrInvalidCredentials
 	}
+	uc.resetFailedLogins(ctx, req.Email)
 
 	// Generate access token
 	agentID := ""
@@ -172,13 +243,108 @@ func (uc *authUseCase) Login(ctx context.Context, req agent_service.LoginRequest
 	}
 
 	return &agent_service.LoginResponse{
+		TokenType:          bearerTokenType,
 		AccessToken:        accessToken,
 		RefreshToken:       refreshToken,
 		AccessTokenExpire:  int64(time.Until(accessTokenExpire).Seconds()),
 		RefreshTokenExpire: int64(time.Until(refreshTokenExpire).Seconds()),
+		SessionID:          sessionID,
 	}, nil
 }
 
+// isLoginRateLimited reports whether an IP address has reached the failed login limit,
+// and how long until its oldest failure leaves the window
+// It fails open, so a Redis outage doesn't block every login
+func (uc *authUseCase) isLoginRateLimited(ctx context.Context, ipAddress string) (time.Duration, bool) {
+	if uc.rateLimit.MaxAttempts <= 0 || ipAddress == "" {
+		return 0, false
+	}
+
+	allowed, retryAfter, err := uc.loginLimiter.Check(ctx, loginRateLimitKeyPrefix+ipAddress, uc.rateLimit.MaxAttempts, uc.rateLimit.Window)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Login rate limit check failed", "ipAddress", ipAddress, "error", err)
+		return 0, false
+	}
+	return retryAfter, !allowed
+}
+
+// normalizeLoginEmail returns the form of an email used in login protection keys
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// isAccountLocked reports whether an email is locked after too many failed logins,
+// and how long until the lock ends
+// It fails open, so a Redis outage doesn't block every login
+func (uc *authUseCase) isAccountLocked(ctx context.Context, email string) (time.Duration, bool) {
+	if uc.lockout.MaxFailedAttempts <= 0 {
+		return 0, false
+	}
+
+	value, err := uc.redisClient.Get(ctx, loginLockKeyPrefix+normalizeLoginEmail(email))
+	if err != nil {
+		if !errors.Is(err, redis.ErrNil) {
+			uc.logger.WarnContext(ctx, "Account lockout check failed", "email", email, "error", err)
+		}
+		return 0, false
+	}
+
+	lockedUntil, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Invalid account lock value", "email", email, "value", value, "error", err)
+		return 0, false
+	}
+	remaining := time.Until(time.Unix(lockedUntil, 0))
+	if remaining <= 0 {
+		return 0, false
+	}
+	return remaining, true
+}
+
+// recordFailedLogin counts a failed login against the client IP address and the email
+// Once an email reaches lockout.MaxFailedAttempts failures within lockout.Duration it is locked for lockout.Duration
+func (uc *authUseCase) recordFailedLogin(ctx context.Context, email, ipAddress string) {
+	if uc.rateLimit.MaxAttempts > 0 && ipAddress != "" {
+		if _, _, err := uc.loginLimiter.Allow(ctx, loginRateLimitKeyPrefix+ipAddress, uc.rateLimit.MaxAttempts, uc.rateLimit.Window); err != nil {
+			uc.logger.WarnContext(ctx, "Failed to record failed login for rate limiting", "ipAddress", ipAddress, "error", err)
+		}
+	}
+
+	if uc.lockout.MaxFailedAttempts <= 0 {
+		return
+	}
+
+	normalized := normalizeLoginEmail(email)
+	allowed, remaining, err := uc.loginLimiter.Allow(ctx, loginFailuresKeyPrefix+normalized, uc.lockout.MaxFailedAttempts, uc.lockout.Duration)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Failed to record failed login", "email", email, "error", err)
+		return
+	}
+	if allowed && remaining > 0 {
+		return
+	}
+
+	// The lock has its own key and TTL, so it lasts the full duration however old the earlier failures are
+	lockedUntil := time.Now().Add(uc.lockout.Duration)
+	if err := uc.redisClient.Set(ctx, loginLockKeyPrefix+normalized, strconv.FormatInt(lockedUntil.Unix(), 10), uc.lockout.Duration); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to lock account", "email", email, "error", err)
+		return
+	}
+	uc.resetFailedLogins(ctx, email)
+	uc.logger.WarnContext(ctx, "Account locked after too many failed logins", "email", email, "duration", uc.lockout.Duration.String())
+}
+
+// resetFailedLogins clears the failed login window of an email, after a successful login or once it is locked
+func (uc *authUseCase) resetFailedLogins(ctx context.Context, email string) {
+	if uc.lockout.MaxFailedAttempts <= 0 {
+		return
+	}
+
+	if err := uc.redisClient.Del(ctx, redis.RateLimitKeyPrefix+loginFailuresKeyPrefix+normalizeLoginEmail(email)); err != nil {
+		uc.logger.WarnContext(ctx, "Failed to reset failed login counter", "email", email, "error", err)
+	}
+}
+
 // Refresh generates new access and refresh tokens using a valid refresh token
 // It implements fail-fast token rotation: the old refresh token must be successfully revoked
 // before new tokens are issued to prevent having both old and new tokens valid simultaneously
@@ -190,8 +356,12 @@ func (uc *authUseCase) Refresh(ctx context.Context, req agent_service.RefreshTok
 	// Validate the refresh token
 	claims, err := uc.jwtClient.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		uc.logger.WarnContext(ctx, "Invalid refresh token", "error", err)
-		return nil, errors.New("invalid refresh token")
+		if errors.Is(err, jwt.ErrRefreshTokenNotFoundOrInvalidErr) || errors.Is(err, jwt.ErrRefreshTokenNotInStoreErr) {
+			uc.logger.WarnContext(ctx, "Refresh token revoked or already used", "error", err)
+		} else {
+			uc.logger.WarnContext(ctx, "Invalid refresh token", "error", err)
+		}
+		return nil, domain.ErrInvalidRefreshToken
 	}
 
 	// Check if the user exists
@@ -204,7 +374,7 @@ func (uc *authUseCase) Refresh(ctx context.Context, req agent_service.RefreshTok
 	// Check if user is active
 	if !user.IsActive {
 		uc.logger.WarnContext(ctx, "User is not active", "userID", claims.UserID)
-		return nil, errors.New("user account is not active")
+		return nil, domain.ErrUserInactive
 	}
 
 	// Revoke the old refresh token (only in stateful mode)
@@ -219,13 +389,11 @@ func (uc *authUseCase) Refresh(ctx context.Context, req agent_service.RefreshTok
 		uc.logger.InfoContext(ctx, "Old refresh token revoked successfully", "userID", claims.UserID, "tokenID", claims.ID)
 	}
 
-	// Generate new tokens
-	var accessToken, refreshToken string
+	// Generate new tokens, keeping the authentication time of the login so a refresh does not count as re-authentication
+	var accessToken, refreshToken, sessionID string
 	if uc.jwtClient.IsStateful() {
 		// Stateful mode: Generate tokens with session tracking in Redis
-		accessToken, refreshToken, _, err = uc.jwtClient.GenerateTokensWithSession(
-			ctx, user.ID, claims.AgentID, claims.AgentType, "", "",
-		)
+		accessToken, refreshToken, sessionID, err = uc.jwtClient.ReissueTokensWithSession(ctx, claims, "", "")
 		if err != nil {
 			uc.logger.ErrorContext(ctx, "Error generating new tokens with session", "userID", user.ID, "error", err)
 			return nil, fmt.Errorf("error generating new tokens with session: %w", err)
@@ -233,13 +401,13 @@ func (uc *authUseCase) Refresh(ctx context.Context, req agent_service.RefreshTok
 		uc.logger.InfoContext(ctx, "Token refresh successful (stateful)", "userID", user.ID)
 	} else {
 		// Stateless mode: Generate tokens without session tracking
-		accessToken, err = uc.jwtClient.GenerateAccessToken(user.ID, claims.AgentID, claims.AgentType)
+		accessToken, err = uc.jwtClient.ReissueAccessToken(claims)
 		if err != nil {
 			uc.logger.ErrorContext(ctx, "Error generating new access token", "userID", user.ID, "error", err)
 			return nil, fmt.Errorf("error generating new access token: %w", err)
 		}
 
-		refreshToken, err = uc.jwtClient.GenerateRefreshToken(user.ID, claims.AgentID, claims.AgentType)
+		refreshToken, err = uc.jwtClient.ReissueRefreshToken(claims)
 		if err != nil {
 			uc.logger.ErrorContext(ctx, "Error generating new refresh token", "userID", user.ID, "error", err)
 			return nil, fmt.Errorf("error generating new refresh token: %w", err)
@@ -262,13 +430,65 @@ func (uc *authUseCase) Refresh(ctx context.Context, req agent_service.RefreshTok
 	}
 
 	return &agent_service.RefreshTokenResponse{
+		TokenType:          bearerTokenType,
 		AccessToken:        accessToken,
 		RefreshToken:       refreshToken,
 		AccessTokenExpire:  int64(time.Until(accessTokenExpire).Seconds()),
 		RefreshTokenExpire: int64(time.Until(refreshTokenExpire).Seconds()),
+		SessionID:          sessionID,
 	}, nil
 }
 
+// Logout ends a login by revoking its refresh token and, in stateful mode, ending its session
+// In stateless mode tokens cannot be revoked, so only the refresh token is validated
+func (uc *authUseCase) Logout(ctx context.Context, refreshToken, sessionID string) error {
+	uc.logger.InfoContext(ctx, "Logout attempt", "sessionID", sessionID)
+
+	claims, err := uc.jwtClient.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		uc.logger.WarnContext(ctx, "Invalid refresh token for logout", "error", err)
+		return domain.ErrInvalidRefreshToken
+	}
+	log := uc.logger.With("userID", claims.UserID)
+
+	if !uc.jwtClient.IsStateful() {
+		log.InfoContext(ctx, "Logout successful (stateless)")
+		return nil
+	}
+
+	// Check the session owner before revoking anything, so a foreign session ID cannot be used
+	if sessionID != "" {
+		session, err := uc.jwtClient.GetSession(ctx, sessionID)
+		if err != nil {
+			if errors.Is(err, jwt.ErrSessionNotFoundErr) {
+				log.WarnContext(ctx, "Session not found for logout", "sessionID", sessionID)
+				return domain.ErrSessionNotFound
+			}
+			log.ErrorContext(ctx, "Error retrieving session for logout", "sessionID", sessionID, "error", err)
+			return fmt.Errorf("error retrieving session: %w", err)
+		}
+		if session.UserID != claims.UserID {
+			log.WarnContext(ctx, "Session belongs to another user", "sessionID", sessionID)
+			return domain.ErrSessionNotFound
+		}
+	}
+
+	if err := uc.jwtClient.RevokeRefreshToken(claims.UserID, claims.ID); err != nil {
+		log.ErrorContext(ctx, "Failed to revoke refresh token", "tokenID", claims.ID, "error", err)
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if sessionID != "" {
+		if err := uc.jwtClient.EndSession(ctx, sessionID); err != nil {
+			log.ErrorContext(ctx, "Failed to end session", "sessionID", sessionID, "error", err)
+			return fmt.Errorf("failed to end session: %w", err)
+		}
+	}
+
+	log.InfoContext(ctx, "Logout successful (stateful)", "sessionID", sessionID)
+	return nil
+}
+
 // Profile retrieves the authenticated user's profile information
 // It extracts the user ID from the context and fetches the user data
 // Returns a UserResponse with user profile data, or an error if retrieval fails
@@ -276,10 +496,10 @@ func (uc *authUseCase) Profile(ctx context.Context) (*agent_service.UserResponse
 	uc.logger.InfoContext(ctx, "Profile request")
 
 	// Extract user ID from context (set by JWT middleware)
-	userID, ok := ctx.Value("user_id").(string)
-	if !ok || userID == "" {
+	userID, ok := contextkeys.UserID(ctx)
+	if !ok {
 		uc.logger.WarnContext(ctx, "User ID not found in context")
-		return nil, errors.New("unauthorized: user ID not found")
+		return nil, domain.ErrUnauthorized
 	}
 
 	// Get user by ID
@@ -297,8 +517,54 @@ func (uc *authUseCase) Profile(ctx context.Context) (*agent_service.UserResponse
 	return agent_service.UserModelToResponse(user), nil
 }
 
+// Sessions lists the active sessions of the authenticated user, one page at a time
+// It extracts the user ID from the context and pages through the sessions tracked by the JWT client
+func (uc *authUseCase) Sessions(ctx context.Context, cursor uint64, limit int) (*agent_service.SessionsResponse, error) {
+	uc.logger.InfoContext(ctx, "Sessions request")
+
+	// Extract user ID from context (set by JWT middleware)
+	userID, ok := contextkeys.UserID(ctx)
+	if !ok {
+		uc.logger.WarnContext(ctx, "User ID not found in context")
+		return nil, domain.ErrUnauthorized
+	}
+
+	if !uc.jwtClient.IsStateful() {
+		uc.logger.WarnContext(ctx, "Sessions requested in stateless mode", "userID", userID)
+		return nil, domain.ErrSessionsNotSupported
+	}
+
+	page, err := uc.jwtClient.ListUserSessions(ctx, userID, jwt.SessionListOptions{
+		Cursor:     cursor,
+		Limit:      limit,
+		ActiveOnly: true,
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Error listing sessions", "userID", userID, "error", err)
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+
+	response := &agent_service.SessionsResponse{
+		Sessions:   make([]agent_service.SessionResponse, len(page.Sessions)),
+		NextCursor: page.NextCursor,
+	}
+	for i, session := range page.Sessions {
+		response.Sessions[i] = agent_service.SessionResponse{
+			SessionID:  session.SessionID,
+			DeviceInfo: session.DeviceInfo,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeen:   session.LastSeen,
+			Status:     session.Status,
+		}
+	}
+
+	uc.logger.InfoContext(ctx, "Sessions retrieved successfully", "userID", userID, "count", len(response.Sessions))
+	return response, nil
+}
+
 // ForgotPassword initiates the password reset process for a user
-// It generates a reset token and stores it in Redis
+// It issues a single-use reset token from the Redis token store
 // It takes a context and a ForgotPasswordRequest
 // Returns a ForgotPasswordResponse with a success message, or an error
 func (uc *authUseCase) ForgotPassword(ctx context.Context, req agent_service.ForgotPasswordRequest) (*agent_service.ForgotPasswordResponse, error) {
@@ -327,23 +593,14 @@ func (uc *authUseCase) ForgotPassword(ctx context.Context, req agent_service.For
 		}, nil
 	}
 
-	// Generate reset token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
-		uc.logger.ErrorContext(ctx, "Error generating reset token", "error", err)
-		return nil, fmt.Errorf("error generating reset token: %w", err)
-	}
-	resetToken := hex.EncodeToString(tokenBytes)
-
-	// Store token in Redis with expiration (15 minutes)
-	key := "reset:" + resetToken
-	err = uc.redisClient.Set(ctx, key, user.ID, 15*time.Minute)
+	// Issue a single-use reset token
+	resetToken, err := uc.tokenStore.IssueToken(ctx, passwordResetTokenPurpose, user.ID, passwordResetTokenTTL)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Error storing reset token in Redis", "userID", user.ID, "error", err)
 		return nil, fmt.Errorf("error storing reset token: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Reset token generated and stored", "userID", user.ID, "token", resetToken)
+	uc.logger.InfoContext(ctx, "Reset token generated and stored", "userID", user.ID)
 
 	// Produce message to Kafka for email sending
 	message := agent_service.PasswordResetMessage{
@@ -356,7 +613,12 @@ func (uc *authUseCase) ForgotPassword(ctx context.Context, req agent_service.For
 		return nil, fmt.Errorf("error marshaling password reset message: %w", err)
 	}
 
-	err = uc.kafkaClient.Produce(ctx, uc.passwordResetTopic, messageBytes)
+	// Key by user ID so all password reset events of a user land on the same partition
+	var headers map[string]string
+	if correlationID := logger.CorrelationID(ctx); correlationID != "" {
+		headers = map[string]string{correlationIDHeader: correlationID}
+	}
+	err = uc.kafkaClient.ProduceWithKey(ctx, uc.passwordResetTopic, []byte(user.ID), messageBytes, headers)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Error producing password reset message to Kafka", "userID", user.ID, "error", err)
 		return nil, fmt.Errorf("error producing password reset message: %w", err)
@@ -372,23 +634,31 @@ func (uc *authUseCase) ForgotPassword(ctx context.Context, req agent_service.For
 }
 
 // ResetPassword resets the user's password using a valid reset token
-// It validates the token, updates the password, and removes the token from Redis
+// It atomically consumes the reset token and updates the password
 // It takes a context and a ResetPasswordRequest
 // Returns a ResetPasswordResponse with a success message, or an error
 func (uc *authUseCase) ResetPassword(ctx context.Context, req agent_service.ResetPasswordRequest) (*agent_service.ResetPasswordResponse, error) {
 	uc.logger.InfoContext(ctx, "Reset password request")
 
-	// Get user ID from Redis
-	key := "reset:" + req.Token
-	userID, err := uc.redisClient.Get(ctx, key)
+	// Consume the reset token so it cannot be used again
+	userID, err := uc.tokenStore.ConsumeToken(ctx, passwordResetTokenPurpose, req.Token)
 	if err != nil {
-		uc.logger.WarnContext(ctx, "Invalid or expired reset token", "token", req.Token)
-		return nil, errors.New("invalid or expired reset token")
+		if errors.Is(err, redis.ErrTokenNotFound) {
+			uc.logger.WarnContext(ctx, "Invalid or expired reset token")
+			return nil, domain.ErrInvalidResetToken
+		}
+		uc.logger.ErrorContext(ctx, "Error consuming reset token", "error", err)
+		return nil, fmt.Errorf("error consuming reset token: %w", err)
 	}
 
 	// Get user by ID
 	user, err := uc.userRepo.GetByID(ctx, userID)
 	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// The user was deleted after the token was issued
+			uc.logger.WarnContext(ctx, "User not found for reset password", "userID", userID)
+			return nil, domain.ErrInvalidResetToken
+		}
 		uc.logger.ErrorContext(ctx, "Error retrieving user for reset password", "userID", userID, "error", err)
 		return nil, fmt.Errorf("error retrieving user: %w", err)
 	}
@@ -396,7 +666,7 @@ func (uc *authUseCase) ResetPassword(ctx context.Context, req agent_service.Rese
 	// Check if user is active
 	if !user.IsActive {
 		uc.logger.WarnContext(ctx, "User is not active for reset password", "userID", userID)
-		return nil, errors.New("user account is not active")
+		return nil, domain.ErrUserInactive
 	}
 
 	// Hash the new password
@@ -413,13 +683,6 @@ func (uc *authUseCase) ResetPassword(ctx context.Context, req agent_service.Rese
 		return nil, fmt.Errorf("error updating password: %w", err)
 	}
 
-	// Delete the reset token from Redis
-	err = uc.redisClient.Del(ctx, key)
-	if err != nil {
-		uc.logger.WarnContext(ctx, "Error deleting reset token from Redis", "userID", userID, "error", err)
-		// Don't fail the operation for this
-	}
-
 	uc.logger.InfoContext(ctx, "Password reset successful", "userID", userID)
 	return &agent_service.ResetPasswordResponse{
 		Message: "Password has been reset successfully",
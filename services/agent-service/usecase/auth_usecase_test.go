@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/domain/repository"
+	"monorepo/contracts/agent_service"
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/logger"
+	"monorepo/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testEmail    = "agent@example.com"
+	testPassword = "correct-password"
+	testIP       = "203.0.113.7"
+)
+
+// fakeUserRepo is an in-memory user repository supporting lookups by email
+// Methods not overridden panic through the nil embedded interface
+type fakeUserRepo struct {
+	repository.User
+	users map[string]*model.User
+}
+
+func (r *fakeUserRepo) GetByEmail(_ context.Context, email string) (*model.User, error) {
+	user, ok := r.users[email]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// fakeJWTClient issues fixed stateless tokens
+// Methods not overridden panic through the nil embedded interface
+type fakeJWTClient struct {
+	jwt.JWTClient
+}
+
+func (c *fakeJWTClient) IsStateful() bool { return false }
+
+func (c *fakeJWTClient) GenerateAccessToken(_, _, _ string) (string, error) { return "access", nil }
+
+func (c *fakeJWTClient) GenerateRefreshToken(_, _, _ string) (string, error) { return "refresh", nil }
+
+func (c *fakeJWTClient) GetTokenExpiration(string) (time.Time, error) {
+	return time.Now().Add(time.Hour), nil
+}
+
+// newTestAuthUseCase returns an auth usecase backed by miniredis with a single active user
+func newTestAuthUseCase(t *testing.T, rateLimit LoginRateLimitConfig, lockout LoginLockoutConfig) (AuthUseCase, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	redisClient, err := redis.New(redis.WithAddrs([]string{server.Addr()}))
+	require.NoError(t, err, "Failed to create Redis client")
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	require.NoError(t, err, "Failed to hash password")
+	userRepo := &fakeUserRepo{users: map[string]*model.User{
+		testEmail: {ID: "user-1", Email: testEmail, Password: string(hash), IsActive: true},
+	}}
+
+	uc := NewAuthUseCase(userRepo, nil, &fakeJWTClient{}, redisClient, nil, "", rateLimit, lockout, logger.NoOpLogger())
+	return uc, server
+}
+
+func login(uc AuthUseCase, password string) error {
+	_, err := uc.Login(context.Background(), agent_service.LoginRequest{Email: testEmail, Password: password}, "test", testIP)
+	return err
+}
+
+func TestLogin_AccountLockout(t *testing.T) {
+	lockout := LoginLockoutConfig{MaxFailedAttempts: 3, Duration: 15 * time.Minute}
+	uc, server := newTestAuthUseCase(t, LoginRateLimitConfig{}, lockout)
+
+	for i := 0; i < lockout.MaxFailedAttempts; i++ {
+		assert.ErrorIs(t, login(uc, "wrong-password"), domain.ErrInvalidCredentials, "Wrong password should be rejected")
+	}
+
+	err := login(uc, testPassword)
+	require.ErrorIs(t, err, domain.ErrAccountLocked, "The account should be locked after too many failures")
+	var retryErr *domain.RetryAfterError
+	require.True(t, errors.As(err, &retryErr), "The lockout should carry a retry delay")
+	assert.InDelta(t, lockout.Duration.Seconds(), retryErr.RetryAfter.Seconds(), 2, "Retry should wait for the remaining lock time")
+	assert.Equal(t, lockout.Duration, server.TTL(loginLockKeyPrefix+testEmail), "The lock should have its own TTL")
+
+	server.FastForward(lockout.Duration)
+	assert.NoError(t, login(uc, testPassword), "The account should unlock once the lock expires")
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	lockout := LoginLockoutConfig{MaxFailedAttempts: 3, Duration: 15 * time.Minute}
+	uc, _ := newTestAuthUseCase(t, LoginRateLimitConfig{}, lockout)
+
+	for round := 0; round < 2; round++ {
+		for i := 0; i < lockout.MaxFailedAttempts-1; i++ {
+			assert.ErrorIs(t, login(uc, "wrong-password"), domain.ErrInvalidCredentials)
+		}
+		assert.NoError(t, login(uc, testPassword), "A successful login should clear earlier failures")
+	}
+}
+
+func TestLogin_RateLimitCountsOnlyFailures(t *testing.T) {
+	rateLimit := LoginRateLimitConfig{MaxAttempts: 2, Window: 15 * time.Minute}
+	uc, _ := newTestAuthUseCase(t, rateLimit, LoginLockoutConfig{})
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, login(uc, testPassword), "Successful logins should not be throttled")
+	}
+
+	for i := 0; i < rateLimit.MaxAttempts; i++ {
+		assert.ErrorIs(t, login(uc, "wrong-password"), domain.ErrInvalidCredentials)
+	}
+
+	err := login(uc, testPassword)
+	require.ErrorIs(t, err, domain.ErrTooManyLoginAttempts, "The IP should be throttled after too many failures")
+	var retryErr *domain.RetryAfterError
+	require.True(t, errors.As(err, &retryErr), "The throttle should carry a retry delay")
+	assert.Positive(t, retryErr.RetryAfter, "Retry delay should be positive")
+	assert.LessOrEqual(t, retryErr.RetryAfter, rateLimit.Window, "Retry should wait at most the window")
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"agent-service/domain"
 	"agent-service/domain/model"
 	"agent-service/domain/repository"
+	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
 
 	"golang.org/x/crypto/bcrypt"
@@ -17,11 +19,14 @@ import (
 // UserUseCase defines business operations for users
 type UserUseCase interface {
 	CreateUser(ctx context.Context, user *model.User) error
+	BulkCreateUsers(ctx context.Context, users []*model.User) (model.BulkResult, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
+	GetUserByIDWithOptions(ctx context.Context, id string, opts repository.GetByIDOptions) (*model.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	UpdateUser(ctx context.Context, user *model.User) error
 	UpdateUserStatus(ctx context.Context, id string, isActive bool) error
 	DeleteUser(ctx context.Context, id string) error
+	RestoreUser(ctx context.Context, id string) error
 	GetUsersByAgentID(ctx context.Context, agentID string) ([]*model.User, error)
 	GetActiveUsers(ctx context.Context) ([]*model.User, error)
 	ListUsers(ctx context.Context, offset, limit int) ([]*model.User, int, error)
@@ -31,6 +36,8 @@ type UserUseCase interface {
 type userUseCase struct {
 	// userRepo is the repository interface for user database operations
 	userRepo repository.User
+	// jwtClient is used to revoke tokens and end sessions of deactivated users
+	jwtClient jwt.JWTClient
 	// logger is used for logging operations within the usecase
 	logger logger.LoggerInterface
 }
@@ -45,10 +52,37 @@ func hashPassword(password string) (string, error) {
 }
 
 // NewUserUseCase creates a new instance of userUseCase
-func NewUserUseCase(userRepo repository.User, appLogger logger.LoggerInterface) UserUseCase {
+func NewUserUseCase(userRepo repository.User, jwtClient jwt.JWTClient, appLogger logger.LoggerInterface) UserUseCase {
 	return &userUseCase{
-		userRepo: userRepo,
-		logger:   appLogger,
+		userRepo:  userRepo,
+		jwtClient: jwtClient,
+		logger:    appLogger,
+	}
+}
+
+// logoutEverywhere revokes all refresh tokens and ends all sessions of a user
+// It runs after the user change is committed, so failures are logged rather than returned:
+// the change has already taken effect and reporting it as failed would invite a retry
+// It is a no-op when the JWT client is stateless, since there is nothing to revoke
+func (uc *userUseCase) logoutEverywhere(ctx context.Context, id string) {
+	log := uc.logger.With("id", id)
+	if uc.jwtClient == nil || !uc.jwtClient.IsStateful() {
+		return
+	}
+
+	// Sessions are ended even if revoking fails, so as much as possible is logged out
+	revokeErr := uc.jwtClient.RevokeAllRefreshTokens(id)
+	if revokeErr != nil {
+		log.ErrorContext(ctx, "Failed to revoke refresh tokens", "error", revokeErr)
+	}
+
+	endErr := uc.jwtClient.EndAllUserSessions(ctx, id)
+	if endErr != nil {
+		log.ErrorContext(ctx, "Failed to end user sessions", "error", endErr)
+	}
+
+	if revokeErr == nil && endErr == nil {
+		log.InfoContext(ctx, "User logged out of all sessions")
 	}
 }
 
@@ -92,72 +126,126 @@ func (uc *userUseCase) CreateUser(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+// BulkCreateUsers creates a batch of users, one row at a time
+// A rejected row does not stop the import; it is reported in the result with its reason
+// Emails repeated within the batch are rejected after their first occurrence
+// Only a cancelled context aborts the import, returning the rows processed so far
+func (uc *userUseCase) BulkCreateUsers(ctx context.Context, users []*model.User) (model.BulkResult, error) {
+	log := uc.logger.With("count", len(users))
+	log.InfoContext(ctx, "Bulk creating users in usecase")
+
+	result := model.BulkResult{}
+	seen := make(map[string]bool, len(users))
+	for i, user := range users {
+		if err := ctx.Err(); err != nil {
+			log.WarnContext(ctx, "Bulk user creation cancelled", "processed", i, "error", err)
+			return result, err
+		}
+
+		email := strings.ToLower(user.Email)
+		if email != "" && seen[email] {
+			log.WarnContext(ctx, "Duplicate email in bulk user batch", "index", i, "email", user.Email)
+			result.Failed = append(result.Failed, model.BulkFailure{Index: i, Email: user.Email, Reason: domain.ErrEmailAlreadyExists.Message})
+			continue
+		}
+		seen[email] = true
+
+		if err := uc.CreateUser(ctx, user); err != nil {
+			var appErr *domain.AppError
+			switch {
+			case domain.IsContextError(err):
+				log.WarnContext(ctx, "Bulk user creation cancelled", "processed", i, "error", err)
+				return result, err
+			case errors.As(err, &appErr):
+				result.Failed = append(result.Failed, model.BulkFailure{Index: i, Email: user.Email, Reason: appErr.Message})
+			default:
+				log.ErrorContext(ctx, "Failed to create user in bulk import", "index", i, "email", user.Email, "error", err)
+				result.Failed = append(result.Failed, model.BulkFailure{Index: i, Email: user.Email, Reason: "failed to create user"})
+			}
+			continue
+		}
+		result.Created = append(result.Created, user)
+	}
+
+	log.InfoContext(ctx, "Bulk user creation completed in usecase", "created", len(result.Created), "failed", len(result.Failed))
+	return result, nil
+}
+
 // GetUserByID retrieves a user by ID
 func (uc *userUseCase) GetUserByID(ctx context.Context, id string) (*model.User, error) {
-	uc.logger.InfoContext(ctx, "Getting user by ID in usecase", "id", id)
+	return uc.GetUserByIDWithOptions(ctx, id, repository.GetByIDOptions{})
+}
+
+// GetUserByIDWithOptions retrieves a user by ID, optionally including deactivated and soft-deleted users
+// It is meant for admin flows, such as reactivating an account
+func (uc *userUseCase) GetUserByIDWithOptions(ctx context.Context, id string, opts repository.GetByIDOptions) (*model.User, error) {
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Getting user by ID in usecase", "includeInactive", opts.IncludeInactive, "includeDeleted", opts.IncludeDeleted)
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid user ID provided", "id", id)
+		log.WarnContext(ctx, "Invalid user ID provided")
 		return nil, domain.ErrInvalidID
 	}
 
-	user, err := uc.userRepo.GetByID(ctx, id)
+	user, err := uc.userRepo.GetByIDWithOptions(ctx, id, opts)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "User not found by ID", "id", id)
+			log.WarnContext(ctx, "User not found by ID")
 			return nil, domain.ErrUserNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error getting user by ID", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error getting user by ID", "error", err)
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "User retrieved by ID in usecase", "id", user.ID, "email", user.Email)
+	log.InfoContext(ctx, "User retrieved by ID in usecase", "email", user.Email)
 	return user, nil
 }
 
 // GetUserByEmail retrieves a user by email
 func (uc *userUseCase) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	uc.logger.InfoContext(ctx, "Getting user by email in usecase", "email", email)
+	log := uc.logger.With("email", email)
+	log.InfoContext(ctx, "Getting user by email in usecase")
 	if email == "" {
-		uc.logger.WarnContext(ctx, "Email is required for user lookup")
+		log.WarnContext(ctx, "Email is required for user lookup")
 		return nil, domain.ErrEmailRequired
 	}
 
 	user, err := uc.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "User not found by email", "email", email)
+			log.WarnContext(ctx, "User not found by email")
 			return nil, domain.ErrUserNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error getting user by email", "email", email, "error", err)
+		log.ErrorContext(ctx, "Error getting user by email", "error", err)
 		return nil, fmt.Errorf("error getting user by email: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "User retrieved by email in usecase", "id", user.ID, "email", user.Email)
+	log.InfoContext(ctx, "User retrieved by email in usecase", "id", user.ID)
 	return user, nil
 }
 
 // UpdateUser updates an existing user
 func (uc *userUseCase) UpdateUser(ctx context.Context, user *model.User) error {
-	uc.logger.InfoContext(ctx, "Updating user in usecase", "id", user.ID, "email", user.Email)
+	log := uc.logger.With("id", user.ID)
+	log.InfoContext(ctx, "Updating user in usecase", "email", user.Email)
 	if user.ID == "" {
-		uc.logger.WarnContext(ctx, "Invalid user ID for update", "id", user.ID)
+		log.WarnContext(ctx, "Invalid user ID for update")
 		return domain.ErrInvalidID
 	}
 
 	if user.Email == "" {
-		uc.logger.WarnContext(ctx, "Email is required for user update", "id", user.ID)
+		log.WarnContext(ctx, "Email is required for user update")
 		return domain.ErrEmailRequired
 	}
 
 	// Check if user with email already exists (excluding current user)
 	existingUser, err := uc.userRepo.GetByEmail(ctx, user.Email)
 	if err != nil && !errors.Is(err, domain.ErrNotFound) {
-		uc.logger.ErrorContext(ctx, "Error checking existing user during update", "email", user.Email, "error", err)
+		log.ErrorContext(ctx, "Error checking existing user during update", "email", user.Email, "error", err)
 		return fmt.Errorf("error checking existing user: %w", err)
 	}
 
 	if existingUser != nil && existingUser.ID != user.ID {
-		uc.logger.WarnContext(ctx, "Email already exists for another user", "email", user.Email, "existing_id", existingUser.ID, "update_id", user.ID)
+		log.WarnContext(ctx, "Email already exists for another user", "email", user.Email, "existing_id", existingUser.ID, "update_id", user.ID)
 		return domain.ErrEmailAlreadyExists
 	}
 
@@ -165,71 +253,110 @@ func (uc *userUseCase) UpdateUser(ctx context.Context, user *model.User) error {
 	if user.Password != "" {
 		hashedPassword, err := hashPassword(user.Password)
 		if err != nil {
-			uc.logger.ErrorContext(ctx, "Failed to hash password during update", "id", user.ID, "error", err)
+			log.ErrorContext(ctx, "Failed to hash password during update", "error", err)
 			return fmt.Errorf("failed to hash password: %w", err)
 		}
 		user.Password = hashedPassword
 	}
 
 	if err := uc.userRepo.Update(ctx, user); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to update user in repository", "id", user.ID, "email", user.Email, "error", err)
+		log.ErrorContext(ctx, "Failed to update user in repository", "email", user.Email, "error", err)
 		return err
 	}
 
-	uc.logger.InfoContext(ctx, "User updated successfully in usecase", "id", user.ID, "email", user.Email)
+	// Force logout on every device when the account is deactivated
+	if !user.IsActive {
+		uc.logoutEverywhere(ctx, user.ID)
+	}
+
+	log.InfoContext(ctx, "User updated successfully in usecase", "email", user.Email)
 	return nil
 }
 
 // UpdateUserStatus updates user status
 func (uc *userUseCase) UpdateUserStatus(ctx context.Context, id string, isActive bool) error {
-	uc.logger.InfoContext(ctx, "Updating user status in usecase", "id", id, "isActive", isActive)
+	log := uc.logger.With("id", id, "isActive", isActive)
+	log.InfoContext(ctx, "Updating user status in usecase")
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid user ID for status update", "id", id)
+		log.WarnContext(ctx, "Invalid user ID for status update")
 		return domain.ErrInvalidID
 	}
 
-	// Get existing user
-	user, err := uc.userRepo.GetByID(ctx, id)
+	// Get existing user, including deactivated ones so they can be reactivated
+	user, err := uc.userRepo.GetByIDWithOptions(ctx, id, repository.GetByIDOptions{IncludeInactive: true})
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "User not found for status update", "id", id)
+			log.WarnContext(ctx, "User not found for status update")
 			return domain.ErrUserNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error getting user for status update", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error getting user for status update", "error", err)
 		return fmt.Errorf("error getting user: %w", err)
 	}
 
-	// Update the status
+	if err := uc.userRepo.UpdateStatus(ctx, user.ID, isActive); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			log.WarnContext(ctx, "User not found for status update")
+			return domain.ErrUserNotFound
+		}
+		log.ErrorContext(ctx, "Failed to update user status in repository", "error", err)
+		return err
+	}
 	user.IsActive = isActive
 
-	if err := uc.userRepo.Update(ctx, user); err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to update user status in repository", "id", user.ID, "isActive", isActive, "error", err)
-		return err
+	// Force logout on every device when the account is deactivated
+	if !isActive {
+		uc.logoutEverywhere(ctx, user.ID)
 	}
 
-	uc.logger.InfoContext(ctx, "User status updated successfully in usecase", "id", user.ID, "isActive", isActive)
+	log.InfoContext(ctx, "User status updated successfully in usecase")
 	return nil
 }
 
 // DeleteUser deletes a user
 func (uc *userUseCase) DeleteUser(ctx context.Context, id string) error {
-	uc.logger.InfoContext(ctx, "Deleting user in usecase", "id", id)
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Deleting user in usecase")
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid user ID for deletion", "id", id)
+		log.WarnContext(ctx, "Invalid user ID for deletion")
 		return domain.ErrInvalidID
 	}
 
 	err := uc.userRepo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "User not found for deletion", "id", id)
+			log.WarnContext(ctx, "User not found for deletion")
 			return domain.ErrUserNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error deleting user", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error deleting user", "error", err)
 		return fmt.Errorf("error deleting user: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "User deleted successfully in usecase", "id", id)
+	uc.logoutEverywhere(ctx, id)
+
+	log.InfoContext(ctx, "User deleted successfully in usecase")
+	return nil
+}
+
+// RestoreUser recovers a soft-deleted user
+// The user keeps its previous status, so a restored account may still need to be activated
+func (uc *userUseCase) RestoreUser(ctx context.Context, id string) error {
+	log := uc.logger.With("id", id)
+	log.InfoContext(ctx, "Restoring user in usecase")
+	if id == "" {
+		log.WarnContext(ctx, "Invalid user ID for restore")
+		return domain.ErrInvalidID
+	}
+
+	if err := uc.userRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			log.WarnContext(ctx, "Deleted user not found for restore")
+			return domain.ErrUserNotFound
+		}
+		log.ErrorContext(ctx, "Error restoring user", "error", err)
+		return fmt.Errorf("error restoring user: %w", err)
+	}
+
+	log.InfoContext(ctx, "User restored successfully in usecase")
 	return nil
 }
 
@@ -258,19 +385,20 @@ func (uc *userUseCase) ListUsers(ctx context.Context, offset, limit int) ([]*mod
 
 // GetUsersByAgentID retrieves users by agent ID
 func (uc *userUseCase) GetUsersByAgentID(ctx context.Context, agentID string) ([]*model.User, error) {
-	uc.logger.InfoContext(ctx, "Getting users by agent ID in usecase", "agentID", agentID)
+	log := uc.logger.With("agentID", agentID)
+	log.InfoContext(ctx, "Getting users by agent ID in usecase")
 	if agentID == "" {
-		uc.logger.WarnContext(ctx, "Agent ID is required for user lookup by agent")
+		log.WarnContext(ctx, "Agent ID is required for user lookup by agent")
 		return nil, domain.ErrInvalidID
 	}
 
 	users, err := uc.userRepo.GetByAgentID(ctx, agentID)
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error getting users by agent ID", "agentID", agentID, "error", err)
+		log.ErrorContext(ctx, "Error getting users by agent ID", "error", err)
 		return nil, fmt.Errorf("error getting users by agent ID: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Users retrieved by agent ID in usecase", "count", len(users), "agentID", agentID)
+	log.InfoContext(ctx, "Users retrieved by agent ID in usecase", "count", len(users))
 	return users, nil
 }
 
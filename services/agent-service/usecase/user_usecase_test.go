@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"agent-service/domain"
+	"agent-service/domain/model"
+	"agent-service/domain/repository"
+	"monorepo/pkg/jwt"
+	"monorepo/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeUserRepo) GetByIDWithOptions(_ context.Context, id string, _ repository.GetByIDOptions) (*model.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user *model.User) error {
+	copied := *user
+	r.users[user.Email] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateStatus(_ context.Context, id string, isActive bool) error {
+	for _, user := range r.users {
+		if user.ID == id {
+			user.IsActive = isActive
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *fakeUserRepo) Delete(_ context.Context, id string) error {
+	for email, user := range r.users {
+		if user.ID == id {
+			delete(r.users, email)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// failingLogoutJWTClient is a stateful JWT client whose token store is unavailable
+// Methods not overridden panic through the nil embedded interface
+type failingLogoutJWTClient struct {
+	jwt.JWTClient
+	revoked, ended []string
+}
+
+func (c *failingLogoutJWTClient) IsStateful() bool { return true }
+
+func (c *failingLogoutJWTClient) RevokeAllRefreshTokens(userID string) error {
+	c.revoked = append(c.revoked, userID)
+	return errors.New("redis unavailable")
+}
+
+func (c *failingLogoutJWTClient) EndAllUserSessions(_ context.Context, userID string) error {
+	c.ended = append(c.ended, userID)
+	return errors.New("redis unavailable")
+}
+
+func TestUserUseCase_LogoutFailureAfterCommit(t *testing.T) {
+	newUseCase := func() (UserUseCase, *fakeUserRepo, *failingLogoutJWTClient) {
+		userRepo := &fakeUserRepo{users: map[string]*model.User{
+			testEmail: {ID: "user-1", Email: testEmail, IsActive: true},
+		}}
+		jwtClient := &failingLogoutJWTClient{}
+		return NewUserUseCase(userRepo, jwtClient, logger.NoOpLogger()), userRepo, jwtClient
+	}
+
+	t.Run("deactivation succeeds", func(t *testing.T) {
+		uc, userRepo, jwtClient := newUseCase()
+
+		require.NoError(t, uc.UpdateUserStatus(context.Background(), "user-1", false), "The committed status change should be reported as done")
+		assert.False(t, userRepo.users[testEmail].IsActive, "The user should be deactivated")
+		assert.Equal(t, []string{"user-1"}, jwtClient.revoked, "Refresh tokens should be revoked")
+		assert.Equal(t, []string{"user-1"}, jwtClient.ended, "Sessions should be ended even if revoking failed")
+	})
+
+	t.Run("deletion succeeds", func(t *testing.T) {
+		uc, userRepo, jwtClient := newUseCase()
+
+		require.NoError(t, uc.DeleteUser(context.Background(), "user-1"), "The committed deletion should be reported as done")
+		assert.Empty(t, userRepo.users, "The user should be deleted")
+		assert.Equal(t, []string{"user-1"}, jwtClient.ended, "Sessions should be ended")
+	})
+}
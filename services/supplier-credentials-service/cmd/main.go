@@ -4,13 +4,11 @@ package main
 
 import (
 	"context"
-	"net/http"
 	"os"
-	"os/signal"
-	"strconv"
-	"syscall"
 	"time"
 
+	"monorepo/pkg/bootstrap"
+	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
 	"monorepo/pkg/postgres"
 	"supplier-credentials-service/config"
@@ -20,18 +18,20 @@ import (
 	"supplier-credentials-service/usecase"
 )
 
+// encryptionKeyCheckTimeout bounds the startup check of the encryption key against stored credentials
+const encryptionKeyCheckTimeout = 10 * time.Second
+
 // main is the entry point of the application
 // It performs the following steps:
 // 1. Initializes the logger
 // 2. Loads configuration from files or environment variables
-// 3. Sets up the database connection
-// 4. Runs database migrations
-// 5. Initializes the repository, usecase, and handler layers
-// 6. Sets up HTTP routes
-// 7. Starts the HTTP server with graceful shutdown
+// 3. Sets up the database connection, migrations and JWT validation via bootstrap
+// 4. Initializes the repository, usecase, and handler layers, and verifies the encryption key
+// 5. Sets up HTTP routes
+// 6. Starts the HTTP server with graceful shutdown
 func main() {
-	// configure logger
-	appLogger := logger.NewJSONDefault()
+	// configure logger, redacting secrets such as passwords and tokens from log attributes
+	appLogger := logger.NewWithOptions(logger.WithJSONFormat(), logger.WithRedactKeys())
 
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -40,98 +40,97 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize PostgreSQL client
-	postgresClient, err := postgres.NewPostgresClient(postgres.Config{
-		Host:            cfg.Infrastructure.Postgres.Host,
-		Port:            cfg.Infrastructure.Postgres.Port,
-		User:            cfg.Infrastructure.Postgres.User,
-		Password:        cfg.Infrastructure.Postgres.Password,
-		DBName:          cfg.Infrastructure.Postgres.DBName,
-		Schema:          cfg.Infrastructure.Postgres.Schema,
-		SSLMode:         cfg.Infrastructure.Postgres.SSLMode,
-		MaxIdleConns:    cfg.Infrastructure.Postgres.MaxIdleConns,
-		MaxOpenConns:    cfg.Infrastructure.Postgres.MaxOpenConns,
-		ConnMaxIdleTime: cfg.Infrastructure.Postgres.ConnMaxIdleTime,
-		ConnMaxLifetime: cfg.Infrastructure.Postgres.ConnMaxLifetime,
-		Debug:           cfg.Infrastructure.Postgres.Debug,
-	})
+	// Apply the configured log level; it can be changed again at runtime with SetLevel
+	logLevel, err := cfg.Application.SlogLevel()
 	if err != nil {
-		appLogger.Error("Failed to connect to database", "error", err)
+		appLogger.Error("Failed to parse log level", "error", err)
 		os.Exit(1)
 	}
+	appLogger.SetLevel(logLevel)
 
-	if cfg.Infrastructure.Postgres.IsUseMigrate {
-		// Run database migrations
-		err = postgresClient.Migrate(
-			&model.Supplier{},
-			&model.AgentSupplierCredential{},
-		)
-		if err != nil {
-			appLogger.Error("Failed to migrate database", "error", err)
-			os.Exit(1)
-		}
+	// Initialize infrastructure
+	app, err := bootstrap.New(bootstrapConfig(cfg), appLogger)
+	if err != nil {
+		appLogger.Error("Failed to initialize infrastructure", "error", err)
+		os.Exit(1)
 	}
 
 	// Initialize repository
-	supplierRepo := pgRepository.NewSupplierRepository(postgresClient.GetDB(), appLogger)
-	credentialRepo := pgRepository.NewCredentialRepository(postgresClient.GetDB(), appLogger)
+	supplierRepo := pgRepository.NewSupplierRepository(app.Postgres.GetDB(), appLogger)
+	credentialRepo := pgRepository.NewCredentialRepository(app.Postgres.GetDB(), appLogger)
 
 	// Initialize usecase
 	supplierUsecase := usecase.NewSupplierUseCase(supplierRepo, appLogger)
-	credentialUsecase := usecase.NewCredentialUseCase(credentialRepo, supplierUsecase, appLogger, cfg.Security.Encryption.Key)
+	credentialUsecase := usecase.NewCredentialUseCase(credentialRepo, supplierUsecase, appLogger,
+		usecase.NewKeyring(cfg.Security.Encryption.KeyID, cfg.Security.Encryption.Key, cfg.Security.Encryption.PreviousKeys))
+
+	// Fail fast on a wrong encryption key instead of serving decrypt errors on every read
+	verifyCtx, cancel := context.WithTimeout(context.Background(), encryptionKeyCheckTimeout)
+	err = credentialUsecase.VerifyEncryptionKey(verifyCtx)
+	cancel()
+	if err != nil {
+		appLogger.Error("Encryption key verification failed", "error", err)
+		_ = app.Close(context.Background())
+		os.Exit(1)
+	}
 
 	// Initialize handlers
 	credentialHandler := httpDelivery.NewCredentialHandler(credentialUsecase, appLogger)
 	supplierHandler := httpDelivery.NewSupplierHandler(supplierUsecase, appLogger)
-	healthHandler := httpDelivery.NewHealthHandler(appLogger)
+	healthHandler := httpDelivery.NewHealthHandler(appLogger, app.Postgres)
 
 	// Initialize router
-	router := httpDelivery.NewRouter(credentialHandler, supplierHandler, healthHandler, appLogger)
+	router := httpDelivery.NewRouter(credentialHandler, supplierHandler, healthHandler, app.JWT, cfg.Server.MaxBodySize, appLogger)
 
-	// Setup routes
-	httpHandler := router.SetupRoutes()
-
-	// Start server
-	server := &http.Server{
-		Addr:         ":" + strconv.Itoa(cfg.Server.Port),
-		Handler:      httpHandler,
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
-	}
-
-	// Create channel to listen for interrupt signal
-	quit := make(chan os.Signal, 1)
-
-	// Register the channel to receive specific signals
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	// Start HTTP server in a separate goroutine
-	go func() {
-		appLogger.Info("Service starting", "name", cfg.Application.Name, "version", cfg.Application.Version, "port", cfg.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			appLogger.Error("Failed to start server", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	// Block until a signal is received
-	<-quit
-	appLogger.Info("Shutting down server...")
-
-	// Create a context with timeout for graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
-	defer cancel()
-
-	// Shutdown the server gracefully
-	if err := server.Shutdown(ctx); err != nil {
-		appLogger.Error("Server forced to shutdown", "error", err)
+	// Start server and block until shutdown
+	if err := app.Run(router.SetupRoutes()); err != nil {
+		appLogger.Error("Server exited with error", "error", err)
 		os.Exit(1)
 	}
+}
 
-	// Close database connection
-	if err := postgresClient.Close(); err != nil {
-		appLogger.Warn("Error closing database connection", "error", err)
+// bootstrapConfig maps the service configuration to the shared infrastructure configuration
+func bootstrapConfig(cfg *config.Config) bootstrap.Config {
+	return bootstrap.Config{
+		Name:    cfg.Application.Name,
+		Version: cfg.Application.Version,
+		Server: bootstrap.ServerConfig{
+			Port:            cfg.Server.Port,
+			ReadTimeout:     time.Duration(cfg.Server.ReadTimeout) * time.Second,
+			WriteTimeout:    time.Duration(cfg.Server.WriteTimeout) * time.Second,
+			ShutdownTimeout: time.Duration(cfg.Server.ShutdownTimeout) * time.Second,
+			Protocols:       cfg.Server.Protocols(),
+			TLSCertFile:     cfg.Server.TLSCertFile,
+			TLSKeyFile:      cfg.Server.TLSKeyFile,
+		},
+		Postgres: &postgres.Config{
+			Host:               cfg.Infrastructure.Postgres.Host,
+			Port:               cfg.Infrastructure.Postgres.Port,
+			User:               cfg.Infrastructure.Postgres.User,
+			Password:           cfg.Infrastructure.Postgres.Password,
+			DBName:             cfg.Infrastructure.Postgres.DBName,
+			Schema:             cfg.Infrastructure.Postgres.Schema,
+			SSLMode:            cfg.Infrastructure.Postgres.SSLMode,
+			MaxIdleConns:       cfg.Infrastructure.Postgres.MaxIdleConns,
+			MaxOpenConns:       cfg.Infrastructure.Postgres.MaxOpenConns,
+			ConnMaxIdleTime:    cfg.Infrastructure.Postgres.ConnMaxIdleTime,
+			ConnMaxLifetime:    cfg.Infrastructure.Postgres.ConnMaxLifetime,
+			Debug:              cfg.Infrastructure.Postgres.Debug,
+			DebugHideParams:    cfg.Infrastructure.Postgres.DebugHideParams,
+			SlowQueryThreshold: time.Duration(cfg.Infrastructure.Postgres.SlowQueryThreshold) * time.Millisecond,
+			ReplicaDSNs:        cfg.Infrastructure.Postgres.ReplicaDSNs,
+			ConnectRetries:     cfg.Infrastructure.Postgres.ConnectRetries,
+			ConnectRetryDelay:  time.Duration(cfg.Infrastructure.Postgres.ConnectRetryDelay) * time.Second,
+		},
+		// Access tokens are only validated here, so the client stays stateless
+		JWT: &jwt.TokenConfig{
+			AccessTokenSecret:  cfg.Security.JWT.AccessTokenSecret,
+			RefreshTokenSecret: cfg.Security.JWT.RefreshTokenSecret,
+		},
+		Migrate: cfg.Infrastructure.Postgres.IsUseMigrate,
+		Models: []any{
+			&model.Supplier{},
+			&model.AgentSupplierCredential{},
+		},
 	}
-
-	appLogger.Info("Server exited")
 }
@@ -3,7 +3,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -28,6 +32,17 @@ type ApplicationConfig struct {
 	Name string `mapstructure:"name"`
 	// Version specifies the version of the application
 	Version string `mapstructure:"version"`
+	// LogLevel specifies the minimum log level: debug, info, warn or error
+	LogLevel string `mapstructure:"log_level"`
+}
+
+// SlogLevel parses LogLevel into a slog level
+func (c ApplicationConfig) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", c.LogLevel, err)
+	}
+	return level, nil
 }
 
 // ServerConfig holds the server configuration
@@ -41,6 +56,31 @@ type ServerConfig struct {
 	WriteTimeout int `mapstructure:"write_timeout"` // seconds
 	// ShutdownTimeout defines the maximum duration the server will wait for active connections to finish during shutdown, in seconds
 	ShutdownTimeout int `mapstructure:"shutdown_timeout"` // seconds
+	// MaxBodySize is the maximum size of request bodies in bytes; larger requests are rejected with a 413
+	MaxBodySize int64 `mapstructure:"max_body_size"` // in bytes
+	// EnableH2C enables HTTP/2 over cleartext (h2c), e.g. behind proxies that speak HTTP/2 to the backend
+	EnableH2C bool `mapstructure:"enable_h2c"`
+	// DisableHTTP2 restricts the server to HTTP/1.1
+	DisableHTTP2 bool `mapstructure:"disable_http2"`
+	// TLSCertFile specifies the TLS certificate file; TLS is enabled when both cert and key files are set
+	TLSCertFile string `mapstructure:"tls_cert_file"`
+	// TLSKeyFile specifies the TLS private key file
+	TLSKeyFile string `mapstructure:"tls_key_file"`
+}
+
+// TLSEnabled reports whether the server should serve TLS
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Protocols returns the HTTP protocols accepted by the server
+// By default HTTP/1.1 is served, plus HTTP/2 when TLS is enabled
+func (c ServerConfig) Protocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(!c.DisableHTTP2)
+	protocols.SetUnencryptedHTTP2(c.EnableH2C && !c.DisableHTTP2)
+	return protocols
 }
 
 // InfrastructureConfig holds the infrastructure configuration
@@ -51,16 +91,31 @@ type InfrastructureConfig struct {
 }
 
 // SecurityConfig holds the security configuration
-// It contains settings for security-related features like encryption
+// It contains settings for security-related features like encryption and JWT
 type SecurityConfig struct {
 	// Encryption contains encryption settings
 	Encryption EncryptionConfig `mapstructure:"encryption"`
+	// JWT contains JWT token configuration
+	JWT JWTConfig `mapstructure:"jwt"`
+}
+
+// JWTConfig holds the JWT configuration
+// The service only validates access tokens issued by the agent service, so the secrets must match its configuration
+type JWTConfig struct {
+	// AccessTokenSecret is the secret key access tokens are signed with
+	AccessTokenSecret string `mapstructure:"access_token_secret"`
+	// RefreshTokenSecret is the secret key refresh tokens are signed with; the JWT client requires it to differ from the access token secret
+	RefreshTokenSecret string `mapstructure:"refresh_token_secret"`
 }
 
 // EncryptionConfig holds the encryption configuration
 type EncryptionConfig struct {
-	// Key is the encryption key for credentials
+	// Key is the primary encryption key for credentials, used for new encryptions
 	Key string `mapstructure:"key"`
+	// KeyID identifies the primary key; it is stored with each ciphertext so the key can be rotated
+	KeyID string `mapstructure:"key_id"`
+	// PreviousKeys maps the ids of retired keys to the keys, to decrypt credentials they encrypted
+	PreviousKeys map[string]string `mapstructure:"previous_keys"`
 }
 
 // PostgresConfig holds the PostgreSQL database configuration
@@ -90,6 +145,16 @@ type PostgresConfig struct {
 	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"` // minutes
 	// Debug enables or disables debug mode for database operations
 	Debug bool `mapstructure:"debug"`
+	// DebugHideParams logs statements in debug mode without bind parameter values
+	DebugHideParams bool `mapstructure:"debug_hide_params"`
+	// SlowQueryThreshold logs statements slower than this as warnings, in milliseconds; zero disables it
+	SlowQueryThreshold int `mapstructure:"slow_query_threshold"` // in milliseconds
+	// ReplicaDSNs lists read replica connection strings; reads are routed to them when set
+	ReplicaDSNs []string `mapstructure:"replica_dsns"`
+	// ConnectRetries specifies how many times a failed initial connection is retried; zero fails fast
+	ConnectRetries int `mapstructure:"connect_retries"`
+	// ConnectRetryDelay specifies the delay before the first retry, doubled after each attempt, in seconds
+	ConnectRetryDelay int `mapstructure:"connect_retry_delay"` // in seconds
 	// IsUseMigrate specifies whether to use database migration
 	IsUseMigrate bool `mapstructure:"is_use_migrate"`
 }
@@ -111,6 +176,11 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.read_timeout", 15)     // seconds
 	viper.SetDefault("server.write_timeout", 15)    // seconds
 	viper.SetDefault("server.shutdown_timeout", 30) // seconds
+	viper.SetDefault("server.max_body_size", 1<<20) // bytes (1 MiB)
+	viper.SetDefault("server.enable_h2c", false)
+	viper.SetDefault("server.disable_http2", false)
+	viper.SetDefault("security.encryption.key_id", "v1")
+	// No defaults for JWT secrets - they must be provided via config or env
 	viper.SetDefault("infrastructure.postgres.host", "localhost")
 	viper.SetDefault("infrastructure.postgres.port", 5432)
 	// No defaults for user and password - they must be provided
@@ -122,8 +192,13 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("infrastructure.postgres.conn_max_idle_time", 5) // minutes
 	viper.SetDefault("infrastructure.postgres.conn_max_lifetime", 60) // minutes
 	viper.SetDefault("infrastructure.postgres.debug", false)
+	viper.SetDefault("infrastructure.postgres.debug_hide_params", false)
+	viper.SetDefault("infrastructure.postgres.slow_query_threshold", 0)
+	viper.SetDefault("infrastructure.postgres.connect_retries", 5)
+	viper.SetDefault("infrastructure.postgres.connect_retry_delay", 1)
 	viper.SetDefault("application.name", "Supplier Credentials Service")
 	viper.SetDefault("application.version", "1.0")
+	viper.SetDefault("application.log_level", "info")
 	viper.SetDefault("infrastructure.kafka.brokers", []string{"localhost:9092"})
 	viper.SetDefault("infrastructure.kafka.topics.password_reset", "supplier-credentials.password.reset")
 
@@ -143,6 +218,20 @@ func LoadConfig() (*Config, error) {
 	if config.Security.Encryption.Key == "" {
 		return nil, errors.New("encryption key is required")
 	}
+	// Viper lowercases map keys, so key ids are lowercased to match the previous keys once retired
+	config.Security.Encryption.KeyID = strings.ToLower(config.Security.Encryption.KeyID)
+	if strings.Contains(config.Security.Encryption.KeyID, ":") {
+		return nil, errors.New("encryption key id must not contain ':'")
+	}
+	if _, ok := config.Security.Encryption.PreviousKeys[config.Security.Encryption.KeyID]; ok {
+		return nil, errors.New("encryption key id must not be reused by a previous key")
+	}
+	if config.Security.JWT.AccessTokenSecret == "" {
+		return nil, errors.New("JWT access token secret is required")
+	}
+	if config.Security.JWT.RefreshTokenSecret == "" {
+		return nil, errors.New("JWT refresh token secret is required")
+	}
 	if config.Infrastructure.Postgres.User == "" {
 		return nil, errors.New("database user is required")
 	}
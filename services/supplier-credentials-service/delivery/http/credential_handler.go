@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"monorepo/contracts/supplier_credentials_service"
 	"monorepo/pkg/api"
+	"monorepo/pkg/contextkeys"
 	"monorepo/pkg/logger"
 	"monorepo/pkg/validator"
 	"supplier-credentials-service/domain"
@@ -38,6 +41,7 @@ func NewCredentialHandler(credentialUseCase usecase.CredentialUseCase, logger lo
 }
 
 // CreateHandler handles HTTP requests to create a credential
+// Responds with the created credential, masked
 func (h *CredentialHandler) CreateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Create credential handler called")
@@ -45,11 +49,17 @@ func (h *CredentialHandler) CreateHandler(w http.ResponseWriter, r *http.Request
 	var req supplier_credentials_service.CreateCredentialRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for credential creation", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
-	req.IataAgentID = ctx.Value("agent_iata_id").(string) // Get IATA agent ID from context (set by middleware)
+	// Get IATA agent ID from context (set by middleware)
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+	req.IataAgentID = iataAgentID
 
 	// Validate the request
 	validationErrors := validator.ValidateStruct(&req)
@@ -71,17 +81,28 @@ func (h *CredentialHandler) CreateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// The usecase replaced the credentials with their ciphertext; respond with the submitted value, masked
+	credential.Credentials = req.Credentials
+
 	h.Logger.InfoContext(ctx, "Credential created successfully in handler", "id", credential.ID)
-	h.API.Created(ctx, w, h.credentialToResponse(credential))
+	h.API.Created(ctx, w, h.credentialToResponse(credential, true))
 }
 
 // ListHandler handles HTTP requests to list credentials
+// Credentials are always masked; use GetByIDHandler with reveal=true to read one in full
+// Results are paginated with the offset and limit query parameters
 func (h *CredentialHandler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "List credentials handler called")
 
 	var req supplier_credentials_service.ListCredentialsRequest
-	req.IataAgentID = ctx.Value("agent_iata_id").(string) // Get IATA agent ID from context (set by middleware)
+	// Get IATA agent ID from context (set by middleware)
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+	req.IataAgentID = iataAgentID
 
 	// Validate the request
 	validationErrors := validator.ValidateStruct(&req)
@@ -91,7 +112,8 @@ func (h *CredentialHandler) ListHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	credentials, err := h.CredentialUseCase.GetCredentialsByAgentID(ctx, req.IataAgentID)
+	offset, limit := paginationParams(r)
+	credentials, total, err := h.CredentialUseCase.GetCredentialsByAgentID(ctx, req.IataAgentID, offset, limit)
 	if err != nil {
 		h.handleCredentialError(ctx, w, err)
 		return
@@ -99,36 +121,90 @@ func (h *CredentialHandler) ListHandler(w http.ResponseWriter, r *http.Request)
 
 	response := make([]*supplier_credentials_service.CredentialResponse, len(credentials))
 	for i, cred := range credentials {
-		response[i] = h.credentialToResponse(cred)
+		response[i] = h.credentialToResponse(cred, true)
 	}
 
-	h.Logger.InfoContext(ctx, "Credentials listed successfully", "count", len(response))
-	h.API.Success(ctx, w, response)
+	h.Logger.InfoContext(ctx, "Credentials listed successfully", "count", len(response), "offset", offset, "limit", limit, "total", total)
+	h.API.SuccessWithMeta(ctx, w, response, paginationMeta(offset, limit, total))
 }
 
 // GetByIDHandler handles HTTP requests to retrieve a credential by ID
+// Credentials are masked unless reveal=true is passed; credentials of other agents are reported as not found
 func (h *CredentialHandler) GetByIDHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Get credential by ID handler called")
 
-	req := supplier_credentials_service.GetCredentialByIDRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for get credential by ID", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	// Get IATA agent ID from context (set by middleware)
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for get credential by ID", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
+		return
+	}
+
+	reveal, err := parseBoolQuery(r, "reveal")
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Invalid reveal parameter", "reveal", r.URL.Query().Get("reveal"))
+		h.API.BadRequest(ctx, w, "Invalid reveal parameter")
+		return
+	}
+
+	credential, err := h.CredentialUseCase.GetCredentialByID(ctx, iataAgentID, id)
+	if err != nil {
+		h.handleCredentialError(ctx, w, err)
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Credential retrieved by ID", "id", credential.ID, "reveal", reveal)
+	h.API.SuccessWithETag(ctx, w, r, h.credentialToResponse(credential, !reveal))
+}
+
+// GetBySupplierHandler handles HTTP requests to retrieve the authenticated agent's credential for a supplier
+// Credentials are masked unless reveal=true is passed
+func (h *CredentialHandler) GetBySupplierHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Get credential by supplier handler called")
+
+	// Get IATA agent ID from context (set by middleware)
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+
+	supplierID := chi.URLParam(r, "supplierID")
+	if err := validator.ValidateVar(supplierID, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for get credential by supplier", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "supplier_id", Message: err.Error()}})
 		return
 	}
 
-	credential, err := h.CredentialUseCase.GetCredentialByID(ctx, req.ID)
+	reveal, err := parseBoolQuery(r, "reveal")
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Invalid reveal parameter", "reveal", r.URL.Query().Get("reveal"))
+		h.API.BadRequest(ctx, w, "Invalid reveal parameter")
+		return
+	}
+
+	credential, err := h.CredentialUseCase.GetCredentialByAgentAndSupplier(ctx, iataAgentID, supplierID)
 	if err != nil {
 		h.handleCredentialError(ctx, w, err)
 		return
 	}
 
-	h.Logger.InfoContext(ctx, "Credential retrieved by ID", "id", credential.ID)
-	h.API.Success(ctx, w, h.credentialToResponse(credential))
+	h.Logger.InfoContext(ctx, "Credential retrieved by supplier", "id", credential.ID, "supplierID", supplierID, "reveal", reveal)
+	h.API.SuccessWithETag(ctx, w, r, h.credentialToResponse(credential, !reveal))
 }
 
 // UpdateHandler handles HTTP requests to update a credential
+// Responds with the updated credential, masked
+// Credentials of other agents are reported as not found
 func (h *CredentialHandler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Update credential handler called")
@@ -136,7 +212,7 @@ func (h *CredentialHandler) UpdateHandler(w http.ResponseWriter, r *http.Request
 	var req supplier_credentials_service.UpdateCredentialRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for credential update", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -151,8 +227,16 @@ func (h *CredentialHandler) UpdateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Only the agent owning the credential may update it
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+
 	credential := &model.AgentSupplierCredential{
 		ID:          req.ID,
+		IataAgentID: iataAgentID,
 		Credentials: req.Credentials,
 	}
 
@@ -161,48 +245,168 @@ func (h *CredentialHandler) UpdateHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// The usecase replaced the credentials with their ciphertext; respond with the submitted value, masked
+	credential.Credentials = req.Credentials
+
 	h.Logger.InfoContext(ctx, "Credential updated successfully", "id", req.ID)
-	h.API.Success(ctx, w, h.credentialToResponse(credential))
+	h.API.Success(ctx, w, h.credentialToResponse(credential, true))
+}
+
+// RotateHandler handles HTTP requests to rotate a credential
+// The previous credentials stay readable for the requested overlap, so suppliers can switch keys without downtime
+// Responds with the rotated credential, masked, including the previous credentials during the overlap
+// Credentials of other agents are reported as not found
+func (h *CredentialHandler) RotateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Rotate credential handler called")
+
+	var req supplier_credentials_service.RotateCredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.Logger.ErrorContext(ctx, "Invalid request body for credential rotation", "error", err)
+		h.API.InvalidBody(ctx, w, err)
+		return
+	}
+
+	// Set ID from URL parameter
+	req.ID = chi.URLParam(r, "id")
+
+	// Validate the request
+	validationErrors := validator.ValidateStruct(&req)
+	if validationErrors != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for credential rotation", "errors", validationErrors)
+		h.API.ValidationError(ctx, w, h.convertValidationErrors(validationErrors))
+		return
+	}
+
+	// Only the agent owning the credential may rotate it
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+
+	overlap := time.Duration(req.OverlapSeconds) * time.Second
+	if err := h.CredentialUseCase.RotateCredential(ctx, iataAgentID, req.ID, req.Credentials, overlap); err != nil {
+		h.handleCredentialError(ctx, w, err)
+		return
+	}
+
+	credential, err := h.CredentialUseCase.GetCredentialByID(ctx, iataAgentID, req.ID)
+	if err != nil {
+		h.handleCredentialError(ctx, w, err)
+		return
+	}
+
+	h.Logger.InfoContext(ctx, "Credential rotated successfully", "id", req.ID, "overlap", overlap)
+	h.API.Success(ctx, w, h.credentialToResponse(credential, true))
 }
 
 // DeleteHandler handles HTTP requests to delete a credential
+// Credentials of other agents are reported as not found
 func (h *CredentialHandler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Delete credential handler called")
 
-	req := supplier_credentials_service.DeleteCredentialRequest{ID: chi.URLParam(r, "id")}
-	if err := validator.ValidateStruct(&req); err != nil {
-		h.Logger.WarnContext(ctx, "Validation failed for delete credential", "errors", err)
-		h.API.ValidationError(ctx, w, h.convertValidationErrors(err))
+	id := chi.URLParam(r, "id")
+	if err := validator.ValidateVar(id, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for delete credential", "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
-	if err := h.CredentialUseCase.DeleteCredential(ctx, req.ID); err != nil {
+	// Only the agent owning the credential may delete it
+	iataAgentID, ok := api.RequireContextString(ctx, contextkeys.AgentIATAIDKey, w)
+	if !ok {
+		h.Logger.WarnContext(ctx, "IATA agent ID missing from request context")
+		return
+	}
+
+	if err := h.CredentialUseCase.DeleteCredential(ctx, iataAgentID, id); err != nil {
 		h.handleCredentialError(ctx, w, err)
 		return
 	}
 
-	h.Logger.InfoContext(ctx, "Credential deleted successfully", "id", req.ID)
+	h.Logger.InfoContext(ctx, "Credential deleted successfully", "id", id)
 	h.API.Success(ctx, w, map[string]string{"message": "Credential deleted successfully"})
 }
 
 // InternalListHandler handles internal requests to list credentials
+// Undecryptable credentials are reported in the failures section; pass strict=true to fail instead
+// Credentials are masked unless reveal=true is passed, for internal callers that must use them
+// Results are paginated with the offset and limit query parameters; failures only cover the current page
 func (h *CredentialHandler) InternalListHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Internal list credentials handler called")
 
-	credentials, err := h.CredentialUseCase.GetAllCredentials(ctx)
+	strict, err := parseBoolQuery(r, "strict")
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Invalid strict parameter", "strict", r.URL.Query().Get("strict"))
+		h.API.BadRequest(ctx, w, "Invalid strict parameter")
+		return
+	}
+
+	reveal, err := parseBoolQuery(r, "reveal")
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Invalid reveal parameter", "reveal", r.URL.Query().Get("reveal"))
+		h.API.BadRequest(ctx, w, "Invalid reveal parameter")
+		return
+	}
+
+	offset, limit := paginationParams(r)
+	list, err := h.CredentialUseCase.GetAllCredentials(ctx, strict, offset, limit)
 	if err != nil {
 		h.handleCredentialError(ctx, w, err)
 		return
 	}
 
-	response := make([]*supplier_credentials_service.CredentialResponse, len(credentials))
-	for i, cred := range credentials {
-		response[i] = h.credentialToResponse(cred)
+	response := &supplier_credentials_service.InternalCredentialListResponse{
+		Credentials: make([]*supplier_credentials_service.CredentialResponse, len(list.Credentials)),
+		Failures:    make([]*supplier_credentials_service.CredentialFailureResponse, len(list.Failures)),
+	}
+	for i, cred := range list.Credentials {
+		response.Credentials[i] = h.credentialToResponse(cred, !reveal)
+	}
+	for i, failure := range list.Failures {
+		response.Failures[i] = &supplier_credentials_service.CredentialFailureResponse{
+			ID:          failure.ID,
+			IataAgentID: failure.IataAgentID,
+			SupplierID:  failure.SupplierID,
+			Reason:      failure.Reason,
+		}
+	}
+
+	h.Logger.InfoContext(ctx, "Credentials listed for internal use", "count", len(response.Credentials), "failed", len(response.Failures), "offset", offset, "limit", limit, "total", list.Total)
+	h.API.SuccessWithMeta(ctx, w, response, paginationMeta(offset, limit, list.Total))
+}
+
+// ReencryptHandler handles internal requests to re-encrypt credentials with the primary key
+// It is run after an encryption key rotation; credentials that could not be re-encrypted are reported in failures
+func (h *CredentialHandler) ReencryptHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.Logger.InfoContext(ctx, "Re-encrypt credentials handler called")
+
+	result, err := h.CredentialUseCase.ReencryptAll(ctx)
+	if err != nil {
+		h.handleCredentialError(ctx, w, err)
+		return
+	}
+
+	response := &supplier_credentials_service.ReencryptCredentialsResponse{
+		Reencrypted: result.Reencrypted,
+		Unchanged:   result.Unchanged,
+		Conflicts:   result.Conflicts,
+		Failures:    make([]*supplier_credentials_service.CredentialFailureResponse, len(result.Failures)),
+	}
+	for i, failure := range result.Failures {
+		response.Failures[i] = &supplier_credentials_service.CredentialFailureResponse{
+			ID:          failure.ID,
+			IataAgentID: failure.IataAgentID,
+			SupplierID:  failure.SupplierID,
+			Reason:      failure.Reason,
+		}
 	}
 
-	h.Logger.InfoContext(ctx, "Credentials listed for internal use", "count", len(response))
+	h.Logger.InfoContext(ctx, "Credentials re-encrypted", "reencrypted", response.Reencrypted, "unchanged", response.Unchanged, "conflicts", response.Conflicts, "failed", len(response.Failures))
 	h.API.Success(ctx, w, response)
 }
 
@@ -216,13 +420,20 @@ func (h *CredentialHandler) handleCredentialError(ctx context.Context, w http.Re
 	case errors.Is(err, domain.ErrInvalidID):
 		h.API.BadRequest(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrIataAgentIDRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "iata_agent_id", Message: err.Error()}})
 	case errors.Is(err, domain.ErrSupplierIDRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "supplier_id", Message: err.Error()}})
 	case errors.Is(err, domain.ErrCredentialsRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "credentials", Message: err.Error()}})
 	case errors.Is(err, domain.ErrCredentialAlreadyExists):
 		h.API.BadRequest(ctx, w, err.Error())
+	case errors.Is(err, domain.ErrCredentialModified):
+		h.API.Conflict(ctx, w, err.Error())
+	case errors.Is(err, domain.ErrInvalidRotationOverlap):
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "overlap_seconds", Message: err.Error()}})
+	case domain.IsContextError(err):
+		h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+		h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 	default:
 		h.API.InternalServerError(ctx, w, "Internal server error")
 	}
@@ -230,26 +441,29 @@ func (h *CredentialHandler) handleCredentialError(ctx context.Context, w http.Re
 
 // convertValidationErrors converts validation errors to API format
 func (h *CredentialHandler) convertValidationErrors(validationErrors map[string]string) []api.ErrorDetail {
-	errorDetails := make([]api.ErrorDetail, 0, len(validationErrors))
-	for field, message := range validationErrors {
-		errorDetails = append(errorDetails, api.ErrorDetail{
-			Field:   field,
-			Message: message,
-		})
-	}
-	return errorDetails
+	return api.ValidationDetails(validationErrors)
 }
 
 // credentialToResponse converts a model to response format
-func (h *CredentialHandler) credentialToResponse(cred *model.AgentSupplierCredential) *supplier_credentials_service.CredentialResponse {
+// When mask is set, the credentials are redacted with maskCredentials
+func (h *CredentialHandler) credentialToResponse(cred *model.AgentSupplierCredential, mask bool) *supplier_credentials_service.CredentialResponse {
+	redact := func(value string) string { return value }
+	if mask {
+		redact = maskCredentials
+	}
 	response := &supplier_credentials_service.CredentialResponse{
 		ID:          cred.ID,
 		IataAgentID: cred.IataAgentID,
 		SupplierID:  cred.SupplierID,
-		Credentials: cred.Credentials,
+		Credentials: redact(cred.Credentials),
+		Masked:      mask,
 		CreatedAt:   cred.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   cred.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+	if cred.PreviousCredentials != "" && cred.PreviousExpiresAt != nil {
+		response.PreviousCredentials = redact(cred.PreviousCredentials)
+		response.PreviousExpiresAt = cred.PreviousExpiresAt.Format("2006-01-02T15:04:05Z07:00")
+	}
 	if cred.Supplier.ID != "" {
 		response.Supplier = &supplier_credentials_service.SupplierResponse{
 			ID:           cred.Supplier.ID,
@@ -259,3 +473,28 @@ func (h *CredentialHandler) credentialToResponse(cred *model.AgentSupplierCreden
 	}
 	return response
 }
+
+// maskVisibleChars is the number of trailing characters left visible by maskCredentials
+const maskVisibleChars = 4
+
+// maskCredentials redacts credentials, keeping only the last few characters to help tell values apart
+// Short values are redacted entirely, so the visible part never gives away most of a secret
+func maskCredentials(value string) string {
+	if value == "" {
+		return ""
+	}
+	const redacted = "********"
+	if len(value) < 3*maskVisibleChars {
+		return redacted
+	}
+	return redacted + value[len(value)-maskVisibleChars:]
+}
+
+// parseBoolQuery returns the boolean value of an optional query parameter, false when it is absent
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
@@ -0,0 +1,288 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"monorepo/contracts/supplier_credentials_service"
+	"monorepo/pkg/api"
+	"monorepo/pkg/contextkeys"
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/domain"
+	"supplier-credentials-service/domain/model"
+	"supplier-credentials-service/domain/repository"
+	"supplier-credentials-service/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCredentialRepo is an in-memory credential repository supporting lookups by ID
+// Methods not overridden panic through the nil embedded interface
+type fakeCredentialRepo struct {
+	repository.Credential
+	credentials map[string]*model.AgentSupplierCredential
+}
+
+func (r *fakeCredentialRepo) GetByID(_ context.Context, id string) (*model.AgentSupplierCredential, error) {
+	cred, ok := r.credentials[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *cred
+	return &copied, nil
+}
+
+func (r *fakeCredentialRepo) GetAll(_ context.Context, offset, limit int) ([]*model.AgentSupplierCredential, int, error) {
+	ids := make([]string, 0, len(r.credentials))
+	for id := range r.credentials {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	page := make([]*model.AgentSupplierCredential, 0, limit)
+	for _, id := range ids[min(offset, len(ids)):min(offset+limit, len(ids))] {
+		copied := *r.credentials[id]
+		page = append(page, &copied)
+	}
+	return page, len(ids), nil
+}
+
+func (r *fakeCredentialRepo) Rotate(_ context.Context, credential *model.AgentSupplierCredential, currentCiphertext string) error {
+	stored, ok := r.credentials[credential.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Credentials != currentCiphertext {
+		return domain.ErrConflict
+	}
+	stored.Credentials = credential.Credentials
+	stored.PreviousCredentials = credential.PreviousCredentials
+	stored.PreviousExpiresAt = credential.PreviousExpiresAt
+	return nil
+}
+
+// newOwnedCredentialHandler returns a handler serving a single credential of ownerID with the given plaintext
+// The repository is returned so tests can inspect and age the stored row
+func newOwnedCredentialHandler(t *testing.T, ownerID, plaintext string) (*CredentialHandler, string, *fakeCredentialRepo) {
+	keyring := usecase.NewKeyring("v1", "0123456789abcdef0123456789abcdef", nil)
+	supplierID := ulid.Make().String()
+	ciphertext, err := keyring.Encrypt(plaintext, usecase.CredentialAAD(ownerID, supplierID))
+	require.NoError(t, err, "Encrypt should not return error")
+
+	id := ulid.Make().String()
+	repo := &fakeCredentialRepo{credentials: map[string]*model.AgentSupplierCredential{
+		id: {ID: id, IataAgentID: ownerID, SupplierID: supplierID, Credentials: ciphertext},
+	}}
+	credentialUseCase := usecase.NewCredentialUseCase(repo, nil, logger.NoOpLogger(), keyring)
+	return NewCredentialHandler(credentialUseCase, logger.NoOpLogger()), id, repo
+}
+
+// callCredential calls handlerFunc as agentID for the credential id, with an optional request body
+func callCredential(handlerFunc http.HandlerFunc, method, agentID, id, query, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/credentials/"+id+query, strings.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = contextkeys.WithAgentIATAID(ctx, agentID)
+
+	w := httptest.NewRecorder()
+	handlerFunc(w, req.WithContext(ctx))
+	return w
+}
+
+// getCredential calls GetByIDHandler as agentID
+func getCredential(handler *CredentialHandler, agentID, id, query string) *httptest.ResponseRecorder {
+	return callCredential(handler.GetByIDHandler, http.MethodGet, agentID, id, query, "")
+}
+
+// decodeCredential decodes the credential of a successful response
+func decodeCredential(t *testing.T, w *httptest.ResponseRecorder) supplier_credentials_service.CredentialResponse {
+	t.Helper()
+	var response struct {
+		Data supplier_credentials_service.CredentialResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+	return response.Data
+}
+
+func TestGetByIDHandler_Ownership(t *testing.T) {
+	const plaintext = `{"username":"owner","password":"s3cret-password"}`
+	ownerID := ulid.Make().String()
+	handler, id, _ := newOwnedCredentialHandler(t, ownerID, plaintext)
+
+	t.Run("owner can reveal", func(t *testing.T) {
+		w := getCredential(handler, ownerID, id, "?reveal=true")
+		require.Equal(t, http.StatusOK, w.Code, "Owner should read its credential")
+
+		var response struct {
+			Data supplier_credentials_service.CredentialResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+		assert.Equal(t, plaintext, response.Data.Credentials, "Revealed credentials should be in plaintext")
+		assert.False(t, response.Data.Masked, "Revealed credentials should not be masked")
+	})
+
+	for _, query := range []string{"", "?reveal=true"} {
+		t.Run("other agent gets 404"+query, func(t *testing.T) {
+			w := getCredential(handler, ulid.Make().String(), id, query)
+			assert.Equal(t, http.StatusNotFound, w.Code, "Another agent should not see the credential")
+			assert.NotContains(t, w.Body.String(), "s3cret", "The secret should not leak")
+
+			var response api.Response
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+			assert.Equal(t, "NOT_FOUND", response.Error.Code, "Unexpected error code")
+		})
+	}
+}
+
+func TestUpdateHandler_MasksResponse(t *testing.T) {
+	ownerID := ulid.Make().String()
+	handler, id, _ := newOwnedCredentialHandler(t, ownerID, `{"username":"owner","password":"old-password"}`)
+	const updated = `{"username":"owner","password":"new-password"}`
+
+	w := callCredential(handler.UpdateHandler, http.MethodPut, ownerID, id, "", `{"credentials":`+strconv.Quote(updated)+`}`)
+	require.Equal(t, http.StatusOK, w.Code, "Owner should update its credential")
+
+	response := decodeCredential(t, w)
+	assert.True(t, response.Masked, "The response should be masked")
+	assert.NotContains(t, response.Credentials, "new-password", "The new secret should not be echoed")
+	assert.NotContains(t, response.Credentials, "v1:", "The ciphertext should not leak")
+	assert.Equal(t, maskCredentials(updated), response.Credentials, "The submitted value should be masked")
+}
+
+func TestCredentialHandler_RotationOverlap(t *testing.T) {
+	ownerID := ulid.Make().String()
+	handler, id, repo := newOwnedCredentialHandler(t, ownerID, "first-credential-value")
+
+	// rotate replaces the credential, keeping the replaced value for overlapSeconds
+	rotate := func(plaintext string, overlapSeconds int) {
+		t.Helper()
+		body := fmt.Sprintf(`{"credentials":%q,"overlap_seconds":%d}`, plaintext, overlapSeconds)
+		w := callCredential(handler.RotateHandler, http.MethodPost, ownerID, id, "", body)
+		require.Equal(t, http.StatusOK, w.Code, "Rotation should succeed")
+	}
+	// reveal reads the credential in plaintext
+	reveal := func() supplier_credentials_service.CredentialResponse {
+		t.Helper()
+		w := getCredential(handler, ownerID, id, "?reveal=true")
+		require.Equal(t, http.StatusOK, w.Code, "Owner should read its credential")
+		return decodeCredential(t, w)
+	}
+
+	t.Run("previous value is returned before expiry", func(t *testing.T) {
+		rotate("second-credential-value", 3600)
+
+		cred := reveal()
+		assert.Equal(t, "second-credential-value", cred.Credentials, "The new value should be current")
+		assert.Equal(t, "first-credential-value", cred.PreviousCredentials, "The replaced value should stay readable")
+		assert.NotEmpty(t, cred.PreviousExpiresAt, "The previous value should carry its expiry")
+	})
+
+	t.Run("previous value is dropped after expiry", func(t *testing.T) {
+		expired := time.Now().Add(-time.Second)
+		repo.credentials[id].PreviousExpiresAt = &expired
+
+		cred := reveal()
+		assert.Equal(t, "second-credential-value", cred.Credentials, "The current value should not change")
+		assert.Empty(t, cred.PreviousCredentials, "An expired previous value should not be returned")
+		assert.Empty(t, cred.PreviousExpiresAt, "An expired previous value should have no expiry")
+	})
+
+	t.Run("zero overlap clears the previous value", func(t *testing.T) {
+		rotate("third-credential-value", 3600)
+		rotate("fourth-credential-value", 0)
+
+		assert.Empty(t, repo.credentials[id].PreviousCredentials, "The previous value should be cleared")
+		assert.Nil(t, repo.credentials[id].PreviousExpiresAt, "The previous expiry should be cleared")
+		assert.Empty(t, reveal().PreviousCredentials, "No previous value should be returned")
+	})
+
+	t.Run("update clears the previous value", func(t *testing.T) {
+		rotate("fifth-credential-value", 3600)
+		require.NotEmpty(t, repo.credentials[id].PreviousCredentials, "The rotation should keep a previous value")
+
+		w := callCredential(handler.UpdateHandler, http.MethodPut, ownerID, id, "", `{"credentials":"sixth-credential-value"}`)
+		require.Equal(t, http.StatusOK, w.Code, "Owner should update its credential")
+
+		assert.Empty(t, repo.credentials[id].PreviousCredentials, "The previous value should be cleared")
+		assert.Nil(t, repo.credentials[id].PreviousExpiresAt, "The previous expiry should be cleared")
+		cred := reveal()
+		assert.Equal(t, "sixth-credential-value", cred.Credentials, "The updated value should be current")
+		assert.Empty(t, cred.PreviousCredentials, "No previous value should be returned")
+	})
+}
+
+func TestInternalListHandler_CorruptCredentials(t *testing.T) {
+	keyring := usecase.NewKeyring("v1", "0123456789abcdef0123456789abcdef", nil)
+	repo := &fakeCredentialRepo{credentials: map[string]*model.AgentSupplierCredential{}}
+
+	// add stores a credential of a new owner and supplier, encrypted for aadOwnerID
+	add := func(plaintext, aadOwnerID string) string {
+		ownerID, supplierID := ulid.Make().String(), ulid.Make().String()
+		if aadOwnerID == "" {
+			aadOwnerID = ownerID
+		}
+		ciphertext, err := keyring.Encrypt(plaintext, usecase.CredentialAAD(aadOwnerID, supplierID))
+		require.NoError(t, err, "Encrypt should not return error")
+
+		id := ulid.Make().String()
+		repo.credentials[id] = &model.AgentSupplierCredential{ID: id, IataAgentID: ownerID, SupplierID: supplierID, Credentials: ciphertext}
+		return id
+	}
+	goodIDs := []string{add(`{"username":"first"}`, ""), add(`{"username":"second"}`, "")}
+	// One row is not ciphertext at all and one was encrypted for another owner
+	garbageID := add(`{"username":"garbage"}`, "")
+	repo.credentials[garbageID].Credentials = "not-a-ciphertext"
+	wrongOwnerID := add(`{"username":"wrong-owner"}`, ulid.Make().String())
+
+	credentialUseCase := usecase.NewCredentialUseCase(repo, nil, logger.NoOpLogger(), keyring)
+	handler := NewCredentialHandler(credentialUseCase, logger.NoOpLogger())
+
+	list := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.InternalListHandler(w, httptest.NewRequest(http.MethodGet, "/internal/credentials"+query, nil))
+		return w
+	}
+
+	t.Run("corrupt rows are skipped and reported", func(t *testing.T) {
+		w := list("?reveal=true")
+		require.Equal(t, http.StatusOK, w.Code, "Corrupt rows should not fail the list")
+
+		var response struct {
+			Data supplier_credentials_service.InternalCredentialListResponse `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+
+		returned := make([]string, 0, len(response.Data.Credentials))
+		for _, cred := range response.Data.Credentials {
+			returned = append(returned, cred.ID)
+			assert.Contains(t, cred.Credentials, "username", "Good rows should be decrypted")
+		}
+		assert.ElementsMatch(t, goodIDs, returned, "Good rows should be returned")
+
+		failed := make([]string, 0, len(response.Data.Failures))
+		for _, failure := range response.Data.Failures {
+			failed = append(failed, failure.ID)
+			assert.Equal(t, repo.credentials[failure.ID].IataAgentID, failure.IataAgentID, "Failure should name the owner")
+			assert.NotEmpty(t, failure.Reason, "Failure should carry a reason")
+		}
+		assert.ElementsMatch(t, []string{garbageID, wrongOwnerID}, failed, "Corrupt rows should be reported")
+	})
+
+	t.Run("strict mode fails the call", func(t *testing.T) {
+		w := list("?strict=true&reveal=true")
+		assert.Equal(t, http.StatusInternalServerError, w.Code, "A corrupt row should fail a strict list")
+		assert.NotContains(t, w.Body.String(), "username", "No credential should leak from a failed list")
+	})
+}
@@ -2,30 +2,40 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"monorepo/pkg/api"
 	"monorepo/pkg/logger"
+	"monorepo/pkg/postgres"
 )
 
+// databasePingTimeout bounds the database ping performed by the health check
+const databasePingTimeout = 2 * time.Second
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
 	// Logger is used for logging operations within the handler
 	Logger logger.LoggerInterface
 	// API provides standardized API response patterns
 	API api.Api
+	// DB is the database checked by the health endpoint; nil skips the check
+	DB postgres.PostgresClient
 }
 
 // NewHealthHandler creates a new instance of HealthHandler
-func NewHealthHandler(logger logger.LoggerInterface) *HealthHandler {
+func NewHealthHandler(logger logger.LoggerInterface, db postgres.PostgresClient) *HealthHandler {
 	return &HealthHandler{
 		Logger: logger,
 		API:    api.New(),
+		DB:     db,
 	}
 }
 
 // HealthCheckHandler handles HTTP requests for health checks
-// It returns a JSON response indicating the service status
+// It pings the database and returns a JSON response indicating the service status
+// Returns a 503 status code if the database is unreachable
 func (h *HealthHandler) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Health check endpoint called")
@@ -35,5 +45,27 @@ func (h *HealthHandler) HealthCheckHandler(w http.ResponseWriter, r *http.Reques
 		"message": "Service is running",
 	}
 
+	if h.DB != nil {
+		pingCtx, cancel := context.WithTimeout(ctx, databasePingTimeout)
+		defer cancel()
+
+		if err := h.DB.Ping(pingCtx); err != nil {
+			h.Logger.ErrorContext(ctx, "Database health check failed", "error", err)
+			h.API.Error(ctx, w, http.StatusServiceUnavailable, &api.Error{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: "Database is unreachable",
+			})
+			return
+		}
+
+		stats := h.DB.Stats()
+		healthData["database"] = map[string]interface{}{
+			"status":           "up",
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}
+	}
+
 	h.API.Success(ctx, w, healthData)
 }
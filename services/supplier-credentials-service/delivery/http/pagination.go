@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"monorepo/pkg/api"
+)
+
+const (
+	// defaultPageLimit is the page size used when the limit query parameter is missing or invalid
+	defaultPageLimit = 10
+	// maxPageLimit caps the page size requested by clients
+	maxPageLimit = 100
+)
+
+// paginationParams parses the offset and limit query parameters
+// Invalid values fall back to the first page and the default limit; the limit is capped at maxPageLimit
+func paginationParams(r *http.Request) (offset, limit int) {
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return offset, limit
+}
+
+// paginationMeta builds the response metadata of a page read at offset with limit out of total records
+func paginationMeta(offset, limit, total int) *api.Meta {
+	if total < 0 {
+		total = 0
+	}
+
+	// Calculate totalPages (0 if no data, else ceiling division)
+	totalPages := 0
+	if total > 0 {
+		totalPages = (total + limit - 1) / limit
+	}
+
+	// Calculate current page (1-based)
+	page := 1
+	if total > 0 && offset < total {
+		page = offset/limit + 1
+	} else if total > 0 && offset >= total {
+		page = totalPages
+	}
+
+	return &api.Meta{
+		Pagination: &api.Pagination{
+			Page:        page,
+			Limit:       limit,
+			Total:       total,
+			TotalPages:  totalPages,
+			HasNextPage: total > 0 && offset+limit < total,
+			HasPrevPage: total > 0 && offset > 0,
+		},
+	}
+}
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"monorepo/pkg/api"
+	"monorepo/pkg/auth"
+	"monorepo/pkg/jwt"
 	"monorepo/pkg/logger"
 	"net/http"
 
@@ -12,14 +15,18 @@ type Router struct {
 	CredentialHandler *CredentialHandler
 	SupplierHandler   *SupplierHandler
 	HealthHandler     *HealthHandler
+	JWTClient         jwt.JWTClient
+	MaxBodySize       int64
 	AppLogger         logger.LoggerInterface
 }
 
-func NewRouter(credentialHandler *CredentialHandler, supplierHandler *SupplierHandler, healthHandler *HealthHandler, appLogger logger.LoggerInterface) *Router {
+func NewRouter(credentialHandler *CredentialHandler, supplierHandler *SupplierHandler, healthHandler *HealthHandler, jwtClient jwt.JWTClient, maxBodySize int64, appLogger logger.LoggerInterface) *Router {
 	return &Router{
 		CredentialHandler: credentialHandler,
 		SupplierHandler:   supplierHandler,
 		HealthHandler:     healthHandler,
+		JWTClient:         jwtClient,
+		MaxBodySize:       maxBodySize,
 		AppLogger:         appLogger,
 	}
 }
@@ -30,15 +37,18 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Add middleware
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.RequestID)
+	router.Use(logger.CorrelationIDMiddleware)
 	router.Use(middleware.Heartbeat("/ping"))
+	router.Use(LoggingMiddleware(r.AppLogger))
+	router.Use(api.MaxBodySize(r.MaxBodySize))
 
 	// Health check endpoint
 	router.Get("/health", r.HealthHandler.HealthCheckHandler)
 
 	router.Route("/api/v1", func(api chi.Router) {
-		// Protected routes - require X-AgentIATA-ID header
+		// Protected routes - require an IATA agent access token
 		api.Route("/", func(protected chi.Router) {
-			protected.Use(AgentIATAMiddleware(r.AppLogger))
+			protected.Use(auth.IATAAgentMiddleware(r.JWTClient, r.AppLogger, r.CredentialHandler.API))
 
 			// Suppliers routes - require authentication
 			protected.Get("/suppliers", r.SupplierHandler.ListSuppliersHandler)
@@ -47,8 +57,10 @@ func (r *Router) SetupRoutes() http.Handler {
 			protected.Route("/credentials", func(credentials chi.Router) {
 				credentials.Post("/", r.CredentialHandler.CreateHandler)
 				credentials.Get("/", r.CredentialHandler.ListHandler)
+				credentials.Get("/supplier/{supplierID}", r.CredentialHandler.GetBySupplierHandler)
 				credentials.Get("/{id}", r.CredentialHandler.GetByIDHandler)
 				credentials.Put("/{id}", r.CredentialHandler.UpdateHandler)
+				credentials.Post("/{id}/rotate", r.CredentialHandler.RotateHandler)
 				credentials.Delete("/{id}", r.CredentialHandler.DeleteHandler)
 			})
 		})
@@ -58,6 +70,7 @@ func (r *Router) SetupRoutes() http.Handler {
 	router.Route("/internal", func(internal chi.Router) {
 		// Internal credentials route - no header validation required for internal calls
 		internal.Get("/credentials", r.CredentialHandler.InternalListHandler)
+		internal.Post("/credentials/reencrypt", r.CredentialHandler.ReencryptHandler)
 
 		// Internal supplier routes - no header validation required for internal calls
 		internal.Get("/supplier", r.SupplierHandler.ListSuppliersHandler)
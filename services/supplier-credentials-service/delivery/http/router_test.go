@@ -0,0 +1,61 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"monorepo/pkg/api"
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_MaxBodySize(t *testing.T) {
+	const maxBodySize = 256
+	appLogger := logger.NoOpLogger()
+	router := NewRouter(
+		NewCredentialHandler(nil, appLogger),
+		NewSupplierHandler(usecase.NewSupplierUseCase(&fakeSupplierRepo{}, appLogger), appLogger),
+		NewHealthHandler(appLogger, nil),
+		nil,
+		maxBodySize,
+		appLogger,
+	).SetupRoutes()
+
+	oversized := strings.Repeat("a", maxBodySize)
+
+	tests := []struct {
+		name          string
+		body          string
+		unknownLength bool
+		wantStatus    int
+	}{
+		// An empty supplier is rejected by validation once the body is read
+		{name: "within limit", body: `{"supplier_code":""}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "over limit", body: `{"supplier_name":"` + oversized + `"}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "streamed over limit", body: `{"supplier_name":"` + oversized + `"}`, unknownLength: true, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/internal/supplier", strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code, "Unexpected status code")
+
+			if tt.wantStatus == http.StatusRequestEntityTooLarge {
+				var response api.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+				assert.Equal(t, "PAYLOAD_TOO_LARGE", response.Error.Code, "Unexpected error code")
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 
 	"monorepo/contracts/supplier_credentials_service"
 	"monorepo/pkg/api"
@@ -44,73 +43,23 @@ func (h *SupplierHandler) ListSuppliersHandler(w http.ResponseWriter, r *http.Re
 	h.Logger.InfoContext(ctx, "List suppliers handler called")
 
 	// Parse query parameters for pagination
-	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
-	if err != nil || offset < 0 {
-		offset = 0
-	}
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = 10
-	}
-
-	if limit > 100 {
-		limit = 100
-	}
+	offset, limit := paginationParams(r)
 
 	// Get suppliers and real total from usecase
 	suppliers, total, err := h.SupplierUseCase.ListSuppliers(ctx, offset, limit)
 	if err != nil {
+		if domain.IsContextError(err) {
+			h.Logger.WarnContext(ctx, "Error listing suppliers: request cancelled", "offset", offset, "limit", limit, "error", err)
+			h.API.ClientClosedRequest(ctx, w, "Request cancelled")
+			return
+		}
 		h.Logger.ErrorContext(ctx, "Error listing suppliers", "offset", offset, "limit", limit, "error", err)
 		h.API.InternalServerError(ctx, w, "Failed to list suppliers")
 		return
 	}
 
-	if total < 0 {
-		total = 0
-	}
-
-	// Calculate totalPages (0 if no data, else ceiling division)
-	totalPages := 0
-	if total > 0 {
-		totalPages = (total + limit - 1) / limit
-	}
-
-	// Calculate current page (1-based)
-	page := 1
-	if total > 0 && offset < total {
-		page = offset/limit + 1
-	} else if total > 0 && offset >= total {
-		page = totalPages
-	}
-
-	// HasNextPage: true if there are more records after this page
-	hasNextPage := false
-	if total > 0 && offset+limit < total {
-		hasNextPage = true
-	}
-
-	// HasPrevPage: true if offset > 0 and there is data
-	hasPrevPage := false
-	if total > 0 && offset > 0 {
-		hasPrevPage = true
-	}
-
-	pagination := &api.Pagination{
-		Page:        page,
-		Limit:       limit,
-		Total:       total,
-		TotalPages:  totalPages,
-		HasNextPage: hasNextPage,
-		HasPrevPage: hasPrevPage,
-	}
-
-	meta := &api.Meta{
-		Pagination: pagination,
-	}
-
 	h.Logger.InfoContext(ctx, "Suppliers listed successfully in handler", "count", len(suppliers), "offset", offset, "limit", limit, "total", total)
-	h.API.SuccessWithMeta(ctx, w, supplierModelsToResponses(suppliers), meta)
+	h.API.SuccessWithMeta(ctx, w, supplierModelsToResponses(suppliers), paginationMeta(offset, limit, total))
 }
 
 // CreateSupplierHandler handles HTTP requests to create a supplier
@@ -121,7 +70,7 @@ func (h *SupplierHandler) CreateSupplierHandler(w http.ResponseWriter, r *http.R
 	var req supplier_credentials_service.CreateSupplierRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for supplier creation", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -169,7 +118,7 @@ func (h *SupplierHandler) UpdateSupplierHandler(w http.ResponseWriter, r *http.R
 	var req supplier_credentials_service.UpdateSupplierRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.Logger.ErrorContext(ctx, "Invalid request body for supplier update", "error", err)
-		h.API.BadRequest(ctx, w, "Invalid request body")
+		h.API.InvalidBody(ctx, w, err)
 		return
 	}
 
@@ -204,19 +153,26 @@ func (h *SupplierHandler) UpdateSupplierHandler(w http.ResponseWriter, r *http.R
 }
 
 // DeleteSupplierHandler handles HTTP requests to delete a supplier
+// A supplier with credentials is rejected with 409 unless force=true is passed, which deletes its credentials too
 func (h *SupplierHandler) DeleteSupplierHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	h.Logger.InfoContext(ctx, "Delete supplier handler called")
 
 	idStr := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id <= 0 {
-		h.Logger.ErrorContext(ctx, "Invalid supplier ID", "id", idStr, "error", err)
-		h.API.BadRequest(ctx, w, "Invalid supplier ID")
+	if err := validator.ValidateVar(idStr, "required,ulid"); err != nil {
+		h.Logger.WarnContext(ctx, "Validation failed for delete supplier", "id", idStr, "error", err)
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "id", Message: err.Error()}})
 		return
 	}
 
-	if err := h.SupplierUseCase.DeleteSupplier(ctx, idStr); err != nil {
+	force, err := parseBoolQuery(r, "force")
+	if err != nil {
+		h.Logger.WarnContext(ctx, "Invalid force parameter", "force", r.URL.Query().Get("force"))
+		h.API.BadRequest(ctx, w, "Invalid force parameter")
+		return
+	}
+
+	if err := h.SupplierUseCase.DeleteSupplier(ctx, idStr, force); err != nil {
 		h.Logger.ErrorContext(ctx, "Error deleting supplier", "id", idStr, "error", err)
 		h.handleSupplierError(ctx, w, err)
 		return
@@ -232,11 +188,16 @@ func (h *SupplierHandler) handleSupplierError(ctx context.Context, w http.Respon
 	case errors.Is(err, domain.ErrSupplierNotFound):
 		h.API.NotFound(ctx, w, err.Error())
 	case errors.Is(err, domain.ErrSupplierCodeRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "supplier_code", Message: err.Error()}})
 	case errors.Is(err, domain.ErrSupplierNameRequired):
-		h.API.BadRequest(ctx, w, err.Error())
+		h.API.ValidationError(ctx, w, []api.ErrorDetail{{Field: "supplier_name", Message: err.Error()}})
 	case errors.Is(err, domain.ErrSupplierCodeAlreadyExists):
 		h.API.Conflict(ctx, w, err.Error())
+	case errors.Is(err, domain.ErrSupplierHasCredentials):
+		h.API.Conflict(ctx, w, err.Error())
+	case domain.IsContextError(err):
+		h.Logger.WarnContext(ctx, "Request cancelled", "error", err)
+		h.API.ClientClosedRequest(ctx, w, "Request cancelled")
 	default:
 		h.API.InternalServerError(ctx, w, "Internal server error")
 	}
@@ -244,14 +205,7 @@ func (h *SupplierHandler) handleSupplierError(ctx context.Context, w http.Respon
 
 // convertValidationErrors converts validation errors to API format
 func (h *SupplierHandler) convertValidationErrors(validationErrors map[string]string) []api.ErrorDetail {
-	errorDetails := make([]api.ErrorDetail, 0, len(validationErrors))
-	for field, message := range validationErrors {
-		errorDetails = append(errorDetails, api.ErrorDetail{
-			Field:   field,
-			Message: message,
-		})
-	}
-	return errorDetails
+	return api.ValidationDetails(validationErrors)
 }
 
 // supplierModelsToResponses converts supplier models to response format
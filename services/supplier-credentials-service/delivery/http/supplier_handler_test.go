@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"monorepo/pkg/api"
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/domain"
+	"supplier-credentials-service/domain/model"
+	"supplier-credentials-service/domain/repository"
+	"supplier-credentials-service/usecase"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSupplierRepo holds one supplier referenced by a number of credentials
+// Methods not overridden panic through the nil embedded interface
+type fakeSupplierRepo struct {
+	repository.Supplier
+	id          string
+	credentials int
+	deleted     bool
+}
+
+func (r *fakeSupplierRepo) GetByID(_ context.Context, id string) (*model.Supplier, error) {
+	if id != r.id || r.deleted {
+		return nil, domain.ErrNotFound
+	}
+	return &model.Supplier{ID: id, SupplierCode: "SUP", SupplierName: "Supplier"}, nil
+}
+
+func (r *fakeSupplierRepo) DeleteUnreferenced(_ context.Context, _ string) (int, error) {
+	if r.credentials == 0 {
+		r.deleted = true
+	}
+	return r.credentials, nil
+}
+
+func (r *fakeSupplierRepo) DeleteWithCredentials(_ context.Context, _ string) (int, error) {
+	deleted := r.credentials
+	r.credentials, r.deleted = 0, true
+	return deleted, nil
+}
+
+func TestDeleteSupplierHandler_Credentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials int
+		query       string
+		wantStatus  int
+		wantDeleted bool
+	}{
+		{name: "unreferenced supplier is deleted", credentials: 0, wantStatus: http.StatusOK, wantDeleted: true},
+		{name: "referenced supplier is a conflict", credentials: 2, wantStatus: http.StatusConflict},
+		{name: "force deletes a referenced supplier", credentials: 2, query: "?force=true", wantStatus: http.StatusOK, wantDeleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSupplierRepo{id: ulid.Make().String(), credentials: tt.credentials}
+			handler := NewSupplierHandler(usecase.NewSupplierUseCase(repo, logger.NoOpLogger()), logger.NoOpLogger())
+			router := chi.NewRouter()
+			router.Delete("/internal/supplier/{id}", handler.DeleteSupplierHandler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/internal/supplier/"+repo.id+tt.query, nil))
+			require.Equal(t, tt.wantStatus, w.Code, "Unexpected status code")
+			assert.Equal(t, tt.wantDeleted, repo.deleted, "Unexpected supplier deletion")
+
+			if tt.wantStatus == http.StatusConflict {
+				var response api.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response), "Failed to decode response")
+				assert.Equal(t, domain.ErrSupplierHasCredentials.Message, response.Error.Message, "Unexpected error message")
+				assert.Equal(t, tt.credentials, repo.credentials, "Credentials should be kept")
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // Error types with HTTP status codes
 type AppError struct {
@@ -42,6 +45,10 @@ var (
 		Message: "supplier with this code already exists",
 		Code:    409, // StatusConflict
 	}
+	ErrSupplierHasCredentials = &AppError{
+		Message: "cannot delete supplier with credentials",
+		Code:    409, // StatusConflict
+	}
 	ErrIataAgentIDRequired = &AppError{
 		Message: "IATA agent ID is required",
 		Code:    400, // StatusBadRequest
@@ -62,9 +69,25 @@ var (
 		Message: "invalid id",
 		Code:    400, // StatusBadRequest
 	}
+	ErrCredentialModified = &AppError{
+		Message: "credential was modified concurrently, please retry",
+		Code:    409, // StatusConflict
+	}
+	ErrInvalidRotationOverlap = &AppError{
+		Message: "rotation overlap must not be negative",
+		Code:    400, // StatusBadRequest
+	}
 )
 
 // Standard error types for repositories
 var (
 	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a conditional write finds the row changed since it was read
+	ErrConflict = errors.New("conflict")
 )
+
+// IsContextError reports whether err was caused by the request context being
+// cancelled or timing out rather than by a genuine failure
+func IsContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -19,14 +19,16 @@ type Supplier struct {
 
 // AgentSupplierCredential represents the credentials for an agent-supplier pair
 type AgentSupplierCredential struct {
-	ID          string         `gorm:"type:char(26);primaryKey"`
-	IataAgentID string         `gorm:"type:char(26);not null;uniqueIndex:iata_agent_id_supplier_id"`
-	SupplierID  string         `gorm:"type:char(26);not null;uniqueIndex:iata_agent_id_supplier_id"`
-	Supplier    Supplier       `gorm:"foreignKey:SupplierID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
-	Credentials string         `gorm:"type:text;not null"` // Encrypted JSON
-	CreatedAt   time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	ID                  string         `gorm:"type:char(26);primaryKey"`
+	IataAgentID         string         `gorm:"type:char(26);not null;uniqueIndex:iata_agent_id_supplier_id"`
+	SupplierID          string         `gorm:"type:char(26);not null;uniqueIndex:iata_agent_id_supplier_id"`
+	Supplier            Supplier       `gorm:"foreignKey:SupplierID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Credentials         string         `gorm:"type:text;not null"` // Encrypted JSON
+	PreviousCredentials string         `gorm:"type:text"`          // Encrypted JSON replaced by the last rotation
+	PreviousExpiresAt   *time.Time     // End of the rotation overlap, after which PreviousCredentials is no longer valid
+	CreatedAt           time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 }
 
 func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
@@ -14,15 +14,19 @@ type Supplier interface {
 	List(ctx context.Context, offset, limit int) ([]*model.Supplier, int, error)
 	Update(ctx context.Context, supplier *model.Supplier) error
 	Delete(ctx context.Context, id string) error
+	DeleteUnreferenced(ctx context.Context, id string) (int, error)
+	DeleteWithCredentials(ctx context.Context, id string) (int, error)
 }
 
 // Credential defines credential-related database operations
 type Credential interface {
 	Create(ctx context.Context, credential *model.AgentSupplierCredential) error
 	GetByID(ctx context.Context, id string) (*model.AgentSupplierCredential, error)
-	GetByAgentID(ctx context.Context, agentID string) ([]*model.AgentSupplierCredential, error)
-	GetAll(ctx context.Context) ([]*model.AgentSupplierCredential, error)
+	GetByAgentID(ctx context.Context, agentID string, offset, limit int) ([]*model.AgentSupplierCredential, int, error)
+	GetAll(ctx context.Context, offset, limit int) ([]*model.AgentSupplierCredential, int, error)
+	GetAllAfter(ctx context.Context, afterID string, limit int) ([]*model.AgentSupplierCredential, error)
 	GetByAgentAndSupplier(ctx context.Context, agentID string, supplierID string) (*model.AgentSupplierCredential, error)
 	Update(ctx context.Context, credential *model.AgentSupplierCredential) error
+	Rotate(ctx context.Context, credential *model.AgentSupplierCredential, currentCiphertext string) error
 	Delete(ctx context.Context, id string) error
 }
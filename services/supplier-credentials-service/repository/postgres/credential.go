@@ -33,6 +33,10 @@ func NewCredentialRepository(db *gorm.DB, logger logger.LoggerInterface) reposit
 func (r *credentialRepository) Create(ctx context.Context, credential *model.AgentSupplierCredential) error {
 	r.logger.InfoContext(ctx, "Creating credential", "agentID", credential.IataAgentID, "supplierID", credential.SupplierID)
 	if err := r.db.WithContext(ctx).Create(credential).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to create credential: request cancelled", "agentID", credential.IataAgentID, "supplierID", credential.SupplierID, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to create credential", "agentID", credential.IataAgentID, "supplierID", credential.SupplierID, "error", err)
 		return fmt.Errorf("failed to create credential: %w", err)
 	}
@@ -49,6 +53,10 @@ func (r *credentialRepository) GetByID(ctx context.Context, id string) (*model.A
 			r.logger.WarnContext(ctx, "Credential not found by ID", "id", id)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get credential by ID: request cancelled", "id", id, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get credential by ID", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get credential: %w", err)
 	}
@@ -56,27 +64,76 @@ func (r *credentialRepository) GetByID(ctx context.Context, id string) (*model.A
 	return &credential, nil
 }
 
-// GetByAgentID retrieves all credentials for an agent
-func (r *credentialRepository) GetByAgentID(ctx context.Context, agentID string) ([]*model.AgentSupplierCredential, error) {
-	r.logger.InfoContext(ctx, "Getting credentials by agent ID", "agentID", agentID)
+// GetByAgentID retrieves a page of the credentials of an agent, ordered by ID
+// Returns the credentials and the total number of credentials of the agent
+func (r *credentialRepository) GetByAgentID(ctx context.Context, agentID string, offset, limit int) ([]*model.AgentSupplierCredential, int, error) {
+	r.logger.InfoContext(ctx, "Getting credentials by agent ID", "agentID", agentID, "offset", offset, "limit", limit)
 	var credentials []*model.AgentSupplierCredential
-	if err := r.db.WithContext(ctx).Preload("Supplier").Where("iata_agent_id = ? AND deleted_at IS NULL", agentID).Find(&credentials).Error; err != nil {
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&model.AgentSupplierCredential{}).Where("iata_agent_id = ? AND deleted_at IS NULL", agentID).Count(&total).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to count credentials by agent ID: request cancelled", "agentID", agentID, "error", err)
+			return nil, 0, err
+		}
+		r.logger.ErrorContext(ctx, "Failed to count credentials by agent ID", "agentID", agentID, "error", err)
+		return nil, 0, fmt.Errorf("failed to count credentials by agent ID: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Supplier").Where("iata_agent_id = ? AND deleted_at IS NULL", agentID).Offset(offset).Limit(limit).Order("id ASC").Find(&credentials).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get credentials by agent ID: request cancelled", "agentID", agentID, "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get credentials by agent ID", "agentID", agentID, "error", err)
-		return nil, fmt.Errorf("failed to get credentials by agent ID: %w", err)
+		return nil, 0, fmt.Errorf("failed to get credentials by agent ID: %w", err)
 	}
-	r.logger.InfoContext(ctx, "Credentials retrieved by agent ID", "count", len(credentials), "agentID", agentID)
-	return credentials, nil
+	r.logger.InfoContext(ctx, "Credentials retrieved by agent ID", "count", len(credentials), "agentID", agentID, "total", total)
+	return credentials, int(total), nil
 }
 
-// GetAll retrieves all credentials
-func (r *credentialRepository) GetAll(ctx context.Context) ([]*model.AgentSupplierCredential, error) {
-	r.logger.InfoContext(ctx, "Getting all credentials")
+// GetAll retrieves a page of all credentials, ordered by ID
+// Returns the credentials and the total number of credentials
+func (r *credentialRepository) GetAll(ctx context.Context, offset, limit int) ([]*model.AgentSupplierCredential, int, error) {
+	r.logger.InfoContext(ctx, "Getting all credentials", "offset", offset, "limit", limit)
 	var credentials []*model.AgentSupplierCredential
-	if err := r.db.WithContext(ctx).Preload("Supplier").Where("deleted_at IS NULL").Find(&credentials).Error; err != nil {
+	var total int64
+
+	if err := r.db.WithContext(ctx).Model(&model.AgentSupplierCredential{}).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to count credentials: request cancelled", "error", err)
+			return nil, 0, err
+		}
+		r.logger.ErrorContext(ctx, "Failed to count credentials", "error", err)
+		return nil, 0, fmt.Errorf("failed to count credentials: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Supplier").Where("deleted_at IS NULL").Offset(offset).Limit(limit).Order("id ASC").Find(&credentials).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get all credentials: request cancelled", "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get all credentials", "error", err)
-		return nil, fmt.Errorf("failed to get all credentials: %w", err)
+		return nil, 0, fmt.Errorf("failed to get all credentials: %w", err)
+	}
+	r.logger.InfoContext(ctx, "All credentials retrieved", "count", len(credentials), "total", total)
+	return credentials, int(total), nil
+}
+
+// GetAllAfter retrieves up to limit credentials with an ID greater than afterID, ordered by ID
+// Unlike offset paging, rows added or deleted concurrently do not shift the next page, so no row is skipped
+func (r *credentialRepository) GetAllAfter(ctx context.Context, afterID string, limit int) ([]*model.AgentSupplierCredential, error) {
+	r.logger.InfoContext(ctx, "Getting credentials after ID", "afterID", afterID, "limit", limit)
+	var credentials []*model.AgentSupplierCredential
+	if err := r.db.WithContext(ctx).Where("id > ? AND deleted_at IS NULL", afterID).Limit(limit).Order("id ASC").Find(&credentials).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get credentials after ID: request cancelled", "afterID", afterID, "error", err)
+			return nil, err
+		}
+		r.logger.ErrorContext(ctx, "Failed to get credentials after ID", "afterID", afterID, "error", err)
+		return nil, fmt.Errorf("failed to get credentials after ID: %w", err)
 	}
-	r.logger.InfoContext(ctx, "All credentials retrieved", "count", len(credentials))
+	r.logger.InfoContext(ctx, "Credentials retrieved after ID", "count", len(credentials), "afterID", afterID)
 	return credentials, nil
 }
 
@@ -89,6 +146,10 @@ func (r *credentialRepository) GetByAgentAndSupplier(ctx context.Context, agentI
 			r.logger.WarnContext(ctx, "Credential not found by agent and supplier", "agentID", agentID, "supplierID", supplierID)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get credential by agent and supplier: request cancelled", "agentID", agentID, "supplierID", supplierID, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get credential by agent and supplier", "agentID", agentID, "supplierID", supplierID, "error", err)
 		return nil, fmt.Errorf("failed to get credential: %w", err)
 	}
@@ -100,6 +161,10 @@ func (r *credentialRepository) GetByAgentAndSupplier(ctx context.Context, agentI
 func (r *credentialRepository) Update(ctx context.Context, credential *model.AgentSupplierCredential) error {
 	r.logger.InfoContext(ctx, "Updating credential", "id", credential.ID, "agentID", credential.IataAgentID)
 	if err := r.db.WithContext(ctx).Model(&model.AgentSupplierCredential{}).Where("id = ?", credential.ID).Updates(credential).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update credential: request cancelled", "id", credential.ID, "agentID", credential.IataAgentID, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to update credential", "id", credential.ID, "agentID", credential.IataAgentID, "error", err)
 		return fmt.Errorf("failed to update credential: %w", err)
 	}
@@ -107,6 +172,46 @@ func (r *credentialRepository) Update(ctx context.Context, credential *model.Age
 	return nil
 }
 
+// Rotate stores rotated credentials along with the previous value and its expiry
+// Unlike Update, the previous credential columns are written even when empty, so a rotation without overlap clears them
+// The write only applies while the stored ciphertext is still currentCiphertext; otherwise ErrConflict is returned,
+// so a concurrent update is not overwritten with a value derived from a stale read
+func (r *credentialRepository) Rotate(ctx context.Context, credential *model.AgentSupplierCredential, currentCiphertext string) error {
+	r.logger.InfoContext(ctx, "Rotating credential", "id", credential.ID)
+	result := r.db.WithContext(ctx).Model(&model.AgentSupplierCredential{}).
+		Where("id = ? AND credentials = ? AND deleted_at IS NULL", credential.ID, currentCiphertext).
+		Select("Credentials", "PreviousCredentials", "PreviousExpiresAt").
+		Updates(credential)
+	if err := result.Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to rotate credential: request cancelled", "id", credential.ID, "error", err)
+			return err
+		}
+		r.logger.ErrorContext(ctx, "Failed to rotate credential", "id", credential.ID, "error", err)
+		return fmt.Errorf("failed to rotate credential: %w", err)
+	}
+	if result.RowsAffected == 0 {
+		// Tell a credential changed since it was read from a deleted one
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&model.AgentSupplierCredential{}).Where("id = ? AND deleted_at IS NULL", credential.ID).Count(&count).Error; err != nil {
+			if domain.IsContextError(err) {
+				r.logger.WarnContext(ctx, "Failed to check rotated credential: request cancelled", "id", credential.ID, "error", err)
+				return err
+			}
+			r.logger.ErrorContext(ctx, "Failed to check rotated credential", "id", credential.ID, "error", err)
+			return fmt.Errorf("failed to check rotated credential: %w", err)
+		}
+		if count > 0 {
+			r.logger.WarnContext(ctx, "Credential modified concurrently during rotation", "id", credential.ID)
+			return domain.ErrConflict
+		}
+		r.logger.WarnContext(ctx, "Credential not found for rotation", "id", credential.ID)
+		return domain.ErrNotFound
+	}
+	r.logger.InfoContext(ctx, "Credential rotated successfully", "id", credential.ID)
+	return nil
+}
+
 // Delete removes a credential (soft delete)
 func (r *credentialRepository) Delete(ctx context.Context, id string) error {
 	r.logger.InfoContext(ctx, "Deleting credential", "id", id)
@@ -114,6 +219,10 @@ func (r *credentialRepository) Delete(ctx context.Context, id string) error {
 
 	// Use soft delete
 	if err := r.db.WithContext(ctx).Delete(credential).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete credential: request cancelled", "id", id, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to delete credential", "id", id, "error", err)
 		return fmt.Errorf("failed to delete credential: %w", err)
 	}
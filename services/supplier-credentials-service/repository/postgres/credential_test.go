@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/domain/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialRepository_GetAllAfter(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectQuery(`SELECT \* FROM "agent_supplier_credentials" WHERE \(id > \$1 AND deleted_at IS NULL\) .*ORDER BY id ASC LIMIT \$\d`).
+		WithArgs("cred-1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("cred-2").AddRow("cred-3"))
+
+	credentials, err := NewCredentialRepository(db, logger.NoOpLogger()).GetAllAfter(context.Background(), "cred-1", 2)
+	require.NoError(t, err, "GetAllAfter should not return error")
+	require.Len(t, credentials, 2, "The page should be returned")
+	assert.Equal(t, "cred-2", credentials[0].ID, "Credentials should be ordered by ID")
+	assert.NoError(t, mock.ExpectationsWereMet(), "The page should start after the given ID")
+}
+
+func TestCredentialRepository_RotateClearsPrevious(t *testing.T) {
+	// GORM skips zero values when updating from a struct, so the empty previous columns must be selected
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "agent_supplier_credentials" SET "credentials"=\$1,"previous_credentials"=\$2,"previous_expires_at"=\$3`).
+		WithArgs("v2:2:new", "", nil, sqlmock.AnyArg(), "cred-1", "v2:2:old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	credential := &model.AgentSupplierCredential{ID: "cred-1", Credentials: "v2:2:new"}
+	err := NewCredentialRepository(db, logger.NoOpLogger()).Rotate(context.Background(), credential, "v2:2:old")
+	require.NoError(t, err, "Rotate should not return error")
+	assert.NoError(t, mock.ExpectationsWereMet(), "The previous columns should be cleared")
+}
@@ -4,6 +4,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"monorepo/pkg/logger"
@@ -12,6 +13,7 @@ import (
 	"supplier-credentials-service/domain/repository"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // supplierRepository implements the Supplier repository interface using PostgreSQL
@@ -34,6 +36,10 @@ func NewSupplierRepository(db *gorm.DB, logger logger.LoggerInterface) repositor
 func (r *supplierRepository) Create(ctx context.Context, supplier *model.Supplier) error {
 	r.logger.InfoContext(ctx, "Creating supplier", "code", supplier.SupplierCode)
 	if err := r.db.WithContext(ctx).Create(supplier).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to create supplier: request cancelled", "code", supplier.SupplierCode, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to create supplier", "code", supplier.SupplierCode, "error", err)
 		return fmt.Errorf("failed to create supplier: %w", err)
 	}
@@ -50,6 +56,10 @@ func (r *supplierRepository) GetByID(ctx context.Context, id string) (*model.Sup
 			r.logger.WarnContext(ctx, "Supplier not found by ID", "id", id)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get supplier by ID: request cancelled", "id", id, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get supplier by ID", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get supplier: %w", err)
 	}
@@ -66,6 +76,10 @@ func (r *supplierRepository) GetByCode(ctx context.Context, code string) (*model
 			r.logger.WarnContext(ctx, "Supplier not found by code", "code", code)
 			return nil, domain.ErrNotFound
 		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to get supplier by code: request cancelled", "code", code, "error", err)
+			return nil, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to get supplier by code", "code", code, "error", err)
 		return nil, fmt.Errorf("failed to get supplier: %w", err)
 	}
@@ -81,12 +95,20 @@ func (r *supplierRepository) List(ctx context.Context, offset, limit int) ([]*mo
 
 	// Get total count
 	if err := r.db.WithContext(ctx).Model(&model.Supplier{}).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to count suppliers: request cancelled", "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to count suppliers", "error", err)
 		return nil, 0, fmt.Errorf("failed to count suppliers: %w", err)
 	}
 
 	// Get paginated suppliers
 	if err := r.db.WithContext(ctx).Where("deleted_at IS NULL").Offset(offset).Limit(limit).Order("id ASC").Find(&suppliers).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to list suppliers: request cancelled", "offset", offset, "limit", limit, "error", err)
+			return nil, 0, err
+		}
 		r.logger.ErrorContext(ctx, "Failed to list suppliers", "offset", offset, "limit", limit, "error", err)
 		return nil, 0, fmt.Errorf("failed to list suppliers: %w", err)
 	}
@@ -99,6 +121,10 @@ func (r *supplierRepository) List(ctx context.Context, offset, limit int) ([]*mo
 func (r *supplierRepository) Update(ctx context.Context, supplier *model.Supplier) error {
 	r.logger.InfoContext(ctx, "Updating supplier", "id", supplier.ID, "code", supplier.SupplierCode)
 	if err := r.db.WithContext(ctx).Model(&model.Supplier{}).Where("id = ?", supplier.ID).Updates(supplier).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to update supplier: request cancelled", "id", supplier.ID, "code", supplier.SupplierCode, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to update supplier", "id", supplier.ID, "code", supplier.SupplierCode, "error", err)
 		return fmt.Errorf("failed to update supplier: %w", err)
 	}
@@ -113,6 +139,10 @@ func (r *supplierRepository) Delete(ctx context.Context, id string) error {
 
 	// Use soft delete
 	if err := r.db.WithContext(ctx).Delete(supplier).Error; err != nil {
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete supplier: request cancelled", "id", id, "error", err)
+			return err
+		}
 		r.logger.ErrorContext(ctx, "Failed to delete supplier", "id", id, "error", err)
 		return fmt.Errorf("failed to delete supplier: %w", err)
 	}
@@ -128,3 +158,98 @@ func (r *supplierRepository) Delete(ctx context.Context, id string) error {
 	r.logger.InfoContext(ctx, "Supplier deleted successfully", "id", id)
 	return nil
 }
+
+// lockSupplier locks the row of a supplier that is not deleted until the transaction ends
+// Credentials cannot be added for the supplier meanwhile, since their foreign key check waits on the lock
+func lockSupplier(tx *gorm.DB, id string) error {
+	var supplier model.Supplier
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND deleted_at IS NULL", id).First(&supplier).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock supplier: %w", err)
+	}
+	return nil
+}
+
+// DeleteUnreferenced soft deletes a supplier unless credentials reference it, in one transaction
+// It returns the number of referencing credentials; the supplier is only deleted when there are none
+func (r *supplierRepository) DeleteUnreferenced(ctx context.Context, id string) (int, error) {
+	r.logger.InfoContext(ctx, "Deleting unreferenced supplier", "id", id)
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSupplier(tx, id); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.AgentSupplierCredential{}).Where("supplier_id = ? AND deleted_at IS NULL", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count supplier credentials: %w", err)
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&model.Supplier{ID: id}).Error; err != nil {
+			return fmt.Errorf("failed to delete supplier: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			r.logger.WarnContext(ctx, "Supplier not found for deletion", "id", id)
+			return 0, err
+		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete unreferenced supplier: request cancelled", "id", id, "error", err)
+			return 0, err
+		}
+		r.logger.ErrorContext(ctx, "Failed to delete unreferenced supplier", "id", id, "error", err)
+		return 0, err
+	}
+
+	if count > 0 {
+		r.logger.WarnContext(ctx, "Supplier not deleted: still referenced by credentials", "id", id, "count", count)
+		return int(count), nil
+	}
+	r.logger.InfoContext(ctx, "Supplier deleted successfully", "id", id)
+	return 0, nil
+}
+
+// DeleteWithCredentials soft deletes a supplier along with the credentials that reference it, in one transaction
+// It returns the number of credentials deleted
+func (r *supplierRepository) DeleteWithCredentials(ctx context.Context, id string) (int, error) {
+	r.logger.InfoContext(ctx, "Deleting supplier with credentials", "id", id)
+	var count int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockSupplier(tx, id); err != nil {
+			return err
+		}
+
+		credentials := tx.Where("supplier_id = ?", id).Delete(&model.AgentSupplierCredential{})
+		if credentials.Error != nil {
+			return fmt.Errorf("failed to delete supplier credentials: %w", credentials.Error)
+		}
+		count = credentials.RowsAffected
+
+		if err := tx.Delete(&model.Supplier{ID: id}).Error; err != nil {
+			return fmt.Errorf("failed to delete supplier: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			r.logger.WarnContext(ctx, "Supplier not found for deletion", "id", id)
+			return 0, err
+		}
+		if domain.IsContextError(err) {
+			r.logger.WarnContext(ctx, "Failed to delete supplier with credentials: request cancelled", "id", id, "error", err)
+			return 0, err
+		}
+		r.logger.ErrorContext(ctx, "Failed to delete supplier with credentials", "id", id, "error", err)
+		return 0, err
+	}
+
+	r.logger.InfoContext(ctx, "Supplier deleted with credentials successfully", "id", id, "count", count)
+	return int(count), nil
+}
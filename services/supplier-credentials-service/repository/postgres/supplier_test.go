@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newMockDB returns a GORM database backed by sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err, "Failed to create sqlmock")
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: gormlogger.Discard})
+	require.NoError(t, err, "Failed to open GORM database")
+	return db, mock
+}
+
+// expectSupplierLock expects the supplier row to be locked, returning it when found
+func expectSupplierLock(mock sqlmock.Sqlmock, id string, found bool) {
+	rows := sqlmock.NewRows([]string{"id"})
+	if found {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "suppliers" .* FOR UPDATE`).WillReturnRows(rows)
+}
+
+func TestSupplierRepository_DeleteUnreferenced(t *testing.T) {
+	const id = "01JABCDEFGHJKMNPQRSTVWXYZ0"
+
+	t.Run("referenced supplier is kept", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		expectSupplierLock(mock, id, true)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "agent_supplier_credentials"`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectCommit()
+
+		count, err := NewSupplierRepository(db, logger.NoOpLogger()).DeleteUnreferenced(context.Background(), id)
+		require.NoError(t, err, "DeleteUnreferenced should not return error")
+		assert.Equal(t, 2, count, "The referencing credentials should be counted")
+		assert.NoError(t, mock.ExpectationsWereMet(), "The supplier should not be deleted")
+	})
+
+	t.Run("unreferenced supplier is deleted in the same transaction", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		expectSupplierLock(mock, id, true)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "agent_supplier_credentials"`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`UPDATE "suppliers" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		count, err := NewSupplierRepository(db, logger.NoOpLogger()).DeleteUnreferenced(context.Background(), id)
+		require.NoError(t, err, "DeleteUnreferenced should not return error")
+		assert.Zero(t, count, "No credentials should reference the supplier")
+		assert.NoError(t, mock.ExpectationsWereMet(), "The count and delete should share one transaction")
+	})
+
+	t.Run("missing supplier", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		expectSupplierLock(mock, id, false)
+		mock.ExpectRollback()
+
+		_, err := NewSupplierRepository(db, logger.NoOpLogger()).DeleteUnreferenced(context.Background(), id)
+		assert.ErrorIs(t, err, domain.ErrNotFound, "A missing supplier should not be found")
+		assert.NoError(t, mock.ExpectationsWereMet(), "The transaction should be rolled back")
+	})
+}
+
+func TestSupplierRepository_DeleteWithCredentials(t *testing.T) {
+	const id = "01JABCDEFGHJKMNPQRSTVWXYZ0"
+
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	expectSupplierLock(mock, id, true)
+	mock.ExpectExec(`UPDATE "agent_supplier_credentials" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`UPDATE "suppliers" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := NewSupplierRepository(db, logger.NoOpLogger()).DeleteWithCredentials(context.Background(), id)
+	require.NoError(t, err, "DeleteWithCredentials should not return error")
+	assert.Equal(t, 3, count, "The deleted credentials should be counted")
+	assert.NoError(t, mock.ExpectationsWereMet(), "The supplier and its credentials should be deleted in one transaction")
+}
@@ -3,12 +3,9 @@ package usecase
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"time"
 
 	"monorepo/pkg/logger"
 	"supplier-credentials-service/domain"
@@ -20,16 +17,79 @@ import (
 type CredentialUseCase interface {
 	// CreateCredential adds a new supplier credential for an agent
 	CreateCredential(ctx context.Context, credential *model.AgentSupplierCredential) error
-	// GetCredentialByID retrieves a credential by its ID
-	GetCredentialByID(ctx context.Context, id string) (*model.AgentSupplierCredential, error)
-	// GetCredentialsByAgentID retrieves all credentials for an agent
-	GetCredentialsByAgentID(ctx context.Context, agentID string) ([]*model.AgentSupplierCredential, error)
-	// GetAllCredentials retrieves all credentials
-	GetAllCredentials(ctx context.Context) ([]*model.AgentSupplierCredential, error)
-	// UpdateCredential modifies an existing credential
+	// GetCredentialByID retrieves a credential of an agent by its ID
+	GetCredentialByID(ctx context.Context, agentID string, id string) (*model.AgentSupplierCredential, error)
+	// GetCredentialByAgentAndSupplier retrieves the credential of an agent for a supplier
+	GetCredentialByAgentAndSupplier(ctx context.Context, agentID string, supplierID string) (*model.AgentSupplierCredential, error)
+	// GetCredentialsByAgentID retrieves a page of the credentials of an agent, along with their total count
+	GetCredentialsByAgentID(ctx context.Context, agentID string, offset, limit int) ([]*model.AgentSupplierCredential, int, error)
+	// GetAllCredentials retrieves a page of all credentials, skipping undecryptable ones unless strict is set
+	GetAllCredentials(ctx context.Context, strict bool, offset, limit int) (*CredentialList, error)
+	// UpdateCredential modifies an existing credential of the agent set in credential.IataAgentID
 	UpdateCredential(ctx context.Context, credential *model.AgentSupplierCredential) error
-	// DeleteCredential removes a credential
-	DeleteCredential(ctx context.Context, id string) error
+	// RotateCredential replaces a credential of an agent, keeping the previous value valid for overlap
+	RotateCredential(ctx context.Context, agentID string, id string, newCredentials string, overlap time.Duration) error
+	// VerifyEncryptionKey checks that the configured keys can encrypt and decrypt stored credentials
+	VerifyEncryptionKey(ctx context.Context) error
+	// ReencryptAll re-encrypts with the primary key and latest format the credentials encrypted otherwise
+	ReencryptAll(ctx context.Context) (*ReencryptResult, error)
+	// DeleteCredential removes a credential of an agent
+	DeleteCredential(ctx context.Context, agentID string, id string) error
+}
+
+// CredentialFailure identifies a credential that could not be decrypted
+type CredentialFailure struct {
+	// ID is the ID of the credential
+	ID string
+	// IataAgentID is the IATA agent owning the credential
+	IataAgentID string
+	// SupplierID is the supplier the credential belongs to
+	SupplierID string
+	// Reason describes why the credential could not be decrypted
+	Reason string
+}
+
+// CredentialList holds decrypted credentials along with the credentials that were skipped
+type CredentialList struct {
+	// Credentials are the successfully decrypted credentials
+	Credentials []*model.AgentSupplierCredential
+	// Failures are the credentials that could not be decrypted
+	Failures []CredentialFailure
+	// Total is the number of credentials across all pages, including those that could not be decrypted
+	Total int
+}
+
+// verifySampleSize is the number of stored credentials VerifyEncryptionKey tries to decrypt
+const verifySampleSize = 10
+
+// reencryptBatchSize is the number of credentials ReencryptAll reads at a time
+const reencryptBatchSize = 100
+
+// normalizePage applies the default and maximum page size to offset and limit
+func normalizePage(offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return offset, limit
+}
+
+// ReencryptResult reports the outcome of re-encrypting credentials with the primary key
+type ReencryptResult struct {
+	// Reencrypted is the number of credentials moved to the primary key and latest format
+	Reencrypted int
+	// Unchanged is the number of credentials already encrypted with the primary key and latest format
+	Unchanged int
+	// Conflicts is the number of credentials skipped because they were updated, rotated or deleted during the run;
+	// they were written with the primary key by that change, or are picked up by the next run
+	Conflicts int
+	// Failures are the credentials that could not be re-encrypted
+	Failures []CredentialFailure
 }
 
 // credentialUseCase implements the CredentialUseCase interface
@@ -40,88 +100,83 @@ type credentialUseCase struct {
 	supplierUseCase SupplierUseCase
 	// logger is used for logging operations within the usecase
 	logger logger.LoggerInterface
-	// encryptionKey is the key used for encrypting/decrypting credentials
-	encryptionKey string
+	// keyring holds the keys used for encrypting/decrypting credentials
+	keyring Keyring
 }
 
 // NewCredentialUseCase creates a new instance of credentialUseCase
-func NewCredentialUseCase(credentialRepo repository.Credential, supplierUseCase SupplierUseCase, appLogger logger.LoggerInterface, encryptionKey string) CredentialUseCase {
+func NewCredentialUseCase(credentialRepo repository.Credential, supplierUseCase SupplierUseCase, appLogger logger.LoggerInterface, keyring Keyring) CredentialUseCase {
 	return &credentialUseCase{
 		credentialRepo:  credentialRepo,
 		supplierUseCase: supplierUseCase,
 		logger:          appLogger,
-		encryptionKey:   encryptionKey,
+		keyring:         keyring,
 	}
 }
 
-// encrypt encrypts the given plaintext using AES-GCM
-func (uc *credentialUseCase) encrypt(plaintext string) (string, error) {
-	if uc.encryptionKey == "" {
-		return "", errors.New("encryption key not set")
-	}
-
-	key := []byte(uc.encryptionKey)
-	if len(key) != 32 {
-		return "", errors.New("encryption key must be 32 bytes")
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return "", err
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+// CredentialAAD returns the associated data binding a ciphertext to its agent-supplier pair
+// A ciphertext copied to another row then fails to decrypt
+func CredentialAAD(iataAgentID, supplierID string) []byte {
+	return []byte(iataAgentID + "|" + supplierID)
 }
 
-// decrypt decrypts the given ciphertext using AES-GCM
-func (uc *credentialUseCase) decrypt(ciphertext string) (string, error) {
-	if uc.encryptionKey == "" {
-		return "", errors.New("encryption key not set")
-	}
+// encrypt encrypts the given plaintext with the primary key using AES-GCM, bound to the agent and supplier
+func (uc *credentialUseCase) encrypt(plaintext, iataAgentID, supplierID string) (string, error) {
+	return uc.keyring.Encrypt(plaintext, CredentialAAD(iataAgentID, supplierID))
+}
 
-	key := []byte(uc.encryptionKey)
-	if len(key) != 32 {
-		return "", errors.New("encryption key must be 32 bytes")
-	}
+// decrypt decrypts the given ciphertext with the key that encrypted it, using AES-GCM
+// Returns ErrCiphertextMismatch if the ciphertext is bound to another agent or supplier
+func (uc *credentialUseCase) decrypt(ciphertext, iataAgentID, supplierID string) (string, error) {
+	return uc.keyring.Decrypt(ciphertext, CredentialAAD(iataAgentID, supplierID))
+}
 
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+// decryptCredential decrypts the credentials of cred in place
+// The previous credentials are decrypted while the rotation overlap lasts and cleared once it has elapsed
+func (uc *credentialUseCase) decryptCredential(cred *model.AgentSupplierCredential) error {
+	decrypted, err := uc.decrypt(cred.Credentials, cred.IataAgentID, cred.SupplierID)
 	if err != nil {
-		return "", err
+		return err
 	}
+	cred.Credentials = decrypted
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
+	if cred.PreviousCredentials == "" || cred.PreviousExpiresAt == nil || !time.Now().Before(*cred.PreviousExpiresAt) {
+		cred.PreviousCredentials = ""
+		cred.PreviousExpiresAt = nil
+		return nil
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	previous, err := uc.decrypt(cred.PreviousCredentials, cred.IataAgentID, cred.SupplierID)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("previous credentials: %w", err)
 	}
+	cred.PreviousCredentials = previous
+	return nil
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
+// getOwnedCredential retrieves a credential by ID and checks that it belongs to agentID
+// A credential of another agent is reported as not found, so its existence is not disclosed
+func (uc *credentialUseCase) getOwnedCredential(ctx context.Context, agentID string, id string) (*model.AgentSupplierCredential, error) {
+	if agentID == "" {
+		uc.logger.WarnContext(ctx, "IATA agent ID is required for credential access", "id", id)
+		return nil, domain.ErrIataAgentIDRequired
 	}
 
-	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
+	credential, err := uc.credentialRepo.GetByID(ctx, id)
 	if err != nil {
-		return "", err
+		if errors.Is(err, domain.ErrNotFound) {
+			uc.logger.WarnContext(ctx, "Credential not found", "id", id)
+			return nil, domain.ErrCredentialNotFound
+		}
+		uc.logger.ErrorContext(ctx, "Error getting credential by ID", "id", id, "error", err)
+		return nil, fmt.Errorf("error getting credential: %w", err)
 	}
 
-	return string(plaintext), nil
+	if credential.IataAgentID != agentID {
+		uc.logger.WarnContext(ctx, "Credential belongs to another agent", "id", id, "agentID", agentID)
+		return nil, domain.ErrCredentialNotFound
+	}
+	return credential, nil
 }
 
 // CreateCredential creates a new supplier credential for an agent
@@ -167,7 +222,7 @@ func (uc *credentialUseCase) CreateCredential(ctx context.Context, credential *m
 	}
 
 	// Encrypt credentials
-	encryptedCredentials, err := uc.encrypt(credential.Credentials)
+	encryptedCredentials, err := uc.encrypt(credential.Credentials, credential.IataAgentID, credential.SupplierID)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to encrypt credentials", "error", err)
 		return fmt.Errorf("failed to encrypt credentials: %w", err)
@@ -184,88 +239,135 @@ func (uc *credentialUseCase) CreateCredential(ctx context.Context, credential *m
 }
 
 // GetCredentialByID retrieves a credential by ID
-func (uc *credentialUseCase) GetCredentialByID(ctx context.Context, id string) (*model.AgentSupplierCredential, error) {
-	uc.logger.InfoContext(ctx, "Getting credential by ID in usecase", "id", id)
+// A credential of another agent is reported as not found
+func (uc *credentialUseCase) GetCredentialByID(ctx context.Context, agentID string, id string) (*model.AgentSupplierCredential, error) {
+	uc.logger.InfoContext(ctx, "Getting credential by ID in usecase", "id", id, "agentID", agentID)
 	if id == "" {
 		uc.logger.WarnContext(ctx, "Invalid credential ID provided", "id", id)
 		return nil, domain.ErrInvalidID
 	}
 
-	credential, err := uc.credentialRepo.GetByID(ctx, id)
+	credential, err := uc.getOwnedCredential(ctx, agentID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt credentials, along with the previous ones during a rotation overlap
+	if err := uc.decryptCredential(credential); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to decrypt credentials", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	uc.logger.InfoContext(ctx, "Credential retrieved by ID in usecase", "id", credential.ID, "agentID", credential.IataAgentID)
+	return credential, nil
+}
+
+// GetCredentialByAgentAndSupplier retrieves the credential of an agent for a supplier
+func (uc *credentialUseCase) GetCredentialByAgentAndSupplier(ctx context.Context, agentID string, supplierID string) (*model.AgentSupplierCredential, error) {
+	log := uc.logger.With("agentID", agentID, "supplierID", supplierID)
+	log.InfoContext(ctx, "Getting credential by agent and supplier in usecase")
+	if agentID == "" {
+		log.WarnContext(ctx, "IATA agent ID is required for credential lookup")
+		return nil, domain.ErrIataAgentIDRequired
+	}
+	if supplierID == "" {
+		log.WarnContext(ctx, "Supplier ID is required for credential lookup")
+		return nil, domain.ErrSupplierIDRequired
+	}
+
+	credential, err := uc.credentialRepo.GetByAgentAndSupplier(ctx, agentID, supplierID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Credential not found", "id", id)
+			log.WarnContext(ctx, "Credential not found for agent and supplier")
 			return nil, domain.ErrCredentialNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error getting credential by ID", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error getting credential by agent and supplier", "error", err)
 		return nil, fmt.Errorf("error getting credential: %w", err)
 	}
 
-	// Decrypt credentials
-	decryptedCredentials, err := uc.decrypt(credential.Credentials)
-	if err != nil {
-		uc.logger.ErrorContext(ctx, "Failed to decrypt credentials", "id", id, "error", err)
+	// Decrypt credentials, along with the previous ones during a rotation overlap
+	if err := uc.decryptCredential(credential); err != nil {
+		log.ErrorContext(ctx, "Failed to decrypt credentials", "id", credential.ID, "error", err)
 		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
 	}
-	credential.Credentials = decryptedCredentials
 
-	uc.logger.InfoContext(ctx, "Credential retrieved by ID in usecase", "id", credential.ID, "agentID", credential.IataAgentID)
+	log.InfoContext(ctx, "Credential retrieved by agent and supplier in usecase", "id", credential.ID)
 	return credential, nil
 }
 
-// GetCredentialsByAgentID retrieves credentials for an agent
-func (uc *credentialUseCase) GetCredentialsByAgentID(ctx context.Context, agentID string) ([]*model.AgentSupplierCredential, error) {
-	uc.logger.InfoContext(ctx, "Getting credentials by agent ID in usecase", "agentID", agentID)
+// GetCredentialsByAgentID retrieves a page of credentials for an agent
+// Returns the credentials and the total number of credentials of the agent
+func (uc *credentialUseCase) GetCredentialsByAgentID(ctx context.Context, agentID string, offset, limit int) ([]*model.AgentSupplierCredential, int, error) {
+	uc.logger.InfoContext(ctx, "Getting credentials by agent ID in usecase", "agentID", agentID, "offset", offset, "limit", limit)
 	if agentID == "" {
 		uc.logger.WarnContext(ctx, "Invalid agent ID provided", "agentID", agentID)
-		return nil, domain.ErrInvalidID
+		return nil, 0, domain.ErrInvalidID
 	}
+	offset, limit = normalizePage(offset, limit)
 
-	credentials, err := uc.credentialRepo.GetByAgentID(ctx, agentID)
+	credentials, total, err := uc.credentialRepo.GetByAgentID(ctx, agentID, offset, limit)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Error getting credentials by agent ID", "agentID", agentID, "error", err)
-		return nil, fmt.Errorf("error getting credentials: %w", err)
+		return nil, 0, fmt.Errorf("error getting credentials: %w", err)
 	}
 
 	// Decrypt credentials for each
 	for _, cred := range credentials {
-		decrypted, err := uc.decrypt(cred.Credentials)
-		if err != nil {
+		if err := uc.decryptCredential(cred); err != nil {
 			uc.logger.ErrorContext(ctx, "Failed to decrypt credentials", "id", cred.ID, "error", err)
-			return nil, fmt.Errorf("failed to decrypt credentials for id %s: %w", cred.ID, err)
+			return nil, 0, fmt.Errorf("failed to decrypt credentials for id %s: %w", cred.ID, err)
 		}
-		cred.Credentials = decrypted
 	}
 
-	uc.logger.InfoContext(ctx, "Credentials retrieved by agent ID in usecase", "count", len(credentials), "agentID", agentID)
-	return credentials, nil
+	uc.logger.InfoContext(ctx, "Credentials retrieved by agent ID in usecase", "count", len(credentials), "agentID", agentID, "total", total)
+	return credentials, total, nil
 }
 
-// GetAllCredentials retrieves all credentials
-func (uc *credentialUseCase) GetAllCredentials(ctx context.Context) ([]*model.AgentSupplierCredential, error) {
-	uc.logger.InfoContext(ctx, "Getting all credentials in usecase")
+// GetAllCredentials retrieves a page of all credentials
+// Credentials that cannot be decrypted are skipped and reported in Failures so one corrupt row
+// does not block the whole list; in strict mode the first such credential fails the call instead
+func (uc *credentialUseCase) GetAllCredentials(ctx context.Context, strict bool, offset, limit int) (*CredentialList, error) {
+	uc.logger.InfoContext(ctx, "Getting all credentials in usecase", "strict", strict, "offset", offset, "limit", limit)
+	offset, limit = normalizePage(offset, limit)
 
-	credentials, err := uc.credentialRepo.GetAll(ctx)
+	credentials, total, err := uc.credentialRepo.GetAll(ctx, offset, limit)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Error getting all credentials", "error", err)
 		return nil, fmt.Errorf("error getting all credentials: %w", err)
 	}
 
 	// Decrypt credentials for each
+	result := &CredentialList{
+		Credentials: make([]*model.AgentSupplierCredential, 0, len(credentials)),
+		Total:       total,
+	}
 	for _, cred := range credentials {
-		decrypted, err := uc.decrypt(cred.Credentials)
-		if err != nil {
+		if err := uc.decryptCredential(cred); err != nil {
 			uc.logger.ErrorContext(ctx, "Failed to decrypt credentials", "id", cred.ID, "error", err)
-			return nil, fmt.Errorf("failed to decrypt credentials for id %s: %w", cred.ID, err)
+			if strict {
+				return nil, fmt.Errorf("failed to decrypt credentials for id %s: %w", cred.ID, err)
+			}
+			result.Failures = append(result.Failures, CredentialFailure{
+				ID:          cred.ID,
+				IataAgentID: cred.IataAgentID,
+				SupplierID:  cred.SupplierID,
+				Reason:      err.Error(),
+			})
+			continue
 		}
-		cred.Credentials = decrypted
+		result.Credentials = append(result.Credentials, cred)
+	}
+
+	if len(result.Failures) > 0 {
+		uc.logger.WarnContext(ctx, "Skipped undecryptable credentials", "count", len(result.Failures))
 	}
 
-	uc.logger.InfoContext(ctx, "All credentials retrieved in usecase", "count", len(credentials))
-	return credentials, nil
+	uc.logger.InfoContext(ctx, "All credentials retrieved in usecase", "count", len(result.Credentials), "failed", len(result.Failures), "total", total)
+	return result, nil
 }
 
 // UpdateCredential updates an existing credential
+// The new value replaces the current one at once; previous credentials of an ongoing rotation are dropped
 func (uc *credentialUseCase) UpdateCredential(ctx context.Context, credential *model.AgentSupplierCredential) error {
 	uc.logger.InfoContext(ctx, "Updating credential in usecase", "id", credential.ID, "agentID", credential.IataAgentID)
 
@@ -280,30 +382,35 @@ func (uc *credentialUseCase) UpdateCredential(ctx context.Context, credential *m
 		return domain.ErrCredentialsRequired
 	}
 
-	// Check if credential exists
-	existing, err := uc.credentialRepo.GetByID(ctx, credential.ID)
+	// Check that the credential exists and belongs to the agent
+	existing, err := uc.getOwnedCredential(ctx, credential.IataAgentID, credential.ID)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Credential not found for update", "id", credential.ID)
-			return domain.ErrCredentialNotFound
-		}
-		uc.logger.ErrorContext(ctx, "Error checking existing credential", "id", credential.ID, "error", err)
-		return fmt.Errorf("error checking existing credential: %w", err)
+		return err
 	}
 
 	// Encrypt new credentials
-	encryptedCredentials, err := uc.encrypt(credential.Credentials)
+	encryptedCredentials, err := uc.encrypt(credential.Credentials, existing.IataAgentID, existing.SupplierID)
 	if err != nil {
 		uc.logger.ErrorContext(ctx, "Failed to encrypt credentials", "error", err)
 		return fmt.Errorf("failed to encrypt credentials: %w", err)
 	}
 	credential.Credentials = encryptedCredentials
 
-	// Preserve agent and supplier IDs
-	credential.IataAgentID = existing.IataAgentID
+	// Preserve the supplier ID
 	credential.SupplierID = existing.SupplierID
+	credential.PreviousCredentials = ""
+	credential.PreviousExpiresAt = nil
 
-	if err := uc.credentialRepo.Update(ctx, credential); err != nil {
+	// Rotate writes the previous credential columns even when empty, so they are cleared
+	if err := uc.credentialRepo.Rotate(ctx, credential, existing.Credentials); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			uc.logger.WarnContext(ctx, "Credential deleted during update", "id", credential.ID)
+			return domain.ErrCredentialNotFound
+		}
+		if errors.Is(err, domain.ErrConflict) {
+			uc.logger.WarnContext(ctx, "Credential modified during update", "id", credential.ID)
+			return domain.ErrCredentialModified
+		}
 		uc.logger.ErrorContext(ctx, "Failed to update credential in repository", "id", credential.ID, "error", err)
 		return err
 	}
@@ -312,23 +419,78 @@ func (uc *credentialUseCase) UpdateCredential(ctx context.Context, credential *m
 	return nil
 }
 
-// DeleteCredential deletes a credential
-func (uc *credentialUseCase) DeleteCredential(ctx context.Context, id string) error {
-	uc.logger.InfoContext(ctx, "Deleting credential in usecase", "id", id)
+// RotateCredential replaces the credentials of an existing credential
+// The replaced value stays readable as the previous credentials until overlap elapses; a zero overlap drops it at once
+// Only one previous value is kept, so rotating again during an overlap drops the oldest value
+func (uc *credentialUseCase) RotateCredential(ctx context.Context, agentID string, id string, newCredentials string, overlap time.Duration) error {
+	uc.logger.InfoContext(ctx, "Rotating credential in usecase", "id", id, "agentID", agentID, "overlap", overlap)
 	if id == "" {
-		uc.logger.WarnContext(ctx, "Invalid credential ID provided", "id", id)
+		uc.logger.WarnContext(ctx, "Credential ID is required for rotation")
 		return domain.ErrInvalidID
 	}
 
-	// Check if credential exists
-	_, err := uc.credentialRepo.GetByID(ctx, id)
+	if newCredentials == "" {
+		uc.logger.WarnContext(ctx, "Credentials are required for rotation", "id", id)
+		return domain.ErrCredentialsRequired
+	}
+
+	if overlap < 0 {
+		uc.logger.WarnContext(ctx, "Negative rotation overlap", "id", id, "overlap", overlap)
+		return domain.ErrInvalidRotationOverlap
+	}
+
+	existing, err := uc.getOwnedCredential(ctx, agentID, id)
+	if err != nil {
+		return err
+	}
+
+	encryptedCredentials, err := uc.encrypt(newCredentials, existing.IataAgentID, existing.SupplierID)
 	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to encrypt credentials", "error", err)
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	rotated := &model.AgentSupplierCredential{
+		ID:          existing.ID,
+		IataAgentID: existing.IataAgentID,
+		SupplierID:  existing.SupplierID,
+		Credentials: encryptedCredentials,
+	}
+	if overlap > 0 {
+		// The stored value is kept encrypted as is
+		expiresAt := time.Now().Add(overlap)
+		rotated.PreviousCredentials = existing.Credentials
+		rotated.PreviousExpiresAt = &expiresAt
+	}
+
+	if err := uc.credentialRepo.Rotate(ctx, rotated, existing.Credentials); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Credential not found for deletion", "id", id)
+			uc.logger.WarnContext(ctx, "Credential deleted during rotation", "id", id)
 			return domain.ErrCredentialNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error checking existing credential", "id", id, "error", err)
-		return fmt.Errorf("error checking existing credential: %w", err)
+		if errors.Is(err, domain.ErrConflict) {
+			uc.logger.WarnContext(ctx, "Credential modified during rotation", "id", id)
+			return domain.ErrCredentialModified
+		}
+		uc.logger.ErrorContext(ctx, "Failed to rotate credential in repository", "id", id, "error", err)
+		return err
+	}
+
+	uc.logger.InfoContext(ctx, "Credential rotated successfully in usecase", "id", id, "agentID", existing.IataAgentID)
+	return nil
+}
+
+// DeleteCredential deletes a credential
+func (uc *credentialUseCase) DeleteCredential(ctx context.Context, agentID string, id string) error {
+	uc.logger.InfoContext(ctx, "Deleting credential in usecase", "id", id, "agentID", agentID)
+	if id == "" {
+		uc.logger.WarnContext(ctx, "Invalid credential ID provided", "id", id)
+		return domain.ErrInvalidID
+	}
+
+	// Check that the credential exists and belongs to the agent
+	if _, err := uc.getOwnedCredential(ctx, agentID, id); err != nil {
+		return err
 	}
 
 	if err := uc.credentialRepo.Delete(ctx, id); err != nil {
@@ -339,3 +501,135 @@ func (uc *credentialUseCase) DeleteCredential(ctx context.Context, id string) er
 	uc.logger.InfoContext(ctx, "Credential deleted successfully in usecase", "id", id)
 	return nil
 }
+
+// ReencryptAll re-encrypts with the primary key every credential encrypted with a retired key or an older format,
+// such as ciphertexts without a key id or not bound to their agent and supplier
+// Previous credentials of an ongoing rotation are re-encrypted too; expired ones are dropped
+// A credential that cannot be re-encrypted is reported in Failures without stopping the run
+// Credentials are read in ID order after the last one seen, so rows deleted during the run do not cause others to be skipped
+// Once it reports no failures, retired keys can be removed from the configuration
+func (uc *credentialUseCase) ReencryptAll(ctx context.Context) (*ReencryptResult, error) {
+	log := uc.logger.With("keyID", uc.keyring.PrimaryID)
+	log.InfoContext(ctx, "Re-encrypting credentials in usecase")
+
+	result := &ReencryptResult{}
+	for lastID := ""; ; {
+		credentials, err := uc.credentialRepo.GetAllAfter(ctx, lastID, reencryptBatchSize)
+		if err != nil {
+			log.ErrorContext(ctx, "Error getting credentials", "afterID", lastID, "error", err)
+			return nil, fmt.Errorf("error getting credentials: %w", err)
+		}
+
+		uc.reencryptBatch(ctx, credentials, result)
+		if err := ctx.Err(); err != nil {
+			log.WarnContext(ctx, "Re-encryption cancelled", "reencrypted", result.Reencrypted, "error", err)
+			return nil, err
+		}
+		if len(credentials) < reencryptBatchSize {
+			break
+		}
+		lastID = credentials[len(credentials)-1].ID
+	}
+
+	log.InfoContext(ctx, "Credentials re-encrypted in usecase", "reencrypted", result.Reencrypted, "unchanged", result.Unchanged, "conflicts", result.Conflicts, "failed", len(result.Failures))
+	return result, nil
+}
+
+// reencryptBatch re-encrypts the credentials that need it and records the outcome in result
+// It stops early if ctx is cancelled
+func (uc *credentialUseCase) reencryptBatch(ctx context.Context, credentials []*model.AgentSupplierCredential, result *ReencryptResult) {
+	for _, cred := range credentials {
+		if ctx.Err() != nil {
+			return
+		}
+
+		expired := cred.PreviousExpiresAt == nil || !time.Now().Before(*cred.PreviousExpiresAt)
+		currentUpToDate := !uc.keyring.NeedsReencryption(cred.Credentials)
+		previousUpToDate := cred.PreviousCredentials == "" || (!expired && !uc.keyring.NeedsReencryption(cred.PreviousCredentials))
+		if currentUpToDate && previousUpToDate {
+			result.Unchanged++
+			continue
+		}
+
+		if err := uc.reencrypt(ctx, cred, expired); err != nil {
+			if errors.Is(err, domain.ErrConflict) || errors.Is(err, domain.ErrNotFound) {
+				uc.logger.WarnContext(ctx, "Credential changed during re-encryption, skipped", "id", cred.ID, "error", err)
+				result.Conflicts++
+				continue
+			}
+			uc.logger.ErrorContext(ctx, "Failed to re-encrypt credential", "id", cred.ID, "error", err)
+			result.Failures = append(result.Failures, CredentialFailure{
+				ID:          cred.ID,
+				IataAgentID: cred.IataAgentID,
+				SupplierID:  cred.SupplierID,
+				Reason:      err.Error(),
+			})
+			continue
+		}
+		result.Reencrypted++
+	}
+}
+
+// reencrypt re-encrypts the current and, unless expired, previous credentials of cred with the primary key
+func (uc *credentialUseCase) reencrypt(ctx context.Context, cred *model.AgentSupplierCredential, previousExpired bool) error {
+	rotated := &model.AgentSupplierCredential{ID: cred.ID}
+
+	plaintext, err := uc.decrypt(cred.Credentials, cred.IataAgentID, cred.SupplierID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+	if rotated.Credentials, err = uc.encrypt(plaintext, cred.IataAgentID, cred.SupplierID); err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	if cred.PreviousCredentials != "" && !previousExpired {
+		previous, err := uc.decrypt(cred.PreviousCredentials, cred.IataAgentID, cred.SupplierID)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt previous credentials: %w", err)
+		}
+		if rotated.PreviousCredentials, err = uc.encrypt(previous, cred.IataAgentID, cred.SupplierID); err != nil {
+			return fmt.Errorf("failed to encrypt previous credentials: %w", err)
+		}
+		rotated.PreviousExpiresAt = cred.PreviousExpiresAt
+	}
+
+	// The write is skipped if the credential was updated or rotated since it was read
+	return uc.credentialRepo.Rotate(ctx, rotated, cred.Credentials)
+}
+
+// VerifyEncryptionKey checks the keyring at startup, so a wrong key fails fast instead of failing every read
+// It round-trips a value through the primary key, then decrypts a sample of stored credentials
+// An error is returned if no sampled credential can be decrypted; a few undecryptable rows are only logged,
+// since a single corrupt row should not keep the service down
+func (uc *credentialUseCase) VerifyEncryptionKey(ctx context.Context) error {
+	log := uc.logger.With("keyID", uc.keyring.PrimaryID)
+	log.InfoContext(ctx, "Verifying encryption key")
+
+	const sentinel = "encryption-key-check"
+	ciphertext, err := uc.encrypt(sentinel, "", "")
+	if err != nil {
+		return fmt.Errorf("encryption key check failed: %w", err)
+	}
+	if plaintext, err := uc.decrypt(ciphertext, "", ""); err != nil || plaintext != sentinel {
+		return fmt.Errorf("encryption key check failed: round trip with key %q failed: %v", uc.keyring.PrimaryID, err)
+	}
+
+	credentials, total, err := uc.credentialRepo.GetAll(ctx, 0, verifySampleSize)
+	if err != nil {
+		return fmt.Errorf("encryption key check failed: error getting credentials: %w", err)
+	}
+
+	var failures []error
+	for _, cred := range credentials {
+		if _, err := uc.decrypt(cred.Credentials, cred.IataAgentID, cred.SupplierID); err != nil {
+			log.WarnContext(ctx, "Stored credential cannot be decrypted", "id", cred.ID, "keyUsed", KeyID(cred.Credentials), "error", err)
+			failures = append(failures, fmt.Errorf("credential %s: %w", cred.ID, err))
+		}
+	}
+	if len(credentials) > 0 && len(failures) == len(credentials) {
+		return fmt.Errorf("encryption key check failed: none of %d sampled credentials can be decrypted, the key is probably wrong: %w", len(credentials), errors.Join(failures...))
+	}
+
+	log.InfoContext(ctx, "Encryption key verified", "sampled", len(credentials), "failed", len(failures), "total", total)
+	return nil
+}
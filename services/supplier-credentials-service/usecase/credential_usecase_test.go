@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"monorepo/pkg/logger"
+	"supplier-credentials-service/domain"
+	"supplier-credentials-service/domain/model"
+	"supplier-credentials-service/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCredentialRepo is an in-memory credential repository keyed by ID
+// Methods not overridden panic through the nil embedded interface
+type fakeCredentialRepo struct {
+	repository.Credential
+	credentials map[string]*model.AgentSupplierCredential
+	// onPage is called with each page returned by GetAllAfter, to change rows while they are processed
+	onPage func(page []*model.AgentSupplierCredential)
+}
+
+func (r *fakeCredentialRepo) GetByID(_ context.Context, id string) (*model.AgentSupplierCredential, error) {
+	cred, ok := r.credentials[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *cred
+	return &copied, nil
+}
+
+func (r *fakeCredentialRepo) GetAllAfter(_ context.Context, afterID string, limit int) ([]*model.AgentSupplierCredential, error) {
+	ids := make([]string, 0, len(r.credentials))
+	for id := range r.credentials {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	page := make([]*model.AgentSupplierCredential, 0, limit)
+	for _, id := range ids[:min(limit, len(ids))] {
+		copied := *r.credentials[id]
+		page = append(page, &copied)
+	}
+	if r.onPage != nil {
+		r.onPage(page)
+	}
+	return page, nil
+}
+
+func (r *fakeCredentialRepo) Rotate(_ context.Context, credential *model.AgentSupplierCredential, currentCiphertext string) error {
+	stored, ok := r.credentials[credential.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	if stored.Credentials != currentCiphertext {
+		return domain.ErrConflict
+	}
+	stored.Credentials = credential.Credentials
+	stored.PreviousCredentials = credential.PreviousCredentials
+	stored.PreviousExpiresAt = credential.PreviousExpiresAt
+	return nil
+}
+
+// newTestCredentialUseCase returns a usecase backed by an empty fake repository and the test keyring
+func newTestCredentialUseCase() (*credentialUseCase, *fakeCredentialRepo) {
+	repo := &fakeCredentialRepo{credentials: map[string]*model.AgentSupplierCredential{}}
+	uc := NewCredentialUseCase(repo, nil, logger.NoOpLogger(), newTestKeyring()).(*credentialUseCase)
+	return uc, repo
+}
+
+// ciphertextFormats returns plaintext encrypted for the given owner and supplier in each ciphertext version
+func ciphertextFormats(t *testing.T, keyring Keyring, plaintext, ownerID, supplierID string) map[int]string {
+	t.Helper()
+	current, err := keyring.Encrypt(plaintext, CredentialAAD(ownerID, supplierID))
+	require.NoError(t, err, "Encrypt should not return error")
+	return map[int]string{
+		CiphertextVersionLegacy: sealLegacy(t, testRetiredKey, plaintext),
+		CiphertextVersionKeyID:  "v1:" + sealLegacy(t, testRetiredKey, plaintext),
+		CiphertextVersionAAD:    current,
+	}
+}
+
+func TestCredentialUseCase_DecryptsEveryCiphertextVersion(t *testing.T) {
+	uc, repo := newTestCredentialUseCase()
+
+	for version, ciphertext := range ciphertextFormats(t, uc.keyring, "secret", "agent", "supplier") {
+		id := fmt.Sprintf("cred-%d", version)
+		repo.credentials[id] = &model.AgentSupplierCredential{ID: id, IataAgentID: "agent", SupplierID: "supplier", Credentials: ciphertext}
+
+		cred, err := uc.GetCredentialByID(context.Background(), "agent", id)
+		require.NoError(t, err, "Version %d should decrypt", version)
+		assert.Equal(t, "secret", cred.Credentials, "Unexpected plaintext for version %d", version)
+	}
+}
+
+func TestCredentialUseCase_ReencryptAll_UpgradesOlderVersions(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestCredentialUseCase()
+	formats := ciphertextFormats(t, uc.keyring, "secret", "agent", "supplier")
+	for _, version := range []int{CiphertextVersionLegacy, CiphertextVersionKeyID} {
+		id := fmt.Sprintf("cred-%d", version)
+		repo.credentials[id] = &model.AgentSupplierCredential{ID: id, IataAgentID: "agent", SupplierID: "supplier", Credentials: formats[version]}
+	}
+
+	result, err := uc.ReencryptAll(ctx)
+	require.NoError(t, err, "ReencryptAll should not return error")
+	assert.Equal(t, 2, result.Reencrypted, "Both rows should be re-encrypted")
+
+	for id, stored := range repo.credentials {
+		keyID, version, _ := parseCiphertext(stored.Credentials)
+		assert.Equal(t, CiphertextVersionAAD, version, "%s should be upgraded to version 2", id)
+		assert.Equal(t, uc.keyring.PrimaryID, keyID, "%s should use the primary key", id)
+
+		// Version 2 binds the ciphertext to its row, so it only decrypts with the row's associated data
+		_, err := uc.keyring.Decrypt(stored.Credentials, CredentialAAD("agent", "other-supplier"))
+		assert.ErrorIs(t, err, ErrCiphertextMismatch, "%s should be bound to its agent and supplier", id)
+	}
+}
+
+func TestCredentialUseCase_CiphertextMismatch(t *testing.T) {
+	uc, repo := newTestCredentialUseCase()
+	ciphertext, err := uc.keyring.Encrypt("secret", CredentialAAD("agent", "supplier"))
+	require.NoError(t, err, "Encrypt should not return error")
+
+	// The ciphertext is copied to rows of another supplier and another agent
+	repo.credentials["other-supplier"] = &model.AgentSupplierCredential{ID: "other-supplier", IataAgentID: "agent", SupplierID: "supplier-2", Credentials: ciphertext}
+	repo.credentials["other-agent"] = &model.AgentSupplierCredential{ID: "other-agent", IataAgentID: "agent-2", SupplierID: "supplier", Credentials: ciphertext}
+
+	_, err = uc.GetCredentialByID(context.Background(), "agent", "other-supplier")
+	assert.ErrorIs(t, err, ErrCiphertextMismatch, "A ciphertext of another supplier should not decrypt")
+
+	_, err = uc.GetCredentialByID(context.Background(), "agent-2", "other-agent")
+	assert.ErrorIs(t, err, ErrCiphertextMismatch, "A ciphertext of another agent should not decrypt")
+}
+
+func TestCredentialUseCase_ReencryptAll(t *testing.T) {
+	ctx := context.Background()
+	uc, repo := newTestCredentialUseCase()
+	live := time.Now().Add(time.Hour)
+	expired := time.Now().Add(-time.Hour)
+
+	// add stores a credential of agent and supplier "s-<id>" with current and previous values in the given versions
+	// A negative previousVersion stores no previous value
+	add := func(id string, version, previousVersion int, previousExpiresAt *time.Time) {
+		cred := &model.AgentSupplierCredential{ID: id, IataAgentID: "agent", SupplierID: "s-" + id}
+		cred.Credentials = ciphertextFormats(t, uc.keyring, "current-"+id, cred.IataAgentID, cred.SupplierID)[version]
+		if previousVersion >= 0 {
+			cred.PreviousCredentials = ciphertextFormats(t, uc.keyring, "previous-"+id, cred.IataAgentID, cred.SupplierID)[previousVersion]
+			cred.PreviousExpiresAt = previousExpiresAt
+		}
+		repo.credentials[id] = cred
+	}
+	add("a-legacy", CiphertextVersionLegacy, -1, nil)
+	add("b-key-id", CiphertextVersionKeyID, CiphertextVersionLegacy, &live)
+	add("c-up-to-date", CiphertextVersionAAD, -1, nil)
+	add("d-up-to-date-previous", CiphertextVersionAAD, CiphertextVersionAAD, &live)
+	add("e-expired-previous", CiphertextVersionAAD, CiphertextVersionKeyID, &expired)
+	add("f-conflict", CiphertextVersionLegacy, -1, nil)
+	add("g-corrupt", CiphertextVersionLegacy, -1, nil)
+	repo.credentials["g-corrupt"].Credentials = "not-a-ciphertext"
+
+	// A retired-key row encrypted with the latest format, as left by a previous primary key
+	retired := NewKeyring("v1", testRetiredKey, nil)
+	retiredCiphertext, err := retired.Encrypt("current-h-retired", CredentialAAD("agent", "s-h-retired"))
+	require.NoError(t, err, "Encrypt should not return error")
+	repo.credentials["h-retired"] = &model.AgentSupplierCredential{ID: "h-retired", IataAgentID: "agent", SupplierID: "s-h-retired", Credentials: retiredCiphertext}
+
+	// f-conflict is updated by another request after it is read
+	repo.onPage = func(page []*model.AgentSupplierCredential) {
+		for _, cred := range page {
+			if cred.ID == "f-conflict" {
+				repo.credentials[cred.ID].Credentials = ciphertextFormats(t, uc.keyring, "updated", "agent", "s-f-conflict")[CiphertextVersionAAD]
+			}
+		}
+	}
+
+	result, err := uc.ReencryptAll(ctx)
+	require.NoError(t, err, "ReencryptAll should not return error")
+	assert.Equal(t, 4, result.Reencrypted, "Legacy, key id, expired previous and retired rows should be re-encrypted")
+	assert.Equal(t, 2, result.Unchanged, "Up-to-date rows should be left alone")
+	assert.Equal(t, 1, result.Conflicts, "The concurrently updated row should be a conflict")
+	require.Len(t, result.Failures, 1, "The corrupt row should be reported")
+	assert.Equal(t, "g-corrupt", result.Failures[0].ID, "Unexpected failed row")
+
+	for _, id := range []string{"a-legacy", "b-key-id", "c-up-to-date", "d-up-to-date-previous", "e-expired-previous", "h-retired"} {
+		stored := repo.credentials[id]
+		assert.False(t, uc.keyring.NeedsReencryption(stored.Credentials), "%s should use the primary key and latest format", id)
+
+		cred, err := uc.GetCredentialByID(ctx, "agent", id)
+		require.NoError(t, err, "%s should decrypt", id)
+		assert.Equal(t, "current-"+id, cred.Credentials, "%s should keep its value", id)
+	}
+
+	t.Run("live previous value is re-encrypted", func(t *testing.T) {
+		stored := repo.credentials["b-key-id"]
+		assert.False(t, uc.keyring.NeedsReencryption(stored.PreviousCredentials), "The previous value should be re-encrypted")
+		require.NotNil(t, stored.PreviousExpiresAt, "The previous value should keep its expiry")
+		assert.True(t, live.Equal(*stored.PreviousExpiresAt), "The expiry should not change")
+
+		cred, err := uc.GetCredentialByID(ctx, "agent", "b-key-id")
+		require.NoError(t, err, "GetCredentialByID should not return error")
+		assert.Equal(t, "previous-b-key-id", cred.PreviousCredentials, "The previous value should stay readable")
+	})
+
+	t.Run("expired previous value is dropped", func(t *testing.T) {
+		stored := repo.credentials["e-expired-previous"]
+		assert.Empty(t, stored.PreviousCredentials, "The expired previous value should be dropped")
+		assert.Nil(t, stored.PreviousExpiresAt, "The expiry should be cleared")
+	})
+
+	t.Run("conflicting row keeps the concurrent update", func(t *testing.T) {
+		cred, err := uc.GetCredentialByID(ctx, "agent", "f-conflict")
+		require.NoError(t, err, "GetCredentialByID should not return error")
+		assert.Equal(t, "updated", cred.Credentials, "The concurrent update should not be overwritten")
+	})
+
+	t.Run("a second run has nothing left to do", func(t *testing.T) {
+		repo.onPage = nil
+		delete(repo.credentials, "g-corrupt")
+
+		result, err := uc.ReencryptAll(ctx)
+		require.NoError(t, err, "ReencryptAll should not return error")
+		assert.Zero(t, result.Reencrypted, "Nothing should be left to re-encrypt")
+		assert.Equal(t, len(repo.credentials), result.Unchanged, "Every row should be up to date")
+	})
+}
+
+func TestCredentialUseCase_ReencryptAll_ConcurrentDeletes(t *testing.T) {
+	uc, repo := newTestCredentialUseCase()
+	total := reencryptBatchSize + reencryptBatchSize/2
+	ids := make([]string, total)
+	for i := range ids {
+		ids[i] = time.Unix(int64(i), 0).UTC().Format("20060102150405")
+		repo.credentials[ids[i]] = &model.AgentSupplierCredential{
+			ID:          ids[i],
+			IataAgentID: "agent",
+			SupplierID:  "supplier",
+			Credentials: sealLegacy(t, testRetiredKey, "secret"),
+		}
+	}
+
+	// Rows of the first page are deleted once it is read, which would shift an offset-based second page
+	deleted := 0
+	repo.onPage = func(page []*model.AgentSupplierCredential) {
+		if deleted > 0 {
+			return
+		}
+		for _, cred := range page[:10] {
+			delete(repo.credentials, cred.ID)
+			deleted++
+		}
+	}
+
+	result, err := uc.ReencryptAll(context.Background())
+	require.NoError(t, err, "ReencryptAll should not return error")
+	assert.Equal(t, total-deleted, result.Reencrypted, "Every remaining row should be re-encrypted")
+	assert.Equal(t, deleted, result.Conflicts, "Deleted rows should be reported as conflicts")
+	for id, cred := range repo.credentials {
+		assert.False(t, uc.keyring.NeedsReencryption(cred.Credentials), "%s should not be skipped", id)
+	}
+}
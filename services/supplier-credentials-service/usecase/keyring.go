@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCiphertextMismatch is returned when a ciphertext bound to associated data is opened with different data,
+// such as a credential copied to another agent-supplier row, or when it was tampered with
+var ErrCiphertextMismatch = errors.New("ciphertext does not match its associated data")
+
+// Ciphertext format versions
+// Versions are only written into the ciphertext from CiphertextVersionAAD on
+const (
+	// CiphertextVersionLegacy is a bare base64 ciphertext without key id or associated data
+	CiphertextVersionLegacy = 0
+	// CiphertextVersionKeyID is "<key id>:<base64>", without associated data
+	CiphertextVersionKeyID = 1
+	// CiphertextVersionAAD is "<key id>:2:<base64>", bound to associated data
+	CiphertextVersionAAD = 2
+)
+
+// errAuthentication is returned by open when a ciphertext fails GCM authentication
+var errAuthentication = errors.New("cipher: message authentication failed")
+
+// ciphertextSeparator separates the parts of a ciphertext; it never occurs in standard base64
+const ciphertextSeparator = ":"
+
+// Keyring holds the AES-256 keys used to encrypt credentials
+// New ciphertexts are encrypted with the primary key, bound to associated data and written as "<id>:2:<base64>"
+// Older ciphertexts are still decrypted: "<id>:<base64>" without associated data, and bare base64 written
+// before key ids were introduced, on which every key is tried
+type Keyring struct {
+	// PrimaryID identifies the key used for new encryptions
+	PrimaryID string
+	// Keys maps key ids to 32-byte keys, including the primary key and retired keys still needed for decryption
+	Keys map[string]string
+}
+
+// NewKeyring creates a keyring with a primary key and the retired keys still needed for decryption
+func NewKeyring(primaryID, primaryKey string, previousKeys map[string]string) Keyring {
+	keys := make(map[string]string, len(previousKeys)+1)
+	for id, key := range previousKeys {
+		keys[id] = key
+	}
+	keys[primaryID] = primaryKey
+	return Keyring{PrimaryID: primaryID, Keys: keys}
+}
+
+// parseCiphertext splits a ciphertext into its key id, format version and base64 payload
+func parseCiphertext(ciphertext string) (id string, version int, encoded string) {
+	id, rest, found := strings.Cut(ciphertext, ciphertextSeparator)
+	if !found {
+		return "", CiphertextVersionLegacy, ciphertext
+	}
+	if v, payload, found := strings.Cut(rest, ciphertextSeparator); found && v == "2" {
+		return id, CiphertextVersionAAD, payload
+	}
+	return id, CiphertextVersionKeyID, rest
+}
+
+// KeyID returns the id of the key that encrypted ciphertext, or an empty string for legacy ciphertexts
+func KeyID(ciphertext string) string {
+	id, _, _ := parseCiphertext(ciphertext)
+	return id
+}
+
+// NeedsReencryption reports whether ciphertext was encrypted with a retired key or an older format
+func (k Keyring) NeedsReencryption(ciphertext string) bool {
+	id, version, _ := parseCiphertext(ciphertext)
+	return id != k.PrimaryID || version != CiphertextVersionAAD
+}
+
+// gcm returns an AES-GCM cipher for the key with the given id
+func (k Keyring) gcm(id string) (cipher.AEAD, error) {
+	key, ok := k.Keys[id]
+	if !ok || key == "" {
+		return nil, fmt.Errorf("encryption key %q not set", id)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key %q must be 32 bytes", id)
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts plaintext with the primary key using AES-GCM, bound to the associated data aad
+// The same aad must be passed to Decrypt
+func (k Keyring) Encrypt(plaintext string, aad []byte) (string, error) {
+	if k.PrimaryID == "" {
+		return "", errors.New("encryption key not set")
+	}
+
+	gcm, err := k.gcm(k.PrimaryID)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), aad)
+	return k.PrimaryID + ciphertextSeparator + "2" + ciphertextSeparator + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts ciphertext with the key it was encrypted with
+// aad is verified for ciphertexts bound to associated data and ignored for older formats
+// Returns ErrCiphertextMismatch if aad does not match the one used for encryption
+func (k Keyring) Decrypt(ciphertext string, aad []byte) (string, error) {
+	id, version, encoded := parseCiphertext(ciphertext)
+	switch version {
+	case CiphertextVersionAAD:
+		plaintext, err := k.open(id, encoded, aad)
+		if errors.Is(err, errAuthentication) {
+			return "", ErrCiphertextMismatch
+		}
+		return plaintext, err
+	case CiphertextVersionKeyID:
+		return k.open(id, encoded, nil)
+	}
+
+	// Legacy ciphertexts predate key ids; try the primary key first, then the retired ones
+	if len(k.Keys) == 0 {
+		return "", errors.New("encryption key not set")
+	}
+	plaintext, err := k.open(k.PrimaryID, encoded, nil)
+	if err == nil {
+		return plaintext, nil
+	}
+	for id := range k.Keys {
+		if id == k.PrimaryID {
+			continue
+		}
+		if plaintext, openErr := k.open(id, encoded, nil); openErr == nil {
+			return plaintext, nil
+		}
+	}
+	return "", err
+}
+
+// open decrypts a base64 ciphertext with the key with the given id
+func (k Keyring) open(id, encoded string, aad []byte) (string, error) {
+	gcm, err := k.gcm(id)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, aad)
+	if err != nil {
+		// GCM does not tell a wrong key from tampered data or associated data
+		return "", errAuthentication
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPrimaryKey = "0123456789abcdef0123456789abcdef"
+	testRetiredKey = "fedcba9876543210fedcba9876543210"
+)
+
+// newTestKeyring returns a keyring with primary key "v2" and retired key "v1"
+func newTestKeyring() Keyring {
+	return NewKeyring("v2", testPrimaryKey, map[string]string{"v1": testRetiredKey})
+}
+
+// sealLegacy encrypts plaintext with key as a bare base64 ciphertext without associated data,
+// the format written before key ids were introduced
+func sealLegacy(t *testing.T, key, plaintext string) string {
+	t.Helper()
+	block, err := aes.NewCipher([]byte(key))
+	require.NoError(t, err, "NewCipher should not return error")
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err, "NewGCM should not return error")
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err, "Failed to generate nonce")
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil))
+}
+
+func TestParseCiphertext(t *testing.T) {
+	tests := []struct {
+		ciphertext  string
+		wantID      string
+		wantVersion int
+		wantEncoded string
+	}{
+		{ciphertext: "v2:2:c2VjcmV0", wantID: "v2", wantVersion: CiphertextVersionAAD, wantEncoded: "c2VjcmV0"},
+		{ciphertext: "v1:c2VjcmV0", wantID: "v1", wantVersion: CiphertextVersionKeyID, wantEncoded: "c2VjcmV0"},
+		{ciphertext: "c2VjcmV0", wantID: "", wantVersion: CiphertextVersionLegacy, wantEncoded: "c2VjcmV0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ciphertext, func(t *testing.T) {
+			id, version, encoded := parseCiphertext(tt.ciphertext)
+			assert.Equal(t, tt.wantID, id, "Unexpected key id")
+			assert.Equal(t, tt.wantVersion, version, "Unexpected version")
+			assert.Equal(t, tt.wantEncoded, encoded, "Unexpected payload")
+			assert.Equal(t, tt.wantID, KeyID(tt.ciphertext), "KeyID should match the parsed id")
+		})
+	}
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	keyring := newTestKeyring()
+	aad := []byte("agent|supplier")
+
+	ciphertext, err := keyring.Encrypt("secret", aad)
+	require.NoError(t, err, "Encrypt should not return error")
+	id, version, _ := parseCiphertext(ciphertext)
+	assert.Equal(t, "v2", id, "New ciphertexts should use the primary key")
+	assert.Equal(t, CiphertextVersionAAD, version, "New ciphertexts should be bound to associated data")
+
+	plaintext, err := keyring.Decrypt(ciphertext, aad)
+	require.NoError(t, err, "Decrypt should not return error")
+	assert.Equal(t, "secret", plaintext, "Unexpected plaintext")
+
+	_, err = keyring.Decrypt(ciphertext, []byte("other-agent|supplier"))
+	assert.ErrorIs(t, err, ErrCiphertextMismatch, "Other associated data should not decrypt")
+
+	tampered := ciphertext[:len(ciphertext)-4] + "AAAA"
+	_, err = keyring.Decrypt(tampered, aad)
+	assert.ErrorIs(t, err, ErrCiphertextMismatch, "A tampered ciphertext should not decrypt")
+}
+
+func TestKeyring_DecryptOlderFormats(t *testing.T) {
+	keyring := newTestKeyring()
+
+	t.Run("key id without associated data", func(t *testing.T) {
+		plaintext, err := keyring.Decrypt("v1:"+sealLegacy(t, testRetiredKey, "secret"), []byte("ignored"))
+		require.NoError(t, err, "Decrypt should not return error")
+		assert.Equal(t, "secret", plaintext, "Unexpected plaintext")
+	})
+
+	for name, key := range map[string]string{"primary": testPrimaryKey, "retired": testRetiredKey} {
+		t.Run("legacy encrypted with the "+name+" key", func(t *testing.T) {
+			plaintext, err := keyring.Decrypt(sealLegacy(t, key, "secret"), nil)
+			require.NoError(t, err, "Every key should be tried on legacy ciphertexts")
+			assert.Equal(t, "secret", plaintext, "Unexpected plaintext")
+		})
+	}
+
+	t.Run("legacy encrypted with an unknown key", func(t *testing.T) {
+		_, err := keyring.Decrypt(sealLegacy(t, "00000000000000000000000000000000", "secret"), nil)
+		assert.Error(t, err, "No configured key should decrypt it")
+	})
+}
+
+func TestKeyring_UnknownAndRetiredKeys(t *testing.T) {
+	retiredCiphertext, err := newTestKeyring().Encrypt("secret", nil)
+	require.NoError(t, err, "Encrypt should not return error")
+
+	t.Run("retired key still configured", func(t *testing.T) {
+		// The old primary key is kept as a retired key after rotating to a new one
+		keyring := NewKeyring("v3", "abcdefghijklmnopqrstuvwxyz012345", map[string]string{"v2": testPrimaryKey})
+		plaintext, err := keyring.Decrypt(retiredCiphertext, nil)
+		require.NoError(t, err, "A retired key should still decrypt")
+		assert.Equal(t, "secret", plaintext, "Unexpected plaintext")
+	})
+
+	t.Run("retired key removed", func(t *testing.T) {
+		keyring := NewKeyring("v3", "abcdefghijklmnopqrstuvwxyz012345", nil)
+		_, err := keyring.Decrypt(retiredCiphertext, nil)
+		assert.ErrorContains(t, err, `encryption key "v2" not set`, "A removed key should be named")
+	})
+
+	t.Run("unknown key id", func(t *testing.T) {
+		_, err := newTestKeyring().Decrypt("v9:"+sealLegacy(t, testPrimaryKey, "secret"), nil)
+		assert.ErrorContains(t, err, `encryption key "v9" not set`, "An unknown key should be named")
+	})
+}
+
+func TestKeyring_NeedsReencryption(t *testing.T) {
+	keyring := newTestKeyring()
+	current, err := keyring.Encrypt("secret", nil)
+	require.NoError(t, err, "Encrypt should not return error")
+
+	tests := []struct {
+		name       string
+		ciphertext string
+		want       bool
+	}{
+		{name: "primary key and latest format", ciphertext: current, want: false},
+		{name: "retired key and latest format", ciphertext: "v1:2:c2VjcmV0", want: true},
+		{name: "primary key without associated data", ciphertext: "v2:c2VjcmV0", want: true},
+		{name: "legacy", ciphertext: "c2VjcmV0", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, keyring.NeedsReencryption(tt.ciphertext), "Unexpected NeedsReencryption")
+		})
+	}
+}
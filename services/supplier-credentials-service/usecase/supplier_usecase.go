@@ -18,8 +18,8 @@ type SupplierUseCase interface {
 	CreateSupplier(ctx context.Context, supplier *model.Supplier) error
 	// UpdateSupplier modifies an existing supplier
 	UpdateSupplier(ctx context.Context, supplier *model.Supplier) error
-	// DeleteSupplier removes a supplier
-	DeleteSupplier(ctx context.Context, id string) error
+	// DeleteSupplier removes a supplier; force also removes the credentials referencing it
+	DeleteSupplier(ctx context.Context, id string, force bool) error
 	// ListSuppliers retrieves a paginated list of suppliers
 	ListSuppliers(ctx context.Context, offset, limit int) ([]*model.Supplier, int, error)
 	// GetSupplierByID retrieves a supplier by ID
@@ -133,6 +133,7 @@ func (uc *supplierUseCase) UpdateSupplier(ctx context.Context, supplier *model.S
 // ListSuppliers returns a paginated list of suppliers
 func (uc *supplierUseCase) ListSuppliers(ctx context.Context, offset, limit int) ([]*model.Supplier, int, error) {
 	uc.logger.InfoContext(ctx, "Listing suppliers in usecase", "offset", offset, "limit", limit)
+	offset, limit = normalizePage(offset, limit)
 
 	suppliers, total, err := uc.supplierRepo.List(ctx, offset, limit)
 	if err != nil {
@@ -163,27 +164,44 @@ func (uc *supplierUseCase) GetSupplierByID(ctx context.Context, id string) (*mod
 }
 
 // DeleteSupplier deletes a supplier
-func (uc *supplierUseCase) DeleteSupplier(ctx context.Context, id string) error {
-	uc.logger.InfoContext(ctx, "Deleting supplier in usecase", "id", id)
+// A supplier still referenced by credentials is only deleted with force, which deletes the credentials too
+func (uc *supplierUseCase) DeleteSupplier(ctx context.Context, id string, force bool) error {
+	log := uc.logger.With("id", id, "force", force)
+	log.InfoContext(ctx, "Deleting supplier in usecase")
 
 	// Check if supplier exists first
 	_, err := uc.supplierRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			uc.logger.WarnContext(ctx, "Supplier not found for deletion", "id", id)
+			log.WarnContext(ctx, "Supplier not found for deletion")
 			return domain.ErrSupplierNotFound
 		}
-		uc.logger.ErrorContext(ctx, "Error checking supplier existence before deletion", "id", id, "error", err)
+		log.ErrorContext(ctx, "Error checking supplier existence before deletion", "error", err)
 		return fmt.Errorf("error checking supplier existence: %w", err)
 	}
 
-	// Delete the supplier
-	err = uc.supplierRepo.Delete(ctx, id)
+	// The repository checks for credentials in the same transaction as the delete,
+	// so none can be added in between and be orphaned
+	var count int
+	if force {
+		count, err = uc.supplierRepo.DeleteWithCredentials(ctx, id)
+	} else {
+		count, err = uc.supplierRepo.DeleteUnreferenced(ctx, id)
+	}
 	if err != nil {
-		uc.logger.ErrorContext(ctx, "Error deleting supplier", "id", id, "error", err)
+		if errors.Is(err, domain.ErrNotFound) {
+			log.WarnContext(ctx, "Supplier not found for deletion")
+			return domain.ErrSupplierNotFound
+		}
+		log.ErrorContext(ctx, "Error deleting supplier", "error", err)
 		return fmt.Errorf("error deleting supplier: %w", err)
 	}
 
-	uc.logger.InfoContext(ctx, "Supplier deleted successfully in usecase", "id", id)
+	if !force && count > 0 {
+		log.WarnContext(ctx, "Cannot delete supplier with credentials", "credentials_count", count)
+		return domain.ErrSupplierHasCredentials
+	}
+
+	log.InfoContext(ctx, "Supplier deleted successfully in usecase", "credentials_deleted", count)
 	return nil
 }